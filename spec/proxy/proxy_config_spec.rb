@@ -54,7 +54,7 @@ describe 'proxy.yml.erb configuration template' do
       "api_password" => "random-switchboard-password",
       "healthcheck_timeout_millis" => 12345,
       "api_uri" => "proxy.some-platform.domain",
-      "api_tls" => { "enabled" => true, "certificate" => "proxy-api-cert", "private_key" => "proxy-api-private-key" }
+      "api_tls" => { "enabled" => true, "certificate" => "proxy-api-cert", "private_key" => "proxy-api-private-key", "ca" => "proxy-api-ca" }
     }
   }
   let(:parsed_config) {
@@ -74,6 +74,7 @@ describe 'proxy.yml.erb configuration template' do
           "Enabled" => true,
           "Certificate" => "proxy-api-cert",
           "PrivateKey" => "proxy-api-private-key",
+          "CA" => "proxy-api-ca",
         },
       },
 
@@ -85,9 +86,30 @@ describe 'proxy.yml.erb configuration template' do
           { "Host" => "mysql1-address", "Name" => "mysql/mysql1-uuid", "Port" => 6033, "StatusEndpoint" => "api/v1/status", "StatusPort" => "9201" },
           { "Host" => "mysql2-address", "Name" => "mysql/mysql2-uuid", "Port" => 6033, "StatusEndpoint" => "api/v1/status", "StatusPort" => "9201" },
         ],
+        "Sockets" => {
+          "DialTimeoutMillis" => 5000,
+          "KeepAliveSeconds" => 0,
+          "NoDelay" => true,
+          "SendBufferBytes" => 0,
+          "ReceiveBufferBytes" => 0,
+          "IdleTimeoutSeconds" => 0,
+        },
       },
       "HealthPort" => 1936,
-      "StaticDir" => '/var/vcap/packages/proxy/static',
+      "TrafficState" => {
+        "Path" => '/var/vcap/data/proxy/traffic-state.json',
+        "OnStartup" => "restore",
+      },
+      "AccessLog" => {
+        "Enabled" => false,
+        "Path" => '/var/vcap/sys/log/proxy/access.log',
+        "MaxSizeMB" => 100,
+        "MaxBackups" => 5,
+      },
+      "Dashboard" => {
+        "SampleIntervalMillis" => 10000,
+        "Samples" => 360,
+      },
       "GaleraAgentTLS" => {
         "Enabled" => true,
         "CA" => "PEM Cert",
@@ -156,4 +178,436 @@ describe 'proxy.yml.erb configuration template' do
       expect(parsed_config["Proxy"]).to include("InactiveMysqlPort" => 3307)
     end
   end
+
+  context 'when inactive_wsrep_sync_wait is configured' do
+    before(:each) do
+      spec["inactive_mysql_port"] = 3307
+      spec["inactive_wsrep_sync_wait"] = 1
+    end
+
+    it 'sets wsrep_sync_wait on sessions through the inactive port' do
+      expect(parsed_config["Proxy"]).to include("InactiveWsrepSyncWait" => 1, "HandshakeTimeoutMillis" => 10000)
+    end
+
+    it 'only sets it on clusters with an inactive port' do
+      spec["clusters"] = [
+        { "name" => "orders", "port" => 4306, "backends" => [{ "name" => "orders/0", "host" => "10.0.16.10" }] },
+        { "name" => "billing", "port" => 5306, "inactive_mysql_port" => 5307, "backends" => [{ "name" => "billing/0", "host" => "10.0.17.10" }] },
+      ]
+
+      expect(parsed_config["Clusters"][0]["Proxy"]).to_not have_key("InactiveWsrepSyncWait")
+      expect(parsed_config["Clusters"][1]["Proxy"]).to include("InactiveWsrepSyncWait" => 1, "HandshakeTimeoutMillis" => 10000)
+    end
+
+    it 'is ignored without an inactive port' do
+      spec.delete("inactive_mysql_port")
+      expect(parsed_config["Proxy"]).to_not have_key("InactiveWsrepSyncWait")
+    end
+  end
+
+  context 'when source filters are configured' do
+    before(:each) do
+      spec["source_filter"] = { "deny" => ["10.1.0.0/16"] }
+      spec["inactive_source_filter"] = { "allow" => ["10.2.0.0/24"] }
+    end
+
+    it 'configures the source filter for each listener' do
+      expect(parsed_config["Proxy"]).to include(
+        "SourceFilter" => { "Allow" => [], "Deny" => ["10.1.0.0/16"] },
+        "InactiveSourceFilter" => { "Allow" => ["10.2.0.0/24"], "Deny" => [] },
+      )
+    end
+  end
+
+  context 'when further clusters are configured' do
+    before(:each) do
+      spec["circuit_breaker"] = { "dial_failures" => 3 }
+      spec["inactive_mysql_port"] = 3307
+      spec["clusters"] = [
+        {
+          "name" => "orders",
+          "port" => 4306,
+          "backends" => [
+            { "name" => "orders/0", "host" => "10.0.16.10" },
+            { "name" => "orders/1", "host" => "10.0.16.11", "port" => 6033, "status_port" => 9201 },
+          ],
+          "galera_agent_tls" => { "enabled" => true, "ca" => "orders CA", "server_name" => "orders agent" },
+        },
+      ]
+    end
+
+    it 'configures each cluster with its own listeners, backends and traffic state' do
+      expect(parsed_config["Clusters"].length).to eq(1)
+      cluster = parsed_config["Clusters"][0]
+
+      expect(cluster["Name"]).to eq("orders")
+      expect(cluster["Proxy"]).to include(
+        "Port" => 4306,
+        "HealthcheckTimeoutMillis" => 12345,
+        "CircuitBreaker" => { "DialFailures" => 3, "WindowMillis" => 10000 },
+        "Backends" => [
+          { "Host" => "10.0.16.10", "Name" => "orders/0", "Port" => 3306, "StatusEndpoint" => "api/v1/status", "StatusPort" => 9200 },
+          { "Host" => "10.0.16.11", "Name" => "orders/1", "Port" => 6033, "StatusEndpoint" => "api/v1/status", "StatusPort" => 9201 },
+        ],
+      )
+      expect(cluster["Proxy"]).to_not have_key("InactiveMysqlPort")
+      expect(cluster["TrafficState"]).to eq(
+        "Path" => "/var/vcap/data/proxy/traffic-state-orders.json",
+        "OnStartup" => "restore",
+      )
+      expect(cluster["GaleraAgentTLS"]).to eq(
+        "Enabled" => true,
+        "CA" => "orders CA",
+        "ServerName" => "orders agent",
+      )
+    end
+
+    it 'does not change the default cluster' do
+      expect(parsed_config["Proxy"]).to include("Port" => 3306, "InactiveMysqlPort" => 3307)
+    end
+  end
+
+  context 'when routing rules are configured' do
+    before(:each) do
+      spec["routing"] = {
+        "rules" => [
+          { "user" => "reporting", "pool" => "readers" },
+          { "schema" => "batch_*", "backend" => "mysql/1" },
+          { "attributes" => { "program_name" => "etl-*" }, "pool" => "readers" },
+        ],
+      }
+    end
+
+    it 'configures handshake routing on the proxy port' do
+      expect(parsed_config["Proxy"]["Routing"]).to eq(
+        "Rules" => [
+          { "User" => "reporting", "Pool" => "readers" },
+          { "Schema" => "batch_*", "Backend" => "mysql/1" },
+          { "Attributes" => { "program_name" => "etl-*" }, "Pool" => "readers" },
+        ],
+      )
+      expect(parsed_config["Proxy"]["HandshakeTimeoutMillis"]).to eq(10000)
+    end
+
+    context 'and further clusters are configured' do
+      before(:each) do
+        spec["clusters"] = [
+          { "name" => "orders", "port" => 4306, "backends" => [{ "name" => "orders/0", "host" => "10.0.16.10" }] },
+          {
+            "name" => "billing",
+            "port" => 4406,
+            "backends" => [{ "name" => "billing/0", "host" => "10.0.17.10" }],
+            "routing_rules" => [{ "user" => "invoices", "backend" => "billing/0" }],
+          },
+        ]
+      end
+
+      it 'only applies each cluster its own rules' do
+        expect(parsed_config["Clusters"][0]["Proxy"]).to_not have_key("Routing")
+        expect(parsed_config["Clusters"][1]["Proxy"]["Routing"]).to eq(
+          "Rules" => [{ "User" => "invoices", "Backend" => "billing/0" }],
+        )
+      end
+    end
+  end
+
+  context 'when user quotas are configured' do
+    before(:each) do
+      spec["user_quotas"] = { "default_max_sessions" => 50, "users" => { "reporting" => 10, "admin" => 0 } }
+    end
+
+    it 'limits the sessions of each user' do
+      expect(parsed_config["Proxy"]["UserQuotas"]).to eq(
+        "DefaultMaxSessions" => 50,
+        "Users" => { "reporting" => 10, "admin" => 0 },
+        "RefuseTLS" => false,
+      )
+      expect(parsed_config["Proxy"]["HandshakeTimeoutMillis"]).to eq(10000)
+    end
+
+    context 'and TLS is refused' do
+      before(:each) do
+        spec["user_quotas"]["refuse_tls"] = true
+      end
+
+      it 'stops offering TLS to clients' do
+        expect(parsed_config["Proxy"]["UserQuotas"]["RefuseTLS"]).to eq(true)
+      end
+    end
+  end
+
+  context 'when user quotas are not configured' do
+    it 'does not limit sessions by user' do
+      expect(parsed_config["Proxy"]).to_not have_key("UserQuotas")
+      expect(parsed_config["Proxy"]).to_not have_key("HandshakeTimeoutMillis")
+    end
+  end
+
+  context 'when reconnect users are configured' do
+    before(:each) do
+      spec["reconnect"] = { "users" => { "app" => "app-password" } }
+    end
+
+    it 'moves their idle sessions on failover' do
+      expect(parsed_config["Proxy"]["Reconnect"]).to eq(
+        "Users" => { "app" => "app-password" },
+        "TimeoutMillis" => 5000,
+      )
+      expect(parsed_config["Proxy"]["HandshakeTimeoutMillis"]).to eq(10000)
+    end
+  end
+
+  context 'when reconnect users are not configured' do
+    it 'closes sessions on failover' do
+      expect(parsed_config["Proxy"]).to_not have_key("Reconnect")
+    end
+  end
+
+  context 'when discovery.name is set' do
+    before(:each) do
+      spec["discovery"] = { "name" => "q-s0.mysql.default.cf.bosh", "type" => "SRV" }
+      spec["clusters"] = [
+        { "name" => "orders", "port" => 4306, "backends" => [{ "name" => "orders/0", "host" => "10.0.16.10" }] },
+      ]
+    end
+
+    it 'finds the backends of the default cluster in DNS' do
+      expect(parsed_config["Proxy"]).to_not have_key("Backends")
+      expect(parsed_config["Proxy"]["Discovery"]).to eq(
+        "Name" => "q-s0.mysql.default.cf.bosh",
+        "Type" => "SRV",
+        "Port" => 6033,
+        "StatusPort" => "9201",
+        "StatusEndpoint" => "api/v1/status",
+        "IntervalMillis" => 10000,
+        "Server" => "",
+      )
+    end
+
+    it 'keeps the backends of further clusters' do
+      expect(parsed_config["Clusters"][0]["Proxy"]).to_not have_key("Discovery")
+      expect(parsed_config["Clusters"][0]["Proxy"]["Backends"].length).to eq(1)
+    end
+  end
+
+  context 'when discovery.name is not set' do
+    it 'uses the instances of the mysql link' do
+      expect(parsed_config["Proxy"]).to_not have_key("Discovery")
+      expect(parsed_config["Proxy"]["Backends"].length).to eq(3)
+    end
+  end
+
+  context 'when bind addresses are set' do
+    before(:each) do
+      spec["bind_addresses"] = ["0.0.0.0", "::"]
+      spec["api_bind_addresses"] = ["10.0.0.5"]
+      spec["health_bind_addresses"] = ["127.0.0.1", "::1"]
+      spec["clusters"] = [
+        { "name" => "orders", "port" => 4306, "backends" => [{ "name" => "orders/0", "host" => "10.0.16.10" }] },
+      ]
+    end
+
+    it 'binds each listener to its addresses' do
+      expect(parsed_config["Proxy"]["BindAddresses"]).to eq(["0.0.0.0", "::"])
+      expect(parsed_config["API"]["BindAddresses"]).to eq(["10.0.0.5"])
+      expect(parsed_config["HealthBindAddresses"]).to eq(["127.0.0.1", "::1"])
+      expect(parsed_config["Clusters"][0]["Proxy"]["BindAddresses"]).to eq(["0.0.0.0", "::"])
+    end
+  end
+
+  context 'when bind addresses are not set' do
+    it 'listens on every address' do
+      expect(parsed_config["Proxy"]).to_not have_key("BindAddresses")
+      expect(parsed_config["API"]).to_not have_key("BindAddresses")
+      expect(parsed_config).to_not have_key("HealthBindAddresses")
+    end
+  end
+
+  context 'when unix_socket.enabled is true' do
+    before(:each) do
+      spec["unix_socket"] = { "enabled" => true }
+      spec["inactive_mysql_port"] = 3307
+      spec["clusters"] = [
+        { "name" => "orders", "port" => 4306, "inactive_mysql_port" => 4307, "backends" => [{ "name" => "orders/0", "host" => "10.0.16.10" }] },
+      ]
+    end
+
+    it 'listens on Unix domain sockets for the default cluster' do
+      expect(parsed_config["Proxy"]["UnixSocket"]).to eq("/var/vcap/data/proxy/mysql.sock")
+      expect(parsed_config["Proxy"]["InactiveUnixSocket"]).to eq("/var/vcap/data/proxy/mysql-inactive.sock")
+      expect(parsed_config["Clusters"][0]["Proxy"]).to_not have_key("UnixSocket")
+      expect(parsed_config["Clusters"][0]["Proxy"]).to_not have_key("InactiveUnixSocket")
+    end
+  end
+
+  context 'when diagnostics.enabled is true' do
+    before(:each) do
+      spec["diagnostics"] = { "enabled" => true, "password" => "diagnostics-password", "bind_addresses" => ["127.0.0.1", "::1"] }
+    end
+
+    it 'serves diagnostics' do
+      expect(parsed_config["Diagnostics"]).to eq(
+        "Enabled" => true,
+        "Port" => 8083,
+        "Username" => "diagnostics",
+        "Password" => "diagnostics-password",
+        "BindAddresses" => ["127.0.0.1", "::1"],
+      )
+    end
+  end
+
+  context 'when diagnostics.enabled is false' do
+    it 'does not serve diagnostics' do
+      expect(parsed_config).to_not have_key("Diagnostics")
+    end
+  end
+
+  context 'when webhooks.urls are set' do
+    before(:each) do
+      spec["webhooks"] = { "urls" => ["https://hooks.example.com/switchboard"], "secret" => "hook-secret" }
+    end
+
+    it 'sends events to the webhooks' do
+      expect(parsed_config["Webhooks"]).to eq(
+        "URLs" => ["https://hooks.example.com/switchboard"],
+        "Secret" => "hook-secret",
+        "QueueSize" => 100,
+        "MaxAttempts" => 5,
+        "RetryMillis" => 1000,
+        "TimeoutMillis" => 5000,
+      )
+    end
+  end
+
+  context 'when webhooks.urls are not set' do
+    it 'sends no events' do
+      expect(parsed_config).to_not have_key("Webhooks")
+    end
+  end
+
+  context 'when the circuit breaker is enabled' do
+    before(:each) { spec["circuit_breaker"] = { "dial_failures" => 3 } }
+
+    it 'configures the circuit breaker' do
+      expect(parsed_config["Proxy"]).to include("CircuitBreaker" => { "DialFailures" => 3, "WindowMillis" => 10000 })
+    end
+  end
+
+  context 'when writer fencing is enabled' do
+    before(:each) { spec["writer_fencing"] = { "enabled" => true } }
+
+    context 'and galera-agent allows fencing' do
+      let(:galera_agent_link) {
+        Bosh::Template::Test::Link.new(
+          name: 'galera-agent',
+          properties: {
+            "port" => "9201",
+            "endpoint_tls" => { "enabled" => false },
+            "endpoint_username" => "galera-agent",
+            "endpoint_password" => "galera-agent-password",
+            "writer_fencing" => { "enabled" => true },
+          }
+        )
+      }
+
+      it 'configures fencing with the galera-agent credentials' do
+        expect(parsed_config["WriterFencing"]).to eq(
+          "Enabled" => true,
+          "Username" => "galera-agent",
+          "Password" => "galera-agent-password",
+          "TimeoutMillis" => 5000,
+        )
+      end
+    end
+
+    context 'and galera-agent does not allow fencing' do
+      it 'fails to render' do
+        expect { parsed_config }.to raise_error(/requires writer_fencing.enabled on the galera-agent job/)
+      end
+    end
+  end
+
+  context 'when flap damping is enabled' do
+    before(:each) { spec["flap_damping"] = { "flaps" => 4 } }
+
+    it 'configures flap damping' do
+      expect(parsed_config["Proxy"]).to include("FlapDamping" => {
+        "Flaps" => 4,
+        "WindowMillis" => 300000,
+        "QuarantineMillis" => 60000,
+        "MaxQuarantineMillis" => 3600000,
+      })
+    end
+  end
+
+  context 'when locality.prefer_local_readers is true' do
+    before(:each) { spec["locality"] = { "prefer_local_readers" => true } }
+
+    it 'labels the proxy and the backends with their availability zones' do
+      expect(parsed_config["Locality"]).to include("ZoneLabel" => "az", "PreferLocalReaders" => true, "PrimaryZone" => "")
+      expect(parsed_config["Locality"]["Labels"]).to include("az")
+      expect(parsed_config["Proxy"]["Backends"]).to all(include("Labels" => include("az")))
+    end
+
+    context 'and locality.zone_label is set' do
+      before(:each) { spec["locality"]["zone_label"] = "rack" }
+
+      it 'records the zones under that label' do
+        expect(parsed_config["Locality"]).to include("ZoneLabel" => "rack")
+        expect(parsed_config["Locality"]["Labels"]).to include("rack")
+        expect(parsed_config["Proxy"]["Backends"]).to all(include("Labels" => include("rack")))
+      end
+    end
+  end
+
+  context 'when admission limits are configured' do
+    before(:each) { spec["admission"] = { "max_sessions" => 500, "connections_per_second" => 50, "queue_timeout_millis" => 250 } }
+
+    it 'limits both proxy listeners' do
+      expected_admission = {
+        "MaxSessions" => 500,
+        "MaxSessionsPerClient" => 0,
+        "ConnectionsPerSecond" => 50,
+        "Burst" => 0,
+        "QueueTimeoutMillis" => 250,
+      }
+      expect(parsed_config["Proxy"]).to include("Admission" => expected_admission, "InactiveAdmission" => expected_admission)
+    end
+  end
+
+  context 'when traffic_state.on_startup is reset' do
+    before(:each) { spec["traffic_state"] = { "on_startup" => "reset" } }
+
+    it 'configures the proxy to reset the traffic state on startup' do
+      expect(parsed_config["TrafficState"]).to include("OnStartup" => "reset")
+    end
+  end
+
+  context 'when access_log.enabled is true' do
+    before(:each) { spec["access_log"] = { "enabled" => true, "max_size_mb" => 10 } }
+
+    it 'enables the access log' do
+      expect(parsed_config["AccessLog"]).to include("Enabled" => true, "MaxSizeMB" => 10, "MaxBackups" => 5)
+    end
+  end
+
+  context 'when capture.enabled is true' do
+    before(:each) { spec["capture"] = { "enabled" => true, "max_duration_seconds" => 60 } }
+
+    it 'lets operators capture session traffic to the ephemeral disk' do
+      expect(parsed_config["Capture"]).to eq(
+        "Enabled" => true,
+        "Directory" => "/var/vcap/data/proxy/captures",
+        "MaxBytes" => 104857600,
+        "MaxDurationSeconds" => 60,
+        "MaxFiles" => 10,
+      )
+    end
+  end
+
+  context 'when capture.enabled is false' do
+    it 'does not render the capture config' do
+      expect(parsed_config).not_to have_key("Capture")
+    end
+  end
 end
@@ -159,6 +159,25 @@ describe 'db_init template' do
       it 'adds a galera-agent seeded_users entry automatically' do
         expect(rendered_template).to match(/CREATE USER IF NOT EXISTS 'galera-agent'@'localhost'/)
       end
+
+      it 'does not let galera-agent change system variables' do
+        expect(rendered_template).to_not match(/GRANT SYSTEM_VARIABLES_ADMIN ON \*\.\* TO 'galera-agent'@'localhost'/)
+      end
+    end
+
+    context 'when the galera-agent link enables writer fencing' do
+      let(:links) {
+        [
+          Bosh::Template::Test::Link.new(
+            name: 'galera-agent',
+            properties: { "db_password" => "galera-agent-db-creds", "writer_fencing" => { "enabled" => true } },
+          )
+        ]
+      }
+
+      it 'lets galera-agent make the node read-only' do
+        expect(rendered_template).to match(/GRANT SYSTEM_VARIABLES_ADMIN ON \*\.\* TO 'galera-agent'@'localhost';/)
+      end
     end
 
     context 'when a galera-agent link is NOT present' do
@@ -28,4 +28,31 @@ describe 'galera-agent-config template' do
     expect(hash_from_yaml).to include("MysqldPath")
     expect(hash_from_yaml["MysqldPath"]).to match('/var/vcap/packages/percona-xtradb-cluster-8.0/bin/mysqld')
   end
+
+  it 'disables writer fencing by default' do
+    hash_from_yaml = YAML.load(template.render(spec, consumes: links))
+
+    expect(hash_from_yaml["WriterFencing"]).to include("Enabled" => false)
+  end
+
+  it 'records writer fencing on the ephemeral disk when enabled' do
+    spec["writer_fencing"] = { "enabled" => true }
+    hash_from_yaml = YAML.load(template.render(spec, consumes: links))
+
+    expect(hash_from_yaml["WriterFencing"]).to eq(
+      "Enabled" => true,
+      "StateFilePath" => "/var/vcap/data/pxc-mysql/writer-fenced",
+    )
+  end
+
+  it 'records writer fencing in a volume BPM lets galera-agent write to' do
+    spec["writer_fencing"] = { "enabled" => true }
+    state_dir = File.dirname(YAML.load(template.render(spec, consumes: links))["WriterFencing"]["StateFilePath"])
+
+    bpm = YAML.load(job.template('config/bpm.yml').render(spec, consumes: links))
+    volumes = bpm["processes"][0].fetch("additional_volumes", [])
+
+    expect(volumes).to include(include("path" => state_dir, "writable" => true))
+    expect(volumes.find { |v| v["path"] == state_dir }).not_to include("mount_only" => true)
+  end
 end
@@ -38,6 +38,15 @@ type SequenceNumberChecker interface {
 	Check(req *http.Request) (string, error)
 }
 
+// Fencer makes the node read-only while a proxy routes writes elsewhere.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Fencer
+type Fencer interface {
+	Fence() (string, error)
+	Unfence() (string, error)
+	Fenced() bool
+}
+
 type RunFunc func(req *http.Request) (string, error)
 
 type router struct {
@@ -47,6 +56,7 @@ type router struct {
 	sequenceNumberChecker SequenceNumberChecker
 	healthchecker         HealthChecker
 	stateSnapshotter      StateSnapshotter
+	fencer                Fencer
 }
 
 func NewRouter(
@@ -56,6 +66,7 @@ func NewRouter(
 	sequenceNumberChecker SequenceNumberChecker,
 	healthchecker HealthChecker,
 	stateSnapshotter StateSnapshotter,
+	fencer Fencer,
 ) (http.Handler, error) {
 	r := router{
 		logger:                logger,
@@ -64,6 +75,7 @@ func NewRouter(
 		sequenceNumberChecker: sequenceNumberChecker,
 		healthchecker:         healthchecker,
 		stateSnapshotter:      stateSnapshotter,
+		fencer:                fencer,
 	}
 
 	routes := rata.Routes{
@@ -95,6 +107,15 @@ func NewRouter(
 		"health":                  r.getInsecureHandler(func(req *http.Request) (string, error) { return "", nil }),
 	}
 
+	if r.fencer != nil {
+		routes = append(routes,
+			rata.Route{Name: "v1_fence", Method: "PUT", Path: "/api/v1/fence"},
+			rata.Route{Name: "v1_unfence", Method: "DELETE", Path: "/api/v1/fence"},
+		)
+		handlers["v1_fence"] = r.getSecureHandler(func(req *http.Request) (string, error) { return r.fencer.Fence() })
+		handlers["v1_unfence"] = r.getSecureHandler(func(req *http.Request) (string, error) { return r.fencer.Unfence() })
+	}
+
 	handler, err := rata.NewRouter(routes, handlers)
 	if err != nil {
 		logger.Error("Error initializing router", err)
@@ -141,7 +162,10 @@ func (r router) v1Status() http.Handler {
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		currentHealth := s.IsHealthy(r.rootConfig.AvailableWhenReadOnly)
+		// A node fenced by a proxy is read-only, but must stay healthy so that
+		// a proxy can make it the writer again.
+		fenced := r.fencer != nil && r.fencer.Fenced()
+		currentHealth := s.IsHealthy(r.rootConfig.AvailableWhenReadOnly || fenced)
 		resp := V1StatusResponse{
 			WsrepLocalState:        uint(s.WsrepLocalState),
 			WsrepLocalStateComment: string(s.WsrepLocalState.Comment()),
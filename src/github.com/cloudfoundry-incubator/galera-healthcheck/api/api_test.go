@@ -31,6 +31,8 @@ var _ = Describe("Sidecar API", func() {
 		sequenceNumber   *apifakes.FakeSequenceNumberChecker
 		healthchecker    *apifakes.FakeHealthChecker
 		stateSnapshotter *apifakes.FakeStateSnapshotter
+		fencer           *apifakes.FakeFencer
+		testConfig       *config.Config
 		ts               *httptest.Server
 
 		ExpectedStateSnapshot domain.DBState
@@ -56,7 +58,11 @@ var _ = Describe("Sidecar API", func() {
 
 		testLogger = lagertest.NewTestLogger("mysql_cmd")
 
-		testConfig := &config.Config{
+		fencer = new(apifakes.FakeFencer)
+		fencer.FenceReturns("fenced", nil)
+		fencer.UnfenceReturns("unfenced", nil)
+
+		testConfig = &config.Config{
 			SidecarEndpoint: config.SidecarEndpointConfig{
 				Username: ApiUsername,
 				Password: ApiPassword,
@@ -69,6 +75,14 @@ var _ = Describe("Sidecar API", func() {
 		monitClient.StartServiceJoinReturns("Successfully sent join request", nil)
 		monitClient.GetStatusReturns("running", nil)
 
+	})
+
+	JustBeforeEach(func() {
+		var f api.Fencer
+		if fencer != nil {
+			f = fencer
+		}
+
 		handler, err := api.NewRouter(
 			testLogger,
 			testConfig,
@@ -76,6 +90,7 @@ var _ = Describe("Sidecar API", func() {
 			sequenceNumber,
 			healthchecker,
 			stateSnapshotter,
+			f,
 		)
 		Expect(err).ToNot(HaveOccurred())
 		ts = httptest.NewServer(handler)
@@ -163,6 +178,34 @@ var _ = Describe("Sidecar API", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
 		})
+
+		It("Calls Fence on the fencer when the node is fenced", func() {
+			resp, err := http.DefaultClient.Do(createReq("api/v1/fence", "PUT"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(fencer.FenceCallCount()).To(Equal(1))
+		})
+
+		It("Calls Unfence on the fencer when the fence is removed", func() {
+			resp, err := http.DefaultClient.Do(createReq("api/v1/fence", "DELETE"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(fencer.UnfenceCallCount()).To(Equal(1))
+		})
+
+		Context("when writer fencing is disabled", func() {
+			BeforeEach(func() {
+				fencer = nil
+			})
+
+			It("does not serve the fence endpoints", func() {
+				resp, err := http.DefaultClient.Do(createReq("api/v1/fence", "PUT"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+			})
+		})
 	})
 
 	Context("when request does not have basic auth", func() {
@@ -209,6 +252,14 @@ var _ = Describe("Sidecar API", func() {
 			Expect(monitClient.GetStatusCallCount()).To(Equal(0))
 		})
 
+		It("requires authentication for /api/v1/fence", func() {
+			resp, err := http.DefaultClient.Do(createReq("api/v1/fence", "PUT"))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(fencer.FenceCallCount()).To(Equal(0))
+		})
+
 		It("requires authentication for /sequence_number", func() {
 			req := createReq("sequence_number", "GET")
 			resp, err := http.DefaultClient.Do(req)
@@ -368,6 +419,35 @@ var _ = Describe("Sidecar API", func() {
 				})
 			})
 
+			Context("when the node is read-only", func() {
+				BeforeEach(func() {
+					testConfig.AvailableWhenReadOnly = false
+					stateSnapshotter.StateReturns(domain.DBState{
+						WsrepLocalIndex: 1,
+						WsrepLocalState: domain.Synced,
+						ReadOnly:        true,
+					}, nil)
+				})
+
+				healthy := func() bool {
+					resp, err := http.DefaultClient.Do(createReq("api/v1/status", "GET"))
+					Expect(err).ToNot(HaveOccurred())
+
+					var state api.V1StatusResponse
+					Expect(json.NewDecoder(resp.Body).Decode(&state)).To(Succeed())
+					return state.Healthy
+				}
+
+				It("is unhealthy", func() {
+					Expect(healthy()).To(BeFalse())
+				})
+
+				It("is healthy when a proxy fenced it", func() {
+					fencer.FencedReturns(true)
+					Expect(healthy()).To(BeTrue())
+				})
+			})
+
 			Context("when getting the state fails", func() {
 				BeforeEach(func() {
 					stateSnapshotter.StateReturns(domain.DBState{}, errors.New("possibly not a galera cluster"))
@@ -0,0 +1,236 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package apifakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/galera-healthcheck/api"
+)
+
+type FakeFencer struct {
+	FenceStub        func() (string, error)
+	fenceMutex       sync.RWMutex
+	fenceArgsForCall []struct {
+	}
+	fenceReturns struct {
+		result1 string
+		result2 error
+	}
+	fenceReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	FencedStub        func() bool
+	fencedMutex       sync.RWMutex
+	fencedArgsForCall []struct {
+	}
+	fencedReturns struct {
+		result1 bool
+	}
+	fencedReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	UnfenceStub        func() (string, error)
+	unfenceMutex       sync.RWMutex
+	unfenceArgsForCall []struct {
+	}
+	unfenceReturns struct {
+		result1 string
+		result2 error
+	}
+	unfenceReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeFencer) Fence() (string, error) {
+	fake.fenceMutex.Lock()
+	ret, specificReturn := fake.fenceReturnsOnCall[len(fake.fenceArgsForCall)]
+	fake.fenceArgsForCall = append(fake.fenceArgsForCall, struct {
+	}{})
+	stub := fake.FenceStub
+	fakeReturns := fake.fenceReturns
+	fake.recordInvocation("Fence", []interface{}{})
+	fake.fenceMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFencer) FenceCallCount() int {
+	fake.fenceMutex.RLock()
+	defer fake.fenceMutex.RUnlock()
+	return len(fake.fenceArgsForCall)
+}
+
+func (fake *FakeFencer) FenceCalls(stub func() (string, error)) {
+	fake.fenceMutex.Lock()
+	defer fake.fenceMutex.Unlock()
+	fake.FenceStub = stub
+}
+
+func (fake *FakeFencer) FenceReturns(result1 string, result2 error) {
+	fake.fenceMutex.Lock()
+	defer fake.fenceMutex.Unlock()
+	fake.FenceStub = nil
+	fake.fenceReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFencer) FenceReturnsOnCall(i int, result1 string, result2 error) {
+	fake.fenceMutex.Lock()
+	defer fake.fenceMutex.Unlock()
+	fake.FenceStub = nil
+	if fake.fenceReturnsOnCall == nil {
+		fake.fenceReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.fenceReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFencer) Fenced() bool {
+	fake.fencedMutex.Lock()
+	ret, specificReturn := fake.fencedReturnsOnCall[len(fake.fencedArgsForCall)]
+	fake.fencedArgsForCall = append(fake.fencedArgsForCall, struct {
+	}{})
+	stub := fake.FencedStub
+	fakeReturns := fake.fencedReturns
+	fake.recordInvocation("Fenced", []interface{}{})
+	fake.fencedMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeFencer) FencedCallCount() int {
+	fake.fencedMutex.RLock()
+	defer fake.fencedMutex.RUnlock()
+	return len(fake.fencedArgsForCall)
+}
+
+func (fake *FakeFencer) FencedCalls(stub func() bool) {
+	fake.fencedMutex.Lock()
+	defer fake.fencedMutex.Unlock()
+	fake.FencedStub = stub
+}
+
+func (fake *FakeFencer) FencedReturns(result1 bool) {
+	fake.fencedMutex.Lock()
+	defer fake.fencedMutex.Unlock()
+	fake.FencedStub = nil
+	fake.fencedReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeFencer) FencedReturnsOnCall(i int, result1 bool) {
+	fake.fencedMutex.Lock()
+	defer fake.fencedMutex.Unlock()
+	fake.FencedStub = nil
+	if fake.fencedReturnsOnCall == nil {
+		fake.fencedReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.fencedReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeFencer) Unfence() (string, error) {
+	fake.unfenceMutex.Lock()
+	ret, specificReturn := fake.unfenceReturnsOnCall[len(fake.unfenceArgsForCall)]
+	fake.unfenceArgsForCall = append(fake.unfenceArgsForCall, struct {
+	}{})
+	stub := fake.UnfenceStub
+	fakeReturns := fake.unfenceReturns
+	fake.recordInvocation("Unfence", []interface{}{})
+	fake.unfenceMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeFencer) UnfenceCallCount() int {
+	fake.unfenceMutex.RLock()
+	defer fake.unfenceMutex.RUnlock()
+	return len(fake.unfenceArgsForCall)
+}
+
+func (fake *FakeFencer) UnfenceCalls(stub func() (string, error)) {
+	fake.unfenceMutex.Lock()
+	defer fake.unfenceMutex.Unlock()
+	fake.UnfenceStub = stub
+}
+
+func (fake *FakeFencer) UnfenceReturns(result1 string, result2 error) {
+	fake.unfenceMutex.Lock()
+	defer fake.unfenceMutex.Unlock()
+	fake.UnfenceStub = nil
+	fake.unfenceReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFencer) UnfenceReturnsOnCall(i int, result1 string, result2 error) {
+	fake.unfenceMutex.Lock()
+	defer fake.unfenceMutex.Unlock()
+	fake.UnfenceStub = nil
+	if fake.unfenceReturnsOnCall == nil {
+		fake.unfenceReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.unfenceReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFencer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeFencer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ api.Fencer = new(FakeFencer)
@@ -26,6 +26,7 @@ type Config struct {
 	MyCnfPath             string                `yaml:"MyCnfPath" validate:"nonzero"`
 	DataDir               string                `yaml:"DataDir" validate:"nonzero"`
 	SidecarEndpoint       SidecarEndpointConfig `yaml:"SidecarEndpoint" validate:"nonzero"`
+	WriterFencing         WriterFencingConfig   `yaml:"WriterFencing"`
 	Logger                lager.Logger          `yaml:"-"`
 }
 
@@ -51,6 +52,14 @@ type SidecarEndpointConfig struct {
 	TLS      EndpointTLS `yaml:"TLS"`
 }
 
+// WriterFencingConfig enables the endpoints proxies use to make this node
+// read-only when they stop routing writes to it. StateFilePath records that
+// the node was fenced by a proxy rather than made read-only by an operator.
+type WriterFencingConfig struct {
+	Enabled       bool   `yaml:"Enabled"`
+	StateFilePath string `yaml:"StateFilePath"`
+}
+
 type EndpointTLS struct {
 	Enabled     bool   `yaml:"Enabled"`
 	Certificate string `yaml:"Certificate"`
@@ -106,6 +115,10 @@ func (c Config) Validate() error {
 		errString = formatErrorString(rootConfigErr, "")
 	}
 
+	if c.WriterFencing.Enabled && c.WriterFencing.StateFilePath == "" {
+		errString += fmt.Sprintf("%s : %s\n", "WriterFencing.StateFilePath", "zero value")
+	}
+
 	if len(errString) > 0 {
 		return errors.New(fmt.Sprintf("Validation errors: %s\n", errString))
 	}
@@ -287,6 +287,16 @@ var _ = Describe("Config", func() {
 			Expect(err.Error()).To(ContainSubstring("Password"))
 		})
 
+		It("returns an error if WriterFencing is enabled without a StateFilePath", func() {
+			rootConfig.WriterFencing.Enabled = true
+			err := rootConfig.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("WriterFencing.StateFilePath"))
+
+			rootConfig.WriterFencing.StateFilePath = "/var/vcap/store/galera-agent/fenced"
+			Expect(rootConfig.Validate()).To(Succeed())
+		})
+
 		It("returns a valid logger", func() {
 			Expect(rootConfig.Logger).ToNot(BeNil())
 		})
@@ -0,0 +1,93 @@
+package fencing
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"sync"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// Fencer makes the local node read-only when a proxy moves the writer away
+// from it, and writable again when a proxy makes it the writer.
+//
+// A marker file records that the node was fenced by a proxy, so that read_only
+// set by an operator is never cleared and a fenced node still reports healthy
+// after the agent restarts. A node that is already read-only when it is fenced
+// is left as it is, without the marker.
+type Fencer struct {
+	db            *sql.DB
+	stateFilePath string
+	logger        lager.Logger
+	m             sync.Mutex
+}
+
+func New(db *sql.DB, stateFilePath string, logger lager.Logger) *Fencer {
+	return &Fencer{
+		db:            db,
+		stateFilePath: stateFilePath,
+		logger:        logger,
+	}
+}
+
+func (f *Fencer) Fence() (string, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if !f.fenced() {
+		var readOnly bool
+		if err := f.db.QueryRow("SELECT @@global.read_only").Scan(&readOnly); err != nil {
+			return "", err
+		}
+		if readOnly {
+			f.logger.Info("Not fencing node: read_only was already enabled")
+			return "already read-only", nil
+		}
+	}
+
+	// Write the marker first: a marker without read_only is harmless, but
+	// read_only without a marker would make the node report unhealthy.
+	if err := os.WriteFile(f.stateFilePath, nil, 0640); err != nil {
+		return "", err
+	}
+
+	if _, err := f.db.Exec("SET GLOBAL read_only = ON"); err != nil {
+		return "", err
+	}
+
+	f.logger.Info("Fenced node: read_only enabled")
+	return "fenced", nil
+}
+
+func (f *Fencer) Unfence() (string, error) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if !f.fenced() {
+		return "not fenced", nil
+	}
+
+	if _, err := f.db.Exec("SET GLOBAL read_only = OFF"); err != nil {
+		return "", err
+	}
+
+	if err := os.Remove(f.stateFilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	f.logger.Info("Unfenced node: read_only disabled")
+	return "unfenced", nil
+}
+
+// Fenced reports whether the node was made read-only by Fence.
+func (f *Fencer) Fenced() bool {
+	f.m.Lock()
+	defer f.m.Unlock()
+	return f.fenced()
+}
+
+func (f *Fencer) fenced() bool {
+	_, err := os.Stat(f.stateFilePath)
+	return err == nil
+}
@@ -0,0 +1,106 @@
+package fencing_test
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/galera-healthcheck/fencing"
+)
+
+var _ = Describe("Fencer", func() {
+	var (
+		db            *sql.DB
+		mock          sqlmock.Sqlmock
+		stateFilePath string
+		fencer        *fencing.Fencer
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		stateFilePath = filepath.Join(GinkgoT().TempDir(), "fenced")
+		fencer = fencing.New(db, stateFilePath, lagertest.NewTestLogger("fencing"))
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Describe("Fence", func() {
+		It("makes the node read-only and records the fence", func() {
+			mock.ExpectQuery("SELECT @@global.read_only").WillReturnRows(sqlmock.NewRows([]string{"@@global.read_only"}).AddRow(0))
+			mock.ExpectExec("SET GLOBAL read_only = ON").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(fencer.Fence()).To(Equal("fenced"))
+			Expect(fencer.Fenced()).To(BeTrue())
+			Expect(stateFilePath).To(BeAnExistingFile())
+		})
+
+		It("fences a node it already fenced again", func() {
+			Expect(os.WriteFile(stateFilePath, nil, 0640)).To(Succeed())
+			mock.ExpectExec("SET GLOBAL read_only = ON").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(fencer.Fence()).To(Equal("fenced"))
+			Expect(fencer.Fenced()).To(BeTrue())
+		})
+
+		It("leaves a node that is already read-only to the operator", func() {
+			mock.ExpectQuery("SELECT @@global.read_only").WillReturnRows(sqlmock.NewRows([]string{"@@global.read_only"}).AddRow(1))
+
+			Expect(fencer.Fence()).To(Equal("already read-only"))
+			Expect(fencer.Fenced()).To(BeFalse())
+			Expect(stateFilePath).NotTo(BeAnExistingFile())
+
+			By("not clearing read_only when the node is unfenced")
+			Expect(fencer.Unfence()).To(Equal("not fenced"))
+		})
+
+		It("returns an error if read_only cannot be read", func() {
+			mock.ExpectQuery("SELECT @@global.read_only").WillReturnError(errors.New("access denied"))
+
+			_, err := fencer.Fence()
+			Expect(err).To(MatchError("access denied"))
+			Expect(fencer.Fenced()).To(BeFalse())
+		})
+
+		It("returns an error if read_only cannot be set", func() {
+			mock.ExpectQuery("SELECT @@global.read_only").WillReturnRows(sqlmock.NewRows([]string{"@@global.read_only"}).AddRow(0))
+			mock.ExpectExec("SET GLOBAL read_only = ON").WillReturnError(errors.New("access denied"))
+
+			_, err := fencer.Fence()
+			Expect(err).To(MatchError("access denied"))
+		})
+	})
+
+	Describe("Unfence", func() {
+		It("does not clear read_only that it did not set", func() {
+			Expect(fencer.Unfence()).To(Equal("not fenced"))
+		})
+
+		It("clears read_only and the fence", func() {
+			Expect(os.WriteFile(stateFilePath, nil, 0640)).To(Succeed())
+			mock.ExpectExec("SET GLOBAL read_only = OFF").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			Expect(fencer.Unfence()).To(Equal("unfenced"))
+			Expect(fencer.Fenced()).To(BeFalse())
+		})
+
+		It("keeps the fence if read_only cannot be cleared", func() {
+			Expect(os.WriteFile(stateFilePath, nil, 0640)).To(Succeed())
+			mock.ExpectExec("SET GLOBAL read_only = OFF").WillReturnError(errors.New("access denied"))
+
+			_, err := fencer.Unfence()
+			Expect(err).To(HaveOccurred())
+			Expect(fencer.Fenced()).To(BeTrue())
+		})
+	})
+})
@@ -14,6 +14,7 @@ import (
 
 	"github.com/cloudfoundry-incubator/galera-healthcheck/api"
 	"github.com/cloudfoundry-incubator/galera-healthcheck/config"
+	"github.com/cloudfoundry-incubator/galera-healthcheck/fencing"
 	"github.com/cloudfoundry-incubator/galera-healthcheck/healthcheck"
 	"github.com/cloudfoundry-incubator/galera-healthcheck/monit_client"
 	"github.com/cloudfoundry-incubator/galera-healthcheck/mysqld_cmd"
@@ -72,6 +73,11 @@ func main() {
 	sequenceNumberchecker := sequence_number.New(db, mysqldCmd, *rootConfig, logger, &mysqlProcessMutex)
 	stateSnapshotter := &healthcheck.DBStateSnapshotter{DB: db}
 
+	var fencer api.Fencer
+	if rootConfig.WriterFencing.Enabled {
+		fencer = fencing.New(db, rootConfig.WriterFencing.StateFilePath, logger.Session("fencing"))
+	}
+
 	router, err := api.NewRouter(
 		logger,
 		rootConfig,
@@ -79,6 +85,7 @@ func main() {
 		sequenceNumberchecker,
 		healthchecker,
 		stateSnapshotter,
+		fencer,
 	)
 	if err != nil {
 		logger.Fatal("Failed to create router", err)
@@ -0,0 +1,118 @@
+// Package accesslog writes one JSON line per proxied MySQL session to a
+// dedicated file, rotating it by size.
+package accesslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+type Entry struct {
+	ClientAddress    string    `json:"client_address"`
+	Listener         string    `json:"listener"`
+	Backend          string    `json:"backend,omitempty"`
+	BackendAddress   string    `json:"backend_address,omitempty"`
+	Start            time.Time `json:"start"`
+	End              time.Time `json:"end"`
+	DurationMillis   int64     `json:"duration_ms"`
+	BytesFromClient  int64     `json:"bytes_from_client"`
+	BytesFromBackend int64     `json:"bytes_from_backend"`
+	CloseReason      string    `json:"close_reason"`
+}
+
+// Writer appends entries to path. Once the file would exceed maxSize bytes it
+// is renamed to path.1 (shifting older files up to path.<maxBackups>) and a
+// new file is started.
+type Writer struct {
+	mutex      sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func New(path string, maxSize int64, maxBackups int) (*Writer, error) {
+	w := &Writer{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *Writer) Record(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.size > 0 && w.size+int64(len(line)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.file.Write(line)
+	w.size += int64(n)
+	return err
+}
+
+func (w *Writer) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.file.Close()
+}
+
+func (w *Writer) open() error {
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return fmt.Errorf("opening access log %s: %w", w.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("opening access log %s: %w", w.path, err)
+	}
+
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("rotating access log %s: %w", w.path, err)
+	}
+
+	if w.maxBackups > 0 {
+		for i := w.maxBackups - 1; i >= 1; i-- {
+			_ = os.Rename(w.backupPath(i), w.backupPath(i+1))
+		}
+		if err := os.Rename(w.path, w.backupPath(1)); err != nil {
+			return fmt.Errorf("rotating access log %s: %w", w.path, err)
+		}
+	} else if err := os.Remove(w.path); err != nil {
+		return fmt.Errorf("rotating access log %s: %w", w.path, err)
+	}
+
+	return w.open()
+}
+
+func (w *Writer) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
@@ -0,0 +1,110 @@
+package accesslog_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/accesslog"
+)
+
+var _ = Describe("Writer", func() {
+	var (
+		dir    string
+		path   string
+		writer *accesslog.Writer
+		entry  accesslog.Entry
+	)
+
+	readEntries := func(path string) []accesslog.Entry {
+		f, err := os.Open(path)
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+
+		var entries []accesslog.Entry
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var e accesslog.Entry
+			Expect(json.Unmarshal(scanner.Bytes(), &e)).To(Succeed())
+			entries = append(entries, e)
+		}
+		return entries
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		path = filepath.Join(dir, "access.log")
+
+		start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		entry = accesslog.Entry{
+			ClientAddress:    "10.0.0.9:51234",
+			Listener:         "0.0.0.0:3306",
+			Backend:          "mysql/0",
+			BackendAddress:   "10.0.0.1:3306",
+			Start:            start,
+			End:              start.Add(1500 * time.Millisecond),
+			DurationMillis:   1500,
+			BytesFromClient:  100,
+			BytesFromBackend: 2000,
+			CloseReason:      "client_closed",
+		}
+	})
+
+	AfterEach(func() {
+		if writer != nil {
+			writer.Close()
+		}
+	})
+
+	It("writes one JSON line per entry", func() {
+		var err error
+		writer, err = accesslog.New(path, 1024*1024, 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(writer.Record(entry)).To(Succeed())
+		Expect(writer.Record(entry)).To(Succeed())
+
+		Expect(readEntries(path)).To(Equal([]accesslog.Entry{entry, entry}))
+	})
+
+	It("appends to an existing log", func() {
+		var err error
+		writer, err = accesslog.New(path, 1024*1024, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.Record(entry)).To(Succeed())
+		Expect(writer.Close()).To(Succeed())
+
+		writer, err = accesslog.New(path, 1024*1024, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.Record(entry)).To(Succeed())
+
+		Expect(readEntries(path)).To(HaveLen(2))
+	})
+
+	It("rotates the log once it reaches the maximum size", func() {
+		line, err := json.Marshal(entry)
+		Expect(err).NotTo(HaveOccurred())
+
+		writer, err = accesslog.New(path, int64(2*(len(line)+1)), 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		for i := 0; i < 7; i++ {
+			Expect(writer.Record(entry)).To(Succeed())
+		}
+
+		Expect(readEntries(path)).To(HaveLen(1))
+		Expect(readEntries(path + ".1")).To(HaveLen(2))
+		Expect(readEntries(path + ".2")).To(HaveLen(2))
+		Expect(path + ".3").NotTo(BeAnExistingFile())
+	})
+
+	It("returns an error when the log cannot be opened", func() {
+		_, err := accesslog.New(filepath.Join(dir, "missing", "access.log"), 1024, 1)
+		Expect(err).To(MatchError(ContainSubstring("opening access log")))
+	})
+})
@@ -0,0 +1,185 @@
+package admission
+
+import (
+	"sync"
+	"time"
+)
+
+// Outcome is the result of asking a Controller to admit a client connection.
+type Outcome string
+
+const (
+	Admitted             Outcome = "admitted"
+	AdmittedAfterQueuing Outcome = "queued"
+	RejectedMaxSessions  Outcome = "rejected_max_sessions"
+	RejectedRateLimit    Outcome = "rejected_rate_limit"
+	RejectedClientLimit  Outcome = "rejected_client_limit"
+)
+
+var Outcomes = []Outcome{
+	Admitted,
+	AdmittedAfterQueuing,
+	RejectedMaxSessions,
+	RejectedRateLimit,
+	RejectedClientLimit,
+}
+
+func (o Outcome) Admitted() bool {
+	return o == Admitted || o == AdmittedAfterQueuing
+}
+
+// Limits bounds the client connections accepted by a single listener. A zero
+// value disables the corresponding limit.
+type Limits struct {
+	MaxSessions          int
+	MaxSessionsPerClient int
+	ConnectionsPerSecond float64
+	Burst                int
+	// QueueTimeout is how long a connection over a limit waits for capacity
+	// before it is rejected. Zero rejects it immediately.
+	QueueTimeout time.Duration
+}
+
+// Controller admits client connections while the listener is within its
+// limits. Connections over a limit are held for up to QueueTimeout, then
+// rejected.
+type Controller struct {
+	limits Limits
+
+	mutex     sync.Mutex
+	changed   chan struct{}
+	sessions  int
+	perClient map[string]int
+	tokens    float64
+	refilled  time.Time
+	outcomes  map[Outcome]uint64
+}
+
+func New(limits Limits) *Controller {
+	if limits.ConnectionsPerSecond > 0 && limits.Burst <= 0 {
+		limits.Burst = int(limits.ConnectionsPerSecond)
+		if limits.Burst < 1 {
+			limits.Burst = 1
+		}
+	}
+
+	return &Controller{
+		limits:    limits,
+		changed:   make(chan struct{}),
+		perClient: map[string]int{},
+		tokens:    float64(limits.Burst),
+		refilled:  time.Now(),
+		outcomes:  map[Outcome]uint64{},
+	}
+}
+
+// Admit blocks until the connection from clientIP is admitted or rejected.
+// When it is admitted, release must be called once the session ends.
+func (c *Controller) Admit(clientIP string) (release func(), outcome Outcome) {
+	deadline := time.Now().Add(c.limits.QueueTimeout)
+	queued := false
+
+	for {
+		c.mutex.Lock()
+		now := time.Now()
+		rejection, retryIn := c.unsafeTryAdmit(clientIP, now)
+		if rejection == "" {
+			outcome = Admitted
+			if queued {
+				outcome = AdmittedAfterQueuing
+			}
+			c.outcomes[outcome]++
+			c.mutex.Unlock()
+			return c.releaseFunc(clientIP), outcome
+		}
+
+		remaining := deadline.Sub(now)
+		if remaining <= 0 {
+			c.outcomes[rejection]++
+			c.mutex.Unlock()
+			return nil, rejection
+		}
+		changed := c.changed
+		c.mutex.Unlock()
+
+		queued = true
+
+		wait := remaining
+		if retryIn > 0 && retryIn < wait {
+			wait = retryIn
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-changed:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// Outcomes returns the number of connections that ended with each outcome.
+func (c *Controller) Outcomes() map[Outcome]uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	outcomes := make(map[Outcome]uint64, len(Outcomes))
+	for _, o := range Outcomes {
+		outcomes[o] = c.outcomes[o]
+	}
+	return outcomes
+}
+
+// unsafeTryAdmit returns the reason the connection cannot be admitted yet, if
+// any, and how long until a rate limit token becomes available.
+func (c *Controller) unsafeTryAdmit(clientIP string, now time.Time) (Outcome, time.Duration) {
+	if c.limits.MaxSessions > 0 && c.sessions >= c.limits.MaxSessions {
+		return RejectedMaxSessions, 0
+	}
+
+	if c.limits.MaxSessionsPerClient > 0 && c.perClient[clientIP] >= c.limits.MaxSessionsPerClient {
+		return RejectedClientLimit, 0
+	}
+
+	if c.limits.ConnectionsPerSecond > 0 {
+		c.unsafeRefill(now)
+		if c.tokens < 1 {
+			missing := 1 - c.tokens
+			return RejectedRateLimit, time.Duration(missing / c.limits.ConnectionsPerSecond * float64(time.Second))
+		}
+		c.tokens--
+	}
+
+	c.sessions++
+	c.perClient[clientIP]++
+	return "", 0
+}
+
+func (c *Controller) unsafeRefill(now time.Time) {
+	elapsed := now.Sub(c.refilled).Seconds()
+	c.refilled = now
+
+	c.tokens += elapsed * c.limits.ConnectionsPerSecond
+	if c.tokens > float64(c.limits.Burst) {
+		c.tokens = float64(c.limits.Burst)
+	}
+}
+
+func (c *Controller) releaseFunc(clientIP string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mutex.Lock()
+			defer c.mutex.Unlock()
+
+			c.sessions--
+			c.perClient[clientIP]--
+			if c.perClient[clientIP] <= 0 {
+				delete(c.perClient, clientIP)
+			}
+
+			close(c.changed)
+			c.changed = make(chan struct{})
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package admission_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/admission"
+)
+
+var _ = Describe("Controller", func() {
+	It("admits every connection when no limits are set", func() {
+		c := admission.New(admission.Limits{})
+		for i := 0; i < 100; i++ {
+			_, outcome := c.Admit("10.0.0.1")
+			Expect(outcome).To(Equal(admission.Admitted))
+		}
+		Expect(c.Outcomes()).To(HaveKeyWithValue(admission.Admitted, uint64(100)))
+	})
+
+	Describe("MaxSessions", func() {
+		var c *admission.Controller
+
+		BeforeEach(func() {
+			c = admission.New(admission.Limits{MaxSessions: 2})
+		})
+
+		It("rejects connections over the limit", func() {
+			_, outcome := c.Admit("10.0.0.1")
+			Expect(outcome).To(Equal(admission.Admitted))
+			_, outcome = c.Admit("10.0.0.2")
+			Expect(outcome).To(Equal(admission.Admitted))
+
+			release, outcome := c.Admit("10.0.0.3")
+			Expect(outcome).To(Equal(admission.RejectedMaxSessions))
+			Expect(outcome.Admitted()).To(BeFalse())
+			Expect(release).To(BeNil())
+
+			Expect(c.Outcomes()).To(Equal(map[admission.Outcome]uint64{
+				admission.Admitted:             2,
+				admission.AdmittedAfterQueuing: 0,
+				admission.RejectedMaxSessions:  1,
+				admission.RejectedRateLimit:    0,
+				admission.RejectedClientLimit:  0,
+			}))
+		})
+
+		It("admits connections again once sessions are released", func() {
+			release, _ := c.Admit("10.0.0.1")
+			_, _ = c.Admit("10.0.0.2")
+
+			release()
+			release()
+
+			_, outcome := c.Admit("10.0.0.3")
+			Expect(outcome).To(Equal(admission.Admitted))
+			_, outcome = c.Admit("10.0.0.4")
+			Expect(outcome).To(Equal(admission.RejectedMaxSessions))
+		})
+	})
+
+	Describe("MaxSessionsPerClient", func() {
+		It("limits the sessions from a single client IP", func() {
+			c := admission.New(admission.Limits{MaxSessionsPerClient: 1})
+
+			_, outcome := c.Admit("10.0.0.1")
+			Expect(outcome).To(Equal(admission.Admitted))
+			_, outcome = c.Admit("10.0.0.1")
+			Expect(outcome).To(Equal(admission.RejectedClientLimit))
+			_, outcome = c.Admit("10.0.0.2")
+			Expect(outcome).To(Equal(admission.Admitted))
+		})
+	})
+
+	Describe("ConnectionsPerSecond", func() {
+		It("allows a burst and then rejects new connections", func() {
+			c := admission.New(admission.Limits{ConnectionsPerSecond: 1, Burst: 3})
+
+			for i := 0; i < 3; i++ {
+				release, outcome := c.Admit("10.0.0.1")
+				Expect(outcome).To(Equal(admission.Admitted))
+				release()
+			}
+
+			_, outcome := c.Admit("10.0.0.1")
+			Expect(outcome).To(Equal(admission.RejectedRateLimit))
+		})
+
+		It("refills tokens over time", func() {
+			c := admission.New(admission.Limits{ConnectionsPerSecond: 50, Burst: 1})
+
+			_, outcome := c.Admit("10.0.0.1")
+			Expect(outcome).To(Equal(admission.Admitted))
+			_, outcome = c.Admit("10.0.0.1")
+			Expect(outcome).To(Equal(admission.RejectedRateLimit))
+
+			Eventually(func() admission.Outcome {
+				_, outcome := c.Admit("10.0.0.1")
+				return outcome
+			}).Should(Equal(admission.Admitted))
+		})
+	})
+
+	Describe("QueueTimeout", func() {
+		It("admits a queued connection when a session is released", func() {
+			c := admission.New(admission.Limits{MaxSessions: 1, QueueTimeout: 5 * time.Second})
+			release, _ := c.Admit("10.0.0.1")
+
+			outcomes := make(chan admission.Outcome)
+			go func() {
+				defer GinkgoRecover()
+				_, outcome := c.Admit("10.0.0.2")
+				outcomes <- outcome
+			}()
+
+			Consistently(outcomes, 100*time.Millisecond).ShouldNot(Receive())
+			release()
+			Eventually(outcomes).Should(Receive(Equal(admission.AdmittedAfterQueuing)))
+		})
+
+		It("waits for a rate limit token", func() {
+			c := admission.New(admission.Limits{ConnectionsPerSecond: 20, Burst: 1, QueueTimeout: time.Second})
+
+			_, outcome := c.Admit("10.0.0.1")
+			Expect(outcome).To(Equal(admission.Admitted))
+
+			start := time.Now()
+			_, outcome = c.Admit("10.0.0.1")
+			Expect(outcome).To(Equal(admission.AdmittedAfterQueuing))
+			Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+		})
+
+		It("rejects a queued connection once the timeout expires", func() {
+			c := admission.New(admission.Limits{MaxSessions: 1, QueueTimeout: 50 * time.Millisecond})
+			_, _ = c.Admit("10.0.0.1")
+
+			start := time.Now()
+			_, outcome := c.Admit("10.0.0.2")
+			Expect(outcome).To(Equal(admission.RejectedMaxSessions))
+			Expect(time.Since(start)).To(BeNumerically(">=", 50*time.Millisecond))
+		})
+	})
+})
@@ -0,0 +1,256 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package apifakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/switchboard/api"
+	"github.com/cloudfoundry-incubator/switchboard/capture"
+)
+
+type FakeCaptures struct {
+	StartStub        func(capture.Filter, time.Duration, int64) (capture.Status, error)
+	startMutex       sync.RWMutex
+	startArgsForCall []struct {
+		arg1 capture.Filter
+		arg2 time.Duration
+		arg3 int64
+	}
+	startReturns struct {
+		result1 capture.Status
+		result2 error
+	}
+	startReturnsOnCall map[int]struct {
+		result1 capture.Status
+		result2 error
+	}
+	StatusStub        func() (capture.Status, bool)
+	statusMutex       sync.RWMutex
+	statusArgsForCall []struct {
+	}
+	statusReturns struct {
+		result1 capture.Status
+		result2 bool
+	}
+	statusReturnsOnCall map[int]struct {
+		result1 capture.Status
+		result2 bool
+	}
+	StopStub        func() (capture.Status, error)
+	stopMutex       sync.RWMutex
+	stopArgsForCall []struct {
+	}
+	stopReturns struct {
+		result1 capture.Status
+		result2 error
+	}
+	stopReturnsOnCall map[int]struct {
+		result1 capture.Status
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCaptures) Start(arg1 capture.Filter, arg2 time.Duration, arg3 int64) (capture.Status, error) {
+	fake.startMutex.Lock()
+	ret, specificReturn := fake.startReturnsOnCall[len(fake.startArgsForCall)]
+	fake.startArgsForCall = append(fake.startArgsForCall, struct {
+		arg1 capture.Filter
+		arg2 time.Duration
+		arg3 int64
+	}{arg1, arg2, arg3})
+	stub := fake.StartStub
+	fakeReturns := fake.startReturns
+	fake.recordInvocation("Start", []interface{}{arg1, arg2, arg3})
+	fake.startMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCaptures) StartCallCount() int {
+	fake.startMutex.RLock()
+	defer fake.startMutex.RUnlock()
+	return len(fake.startArgsForCall)
+}
+
+func (fake *FakeCaptures) StartCalls(stub func(capture.Filter, time.Duration, int64) (capture.Status, error)) {
+	fake.startMutex.Lock()
+	defer fake.startMutex.Unlock()
+	fake.StartStub = stub
+}
+
+func (fake *FakeCaptures) StartArgsForCall(i int) (capture.Filter, time.Duration, int64) {
+	fake.startMutex.RLock()
+	defer fake.startMutex.RUnlock()
+	argsForCall := fake.startArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeCaptures) StartReturns(result1 capture.Status, result2 error) {
+	fake.startMutex.Lock()
+	defer fake.startMutex.Unlock()
+	fake.StartStub = nil
+	fake.startReturns = struct {
+		result1 capture.Status
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCaptures) StartReturnsOnCall(i int, result1 capture.Status, result2 error) {
+	fake.startMutex.Lock()
+	defer fake.startMutex.Unlock()
+	fake.StartStub = nil
+	if fake.startReturnsOnCall == nil {
+		fake.startReturnsOnCall = make(map[int]struct {
+			result1 capture.Status
+			result2 error
+		})
+	}
+	fake.startReturnsOnCall[i] = struct {
+		result1 capture.Status
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCaptures) Status() (capture.Status, bool) {
+	fake.statusMutex.Lock()
+	ret, specificReturn := fake.statusReturnsOnCall[len(fake.statusArgsForCall)]
+	fake.statusArgsForCall = append(fake.statusArgsForCall, struct {
+	}{})
+	stub := fake.StatusStub
+	fakeReturns := fake.statusReturns
+	fake.recordInvocation("Status", []interface{}{})
+	fake.statusMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCaptures) StatusCallCount() int {
+	fake.statusMutex.RLock()
+	defer fake.statusMutex.RUnlock()
+	return len(fake.statusArgsForCall)
+}
+
+func (fake *FakeCaptures) StatusCalls(stub func() (capture.Status, bool)) {
+	fake.statusMutex.Lock()
+	defer fake.statusMutex.Unlock()
+	fake.StatusStub = stub
+}
+
+func (fake *FakeCaptures) StatusReturns(result1 capture.Status, result2 bool) {
+	fake.statusMutex.Lock()
+	defer fake.statusMutex.Unlock()
+	fake.StatusStub = nil
+	fake.statusReturns = struct {
+		result1 capture.Status
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *FakeCaptures) StatusReturnsOnCall(i int, result1 capture.Status, result2 bool) {
+	fake.statusMutex.Lock()
+	defer fake.statusMutex.Unlock()
+	fake.StatusStub = nil
+	if fake.statusReturnsOnCall == nil {
+		fake.statusReturnsOnCall = make(map[int]struct {
+			result1 capture.Status
+			result2 bool
+		})
+	}
+	fake.statusReturnsOnCall[i] = struct {
+		result1 capture.Status
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *FakeCaptures) Stop() (capture.Status, error) {
+	fake.stopMutex.Lock()
+	ret, specificReturn := fake.stopReturnsOnCall[len(fake.stopArgsForCall)]
+	fake.stopArgsForCall = append(fake.stopArgsForCall, struct {
+	}{})
+	stub := fake.StopStub
+	fakeReturns := fake.stopReturns
+	fake.recordInvocation("Stop", []interface{}{})
+	fake.stopMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCaptures) StopCallCount() int {
+	fake.stopMutex.RLock()
+	defer fake.stopMutex.RUnlock()
+	return len(fake.stopArgsForCall)
+}
+
+func (fake *FakeCaptures) StopCalls(stub func() (capture.Status, error)) {
+	fake.stopMutex.Lock()
+	defer fake.stopMutex.Unlock()
+	fake.StopStub = stub
+}
+
+func (fake *FakeCaptures) StopReturns(result1 capture.Status, result2 error) {
+	fake.stopMutex.Lock()
+	defer fake.stopMutex.Unlock()
+	fake.StopStub = nil
+	fake.stopReturns = struct {
+		result1 capture.Status
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCaptures) StopReturnsOnCall(i int, result1 capture.Status, result2 error) {
+	fake.stopMutex.Lock()
+	defer fake.stopMutex.Unlock()
+	fake.StopStub = nil
+	if fake.stopReturnsOnCall == nil {
+		fake.stopReturnsOnCall = make(map[int]struct {
+			result1 capture.Status
+			result2 error
+		})
+	}
+	fake.stopReturnsOnCall[i] = struct {
+		result1 capture.Status
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCaptures) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeCaptures) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ api.Captures = new(FakeCaptures)
@@ -28,6 +28,21 @@ type FakeClusterManager struct {
 	enableTrafficArgsForCall []struct {
 		arg1 string
 	}
+	UpdateTrafficStub        func(bool, string, string) (api.ClusterJSON, error)
+	updateTrafficMutex       sync.RWMutex
+	updateTrafficArgsForCall []struct {
+		arg1 bool
+		arg2 string
+		arg3 string
+	}
+	updateTrafficReturns struct {
+		result1 api.ClusterJSON
+		result2 error
+	}
+	updateTrafficReturnsOnCall map[int]struct {
+		result1 api.ClusterJSON
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -37,15 +52,16 @@ func (fake *FakeClusterManager) AsJSON() api.ClusterJSON {
 	ret, specificReturn := fake.asJSONReturnsOnCall[len(fake.asJSONArgsForCall)]
 	fake.asJSONArgsForCall = append(fake.asJSONArgsForCall, struct {
 	}{})
+	stub := fake.AsJSONStub
+	fakeReturns := fake.asJSONReturns
 	fake.recordInvocation("AsJSON", []interface{}{})
 	fake.asJSONMutex.Unlock()
-	if fake.AsJSONStub != nil {
-		return fake.AsJSONStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.asJSONReturns
 	return fakeReturns.result1
 }
 
@@ -89,9 +105,10 @@ func (fake *FakeClusterManager) DisableTraffic(arg1 string) {
 	fake.disableTrafficArgsForCall = append(fake.disableTrafficArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.DisableTrafficStub
 	fake.recordInvocation("DisableTraffic", []interface{}{arg1})
 	fake.disableTrafficMutex.Unlock()
-	if fake.DisableTrafficStub != nil {
+	if stub != nil {
 		fake.DisableTrafficStub(arg1)
 	}
 }
@@ -120,9 +137,10 @@ func (fake *FakeClusterManager) EnableTraffic(arg1 string) {
 	fake.enableTrafficArgsForCall = append(fake.enableTrafficArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.EnableTrafficStub
 	fake.recordInvocation("EnableTraffic", []interface{}{arg1})
 	fake.enableTrafficMutex.Unlock()
-	if fake.EnableTrafficStub != nil {
+	if stub != nil {
 		fake.EnableTrafficStub(arg1)
 	}
 }
@@ -146,15 +164,75 @@ func (fake *FakeClusterManager) EnableTrafficArgsForCall(i int) string {
 	return argsForCall.arg1
 }
 
+func (fake *FakeClusterManager) UpdateTraffic(arg1 bool, arg2 string, arg3 string) (api.ClusterJSON, error) {
+	fake.updateTrafficMutex.Lock()
+	ret, specificReturn := fake.updateTrafficReturnsOnCall[len(fake.updateTrafficArgsForCall)]
+	fake.updateTrafficArgsForCall = append(fake.updateTrafficArgsForCall, struct {
+		arg1 bool
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.UpdateTrafficStub
+	fakeReturns := fake.updateTrafficReturns
+	fake.recordInvocation("UpdateTraffic", []interface{}{arg1, arg2, arg3})
+	fake.updateTrafficMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClusterManager) UpdateTrafficCallCount() int {
+	fake.updateTrafficMutex.RLock()
+	defer fake.updateTrafficMutex.RUnlock()
+	return len(fake.updateTrafficArgsForCall)
+}
+
+func (fake *FakeClusterManager) UpdateTrafficCalls(stub func(bool, string, string) (api.ClusterJSON, error)) {
+	fake.updateTrafficMutex.Lock()
+	defer fake.updateTrafficMutex.Unlock()
+	fake.UpdateTrafficStub = stub
+}
+
+func (fake *FakeClusterManager) UpdateTrafficArgsForCall(i int) (bool, string, string) {
+	fake.updateTrafficMutex.RLock()
+	defer fake.updateTrafficMutex.RUnlock()
+	argsForCall := fake.updateTrafficArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeClusterManager) UpdateTrafficReturns(result1 api.ClusterJSON, result2 error) {
+	fake.updateTrafficMutex.Lock()
+	defer fake.updateTrafficMutex.Unlock()
+	fake.UpdateTrafficStub = nil
+	fake.updateTrafficReturns = struct {
+		result1 api.ClusterJSON
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClusterManager) UpdateTrafficReturnsOnCall(i int, result1 api.ClusterJSON, result2 error) {
+	fake.updateTrafficMutex.Lock()
+	defer fake.updateTrafficMutex.Unlock()
+	fake.UpdateTrafficStub = nil
+	if fake.updateTrafficReturnsOnCall == nil {
+		fake.updateTrafficReturnsOnCall = make(map[int]struct {
+			result1 api.ClusterJSON
+			result2 error
+		})
+	}
+	fake.updateTrafficReturnsOnCall[i] = struct {
+		result1 api.ClusterJSON
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClusterManager) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.asJSONMutex.RLock()
-	defer fake.asJSONMutex.RUnlock()
-	fake.disableTrafficMutex.RLock()
-	defer fake.disableTrafficMutex.RUnlock()
-	fake.enableTrafficMutex.RLock()
-	defer fake.enableTrafficMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,227 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package apifakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/switchboard/api"
+	"github.com/cloudfoundry-incubator/switchboard/history"
+)
+
+type FakeHistory struct {
+	FailoversStub        func() []history.Failover
+	failoversMutex       sync.RWMutex
+	failoversArgsForCall []struct {
+	}
+	failoversReturns struct {
+		result1 []history.Failover
+	}
+	failoversReturnsOnCall map[int]struct {
+		result1 []history.Failover
+	}
+	HealthchecksStub        func() map[string][]history.Healthcheck
+	healthchecksMutex       sync.RWMutex
+	healthchecksArgsForCall []struct {
+	}
+	healthchecksReturns struct {
+		result1 map[string][]history.Healthcheck
+	}
+	healthchecksReturnsOnCall map[int]struct {
+		result1 map[string][]history.Healthcheck
+	}
+	SessionsStub        func() []history.SessionSample
+	sessionsMutex       sync.RWMutex
+	sessionsArgsForCall []struct {
+	}
+	sessionsReturns struct {
+		result1 []history.SessionSample
+	}
+	sessionsReturnsOnCall map[int]struct {
+		result1 []history.SessionSample
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeHistory) Failovers() []history.Failover {
+	fake.failoversMutex.Lock()
+	ret, specificReturn := fake.failoversReturnsOnCall[len(fake.failoversArgsForCall)]
+	fake.failoversArgsForCall = append(fake.failoversArgsForCall, struct {
+	}{})
+	stub := fake.FailoversStub
+	fakeReturns := fake.failoversReturns
+	fake.recordInvocation("Failovers", []interface{}{})
+	fake.failoversMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeHistory) FailoversCallCount() int {
+	fake.failoversMutex.RLock()
+	defer fake.failoversMutex.RUnlock()
+	return len(fake.failoversArgsForCall)
+}
+
+func (fake *FakeHistory) FailoversCalls(stub func() []history.Failover) {
+	fake.failoversMutex.Lock()
+	defer fake.failoversMutex.Unlock()
+	fake.FailoversStub = stub
+}
+
+func (fake *FakeHistory) FailoversReturns(result1 []history.Failover) {
+	fake.failoversMutex.Lock()
+	defer fake.failoversMutex.Unlock()
+	fake.FailoversStub = nil
+	fake.failoversReturns = struct {
+		result1 []history.Failover
+	}{result1}
+}
+
+func (fake *FakeHistory) FailoversReturnsOnCall(i int, result1 []history.Failover) {
+	fake.failoversMutex.Lock()
+	defer fake.failoversMutex.Unlock()
+	fake.FailoversStub = nil
+	if fake.failoversReturnsOnCall == nil {
+		fake.failoversReturnsOnCall = make(map[int]struct {
+			result1 []history.Failover
+		})
+	}
+	fake.failoversReturnsOnCall[i] = struct {
+		result1 []history.Failover
+	}{result1}
+}
+
+func (fake *FakeHistory) Healthchecks() map[string][]history.Healthcheck {
+	fake.healthchecksMutex.Lock()
+	ret, specificReturn := fake.healthchecksReturnsOnCall[len(fake.healthchecksArgsForCall)]
+	fake.healthchecksArgsForCall = append(fake.healthchecksArgsForCall, struct {
+	}{})
+	stub := fake.HealthchecksStub
+	fakeReturns := fake.healthchecksReturns
+	fake.recordInvocation("Healthchecks", []interface{}{})
+	fake.healthchecksMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeHistory) HealthchecksCallCount() int {
+	fake.healthchecksMutex.RLock()
+	defer fake.healthchecksMutex.RUnlock()
+	return len(fake.healthchecksArgsForCall)
+}
+
+func (fake *FakeHistory) HealthchecksCalls(stub func() map[string][]history.Healthcheck) {
+	fake.healthchecksMutex.Lock()
+	defer fake.healthchecksMutex.Unlock()
+	fake.HealthchecksStub = stub
+}
+
+func (fake *FakeHistory) HealthchecksReturns(result1 map[string][]history.Healthcheck) {
+	fake.healthchecksMutex.Lock()
+	defer fake.healthchecksMutex.Unlock()
+	fake.HealthchecksStub = nil
+	fake.healthchecksReturns = struct {
+		result1 map[string][]history.Healthcheck
+	}{result1}
+}
+
+func (fake *FakeHistory) HealthchecksReturnsOnCall(i int, result1 map[string][]history.Healthcheck) {
+	fake.healthchecksMutex.Lock()
+	defer fake.healthchecksMutex.Unlock()
+	fake.HealthchecksStub = nil
+	if fake.healthchecksReturnsOnCall == nil {
+		fake.healthchecksReturnsOnCall = make(map[int]struct {
+			result1 map[string][]history.Healthcheck
+		})
+	}
+	fake.healthchecksReturnsOnCall[i] = struct {
+		result1 map[string][]history.Healthcheck
+	}{result1}
+}
+
+func (fake *FakeHistory) Sessions() []history.SessionSample {
+	fake.sessionsMutex.Lock()
+	ret, specificReturn := fake.sessionsReturnsOnCall[len(fake.sessionsArgsForCall)]
+	fake.sessionsArgsForCall = append(fake.sessionsArgsForCall, struct {
+	}{})
+	stub := fake.SessionsStub
+	fakeReturns := fake.sessionsReturns
+	fake.recordInvocation("Sessions", []interface{}{})
+	fake.sessionsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeHistory) SessionsCallCount() int {
+	fake.sessionsMutex.RLock()
+	defer fake.sessionsMutex.RUnlock()
+	return len(fake.sessionsArgsForCall)
+}
+
+func (fake *FakeHistory) SessionsCalls(stub func() []history.SessionSample) {
+	fake.sessionsMutex.Lock()
+	defer fake.sessionsMutex.Unlock()
+	fake.SessionsStub = stub
+}
+
+func (fake *FakeHistory) SessionsReturns(result1 []history.SessionSample) {
+	fake.sessionsMutex.Lock()
+	defer fake.sessionsMutex.Unlock()
+	fake.SessionsStub = nil
+	fake.sessionsReturns = struct {
+		result1 []history.SessionSample
+	}{result1}
+}
+
+func (fake *FakeHistory) SessionsReturnsOnCall(i int, result1 []history.SessionSample) {
+	fake.sessionsMutex.Lock()
+	defer fake.sessionsMutex.Unlock()
+	fake.SessionsStub = nil
+	if fake.sessionsReturnsOnCall == nil {
+		fake.sessionsReturnsOnCall = make(map[int]struct {
+			result1 []history.SessionSample
+		})
+	}
+	fake.sessionsReturnsOnCall[i] = struct {
+		result1 []history.SessionSample
+	}{result1}
+}
+
+func (fake *FakeHistory) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeHistory) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ api.History = new(FakeHistory)
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	"github.com/cloudfoundry-incubator/switchboard/capture"
+)
+
+// Error codes returned by the capture endpoint.
+const (
+	ErrCodeCaptureRunning    = "capture_running"
+	ErrCodeCaptureNotRunning = "capture_not_running"
+)
+
+// Captures starts and stops captures of the traffic of selected sessions.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Captures
+type Captures interface {
+	Start(filter capture.Filter, duration time.Duration, maxBytes int64) (capture.Status, error)
+	Stop() (capture.Status, error)
+	Status() (capture.Status, bool)
+}
+
+// V1CaptureRequest is the body of POST /v1/capture. Client or Backend is
+// required; zero DurationSeconds and MaxBytes stand for the proxy's limits.
+type V1CaptureRequest struct {
+	Client          string `json:"client"`
+	Backend         string `json:"backend"`
+	DurationSeconds uint   `json:"durationSeconds"`
+	MaxBytes        int64  `json:"maxBytes"`
+}
+
+// V1CaptureEndpoint serves the status of the running or last capture, starts
+// a capture on POST and stops it on DELETE.
+var V1CaptureEndpoint = func(captures Captures, logger lager.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			status, ok := captures.Status()
+			if !ok {
+				writeV1Error(w, http.StatusNotFound, ErrCodeNotFound, "no capture has been started")
+				return
+			}
+			writeV1JSON(w, http.StatusOK, status)
+		case http.MethodPost:
+			handleV1StartCapture(w, req, captures, logger)
+		case http.MethodDelete:
+			status, err := captures.Stop()
+			if errors.Is(err, capture.ErrNotRunning) {
+				writeV1Error(w, http.StatusConflict, ErrCodeCaptureNotRunning, err.Error())
+				return
+			}
+			if err != nil {
+				writeV1Error(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+				return
+			}
+			logger.Info("API /v1/capture stopped", lager.Data{"path": status.Path})
+			writeV1JSON(w, http.StatusOK, status)
+		default:
+			writeV1Error(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		}
+	})
+}
+
+func handleV1StartCapture(w http.ResponseWriter, req *http.Request, captures Captures, logger lager.Logger) {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		writeV1Error(w, http.StatusUnsupportedMediaType, ErrCodeUnsupportedMediaType, "request body must be application/json")
+		return
+	}
+
+	var request V1CaptureRequest
+	decoder := json.NewDecoder(io.LimitReader(req.Body, maxV1RequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		writeV1Error(w, http.StatusBadRequest, ErrCodeInvalidJSON, err.Error())
+		return
+	}
+
+	status, err := captures.Start(
+		capture.Filter{Client: request.Client, Backend: request.Backend},
+		time.Duration(request.DurationSeconds)*time.Second,
+		request.MaxBytes,
+	)
+	switch {
+	case errors.Is(err, capture.ErrInvalid):
+		writeV1Error(w, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	case errors.Is(err, capture.ErrRunning):
+		writeV1Error(w, http.StatusConflict, ErrCodeCaptureRunning, err.Error())
+		return
+	case err != nil:
+		writeV1Error(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	logger.Info("API /v1/capture started", lager.Data{"path": status.Path, "filter": status.Filter})
+	writeV1JSON(w, http.StatusCreated, status)
+}
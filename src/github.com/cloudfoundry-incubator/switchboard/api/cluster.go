@@ -13,6 +13,7 @@ type ClusterManager interface {
 	AsJSON() ClusterJSON
 	EnableTraffic(string)
 	DisableTraffic(string)
+	UpdateTraffic(enabled bool, message string, ifMatch string) (ClusterJSON, error)
 }
 
 var ClusterEndpoint = func(clusterManager ClusterManager, logger lager.Logger) http.HandlerFunc {
@@ -22,8 +23,9 @@ var ClusterEndpoint = func(clusterManager ClusterManager, logger lager.Logger) h
 			writeClusterResponse(w, clusterManager)
 			return
 		case "PATCH":
-			handleUpdate(w, req, clusterManager, logger)
-			writeClusterResponse(w, clusterManager)
+			if handleUpdate(w, req, clusterManager, logger) {
+				writeClusterResponse(w, clusterManager)
+			}
 			return
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -51,13 +53,13 @@ func handleUpdate(
 	req *http.Request,
 	cluster ClusterManager,
 	logger lager.Logger,
-) {
+) bool {
 	logger.Debug("API /cluster update")
 
 	err := req.ParseForm()
 	if err != nil {
 		http.Error(w, "Failed to parse form", http.StatusBadRequest)
-		return
+		return false
 	}
 
 	logger.Debug("API /cluster req form", lager.Data{"form": req.Form})
@@ -66,7 +68,7 @@ func handleUpdate(
 	enabled, err := strconv.ParseBool(enabledStr)
 	if err != nil {
 		http.Error(w, "Failed to parse trafficEnabled", http.StatusBadRequest)
-		return
+		return false
 	}
 
 	if enabled {
@@ -76,8 +78,10 @@ func handleUpdate(
 		message := req.Form.Get("message")
 		if message == "" {
 			http.Error(w, "message must not be empty", http.StatusBadRequest)
-			return
+			return false
 		}
 		cluster.DisableTraffic(message)
 	}
+
+	return true
 }
@@ -15,8 +15,10 @@ type ClusterAPI struct {
 	lastUpdated         time.Time
 	trafficEnabled      bool
 	trafficEnabledChans []chan<- bool
+	trafficHooks        []func(enabled bool, message string)
 	ActiveBackendChan   chan *domain.Backend
 	activeBackend       *BackendJSON
+	stateStore          StateStore
 }
 
 func NewClusterAPI(
@@ -33,6 +35,45 @@ func (c *ClusterAPI) RegisterTrafficEnabledChan(chanToRegister chan bool) {
 	c.trafficEnabledChans = append(c.trafficEnabledChans, chanToRegister)
 }
 
+// OnTrafficChange calls f after each change of the traffic state through
+// EnableTraffic, DisableTraffic or UpdateTraffic, with the new state and its
+// message. f is called with the state locked, so it must not block.
+func (c *ClusterAPI) OnTrafficChange(f func(enabled bool, message string)) {
+	c.trafficHooks = append(c.trafficHooks, f)
+}
+
+// UseStateStore persists every subsequent traffic change to store. When
+// restore is true, previously persisted state is loaded first; otherwise it is
+// overwritten with the current state. Call it before registering subscribers,
+// as the restored state is not published to them.
+func (c *ClusterAPI) UseStateStore(store StateStore, restore bool) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.stateStore = store
+
+	if restore {
+		state, found, err := store.Load()
+		if err != nil {
+			return err
+		}
+
+		if found {
+			c.trafficEnabled = state.TrafficEnabled
+			c.message = state.Message
+			c.lastUpdated = state.LastUpdated
+			c.logger.Info("Restored persisted traffic state", lager.Data{
+				"trafficEnabled": state.TrafficEnabled,
+				"message":        state.Message,
+				"lastUpdated":    state.LastUpdated,
+			})
+			return nil
+		}
+	}
+
+	return store.Save(c.unsafeState())
+}
+
 func (c *ClusterAPI) ListenForActiveBackend() {
 	for b := range c.ActiveBackendChan {
 		c.mutex.Lock()
@@ -57,6 +98,18 @@ func (c *ClusterAPI) AsJSON() ClusterJSON {
 	c.mutex.RLock()
 	defer c.mutex.RUnlock()
 
+	return c.unsafeAsJSON()
+}
+
+// TrafficMessage returns the message given with the last traffic change.
+func (c *ClusterAPI) TrafficMessage() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.message
+}
+
+func (c *ClusterAPI) unsafeAsJSON() ClusterJSON {
 	return ClusterJSON{
 		TrafficEnabled: c.trafficEnabled,
 		Message:        c.message,
@@ -69,30 +122,71 @@ func (c *ClusterAPI) EnableTraffic(message string) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	c.logger.Info("Enabling traffic for cluster", lager.Data{"message": message})
+	c.unsafeSetTraffic(true, message)
+}
 
-	c.message = message
-	c.lastUpdated = time.Now()
-	c.trafficEnabled = true
+func (c *ClusterAPI) DisableTraffic(message string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-	for _, trafficEnabledChan := range c.trafficEnabledChans {
-		trafficEnabledChan <- c.trafficEnabled
-	}
+	c.unsafeSetTraffic(false, message)
 }
 
-func (c *ClusterAPI) DisableTraffic(message string) {
+// UpdateTraffic sets the traffic state like EnableTraffic and DisableTraffic,
+// but only when ifMatch is empty, "*", or lists the ETag of the current
+// state. Otherwise it returns ErrPreconditionFailed and leaves the state
+// unchanged.
+func (c *ClusterAPI) UpdateTraffic(enabled bool, message string, ifMatch string) (ClusterJSON, error) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	c.logger.Info("Disabling traffic for cluster", lager.Data{"message": message})
+	if ifMatch != "" && !matchesETag(ifMatch, c.unsafeState().ETag()) {
+		return c.unsafeAsJSON(), ErrPreconditionFailed
+	}
+
+	c.unsafeSetTraffic(enabled, message)
+
+	return c.unsafeAsJSON(), nil
+}
+
+func (c *ClusterAPI) unsafeSetTraffic(enabled bool, message string) {
+	if enabled {
+		c.logger.Info("Enabling traffic for cluster", lager.Data{"message": message})
+	} else {
+		c.logger.Info("Disabling traffic for cluster", lager.Data{"message": message})
+	}
 
 	c.message = message
 	c.lastUpdated = time.Now()
-	c.trafficEnabled = false
+	c.trafficEnabled = enabled
+
+	c.persist()
 
 	for _, trafficEnabledChan := range c.trafficEnabledChans {
 		trafficEnabledChan <- c.trafficEnabled
 	}
+
+	for _, f := range c.trafficHooks {
+		f(c.trafficEnabled, message)
+	}
+}
+
+func (c *ClusterAPI) persist() {
+	if c.stateStore == nil {
+		return
+	}
+
+	if err := c.stateStore.Save(c.unsafeState()); err != nil {
+		c.logger.Error("Failed to persist traffic state", err)
+	}
+}
+
+func (c *ClusterAPI) unsafeState() ClusterState {
+	return ClusterState{
+		TrafficEnabled: c.trafficEnabled,
+		Message:        c.message,
+		LastUpdated:    c.lastUpdated,
+	}
 }
 
 type ClusterJSON struct {
@@ -102,6 +196,16 @@ type ClusterJSON struct {
 	LastUpdated    time.Time    `json:"lastUpdated"`
 }
 
+// ETag identifies the traffic state of the cluster. Active backend changes
+// are deliberately excluded, so they never invalidate an operator's update.
+func (j ClusterJSON) ETag() string {
+	return ClusterState{
+		TrafficEnabled: j.TrafficEnabled,
+		Message:        j.Message,
+		LastUpdated:    j.LastUpdated,
+	}.ETag()
+}
+
 type BackendJSON struct {
 	Host string `json:"host"`
 	Port uint   `json:"port"`
@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"errors"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -9,6 +10,7 @@ import (
 	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/lager/v3/lagertest"
 	"github.com/cloudfoundry-incubator/switchboard/api"
+	"github.com/cloudfoundry-incubator/switchboard/api/apifakes"
 	"github.com/cloudfoundry-incubator/switchboard/domain"
 )
 
@@ -134,6 +136,7 @@ var _ = Describe("ClusterAPI", func() {
 			clusterJSON := cluster.AsJSON()
 
 			Expect(clusterJSON.Message).To(Equal(message))
+			Expect(cluster.TrafficMessage()).To(Equal(message))
 		})
 
 		It("records the current time", func() {
@@ -147,4 +150,199 @@ var _ = Describe("ClusterAPI", func() {
 			Expect(clusterJSON.LastUpdated.Before(afterTime)).To(BeTrue())
 		})
 	})
+
+	Describe("UpdateTraffic", func() {
+		It("applies the update without a precondition", func() {
+			clusterJSON, err := cluster.UpdateTraffic(false, "fencing", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusterJSON.TrafficEnabled).To(BeFalse())
+			Expect(clusterJSON.Message).To(Equal("fencing"))
+			Eventually(trafficEnabledChan1).Should(Receive(BeFalse()))
+		})
+
+		It("applies the update when the ETag matches", func() {
+			etag := cluster.AsJSON().ETag()
+
+			clusterJSON, err := cluster.UpdateTraffic(false, "fencing", etag)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusterJSON.ETag()).NotTo(Equal(etag))
+		})
+
+		It("applies the update whatever the state when the precondition is *", func() {
+			cluster.DisableTraffic("someone else")
+
+			clusterJSON, err := cluster.UpdateTraffic(true, "mine", "*")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusterJSON.TrafficEnabled).To(BeTrue())
+		})
+
+		It("rejects the update when the ETag does not match", func() {
+			etag := cluster.AsJSON().ETag()
+			cluster.DisableTraffic("someone else")
+			Eventually(trafficEnabledChan1).Should(Receive())
+
+			clusterJSON, err := cluster.UpdateTraffic(true, "mine", etag)
+			Expect(err).To(MatchError(api.ErrPreconditionFailed))
+			Expect(clusterJSON.Message).To(Equal("someone else"))
+			Expect(cluster.AsJSON().TrafficEnabled).To(BeFalse())
+			Consistently(trafficEnabledChan1).ShouldNot(Receive())
+		})
+
+		It("is not affected by active backend changes", func() {
+			etag := cluster.AsJSON().ETag()
+
+			go cluster.ListenForActiveBackend()
+			cluster.ActiveBackendChan <- domain.NewBackend("backend-0", "192.0.2.10", 3306, 9292, "", logger)
+			Eventually(func() *api.BackendJSON { return cluster.AsJSON().ActiveBackend }).ShouldNot(BeNil())
+
+			Expect(cluster.AsJSON().ETag()).To(Equal(etag))
+		})
+	})
+
+	Describe("OnTrafficChange", func() {
+		type change struct {
+			enabled bool
+			message string
+		}
+		var changes []change
+
+		JustBeforeEach(func() {
+			changes = nil
+			cluster.OnTrafficChange(func(enabled bool, message string) {
+				changes = append(changes, change{enabled: enabled, message: message})
+			})
+		})
+
+		It("reports each change of the traffic state", func() {
+			cluster.DisableTraffic("maintenance")
+			_, err := cluster.UpdateTraffic(true, "done", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(changes).To(Equal([]change{
+				{enabled: false, message: "maintenance"},
+				{enabled: true, message: "done"},
+			}))
+		})
+
+		It("does not report rejected updates", func() {
+			_, err := cluster.UpdateTraffic(false, "mine", `"stale"`)
+			Expect(err).To(MatchError(api.ErrPreconditionFailed))
+
+			Expect(changes).To(BeEmpty())
+		})
+	})
+
+	Describe("UseStateStore", func() {
+		var (
+			store   *apifakes.FakeStateStore
+			restore bool
+			err     error
+		)
+
+		BeforeEach(func() {
+			store = new(apifakes.FakeStateStore)
+			restore = true
+		})
+
+		JustBeforeEach(func() {
+			err = cluster.UseStateStore(store, restore)
+		})
+
+		When("state has been persisted", func() {
+			var lastUpdated time.Time
+
+			BeforeEach(func() {
+				lastUpdated = time.Now().Add(-time.Hour)
+				store.LoadReturns(api.ClusterState{
+					TrafficEnabled: false,
+					Message:        "data repair",
+					LastUpdated:    lastUpdated,
+				}, true, nil)
+			})
+
+			It("restores the persisted state", func() {
+				Expect(err).NotTo(HaveOccurred())
+
+				clusterJSON := cluster.AsJSON()
+				Expect(clusterJSON.TrafficEnabled).To(BeFalse())
+				Expect(clusterJSON.Message).To(Equal("data repair"))
+				Expect(clusterJSON.LastUpdated).To(Equal(lastUpdated))
+			})
+
+			It("does not publish the restored state", func() {
+				Consistently(trafficEnabledChan1).ShouldNot(Receive())
+			})
+
+			It("does not rewrite the persisted state", func() {
+				Expect(store.SaveCallCount()).To(BeZero())
+			})
+
+			When("configured to reset the state", func() {
+				BeforeEach(func() {
+					restore = false
+				})
+
+				It("ignores the persisted state", func() {
+					Expect(store.LoadCallCount()).To(BeZero())
+					Expect(cluster.AsJSON().TrafficEnabled).To(BeTrue())
+				})
+
+				It("overwrites the persisted state", func() {
+					Expect(store.SaveCallCount()).To(Equal(1))
+					Expect(store.SaveArgsForCall(0).TrafficEnabled).To(BeTrue())
+				})
+			})
+		})
+
+		When("no state has been persisted", func() {
+			It("starts with traffic enabled and persists it", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cluster.AsJSON().TrafficEnabled).To(BeTrue())
+				Expect(store.SaveCallCount()).To(Equal(1))
+				Expect(store.SaveArgsForCall(0).TrafficEnabled).To(BeTrue())
+			})
+		})
+
+		When("loading the state fails", func() {
+			BeforeEach(func() {
+				store.LoadReturns(api.ClusterState{}, false, errors.New("corrupt"))
+			})
+
+			It("returns the error", func() {
+				Expect(err).To(MatchError("corrupt"))
+			})
+		})
+
+		Describe("changing traffic", func() {
+			It("persists disabling traffic", func() {
+				cluster.DisableTraffic("fencing")
+
+				Expect(store.SaveCallCount()).To(Equal(2))
+				state := store.SaveArgsForCall(1)
+				Expect(state.TrafficEnabled).To(BeFalse())
+				Expect(state.Message).To(Equal("fencing"))
+				Expect(state.LastUpdated).To(Equal(cluster.AsJSON().LastUpdated))
+			})
+
+			It("persists enabling traffic", func() {
+				cluster.EnableTraffic("done")
+
+				Expect(store.SaveCallCount()).To(Equal(2))
+				Expect(store.SaveArgsForCall(1).TrafficEnabled).To(BeTrue())
+			})
+
+			When("persisting fails", func() {
+				BeforeEach(func() {
+					store.SaveReturns(errors.New("disk full"))
+				})
+
+				It("still applies and publishes the change", func() {
+					cluster.DisableTraffic("fencing")
+
+					Expect(cluster.AsJSON().TrafficEnabled).To(BeFalse())
+					Eventually(trafficEnabledChan1).Should(Receive(BeFalse()))
+				})
+			})
+		})
+	})
 })
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ClusterState is the subset of ClusterAPI state that survives a proxy restart.
+type ClusterState struct {
+	TrafficEnabled bool      `json:"trafficEnabled"`
+	Message        string    `json:"message"`
+	LastUpdated    time.Time `json:"lastUpdated"`
+}
+
+var ErrPreconditionFailed = errors.New("cluster state does not match If-Match")
+
+// ETag is a strong entity tag for the state, quoted as required by RFC 9110.
+func (s ClusterState) ETag() string {
+	data, _ := json.Marshal(ClusterState{
+		TrafficEnabled: s.TrafficEnabled,
+		Message:        s.Message,
+		LastUpdated:    s.LastUpdated.UTC(),
+	})
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// matchesETag reports whether the If-Match header ifMatch, "*" or a list of
+// entity tags, matches etag. Weak tags never match, as If-Match uses strong
+// comparison.
+func matchesETag(ifMatch, etag string) bool {
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . StateStore
+type StateStore interface {
+	// Load returns the persisted state, or false if nothing has been persisted yet.
+	Load() (ClusterState, bool, error)
+	Save(ClusterState) error
+}
+
+type fileStateStore struct {
+	path string
+}
+
+func NewFileStateStore(path string) StateStore {
+	return &fileStateStore{path: path}
+}
+
+func (s *fileStateStore) Load() (ClusterState, bool, error) {
+	var state ClusterState
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, false, nil
+	}
+	if err != nil {
+		return state, false, fmt.Errorf("reading traffic state file %s: %w", s.path, err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, false, fmt.Errorf("parsing traffic state file %s: %w", s.path, err)
+	}
+
+	return state, true, nil
+}
+
+// Save writes the state to a temporary file and renames it into place, so a
+// crash mid-write never leaves a truncated state file behind.
+func (s *fileStateStore) Save(state ClusterState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("writing traffic state file %s: %w", s.path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing traffic state file %s: %w", s.path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing traffic state file %s: %w", s.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing traffic state file %s: %w", s.path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("writing traffic state file %s: %w", s.path, err)
+	}
+
+	return nil
+}
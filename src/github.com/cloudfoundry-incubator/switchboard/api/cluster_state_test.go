@@ -0,0 +1,59 @@
+package api_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/api"
+)
+
+var _ = Describe("FileStateStore", func() {
+	var (
+		path  string
+		store api.StateStore
+	)
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "traffic-state.json")
+		store = api.NewFileStateStore(path)
+	})
+
+	It("reports nothing persisted when the file does not exist", func() {
+		_, found, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+
+	It("round-trips saved state", func() {
+		state := api.ClusterState{
+			TrafficEnabled: false,
+			Message:        "data repair",
+			LastUpdated:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		}
+		Expect(store.Save(state)).To(Succeed())
+
+		loaded, found, err := store.Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(loaded).To(Equal(state))
+	})
+
+	It("does not leave temporary files behind", func() {
+		Expect(store.Save(api.ClusterState{TrafficEnabled: true})).To(Succeed())
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+	})
+
+	It("returns an error when the file is corrupt", func() {
+		Expect(os.WriteFile(path, []byte("{not json"), 0600)).To(Succeed())
+
+		_, _, err := store.Load()
+		Expect(err).To(MatchError(ContainSubstring("parsing traffic state file")))
+	})
+})
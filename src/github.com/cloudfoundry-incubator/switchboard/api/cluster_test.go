@@ -3,6 +3,7 @@ package api_test
 import (
 	"encoding/base64"
 	"encoding/json"
+	"io"
 	"net/http"
 	"time"
 
@@ -187,6 +188,21 @@ var _ = Describe("ClusterEndpoint", func() {
 
 					Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
 				})
+
+				It("does not append the cluster state to the error", func() {
+					fakeCluster.AsJSONReturns(api.ClusterJSON{Message: "cluster-state"})
+
+					req, err := http.NewRequest("PATCH", server.URL()+"?trafficEnabled=false", nil)
+					Expect(err).NotTo(HaveOccurred())
+
+					client := &http.Client{}
+					resp, err := client.Do(req)
+					Expect(err).NotTo(HaveOccurred())
+
+					body, err := io.ReadAll(resp.Body)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(body)).To(Equal("message must not be empty\n"))
+				})
 			})
 
 			Context("when the URL is missing trafficEnabled", func() {
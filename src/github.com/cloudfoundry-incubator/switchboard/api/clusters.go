@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+// Cluster is one of the clusters a proxy routes to. Each is served under
+// /v0/clusters/<Name> and /v1/clusters/<Name>.
+type Cluster struct {
+	Name           string
+	ClusterManager ClusterManager
+	Backends       *domain.BackendSet
+	// History, when set, is served under /v1/clusters/<Name>/history.
+	History History
+}
+
+type ClusterSummary struct {
+	Name string `json:"name"`
+	ClusterJSON
+}
+
+func summarize(clusters []Cluster) []ClusterSummary {
+	summaries := []ClusterSummary{}
+	for _, c := range clusters {
+		summaries = append(summaries, ClusterSummary{
+			Name:        c.Name,
+			ClusterJSON: c.ClusterManager.AsJSON(),
+		})
+	}
+	return summaries
+}
+
+var ClustersIndex = func(clusters []Cluster) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		clustersJSON, err := json.Marshal(summarize(clusters))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, err = w.Write(clustersJSON)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	})
+}
+
+var V1ClustersIndex = func(clusters []Cluster) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeV1Error(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		writeV1JSON(w, http.StatusOK, summarize(clusters))
+	})
+}
@@ -0,0 +1,136 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing/fstest"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/api"
+	"github.com/cloudfoundry-incubator/switchboard/api/apifakes"
+	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/history"
+)
+
+var _ = Describe("Cluster endpoints", func() {
+	var (
+		server         *httptest.Server
+		defaultCluster *api.ClusterAPI
+		otherCluster   *api.ClusterAPI
+		otherBackend   *domain.Backend
+		otherHistory   *apifakes.FakeHistory
+	)
+
+	BeforeEach(func() {
+		logger := lagertest.NewTestLogger("clusters test")
+		defaultBackends := domain.NewBackendSet([]*domain.Backend{
+			domain.NewBackend("backend-0", "10.0.0.1", 3306, 9200, "api/v1/status", logger),
+		})
+		otherBackend = domain.NewBackend("backend-0", "10.0.1.1", 3306, 9200, "api/v1/status", logger)
+
+		defaultCluster = api.NewClusterAPI(logger)
+		otherCluster = api.NewClusterAPI(logger)
+		otherHistory = new(apifakes.FakeHistory)
+
+		server = httptest.NewServer(api.NewHandler(defaultCluster, defaultBackends, []api.Cluster{
+			{Name: "default", ClusterManager: defaultCluster, Backends: defaultBackends},
+			{Name: "cluster-b", ClusterManager: otherCluster, Backends: domain.NewBackendSet([]*domain.Backend{otherBackend}), History: otherHistory},
+		}, nil, nil, logger, config.API{
+			Username: "username",
+			Password: "password",
+		}, fstest.MapFS{}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	do := func(method, path, contentType, body string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		req.SetBasicAuth("username", "password")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	It("lists every cluster", func() {
+		otherCluster.DisableTraffic("maintenance")
+
+		resp := do("GET", "/v1/clusters", "", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		var clusters []api.ClusterSummary
+		Expect(json.NewDecoder(resp.Body).Decode(&clusters)).To(Succeed())
+		Expect(clusters).To(HaveLen(2))
+		Expect(clusters[0].Name).To(Equal("default"))
+		Expect(clusters[0].TrafficEnabled).To(BeTrue())
+		Expect(clusters[1].Name).To(Equal("cluster-b"))
+		Expect(clusters[1].TrafficEnabled).To(BeFalse())
+		Expect(clusters[1].Message).To(Equal("maintenance"))
+	})
+
+	It("serves the backends of a cluster by name", func() {
+		resp := do("GET", "/v0/clusters/cluster-b/backends", "", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		var backends []api.V0BackendResponse
+		Expect(json.NewDecoder(resp.Body).Decode(&backends)).To(Succeed())
+		Expect(backends).To(ConsistOf(api.V0BackendResponse{
+			Name: "backend-0", Host: "10.0.1.1", Port: 3306, TrafficEnabled: true,
+		}))
+	})
+
+	It("changes the traffic of only the named cluster", func() {
+		resp := do("PATCH", "/v0/clusters/cluster-b", "application/x-www-form-urlencoded", "trafficEnabled=false&message=repair")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		Expect(otherCluster.AsJSON().TrafficEnabled).To(BeFalse())
+		Expect(defaultCluster.AsJSON().TrafficEnabled).To(BeTrue())
+
+		resp = do("PATCH", "/v1/clusters/cluster-b", "application/json", `{"trafficEnabled": true}`)
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(otherCluster.AsJSON().TrafficEnabled).To(BeTrue())
+	})
+
+	It("releases a quarantined backend of the named cluster", func() {
+		otherBackend.EnableFlapDamping(1, time.Minute, time.Minute, time.Hour)
+		otherBackend.SetHealthy()
+		otherBackend.SetUnhealthy()
+		Expect(otherBackend.Quarantined()).To(BeTrue())
+
+		resp := do("DELETE", "/v1/clusters/cluster-b/backends/backend-0/quarantine", "", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(otherBackend.Quarantined()).To(BeFalse())
+	})
+
+	It("serves the history of the named cluster", func() {
+		otherHistory.FailoversReturns([]history.Failover{{From: "backend-0"}})
+
+		resp := do("GET", "/v1/clusters/cluster-b/history/failovers", "", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		var failovers []history.Failover
+		Expect(json.NewDecoder(resp.Body).Decode(&failovers)).To(Succeed())
+		Expect(failovers).To(ConsistOf(history.Failover{From: "backend-0"}))
+
+		resp = do("GET", "/v1/clusters/default/history/failovers", "", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+
+	It("returns 404 for an unknown cluster", func() {
+		resp := do("GET", "/v1/clusters/cluster-z/backends", "", "")
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+	})
+})
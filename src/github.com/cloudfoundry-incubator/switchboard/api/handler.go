@@ -1,7 +1,9 @@
 package api
 
 import (
+	"io/fs"
 	"net/http"
+	"strings"
 
 	"code.cloudfoundry.org/lager/v3"
 	"github.com/cloudfoundry-incubator/switchboard/api/middleware"
@@ -11,22 +13,60 @@ import (
 
 func NewHandler(
 	clusterManager ClusterManager,
-	backends []*domain.Backend,
+	backends *domain.BackendSet,
+	clusters []Cluster,
+	captures Captures,
+	clusterHistory History,
 	logger lager.Logger,
 	apiConfig config.API,
-	staticDir string,
+	assets fs.FS,
 ) http.Handler {
 	mux := http.NewServeMux()
 
-	mux.Handle("/", http.FileServer(http.Dir(staticDir)))
+	mux.Handle("/", http.FileServer(http.FS(assets)))
 
 	mux.Handle("/v0/backends", BackendsIndex(backends, clusterManager))
 	mux.Handle("/v0/cluster", ClusterEndpoint(clusterManager, logger))
 
+	mux.Handle("/v1/backends", V1BackendsIndex(backends, clusterManager))
+	mux.Handle("/v1/backends/", V1QuarantineEndpoint(backends, clusterManager, logger))
+	mux.Handle("/v1/cluster", V1ClusterEndpoint(clusterManager, logger))
+	mux.Handle("/v1/openapi.json", OpenAPIEndpoint)
+	if captures != nil {
+		mux.Handle("/v1/capture", V1CaptureEndpoint(captures, logger))
+	}
+	handleHistory(mux, "/v1", clusterHistory)
+
+	// The unnamespaced endpoints above serve the default cluster; every
+	// cluster, including the default one, is also served by name.
+	mux.Handle("/v0/clusters", ClustersIndex(clusters))
+	mux.Handle("/v1/clusters", V1ClustersIndex(clusters))
+	for _, c := range clusters {
+		v0Prefix, v1Prefix := "/v0/clusters/"+c.Name, "/v1/clusters/"+c.Name
+
+		mux.Handle(v0Prefix, ClusterEndpoint(c.ClusterManager, logger))
+		mux.Handle(v0Prefix+"/backends", BackendsIndex(c.Backends, c.ClusterManager))
+
+		mux.Handle(v1Prefix, V1ClusterEndpoint(c.ClusterManager, logger))
+		mux.Handle(v1Prefix+"/backends", V1BackendsIndex(c.Backends, c.ClusterManager))
+		mux.Handle(v1Prefix+"/backends/", V1QuarantineEndpoint(c.Backends, c.ClusterManager, logger))
+		handleHistory(mux, v1Prefix, c.History)
+	}
+
 	return middleware.Chain{
 		middleware.NewPanicRecovery(logger),
-		middleware.NewLogger(logger, "/v0"),
+		middleware.NewLogger(logger, "/v"),
 		middleware.NewHttpsEnforcer(apiConfig.ForceHttps),
-		middleware.NewBasicAuth(apiConfig.Username, apiConfig.Password),
+		middleware.NewBasicAuthResponding(apiConfig.Username, apiConfig.Password, http.HandlerFunc(unauthorized)),
 	}.Wrap(mux)
 }
+
+// unauthorized answers requests without valid credentials, with the v1 error
+// body on the v1 endpoints.
+func unauthorized(w http.ResponseWriter, req *http.Request) {
+	if strings.HasPrefix(req.URL.Path, "/v1/") {
+		writeV1Error(w, http.StatusUnauthorized, ErrCodeUnauthorized, "not authorized")
+		return
+	}
+	http.Error(w, "Not Authorized", http.StatusUnauthorized)
+}
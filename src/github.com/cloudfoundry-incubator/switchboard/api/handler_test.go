@@ -3,6 +3,7 @@ package api_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"testing/fstest"
 
 	"code.cloudfoundry.org/lager/v3/lagertest"
 	"github.com/cloudfoundry-incubator/switchboard/api"
@@ -24,24 +25,29 @@ var _ = Describe("Handler", func() {
 	)
 
 	JustBeforeEach(func() {
-		backends := []*domain.Backend{}
+		backends := domain.NewBackendSet(nil)
 
 		cluster = new(apifakes.FakeClusterManager)
 		logger := lagertest.NewTestLogger("Handler Test")
 
-		staticDir := ""
+		assets := fstest.MapFS{
+			"index.html": {Data: []byte("<html>switchboard</html>")},
+		}
 		handler = api.NewHandler(
 			cluster,
 			backends,
+			nil,
+			nil,
+			nil,
 			logger,
 			cfg,
-			staticDir,
+			assets,
 		)
 	})
 
 	Context("when a request panics", func() {
 		var (
-			realBackendsIndex func([]*domain.Backend, api.ClusterManager) http.Handler
+			realBackendsIndex func(*domain.BackendSet, api.ClusterManager) http.Handler
 			responseWriter    *apifakes.FakeResponseWriter
 			request           *http.Request
 		)
@@ -53,7 +59,7 @@ var _ = Describe("Handler", func() {
 				Password:   "bar",
 			}
 			realBackendsIndex = api.BackendsIndex
-			api.BackendsIndex = func([]*domain.Backend, api.ClusterManager) http.Handler {
+			api.BackendsIndex = func(*domain.BackendSet, api.ClusterManager) http.Handler {
 				return http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
 					panic("fake request panic")
 				})
@@ -78,6 +84,27 @@ var _ = Describe("Handler", func() {
 		})
 	})
 
+	Context("when the dashboard is requested", func() {
+		BeforeEach(func() {
+			cfg = config.API{
+				Username: "foo",
+				Password: "bar",
+			}
+		})
+
+		It("serves it from the assets", func() {
+			responseRecorder = httptest.NewRecorder()
+			request, err := http.NewRequest("GET", "/", nil)
+			Expect(err).NotTo(HaveOccurred())
+			request.SetBasicAuth("foo", "bar")
+
+			handler.ServeHTTP(responseRecorder, request)
+
+			Expect(responseRecorder.Code).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Body.String()).To(Equal("<html>switchboard</html>"))
+		})
+	})
+
 	Context("when request does not contain https header", func() {
 
 		var request *http.Request
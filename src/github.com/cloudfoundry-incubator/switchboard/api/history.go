@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/switchboard/history"
+)
+
+// History is the recent history of a cluster shown on the dashboard.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . History
+type History interface {
+	Sessions() []history.SessionSample
+	Failovers() []history.Failover
+	Healthchecks() map[string][]history.Healthcheck
+}
+
+// V1SessionHistory serves the session count of each backend over time.
+var V1SessionHistory = func(clusterHistory History) http.Handler {
+	return v1HistoryEndpoint(func() interface{} { return clusterHistory.Sessions() })
+}
+
+// V1FailoverHistory serves the recent changes of the active backend.
+var V1FailoverHistory = func(clusterHistory History) http.Handler {
+	return v1HistoryEndpoint(func() interface{} { return clusterHistory.Failovers() })
+}
+
+// V1HealthcheckHistory serves the recent healthchecks of each backend and
+// how long they took.
+var V1HealthcheckHistory = func(clusterHistory History) http.Handler {
+	return v1HistoryEndpoint(func() interface{} { return clusterHistory.Healthchecks() })
+}
+
+func v1HistoryEndpoint(series func() interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeV1Error(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		writeV1JSON(w, http.StatusOK, series())
+	})
+}
+
+func handleHistory(mux *http.ServeMux, prefix string, clusterHistory History) {
+	if clusterHistory == nil {
+		return
+	}
+
+	mux.Handle(prefix+"/history/sessions", V1SessionHistory(clusterHistory))
+	mux.Handle(prefix+"/history/failovers", V1FailoverHistory(clusterHistory))
+	mux.Handle(prefix+"/history/healthchecks", V1HealthcheckHistory(clusterHistory))
+}
@@ -7,6 +7,10 @@ import (
 
 type BasicAuth struct {
 	Username, Password string
+	// Unauthorized writes the response to requests without valid
+	// credentials, after the WWW-Authenticate header is set. When nil, the
+	// response is a plain text 401.
+	Unauthorized http.Handler
 }
 
 func NewBasicAuth(username, password string) Middleware {
@@ -16,6 +20,16 @@ func NewBasicAuth(username, password string) Middleware {
 	}
 }
 
+// NewBasicAuthResponding is NewBasicAuth with unauthorized writing the
+// response to requests without valid credentials.
+func NewBasicAuthResponding(username, password string, unauthorized http.Handler) Middleware {
+	return BasicAuth{
+		Username:     username,
+		Password:     password,
+		Unauthorized: unauthorized,
+	}
+}
+
 func (b BasicAuth) Wrap(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		username, password, ok := req.BasicAuth()
@@ -25,6 +39,10 @@ func (b BasicAuth) Wrap(next http.Handler) http.Handler {
 			next.ServeHTTP(rw, req)
 		} else {
 			rw.Header().Set("WWW-Authenticate", "Basic realm=\"Authorization Required\"")
+			if b.Unauthorized != nil {
+				b.Unauthorized.ServeHTTP(rw, req)
+				return
+			}
 			http.Error(rw, "Not Authorized", http.StatusUnauthorized)
 		}
 	})
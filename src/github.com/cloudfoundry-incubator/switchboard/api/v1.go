@@ -0,0 +1,222 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+const maxV1RequestSize = 64 * 1024
+
+// Error codes returned in V1Error.Code.
+const (
+	ErrCodeInvalidJSON          = "invalid_json"
+	ErrCodeInvalidRequest       = "invalid_request"
+	ErrCodeMessageRequired      = "message_required"
+	ErrCodeUnsupportedMediaType = "unsupported_media_type"
+	ErrCodeMethodNotAllowed     = "method_not_allowed"
+	ErrCodeUnauthorized         = "unauthorized"
+	ErrCodePreconditionFailed   = "precondition_failed"
+	ErrCodeNotFound             = "not_found"
+	ErrCodeNotQuarantined       = "not_quarantined"
+	ErrCodeInternal             = "internal_error"
+)
+
+//go:embed openapi.json
+var openAPIDocument []byte
+
+type V1Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type V1ErrorResponse struct {
+	Error V1Error `json:"error"`
+}
+
+type V1BackendResponse struct {
+	Host                string            `json:"host"`
+	Port                uint              `json:"port"`
+	Healthy             bool              `json:"healthy"`
+	Name                string            `json:"name"`
+	CurrentSessionCount uint              `json:"currentSessionCount"`
+	Active              bool              `json:"active"`
+	Labels              map[string]string `json:"labels,omitempty"`
+	QuarantinedUntil    *time.Time        `json:"quarantinedUntil,omitempty"`
+}
+
+// V1ClusterUpdate is the body of PATCH /v1/cluster. TrafficEnabled is
+// required; Message is required when disabling traffic.
+type V1ClusterUpdate struct {
+	TrafficEnabled *bool  `json:"trafficEnabled"`
+	Message        string `json:"message"`
+}
+
+func (bs Backends) AsV1JSON(cluster ClusterManager) []V1BackendResponse {
+	activeBackend := cluster.AsJSON().ActiveBackend
+
+	json := []V1BackendResponse{}
+	for _, b := range bs {
+		json = append(json, asV1Backend(b, activeBackend))
+	}
+	return json
+}
+
+func asV1Backend(b *domain.Backend, activeBackend *BackendJSON) V1BackendResponse {
+	j := b.AsJSON()
+	return V1BackendResponse{
+		Host:                j.Host,
+		Port:                j.Port,
+		Healthy:             j.Healthy,
+		Name:                j.Name,
+		CurrentSessionCount: j.CurrentSessionCount,
+		Active:              activeBackend != nil && j.Name == activeBackend.Name,
+		Labels:              j.Labels,
+		QuarantinedUntil:    j.QuarantinedUntil,
+	}
+}
+
+var V1BackendsIndex = func(backends *domain.BackendSet, clusterManager ClusterManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeV1Error(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		writeV1JSON(w, http.StatusOK, Backends(backends.All()).AsV1JSON(clusterManager))
+	})
+}
+
+// V1QuarantineEndpoint releases a backend quarantined for flapping on
+// DELETE /v1/backends/<name>/quarantine or
+// DELETE /v1/clusters/<cluster>/backends/<name>/quarantine. Backend names may
+// contain slashes.
+var V1QuarantineEndpoint = func(backends *domain.BackendSet, clusterManager ClusterManager, logger lager.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, name, ok := strings.Cut(req.URL.Path, "/backends/")
+		if ok {
+			name, ok = strings.CutSuffix(name, "/quarantine")
+		}
+		if !ok || name == "" {
+			writeV1Error(w, http.StatusNotFound, ErrCodeNotFound, "not found")
+			return
+		}
+
+		if req.Method != http.MethodDelete {
+			writeV1Error(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		for _, b := range backends.All() {
+			if b.AsJSON().Name != name {
+				continue
+			}
+
+			if !b.ReleaseQuarantine() {
+				writeV1Error(w, http.StatusConflict, ErrCodeNotQuarantined, "backend is not quarantined")
+				return
+			}
+
+			logger.Info("API /v1/backends quarantine released", lager.Data{"backend": name})
+			writeV1JSON(w, http.StatusOK, asV1Backend(b, clusterManager.AsJSON().ActiveBackend))
+			return
+		}
+
+		writeV1Error(w, http.StatusNotFound, ErrCodeNotFound, "no backend named "+name)
+	})
+}
+
+// V1ClusterEndpoint serves the cluster state with an ETag, and applies JSON
+// updates. Updates that carry an If-Match header other than * are rejected
+// with 412 when another operator has changed the traffic state since it was
+// read.
+var V1ClusterEndpoint = func(clusterManager ClusterManager, logger lager.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			writeV1Cluster(w, clusterManager.AsJSON())
+		case http.MethodPatch:
+			handleV1Update(w, req, clusterManager, logger)
+		default:
+			writeV1Error(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		}
+	})
+}
+
+var OpenAPIEndpoint = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = w.Write(openAPIDocument)
+})
+
+func handleV1Update(w http.ResponseWriter, req *http.Request, clusterManager ClusterManager, logger lager.Logger) {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		writeV1Error(w, http.StatusUnsupportedMediaType, ErrCodeUnsupportedMediaType, "request body must be application/json")
+		return
+	}
+
+	var update V1ClusterUpdate
+	decoder := json.NewDecoder(io.LimitReader(req.Body, maxV1RequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&update); err != nil {
+		writeV1Error(w, http.StatusBadRequest, ErrCodeInvalidJSON, err.Error())
+		return
+	}
+
+	if update.TrafficEnabled == nil {
+		writeV1Error(w, http.StatusBadRequest, ErrCodeInvalidRequest, "trafficEnabled is required")
+		return
+	}
+
+	if !*update.TrafficEnabled && update.Message == "" {
+		writeV1Error(w, http.StatusBadRequest, ErrCodeMessageRequired, "message must not be empty when disabling traffic")
+		return
+	}
+
+	logger.Debug("API /v1/cluster update", lager.Data{"trafficEnabled": *update.TrafficEnabled, "message": update.Message})
+
+	cluster, err := clusterManager.UpdateTraffic(*update.TrafficEnabled, update.Message, req.Header.Get("If-Match"))
+	if errors.Is(err, ErrPreconditionFailed) {
+		w.Header().Set("ETag", cluster.ETag())
+		writeV1Error(w, http.StatusPreconditionFailed, ErrCodePreconditionFailed, "the cluster state has changed since it was read; fetch it again and retry")
+		return
+	}
+	if err != nil {
+		writeV1Error(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	writeV1Cluster(w, cluster)
+}
+
+func writeV1Cluster(w http.ResponseWriter, cluster ClusterJSON) {
+	w.Header().Set("ETag", cluster.ETag())
+	writeV1JSON(w, http.StatusOK, cluster)
+}
+
+func writeV1Error(w http.ResponseWriter, status int, code, message string) {
+	writeV1JSON(w, status, V1ErrorResponse{
+		Error: V1Error{Code: code, Message: message},
+	})
+}
+
+func writeV1JSON(w http.ResponseWriter, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		body, _ = json.Marshal(V1ErrorResponse{Error: V1Error{Code: ErrCodeInternal, Message: err.Error()}})
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,369 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing/fstest"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/api"
+	"github.com/cloudfoundry-incubator/switchboard/api/apifakes"
+	"github.com/cloudfoundry-incubator/switchboard/capture"
+	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/history"
+)
+
+var _ = Describe("V1 API", func() {
+	var (
+		server         *httptest.Server
+		cluster        *api.ClusterAPI
+		backend        *domain.Backend
+		captures       *apifakes.FakeCaptures
+		clusterHistory *apifakes.FakeHistory
+	)
+
+	BeforeEach(func() {
+		logger := lagertest.NewTestLogger("v1 test")
+		backend = domain.NewBackend("backend-0", "10.0.0.1", 3306, 9200, "api/v1/status", logger)
+		backends := domain.NewBackendSet([]*domain.Backend{backend})
+
+		cluster = api.NewClusterAPI(logger)
+		captures = new(apifakes.FakeCaptures)
+		clusterHistory = new(apifakes.FakeHistory)
+		server = httptest.NewServer(api.NewHandler(cluster, backends, nil, captures, clusterHistory, logger, config.API{
+			Username: "username",
+			Password: "password",
+		}, fstest.MapFS{}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	do := func(method, path, body string, headers map[string]string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		req.SetBasicAuth("username", "password")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	patch := func(body string, headers map[string]string) *http.Response {
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		if _, ok := headers["Content-Type"]; !ok {
+			headers["Content-Type"] = "application/json"
+		}
+		return do("PATCH", "/v1/cluster", body, headers)
+	}
+
+	expectError := func(resp *http.Response, status int, code string) {
+		Expect(resp.StatusCode).To(Equal(status))
+		Expect(resp.Header.Get("Content-Type")).To(ContainSubstring("application/json"))
+
+		var errResp api.V1ErrorResponse
+		Expect(json.NewDecoder(resp.Body).Decode(&errResp)).To(Succeed())
+		Expect(errResp.Error.Code).To(Equal(code))
+		Expect(errResp.Error.Message).NotTo(BeEmpty())
+	}
+
+	Describe("without credentials", func() {
+		It("rejects v1 requests with a structured error", func() {
+			resp, err := http.Get(server.URL + "/v1/cluster")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resp.Header.Get("WWW-Authenticate")).To(HavePrefix("Basic"))
+			expectError(resp, http.StatusUnauthorized, api.ErrCodeUnauthorized)
+		})
+
+		It("rejects v0 requests as before", func() {
+			resp, err := http.Get(server.URL + "/v0/cluster")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+			Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/plain"))
+		})
+	})
+
+	Describe("GET /v1/backends", func() {
+		It("returns the backends", func() {
+			resp := do("GET", "/v1/backends", "", nil)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var backends []api.V1BackendResponse
+			Expect(json.NewDecoder(resp.Body).Decode(&backends)).To(Succeed())
+			Expect(backends).To(Equal([]api.V1BackendResponse{
+				{Name: "backend-0", Host: "10.0.0.1", Port: 3306},
+			}))
+		})
+
+		It("rejects other methods with a structured error", func() {
+			expectError(do("POST", "/v1/backends", "", nil), http.StatusMethodNotAllowed, api.ErrCodeMethodNotAllowed)
+		})
+	})
+
+	Describe("DELETE /v1/backends/<name>/quarantine", func() {
+		BeforeEach(func() {
+			backend.EnableFlapDamping(1, time.Minute, time.Hour, time.Hour)
+			backend.SetHealthy()
+			backend.SetUnhealthy()
+		})
+
+		It("releases the quarantined backend", func() {
+			var backends []api.V1BackendResponse
+			Expect(json.NewDecoder(do("GET", "/v1/backends", "", nil).Body).Decode(&backends)).To(Succeed())
+			Expect(backends[0].QuarantinedUntil).NotTo(BeNil())
+
+			resp := do("DELETE", "/v1/backends/backend-0/quarantine", "", nil)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var released api.V1BackendResponse
+			Expect(json.NewDecoder(resp.Body).Decode(&released)).To(Succeed())
+			Expect(released.Name).To(Equal("backend-0"))
+			Expect(released.QuarantinedUntil).To(BeNil())
+			Expect(backend.Quarantined()).To(BeFalse())
+		})
+
+		It("rejects releasing a backend that is not quarantined", func() {
+			Expect(backend.ReleaseQuarantine()).To(BeTrue())
+			expectError(do("DELETE", "/v1/backends/backend-0/quarantine", "", nil), http.StatusConflict, api.ErrCodeNotQuarantined)
+		})
+
+		It("rejects unknown backends", func() {
+			expectError(do("DELETE", "/v1/backends/backend-9/quarantine", "", nil), http.StatusNotFound, api.ErrCodeNotFound)
+		})
+
+		It("rejects other methods with a structured error", func() {
+			expectError(do("POST", "/v1/backends/backend-0/quarantine", "", nil), http.StatusMethodNotAllowed, api.ErrCodeMethodNotAllowed)
+		})
+	})
+
+	Describe("GET /v1/cluster", func() {
+		It("returns the cluster with an ETag", func() {
+			resp := do("GET", "/v1/cluster", "", nil)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(resp.Header.Get("ETag")).To(Equal(cluster.AsJSON().ETag()))
+
+			var clusterJSON api.ClusterJSON
+			Expect(json.NewDecoder(resp.Body).Decode(&clusterJSON)).To(Succeed())
+			Expect(clusterJSON.TrafficEnabled).To(BeTrue())
+		})
+	})
+
+	Describe("PATCH /v1/cluster", func() {
+		It("disables traffic", func() {
+			resp := patch(`{"trafficEnabled": false, "message": "data repair"}`, nil)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var clusterJSON api.ClusterJSON
+			Expect(json.NewDecoder(resp.Body).Decode(&clusterJSON)).To(Succeed())
+			Expect(clusterJSON.TrafficEnabled).To(BeFalse())
+			Expect(clusterJSON.Message).To(Equal("data repair"))
+
+			Expect(cluster.AsJSON().TrafficEnabled).To(BeFalse())
+			Expect(resp.Header.Get("ETag")).To(Equal(cluster.AsJSON().ETag()))
+		})
+
+		It("requires a message to disable traffic", func() {
+			expectError(patch(`{"trafficEnabled": false}`, nil), http.StatusBadRequest, api.ErrCodeMessageRequired)
+			Expect(cluster.AsJSON().TrafficEnabled).To(BeTrue())
+		})
+
+		It("requires trafficEnabled", func() {
+			expectError(patch(`{"message": "hi"}`, nil), http.StatusBadRequest, api.ErrCodeInvalidRequest)
+		})
+
+		It("rejects malformed JSON", func() {
+			expectError(patch(`{"trafficEnabled": `, nil), http.StatusBadRequest, api.ErrCodeInvalidJSON)
+		})
+
+		It("rejects unknown fields", func() {
+			expectError(patch(`{"trafficEnabled": true, "traficEnabled": false}`, nil), http.StatusBadRequest, api.ErrCodeInvalidJSON)
+		})
+
+		It("rejects form-encoded bodies", func() {
+			resp := patch("trafficEnabled=false&message=x", map[string]string{"Content-Type": "application/x-www-form-urlencoded"})
+			expectError(resp, http.StatusUnsupportedMediaType, api.ErrCodeUnsupportedMediaType)
+		})
+
+		Context("with If-Match", func() {
+			var etag string
+
+			BeforeEach(func() {
+				etag = do("GET", "/v1/cluster", "", nil).Header.Get("ETag")
+				Expect(etag).NotTo(BeEmpty())
+			})
+
+			It("applies the update when the state has not changed", func() {
+				resp := patch(`{"trafficEnabled": false, "message": "mine"}`, map[string]string{"If-Match": etag})
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(resp.Header.Get("ETag")).NotTo(Equal(etag))
+			})
+
+			It("rejects the update when another operator changed the state", func() {
+				cluster.DisableTraffic("theirs")
+
+				resp := patch(`{"trafficEnabled": true, "message": "mine"}`, map[string]string{"If-Match": etag})
+				Expect(resp.Header.Get("ETag")).To(Equal(cluster.AsJSON().ETag()))
+				expectError(resp, http.StatusPreconditionFailed, api.ErrCodePreconditionFailed)
+
+				Expect(cluster.AsJSON().TrafficEnabled).To(BeFalse())
+				Expect(cluster.AsJSON().Message).To(Equal("theirs"))
+			})
+
+			It("applies the update whatever the state when it is *", func() {
+				cluster.DisableTraffic("theirs")
+
+				resp := patch(`{"trafficEnabled": true, "message": "mine"}`, map[string]string{"If-Match": "*"})
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+				Expect(cluster.AsJSON().TrafficEnabled).To(BeTrue())
+			})
+
+			It("applies the update when one of several ETags matches", func() {
+				resp := patch(`{"trafficEnabled": false, "message": "mine"}`, map[string]string{"If-Match": `"stale", ` + etag})
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			})
+		})
+	})
+
+	Describe("/v1/capture", func() {
+		post := func(body string) *http.Response {
+			return do("POST", "/v1/capture", body, map[string]string{"Content-Type": "application/json"})
+		}
+
+		It("starts a capture", func() {
+			captures.StartReturns(capture.Status{Running: true, Path: "/captures/capture-1.swbcap"}, nil)
+
+			resp := post(`{"client": "10.0.0.9", "backend": "backend-0", "durationSeconds": 30, "maxBytes": 4096}`)
+			Expect(resp.StatusCode).To(Equal(http.StatusCreated))
+
+			var status capture.Status
+			Expect(json.NewDecoder(resp.Body).Decode(&status)).To(Succeed())
+			Expect(status.Path).To(Equal("/captures/capture-1.swbcap"))
+
+			Expect(captures.StartCallCount()).To(Equal(1))
+			filter, duration, maxBytes := captures.StartArgsForCall(0)
+			Expect(filter).To(Equal(capture.Filter{Client: "10.0.0.9", Backend: "backend-0"}))
+			Expect(duration).To(Equal(30 * time.Second))
+			Expect(maxBytes).To(Equal(int64(4096)))
+		})
+
+		It("rejects captures that cannot be started as requested", func() {
+			captures.StartReturns(capture.Status{}, fmt.Errorf("%w: a client or a backend is required", capture.ErrInvalid))
+			expectError(post(`{}`), http.StatusBadRequest, api.ErrCodeInvalidRequest)
+		})
+
+		It("rejects a second capture", func() {
+			captures.StartReturns(capture.Status{}, capture.ErrRunning)
+			expectError(post(`{"client": "10.0.0.9"}`), http.StatusConflict, api.ErrCodeCaptureRunning)
+		})
+
+		It("rejects unknown fields", func() {
+			expectError(post(`{"client": "10.0.0.9", "durationMillis": 30}`), http.StatusBadRequest, api.ErrCodeInvalidJSON)
+			Expect(captures.StartCallCount()).To(BeZero())
+		})
+
+		It("returns the status of the last capture", func() {
+			captures.StatusReturns(capture.Status{Path: "/captures/capture-1.swbcap", StopReason: capture.StopReasonExpired}, true)
+
+			resp := do("GET", "/v1/capture", "", nil)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var status capture.Status
+			Expect(json.NewDecoder(resp.Body).Decode(&status)).To(Succeed())
+			Expect(status.StopReason).To(Equal(capture.StopReasonExpired))
+		})
+
+		It("returns not found before the first capture", func() {
+			expectError(do("GET", "/v1/capture", "", nil), http.StatusNotFound, api.ErrCodeNotFound)
+		})
+
+		It("stops the running capture", func() {
+			captures.StopReturns(capture.Status{StopReason: capture.StopReasonStopped}, nil)
+
+			resp := do("DELETE", "/v1/capture", "", nil)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			Expect(captures.StopCallCount()).To(Equal(1))
+		})
+
+		It("rejects stopping when no capture is running", func() {
+			captures.StopReturns(capture.Status{}, capture.ErrNotRunning)
+			expectError(do("DELETE", "/v1/capture", "", nil), http.StatusConflict, api.ErrCodeCaptureNotRunning)
+		})
+	})
+
+	Describe("GET /v1/history", func() {
+		sampled := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+		It("returns the session counts over time", func() {
+			clusterHistory.SessionsReturns([]history.SessionSample{
+				{Time: sampled, Sessions: map[string]uint{"backend-0": 3}},
+			})
+
+			resp := do("GET", "/v1/history/sessions", "", nil)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var samples []history.SessionSample
+			Expect(json.NewDecoder(resp.Body).Decode(&samples)).To(Succeed())
+			Expect(samples).To(HaveLen(1))
+			Expect(samples[0].Time).To(BeTemporally("==", sampled))
+			Expect(samples[0].Sessions).To(Equal(map[string]uint{"backend-0": 3}))
+		})
+
+		It("returns the failovers", func() {
+			clusterHistory.FailoversReturns([]history.Failover{{Time: sampled, From: "backend-0"}})
+
+			resp := do("GET", "/v1/history/failovers", "", nil)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var failovers []map[string]interface{}
+			Expect(json.NewDecoder(resp.Body).Decode(&failovers)).To(Succeed())
+			Expect(failovers).To(ConsistOf(HaveKeyWithValue("from", "backend-0")))
+			Expect(failovers[0]).NotTo(HaveKey("to"))
+		})
+
+		It("returns the healthchecks of each backend", func() {
+			clusterHistory.HealthchecksReturns(map[string][]history.Healthcheck{
+				"backend-0": {{Time: sampled, Healthy: true, LatencyMillis: 1.5}},
+			})
+
+			resp := do("GET", "/v1/history/healthchecks", "", nil)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var healthchecks map[string][]history.Healthcheck
+			Expect(json.NewDecoder(resp.Body).Decode(&healthchecks)).To(Succeed())
+			Expect(healthchecks["backend-0"]).To(HaveLen(1))
+			Expect(healthchecks["backend-0"][0].LatencyMillis).To(Equal(1.5))
+		})
+
+		It("rejects other methods with a structured error", func() {
+			expectError(do("DELETE", "/v1/history/failovers", "", nil), http.StatusMethodNotAllowed, api.ErrCodeMethodNotAllowed)
+		})
+	})
+
+	Describe("GET /v1/openapi.json", func() {
+		It("serves the OpenAPI document", func() {
+			resp := do("GET", "/v1/openapi.json", "", nil)
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var doc map[string]interface{}
+			Expect(json.NewDecoder(resp.Body).Decode(&doc)).To(Succeed())
+			Expect(doc).To(HaveKeyWithValue("openapi", HavePrefix("3.")))
+			Expect(doc["paths"]).To(HaveKey("/v1/cluster"))
+		})
+	})
+})
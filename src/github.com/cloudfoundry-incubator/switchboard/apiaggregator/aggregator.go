@@ -0,0 +1,254 @@
+package apiaggregator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	"github.com/cloudfoundry-incubator/switchboard/api"
+	"github.com/cloudfoundry-incubator/switchboard/config"
+)
+
+const maxResponseSize = 1 << 20
+
+// Aggregator queries the API of every proxy in the deployment.
+type Aggregator struct {
+	client    *http.Client
+	proxyURIs []string
+	username  string
+	password  string
+	logger    lager.Logger
+}
+
+type ProxyStatus struct {
+	URI       string                  `json:"uri"`
+	Reachable bool                    `json:"reachable"`
+	Error     string                  `json:"error,omitempty"`
+	Cluster   *api.ClusterJSON        `json:"cluster,omitempty"`
+	Backends  []api.V0BackendResponse `json:"backends,omitempty"`
+}
+
+type BackendStatus struct {
+	Name string `json:"name"`
+	Host string `json:"host"`
+	Port uint   `json:"port"`
+	// Healthy is true only when every reachable proxy reports the backend healthy.
+	Healthy             bool     `json:"healthy"`
+	CurrentSessionCount uint     `json:"currentSessionCount"`
+	ActiveOn            []string `json:"activeOn"`
+}
+
+type ClusterStatus struct {
+	Proxies                   []ProxyStatus   `json:"proxies"`
+	Backends                  []BackendStatus `json:"backends"`
+	ActiveBackendDisagreement bool            `json:"activeBackendDisagreement"`
+}
+
+type TrafficUpdateResult struct {
+	URI       string           `json:"uri"`
+	Succeeded bool             `json:"succeeded"`
+	Error     string           `json:"error,omitempty"`
+	Cluster   *api.ClusterJSON `json:"cluster,omitempty"`
+}
+
+func NewAggregator(client *http.Client, apiConfig config.API, logger lager.Logger) *Aggregator {
+	var proxyURIs []string
+	for _, uri := range apiConfig.ProxyURIs {
+		proxyURIs = append(proxyURIs, proxyBaseURL(uri, apiConfig.ForceHttps))
+	}
+
+	return &Aggregator{
+		client:    client,
+		proxyURIs: proxyURIs,
+		username:  apiConfig.Username,
+		password:  apiConfig.Password,
+		logger:    logger,
+	}
+}
+
+// proxyBaseURL qualifies bare ProxyURIs (e.g. "0-proxy.example.com") with a scheme.
+func proxyBaseURL(uri string, forceHttps bool) string {
+	if strings.Contains(uri, "://") {
+		return strings.TrimSuffix(uri, "/")
+	}
+
+	if forceHttps {
+		return "https://" + uri
+	}
+	return "http://" + uri
+}
+
+func (a *Aggregator) Status() ClusterStatus {
+	proxies := make([]ProxyStatus, len(a.proxyURIs))
+
+	var wg sync.WaitGroup
+	for i, uri := range a.proxyURIs {
+		wg.Add(1)
+		go func(i int, uri string) {
+			defer wg.Done()
+			proxies[i] = a.queryProxy(uri)
+		}(i, uri)
+	}
+	wg.Wait()
+
+	return mergeStatus(proxies)
+}
+
+func (a *Aggregator) queryProxy(uri string) ProxyStatus {
+	status := ProxyStatus{URI: uri}
+
+	var cluster api.ClusterJSON
+	if err := a.getJSON(uri+"/v0/cluster", &cluster); err != nil {
+		a.logger.Error("Failed to query proxy cluster", err, lager.Data{"proxy": uri})
+		status.Error = err.Error()
+		return status
+	}
+
+	var backends []api.V0BackendResponse
+	if err := a.getJSON(uri+"/v0/backends", &backends); err != nil {
+		a.logger.Error("Failed to query proxy backends", err, lager.Data{"proxy": uri})
+		status.Error = err.Error()
+		return status
+	}
+
+	status.Reachable = true
+	status.Cluster = &cluster
+	status.Backends = backends
+	return status
+}
+
+func (a *Aggregator) getJSON(url string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	return a.do(req, v)
+}
+
+func (a *Aggregator) do(req *http.Request, v interface{}) error {
+	req.SetBasicAuth(a.username, a.password)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("reading response from %s: %w", req.URL, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s returned %s: %s", req.Method, req.URL, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("parsing response from %s: %w", req.URL, err)
+	}
+
+	return nil
+}
+
+// SetTraffic applies the same traffic change to every proxy concurrently.
+func (a *Aggregator) SetTraffic(enabled bool, message string) []TrafficUpdateResult {
+	results := make([]TrafficUpdateResult, len(a.proxyURIs))
+
+	form := url.Values{}
+	form.Set("trafficEnabled", strconv.FormatBool(enabled))
+	form.Set("message", message)
+	body := form.Encode()
+
+	var wg sync.WaitGroup
+	for i, uri := range a.proxyURIs {
+		wg.Add(1)
+		go func(i int, uri string) {
+			defer wg.Done()
+
+			result := TrafficUpdateResult{URI: uri}
+			defer func() { results[i] = result }()
+
+			req, err := http.NewRequest(http.MethodPatch, uri+"/v0/cluster", strings.NewReader(body))
+			if err != nil {
+				result.Error = err.Error()
+				return
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var cluster api.ClusterJSON
+			if err := a.do(req, &cluster); err != nil {
+				a.logger.Error("Failed to update traffic on proxy", err, lager.Data{"proxy": uri})
+				result.Error = err.Error()
+				return
+			}
+
+			result.Succeeded = true
+			result.Cluster = &cluster
+		}(i, uri)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func mergeStatus(proxies []ProxyStatus) ClusterStatus {
+	status := ClusterStatus{
+		Proxies:  proxies,
+		Backends: []BackendStatus{},
+	}
+
+	backendsByName := map[string]*BackendStatus{}
+	activeBackends := map[string]struct{}{}
+
+	for _, proxy := range proxies {
+		if !proxy.Reachable {
+			continue
+		}
+
+		activeName := ""
+		if proxy.Cluster.ActiveBackend != nil {
+			activeName = proxy.Cluster.ActiveBackend.Name
+		}
+		activeBackends[activeName] = struct{}{}
+
+		for _, b := range proxy.Backends {
+			merged, ok := backendsByName[b.Name]
+			if !ok {
+				merged = &BackendStatus{
+					Name:     b.Name,
+					Host:     b.Host,
+					Port:     b.Port,
+					Healthy:  true,
+					ActiveOn: []string{},
+				}
+				backendsByName[b.Name] = merged
+			}
+
+			merged.Healthy = merged.Healthy && b.Healthy
+			merged.CurrentSessionCount += b.CurrentSessionCount
+			if b.Name == activeName {
+				merged.ActiveOn = append(merged.ActiveOn, proxy.URI)
+			}
+		}
+	}
+
+	for _, b := range backendsByName {
+		status.Backends = append(status.Backends, *b)
+	}
+	sort.Slice(status.Backends, func(i, j int) bool {
+		return status.Backends[i].Name < status.Backends[j].Name
+	})
+
+	status.ActiveBackendDisagreement = len(activeBackends) > 1
+
+	return status
+}
@@ -0,0 +1,80 @@
+package apiaggregator
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+type trafficUpdateResponse struct {
+	Proxies []TrafficUpdateResult `json:"proxies"`
+}
+
+// ClusterEndpoint serves the merged view of every proxy on GET, and applies a
+// traffic change to every proxy on PATCH. The PATCH parameters match the
+// per-proxy /v0/cluster endpoint and are validated before any proxy is
+// contacted, so a malformed request never reaches only some of them.
+var ClusterEndpoint = func(aggregator *Aggregator, logger lager.Logger) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case "GET":
+			writeJSON(w, http.StatusOK, aggregator.Status())
+		case "PATCH":
+			handleUpdate(w, req, aggregator, logger)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func handleUpdate(
+	w http.ResponseWriter,
+	req *http.Request,
+	aggregator *Aggregator,
+	logger lager.Logger,
+) {
+	err := req.ParseForm()
+	if err != nil {
+		http.Error(w, "Failed to parse form", http.StatusBadRequest)
+		return
+	}
+
+	enabled, err := strconv.ParseBool(req.Form.Get("trafficEnabled"))
+	if err != nil {
+		http.Error(w, "Failed to parse trafficEnabled", http.StatusBadRequest)
+		return
+	}
+
+	message := req.Form.Get("message")
+	if !enabled && message == "" {
+		http.Error(w, "message must not be empty", http.StatusBadRequest)
+		return
+	}
+
+	logger.Info("Updating traffic on all proxies", lager.Data{"trafficEnabled": enabled, "message": message})
+
+	results := aggregator.SetTraffic(enabled, message)
+
+	status := http.StatusOK
+	for _, result := range results {
+		if !result.Succeeded {
+			status = http.StatusBadGateway
+		}
+	}
+
+	writeJSON(w, status, trafficUpdateResponse{Proxies: results})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,222 @@
+package apiaggregator_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/cloudfoundry-incubator/switchboard/api"
+	"github.com/cloudfoundry-incubator/switchboard/apiaggregator"
+	"github.com/cloudfoundry-incubator/switchboard/config"
+)
+
+var _ = Describe("ClusterEndpoint", func() {
+	var (
+		proxy0, proxy1 *ghttp.Server
+		server         *ghttp.Server
+	)
+
+	backends := func(active string, sessions0, sessions1 uint, healthy1 bool) []api.V0BackendResponse {
+		return []api.V0BackendResponse{
+			{Name: "backend-0", Host: "10.0.0.1", Port: 3306, Healthy: true, CurrentSessionCount: sessions0, Active: active == "backend-0"},
+			{Name: "backend-1", Host: "10.0.0.2", Port: 3306, Healthy: healthy1, CurrentSessionCount: sessions1, Active: active == "backend-1"},
+		}
+	}
+
+	cluster := func(active string) api.ClusterJSON {
+		return api.ClusterJSON{
+			TrafficEnabled: true,
+			ActiveBackend:  &api.BackendJSON{Name: active},
+		}
+	}
+
+	BeforeEach(func() {
+		proxy0 = ghttp.NewServer()
+		proxy1 = ghttp.NewServer()
+
+		aggregator := apiaggregator.NewAggregator(&http.Client{}, config.API{
+			ProxyURIs: []string{proxy0.URL(), proxy1.URL()},
+			Username:  "username",
+			Password:  "password",
+		}, lagertest.NewTestLogger("aggregator"))
+
+		server = ghttp.NewServer()
+		server.AppendHandlers(apiaggregator.ClusterEndpoint(aggregator, lagertest.NewTestLogger("aggregator")))
+	})
+
+	AfterEach(func() {
+		proxy0.Close()
+		proxy1.Close()
+		server.Close()
+	})
+
+	getStatus := func() apiaggregator.ClusterStatus {
+		resp, err := http.Get(server.URL())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		var status apiaggregator.ClusterStatus
+		Expect(json.NewDecoder(resp.Body).Decode(&status)).To(Succeed())
+		return status
+	}
+
+	Describe("GET", func() {
+		When("every proxy agrees on the active backend", func() {
+			BeforeEach(func() {
+				proxy0.RouteToHandler("GET", "/v0/cluster", ghttp.CombineHandlers(
+					ghttp.VerifyBasicAuth("username", "password"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, cluster("backend-0")),
+				))
+				proxy0.RouteToHandler("GET", "/v0/backends", ghttp.RespondWithJSONEncoded(http.StatusOK, backends("backend-0", 3, 0, true)))
+				proxy1.RouteToHandler("GET", "/v0/cluster", ghttp.RespondWithJSONEncoded(http.StatusOK, cluster("backend-0")))
+				proxy1.RouteToHandler("GET", "/v0/backends", ghttp.RespondWithJSONEncoded(http.StatusOK, backends("backend-0", 4, 1, false)))
+			})
+
+			It("sums session counts across proxies", func() {
+				status := getStatus()
+
+				Expect(status.Backends).To(HaveLen(2))
+				Expect(status.Backends[0].Name).To(Equal("backend-0"))
+				Expect(status.Backends[0].CurrentSessionCount).To(BeEquivalentTo(7))
+				Expect(status.Backends[1].CurrentSessionCount).To(BeEquivalentTo(1))
+			})
+
+			It("reports each proxy's view and the proxies using each backend", func() {
+				status := getStatus()
+
+				Expect(status.Proxies).To(HaveLen(2))
+				Expect(status.Proxies[0].Reachable).To(BeTrue())
+				Expect(status.Proxies[0].Cluster.ActiveBackend.Name).To(Equal("backend-0"))
+				Expect(status.Backends[0].ActiveOn).To(ConsistOf(proxy0.URL(), proxy1.URL()))
+				Expect(status.Backends[1].ActiveOn).To(BeEmpty())
+			})
+
+			It("only reports a backend healthy when every proxy does", func() {
+				status := getStatus()
+
+				Expect(status.Backends[0].Healthy).To(BeTrue())
+				Expect(status.Backends[1].Healthy).To(BeFalse())
+			})
+
+			It("does not flag a disagreement", func() {
+				Expect(getStatus().ActiveBackendDisagreement).To(BeFalse())
+			})
+		})
+
+		When("proxies disagree on the active backend", func() {
+			BeforeEach(func() {
+				proxy0.RouteToHandler("GET", "/v0/cluster", ghttp.RespondWithJSONEncoded(http.StatusOK, cluster("backend-0")))
+				proxy0.RouteToHandler("GET", "/v0/backends", ghttp.RespondWithJSONEncoded(http.StatusOK, backends("backend-0", 0, 0, true)))
+				proxy1.RouteToHandler("GET", "/v0/cluster", ghttp.RespondWithJSONEncoded(http.StatusOK, cluster("backend-1")))
+				proxy1.RouteToHandler("GET", "/v0/backends", ghttp.RespondWithJSONEncoded(http.StatusOK, backends("backend-1", 0, 0, true)))
+			})
+
+			It("flags the disagreement", func() {
+				status := getStatus()
+
+				Expect(status.ActiveBackendDisagreement).To(BeTrue())
+				Expect(status.Backends[0].ActiveOn).To(ConsistOf(proxy0.URL()))
+				Expect(status.Backends[1].ActiveOn).To(ConsistOf(proxy1.URL()))
+			})
+		})
+
+		When("a proxy is unreachable", func() {
+			BeforeEach(func() {
+				proxy0.RouteToHandler("GET", "/v0/cluster", ghttp.RespondWithJSONEncoded(http.StatusOK, cluster("backend-0")))
+				proxy0.RouteToHandler("GET", "/v0/backends", ghttp.RespondWithJSONEncoded(http.StatusOK, backends("backend-0", 2, 0, true)))
+				proxy1.RouteToHandler("GET", "/v0/cluster", ghttp.RespondWith(http.StatusUnauthorized, "Not Authorized"))
+			})
+
+			It("reports the proxy as unreachable and merges the rest", func() {
+				status := getStatus()
+
+				Expect(status.Proxies[1].Reachable).To(BeFalse())
+				Expect(status.Proxies[1].Error).To(ContainSubstring("401"))
+				Expect(status.Backends[0].CurrentSessionCount).To(BeEquivalentTo(2))
+				Expect(status.ActiveBackendDisagreement).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("PATCH", func() {
+		patch := func(form url.Values) *http.Response {
+			req, err := http.NewRequest("PATCH", server.URL(), strings.NewReader(form.Encode()))
+			Expect(err).NotTo(HaveOccurred())
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			resp, err := http.DefaultClient.Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			return resp
+		}
+
+		It("disables traffic on every proxy", func() {
+			for _, proxy := range []*ghttp.Server{proxy0, proxy1} {
+				proxy.RouteToHandler("PATCH", "/v0/cluster", ghttp.CombineHandlers(
+					ghttp.VerifyBasicAuth("username", "password"),
+					ghttp.VerifyForm(url.Values{"trafficEnabled": {"false"}, "message": {"data repair"}}),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, api.ClusterJSON{TrafficEnabled: false, Message: "data repair"}),
+				))
+			}
+
+			resp := patch(url.Values{"trafficEnabled": {"false"}, "message": {"data repair"}})
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var body struct {
+				Proxies []apiaggregator.TrafficUpdateResult
+			}
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			Expect(body.Proxies).To(HaveLen(2))
+			for _, result := range body.Proxies {
+				Expect(result.Succeeded).To(BeTrue())
+				Expect(result.Cluster.TrafficEnabled).To(BeFalse())
+			}
+
+			Expect(proxy0.ReceivedRequests()).To(HaveLen(1))
+			Expect(proxy1.ReceivedRequests()).To(HaveLen(1))
+		})
+
+		It("reports a bad gateway when any proxy fails", func() {
+			proxy0.RouteToHandler("PATCH", "/v0/cluster", ghttp.RespondWithJSONEncoded(http.StatusOK, api.ClusterJSON{TrafficEnabled: true}))
+			proxy1.RouteToHandler("PATCH", "/v0/cluster", ghttp.RespondWith(http.StatusInternalServerError, "boom"))
+
+			resp := patch(url.Values{"trafficEnabled": {"true"}})
+			Expect(resp.StatusCode).To(Equal(http.StatusBadGateway))
+
+			var body struct {
+				Proxies []apiaggregator.TrafficUpdateResult
+			}
+			Expect(json.NewDecoder(resp.Body).Decode(&body)).To(Succeed())
+			Expect(body.Proxies[0].Succeeded).To(BeTrue())
+			Expect(body.Proxies[1].Succeeded).To(BeFalse())
+			Expect(body.Proxies[1].Error).To(ContainSubstring("boom"))
+		})
+
+		It("requires a message to disable traffic without contacting any proxy", func() {
+			resp := patch(url.Values{"trafficEnabled": {"false"}})
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+
+			Expect(proxy0.ReceivedRequests()).To(BeEmpty())
+			Expect(proxy1.ReceivedRequests()).To(BeEmpty())
+		})
+
+		It("rejects an unparsable trafficEnabled", func() {
+			resp := patch(url.Values{"trafficEnabled": {"maybe"}})
+			Expect(resp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	It("rejects other methods", func() {
+		req, err := http.NewRequest("DELETE", server.URL(), nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
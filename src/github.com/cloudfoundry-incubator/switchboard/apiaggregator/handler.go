@@ -17,9 +17,13 @@ import (
 func NewHandler(
 	logger lager.Logger,
 	apiConfig config.API,
+	client *http.Client,
 ) http.Handler {
 	mux := http.NewServeMux()
 
+	aggregator := NewAggregator(client, apiConfig, logger)
+	mux.Handle("/v0/cluster", ClusterEndpoint(aggregator, logger))
+
 	mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t, err := template.New("proxySpringboard").Parse(
 			`
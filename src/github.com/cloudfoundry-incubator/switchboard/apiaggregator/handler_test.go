@@ -27,12 +27,13 @@ var _ = Describe("Handler", func() {
 		handler = apiaggregator.NewHandler(
 			logger,
 			cfg,
+			&http.Client{},
 		)
 	})
 
 	Context("when a request panics", func() {
 		var (
-			realBackendsIndex func([]*domain.Backend, api.ClusterManager) http.Handler
+			realBackendsIndex func(*domain.BackendSet, api.ClusterManager) http.Handler
 			responseWriter    *apifakes.FakeResponseWriter
 			request           *http.Request
 		)
@@ -44,7 +45,7 @@ var _ = Describe("Handler", func() {
 				Password:   "bar",
 			}
 			realBackendsIndex = api.BackendsIndex
-			api.BackendsIndex = func([]*domain.Backend, api.ClusterManager) http.Handler {
+			api.BackendsIndex = func(*domain.BackendSet, api.ClusterManager) http.Handler {
 				return http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
 					panic("fake request panic")
 				})
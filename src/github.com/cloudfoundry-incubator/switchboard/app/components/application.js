@@ -1,5 +1,6 @@
 require('babel/polyfill');
 var Backends = require('./backends');
+var History = require('./history');
 var React = require('react/addons');
 var Layout = require('../../serve/components/layout');
 var request = require('superagent');
@@ -9,14 +10,17 @@ var cx = React.addons.classSet;
 
 var Application = React.createClass({
   getInitialState() {
-    return {backends: []}
+    return {backends: [], sessions: [], failovers: [], healthchecks: {}}
   },
 
   statics: {
-    POLL_INTERVAL: 1 * 1000
+    POLL_INTERVAL: 1 * 1000,
+    HISTORY_POLL_INTERVAL: 10 * 1000,
+    HISTORY_SERIES: ['sessions', 'failovers', 'healthchecks']
   },
 
   componentDidMount() {
+    this.pollHistory();
     this.pollBackends();
   },
 
@@ -34,8 +38,26 @@ var Application = React.createClass({
     setCorrectingInterval(this.updateBackends, Application.POLL_INTERVAL);
   },
 
+  updateHistory() {
+    Application.HISTORY_SERIES.forEach(function(series) {
+      request.get(`/v1/history/${series}`)
+        .accept('json')
+        .end(function(err, res) {
+          if (err) return;
+          var state = {};
+          state[series] = res.body;
+          this.setState(state);
+        }.bind(this));
+    }, this);
+  },
+
+  pollHistory() {
+    this.updateHistory();
+    setCorrectingInterval(this.updateHistory, Application.HISTORY_POLL_INTERVAL);
+  },
+
   render() {
-    var {backends} = this.state;
+    var {backends, sessions, failovers, healthchecks} = this.state;
     var healthyCount = backends && backends.reduce((memo, b) => memo + (b.healthy ? 1 : 0), 0);
     var healthy = healthyCount === backends.length;
     var healthText = healthy ? 'All nodes are healthy!' : `${backends.length - healthyCount} out of ${backends.length} nodes are unhealthy.`;
@@ -72,6 +94,11 @@ var Application = React.createClass({
                 <Backends backends={backends}/>
               </div>
             </div>
+            <div className="row mtxl">
+              <div className="col-sm-24 mtl">
+                <History sessions={sessions} failovers={failovers} healthchecks={healthchecks}/>
+              </div>
+            </div>
           </div>
         </div>
       </div>
@@ -0,0 +1,135 @@
+var React = require('react/addons');
+var sortBy = require('lodash.sortby');
+var types = React.PropTypes;
+
+var Sparkline = React.createClass({
+  propTypes: {
+    values: types.array.isRequired
+  },
+
+  statics: {
+    WIDTH: 240,
+    HEIGHT: 32
+  },
+
+  render() {
+    var {values} = this.props;
+    var max = values.reduce((memo, v) => Math.max(memo, v), 1);
+    var step = values.length > 1 ? Sparkline.WIDTH / (values.length - 1) : 0;
+    var points = values.map((v, i) => `${i * step},${Sparkline.HEIGHT - v / max * Sparkline.HEIGHT}`).join(' ');
+    return (
+      <svg className="sparkline" width={Sparkline.WIDTH} height={Sparkline.HEIGHT}>
+        <polyline points={points} fill="none" stroke="#00a79d" strokeWidth={2}/>
+      </svg>
+    );
+  }
+});
+
+var History = React.createClass({
+  propTypes: {
+    sessions: types.array.isRequired,
+    failovers: types.array.isRequired,
+    healthchecks: types.object.isRequired
+  },
+
+  renderBackends() {
+    var {sessions, healthchecks} = this.props;
+    return sortBy(Object.keys(healthchecks), name => name).map(function(name) {
+      var checks = healthchecks[name];
+      var latest = checks[checks.length - 1];
+      return (
+        <tr key={name}>
+          <td className="ptm txt-m">
+            <h4 className="mlm">{name}</h4>
+          </td>
+          <td className="txt-m">
+            <Sparkline values={sessions.map(s => s.sessions[name] || 0)}/>
+          </td>
+          <td className="txt-m">
+            <Sparkline values={checks.map(c => c.latencyMillis)}/>
+          </td>
+          <td className="txt-m">
+            <h4 className="mlm">{latest ? `${latest.latencyMillis.toFixed(1)} ms` : '-'}</h4>
+          </td>
+        </tr>
+      );
+    });
+  },
+
+  renderFailovers() {
+    var {failovers} = this.props;
+    if (!failovers.length) {
+      return (
+        <tr>
+          <td className="txt-m" colSpan={3}>
+            <h4 className="mlm">No failovers recorded.</h4>
+          </td>
+        </tr>
+      );
+    }
+
+    return failovers.slice().reverse().map(function(failover, i) {
+      return (
+        <tr key={i}>
+          <td className="txt-m">
+            <h4 className="mlm">{new Date(failover.time).toLocaleString()}</h4>
+          </td>
+          <td className="txt-m">
+            <h4 className="mlm">{failover.from || 'none'}</h4>
+          </td>
+          <td className="txt-m">
+            <h4 className="mlm">{failover.to || 'none'}</h4>
+          </td>
+        </tr>
+      );
+    });
+  },
+
+  render() {
+    return (
+      <div>
+        <table className="table table-data table-light man">
+          <thead>
+            <tr>
+              <th className="col-sm-6">
+                <h5 className="em-max mlm">Nodes</h5>
+              </th>
+              <th className="col-sm-6">
+                <h5 className="em-max mlm">Sessions Over Time</h5>
+              </th>
+              <th className="col-sm-6">
+                <h5 className="em-max mlm">Health Check Latency</h5>
+              </th>
+              <th className="col-sm-6">
+                <h5 className="em-max mlm">Last Health Check</h5>
+              </th>
+            </tr>
+          </thead>
+          <tbody>
+          {this.renderBackends()}
+          </tbody>
+        </table>
+        <table className="table table-data table-light man mtxl">
+          <thead>
+            <tr>
+              <th className="col-sm-8">
+                <h5 className="em-max mlm">Failed Over At</h5>
+              </th>
+              <th className="col-sm-8">
+                <h5 className="em-max mlm">From</h5>
+              </th>
+              <th className="col-sm-8">
+                <h5 className="em-max mlm">To</h5>
+              </th>
+            </tr>
+          </thead>
+          <tbody>
+          {this.renderFailovers()}
+          </tbody>
+        </table>
+      </div>
+    );
+  }
+});
+
+module.exports = History;
@@ -0,0 +1,236 @@
+package capture
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+)
+
+// maxQueryLength is how much of a query Decode prints.
+const maxQueryLength = 200
+
+type phase int
+
+const (
+	phaseGreeting phase = iota
+	phaseAuthentication
+	phaseCommand
+	// phaseOpaque follows a TLS upgrade or the switch to the compressed
+	// protocol, after which packets cannot be told apart.
+	phaseOpaque
+)
+
+// decodedSession is the protocol state of one session of a capture.
+type decodedSession struct {
+	phase            phase
+	opaqueReason     string
+	compress         bool
+	awaitingResponse bool
+	fromClient       []byte
+	fromBackend      []byte
+}
+
+// Decode prints one line for each session opened or closed in the capture
+// read from r, and for each MySQL packet its sessions exchanged: the packet
+// header and, where known, the kind of packet or command.
+func Decode(r io.Reader, out io.Writer) error {
+	reader, err := NewReader(r)
+	if err != nil {
+		return err
+	}
+
+	sessions := map[uint64]*decodedSession{}
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		prefix := fmt.Sprintf("%s session %d", record.Time.UTC().Format("2006-01-02T15:04:05.000000Z"), record.Session)
+
+		s, ok := sessions[record.Session]
+		if !ok {
+			s = &decodedSession{}
+			sessions[record.Session] = s
+		}
+
+		switch record.Kind {
+		case KindOpen:
+			var info Session
+			_ = json.Unmarshal(record.Data, &info)
+			fmt.Fprintf(out, "%s opened: client %s, backend %s (%s)\n", prefix, info.Client, info.Backend, info.BackendAddress)
+		case KindClose:
+			if n := len(s.fromClient) + len(s.fromBackend); n > 0 && s.phase != phaseOpaque {
+				fmt.Fprintf(out, "%s closed with %d bytes of incomplete packets\n", prefix, n)
+			} else {
+				fmt.Fprintf(out, "%s closed\n", prefix)
+			}
+			delete(sessions, record.Session)
+		case KindFromClient:
+			s.fromClient = s.decode(out, prefix, "client ", append(s.fromClient, record.Data...), s.describeFromClient)
+		case KindFromBackend:
+			s.fromBackend = s.decode(out, prefix, "backend", append(s.fromBackend, record.Data...), s.describeFromBackend)
+		default:
+			fmt.Fprintf(out, "%s unknown record kind %d\n", prefix, record.Kind)
+		}
+	}
+}
+
+// decode prints the complete packets in buf and returns what remains of it.
+func (s *decodedSession) decode(out io.Writer, prefix, direction string, buf []byte, describe func(mysqlproto.Packet) string) []byte {
+	for {
+		if s.phase == phaseOpaque {
+			if len(buf) > 0 {
+				fmt.Fprintf(out, "%s %s %d bytes, not decoded (%s)\n", prefix, direction, len(buf), s.opaqueReason)
+			}
+			return nil
+		}
+
+		if len(buf) < 4 {
+			return buf
+		}
+		length := int(buf[0]) | int(buf[1])<<8 | int(buf[2])<<16
+		if len(buf) < 4+length {
+			return buf
+		}
+
+		packet := mysqlproto.Packet{Sequence: buf[3], Payload: buf[4 : 4+length]}
+		buf = buf[4+length:]
+
+		line := fmt.Sprintf("%s %s seq=%d len=%d", prefix, direction, packet.Sequence, length)
+		if summary := describe(packet); summary != "" {
+			line += " " + summary
+		}
+		fmt.Fprintln(out, line)
+	}
+}
+
+func (s *decodedSession) describeFromClient(p mysqlproto.Packet) string {
+	switch s.phase {
+	case phaseGreeting, phaseAuthentication:
+		// Only commands start a new sequence, so the proxy relayed the whole
+		// authentication itself before the capture saw the session.
+		if p.Sequence == 0 {
+			s.phase = phaseCommand
+			return s.describeFromClient(p)
+		}
+		if mysqlproto.IsSSLRequest(p.Payload) {
+			s.becomeOpaque("TLS")
+			return "SSL request"
+		}
+		if s.phase == phaseGreeting || p.Sequence == 1 {
+			if response, err := mysqlproto.ParseHandshakeResponse(p.Payload); err == nil {
+				s.compress = response.Capabilities&mysqlproto.ClientCompress != 0
+				return fmt.Sprintf("handshake response: user %q, schema %q, auth plugin %q", response.Username, response.Database, response.AuthPluginName)
+			}
+		}
+		return "authentication data"
+	}
+
+	if p.Sequence != 0 || len(p.Payload) == 0 {
+		return ""
+	}
+
+	s.awaitingResponse = true
+	command := p.Payload[0]
+	name := mysqlproto.CommandName(command)
+	switch command {
+	case mysqlproto.ComQuery, mysqlproto.ComStmtPrepare:
+		return name + " " + quote(p.Payload[1:])
+	case mysqlproto.ComInitDB:
+		return name + " " + strconv.Quote(string(p.Payload[1:]))
+	}
+	return name
+}
+
+func (s *decodedSession) describeFromBackend(p mysqlproto.Packet) string {
+	header := byte(0)
+	if len(p.Payload) > 0 {
+		header = p.Payload[0]
+	}
+
+	if header == 0xff {
+		code, sqlState, message, err := mysqlproto.ParseErrorPacket(p.Payload)
+		if err != nil {
+			return "ERR"
+		}
+		s.awaitingResponse = false
+		return fmt.Sprintf("ERR %d (%s): %s", code, sqlState, message)
+	}
+
+	switch s.phase {
+	case phaseGreeting:
+		s.phase = phaseAuthentication
+		if handshake, err := mysqlproto.ParseHandshake(p.Payload); err == nil && p.Sequence == 0 {
+			return fmt.Sprintf("handshake: server %q, connection id %d, auth plugin %q", handshake.ServerVersion, handshake.ConnectionID, handshake.AuthPluginName)
+		}
+		// The proxy relayed the handshake itself before the capture saw the
+		// session, so this is already part of the authentication.
+		return s.describeFromBackend(p)
+
+	case phaseAuthentication:
+		switch header {
+		case 0x00:
+			if s.compress {
+				s.becomeOpaque("compressed protocol")
+			} else {
+				s.phase = phaseCommand
+			}
+			return "OK, authenticated"
+		case 0xfe:
+			plugin, _, _ := cutNul(p.Payload[1:])
+			return fmt.Sprintf("auth switch request: auth plugin %q", plugin)
+		case 0x01:
+			return "auth more data"
+		}
+		return "authentication data"
+	}
+
+	if s.awaitingResponse {
+		s.awaitingResponse = false
+		switch {
+		case header == 0x00:
+			return "OK"
+		case header == 0xfb:
+			return "LOCAL INFILE request"
+		case header < 0xfb:
+			return fmt.Sprintf("result set, %d columns", header)
+		}
+		return "result set"
+	}
+
+	// A row only starts with 0xfe when its first value is longer than 2^24
+	// bytes, and then it fills the packet.
+	if header == 0xfe && len(p.Payload) < mysqlproto.MaxPayloadLength {
+		return "end of result set"
+	}
+	return ""
+}
+
+func (s *decodedSession) becomeOpaque(reason string) {
+	s.phase = phaseOpaque
+	s.opaqueReason = reason
+}
+
+func quote(b []byte) string {
+	if len(b) > maxQueryLength {
+		return strconv.Quote(string(b[:maxQueryLength])) + "..."
+	}
+	return strconv.Quote(string(b))
+}
+
+func cutNul(b []byte) (string, []byte, bool) {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i]), b[i+1:], true
+		}
+	}
+	return string(b), nil, false
+}
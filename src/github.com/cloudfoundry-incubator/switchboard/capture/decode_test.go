@@ -0,0 +1,171 @@
+package capture_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"github.com/go-sql-driver/mysql"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/switchboard/capture"
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/fakecluster"
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+)
+
+var _ = Describe("Decode", func() {
+	It("prints the packets of a session captured through a backend", func() {
+		logger := lagertest.NewTestLogger("decode")
+
+		backendAddress := fmt.Sprintf("127.0.0.1:%d", 10700+GinkgoParallelProcess())
+		backendProcess := ifrit.Invoke(fakecluster.NewBackendRunner(backendAddress, fakecluster.NewNode("fake-node-0", 0), fakecluster.ProtocolMySQL, logger))
+		defer func() {
+			backendProcess.Signal(os.Kill)
+			Eventually(backendProcess.Wait()).Should(Receive())
+		}()
+
+		backendHost, backendPort, err := net.SplitHostPort(backendAddress)
+		Expect(err).NotTo(HaveOccurred())
+		var port uint
+		_, err = fmt.Sscan(backendPort, &port)
+		Expect(err).NotTo(HaveOccurred())
+
+		recorder := capture.NewRecorder(GinkgoT().TempDir(), capture.Limits{MaxBytes: 1024 * 1024, MaxDuration: time.Minute}, logger)
+		backend := domain.NewBackend("mysql/0", backendHost, port, 9200, "api/v1/status", logger)
+		backend.SetTap(recorder)
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+		bridged := make(chan struct{})
+		go func() {
+			defer close(bridged)
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = backend.Bridge(conn)
+		}()
+
+		_, err = recorder.Start(capture.Filter{Client: "127.0.0.1"}, 0, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		_ = mysql.SetLogger(&mysql.NopLogger{})
+		db, err := sql.Open("mysql", "reporting:secret@tcp("+listener.Addr().String()+")/orders")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = db.Exec("CREATE TABLE t (id int)")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Close()).To(Succeed())
+		Eventually(bridged).Should(BeClosed())
+
+		status, err := recorder.Stop()
+		Expect(err).NotTo(HaveOccurred())
+
+		file, err := os.Open(status.Path)
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+
+		out := &bytes.Buffer{}
+		Expect(capture.Decode(file, out)).To(Succeed())
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		Expect(lines).To(HaveLen(8))
+		Expect(lines[0]).To(MatchRegexp(`^\S+ session 1 opened: client 127\.0\.0\.1:\d+, backend mysql/0 \(127\.0\.0\.1:\d+\)$`))
+		Expect(lines[1]).To(MatchRegexp(`session 1 backend seq=0 len=\d+ handshake: server "[^"]+", connection id \d+, auth plugin "mysql_native_password"$`))
+		Expect(lines[2]).To(MatchRegexp(`session 1 client  seq=1 len=\d+ handshake response: user "reporting", schema "orders", auth plugin "mysql_native_password"$`))
+		Expect(lines[3]).To(HaveSuffix(`session 1 backend seq=2 len=7 OK, authenticated`))
+		Expect(lines[4]).To(MatchRegexp(`session 1 client  seq=0 len=\d+ COM_QUERY "CREATE TABLE t \(id int\)"$`))
+		Expect(lines[5]).To(HaveSuffix(`session 1 backend seq=1 len=7 OK`))
+		Expect(lines[6]).To(HaveSuffix(`session 1 client  seq=0 len=1 COM_QUIT`))
+		Expect(lines[7]).To(HaveSuffix(`session 1 closed`))
+	})
+
+	It("does not decode sessions upgraded to TLS", func() {
+		sslRequest := make([]byte, 32)
+		binary.LittleEndian.PutUint32(sslRequest, mysqlproto.ClientProtocol41|mysqlproto.ClientSSL)
+
+		out := &bytes.Buffer{}
+		Expect(capture.Decode(captureFile(
+			capture.Record{Session: 7, Kind: capture.KindFromClient, Data: packet(1, sslRequest)},
+			capture.Record{Session: 7, Kind: capture.KindFromClient, Data: []byte{0x16, 0x03, 0x01, 0x02, 0x00}},
+		), out)).To(Succeed())
+
+		Expect(out.String()).To(ContainSubstring("session 7 client  seq=1 len=32 SSL request\n"))
+		Expect(out.String()).To(ContainSubstring("session 7 client  5 bytes, not decoded (TLS)\n"))
+	})
+
+	It("decodes packets split across records", func() {
+		query := packet(0, []byte{mysqlproto.ComPing})
+		errPacket := packet(1, mysqlproto.ErrorPacket(1105, "HY000", "unknown error"))
+
+		out := &bytes.Buffer{}
+		Expect(capture.Decode(captureFile(
+			capture.Record{Session: 1, Kind: capture.KindFromBackend, Data: packet(2, []byte{0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00})},
+			capture.Record{Session: 1, Kind: capture.KindFromClient, Data: query[:2]},
+			capture.Record{Session: 1, Kind: capture.KindFromClient, Data: query[2:]},
+			capture.Record{Session: 1, Kind: capture.KindFromBackend, Data: errPacket},
+			capture.Record{Session: 1, Kind: capture.KindClose},
+		), out)).To(Succeed())
+
+		Expect(strings.Split(out.String(), "\n")).To(ConsistOf(
+			HaveSuffix("session 1 backend seq=2 len=7 OK, authenticated"),
+			HaveSuffix("session 1 client  seq=0 len=1 COM_PING"),
+			HaveSuffix("session 1 backend seq=1 len=22 ERR 1105 (HY000): unknown error"),
+			HaveSuffix("session 1 closed"),
+			"",
+		))
+	})
+
+	It("decodes sessions whose authentication the proxy relayed itself", func() {
+		out := &bytes.Buffer{}
+		Expect(capture.Decode(captureFile(
+			capture.Record{Session: 1, Kind: capture.KindFromClient, Data: packet(0, []byte("\x03SELECT 1"))},
+			capture.Record{Session: 1, Kind: capture.KindFromBackend, Data: packet(1, []byte{0x01})},
+		), out)).To(Succeed())
+
+		Expect(strings.Split(out.String(), "\n")).To(ConsistOf(
+			HaveSuffix(`session 1 client  seq=0 len=9 COM_QUERY "SELECT 1"`),
+			HaveSuffix("session 1 backend seq=1 len=1 result set, 1 columns"),
+			"",
+		))
+	})
+
+	It("fails on a truncated capture", func() {
+		data, err := io.ReadAll(captureFile(capture.Record{Session: 1, Kind: capture.KindFromClient, Data: []byte("query")}))
+		Expect(err).NotTo(HaveOccurred())
+
+		err = capture.Decode(bytes.NewReader(data[:len(data)-1]), io.Discard)
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+	})
+
+	It("fails on files that are not captures", func() {
+		Expect(capture.Decode(strings.NewReader("GIF89a"), io.Discard)).To(MatchError("not a switchboard capture file"))
+	})
+})
+
+func packet(sequence byte, payload []byte) []byte {
+	b := &bytes.Buffer{}
+	Expect(mysqlproto.WritePacket(b, mysqlproto.Packet{Sequence: sequence, Payload: payload})).To(Succeed())
+	return b.Bytes()
+}
+
+func captureFile(records ...capture.Record) io.Reader {
+	b := bytes.NewBufferString(capture.Magic)
+	for _, r := range records {
+		if r.Time.IsZero() {
+			r.Time = time.Now()
+		}
+		b.Write(r.Marshal())
+	}
+	return bytes.NewReader(b.Bytes())
+}
@@ -0,0 +1,102 @@
+// Package capture records the traffic of selected proxied sessions to a file,
+// and decodes the MySQL packets in such files.
+package capture
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Magic starts every capture file.
+const Magic = "SWBCAP01"
+
+// Kind is the type of a Record.
+type Kind byte
+
+const (
+	// KindOpen starts a session. Its data is the JSON encoded Session.
+	KindOpen Kind = 1
+	// KindFromClient carries bytes the client sent to the backend.
+	KindFromClient Kind = 2
+	// KindFromBackend carries bytes the backend sent to the client.
+	KindFromBackend Kind = 3
+	// KindClose ends a session.
+	KindClose Kind = 4
+)
+
+// recordHeaderLength is the size of the time, session, kind and length
+// fields that precede the data of every record.
+const recordHeaderLength = 8 + 8 + 1 + 4
+
+// Session describes a captured session.
+type Session struct {
+	Client         string `json:"client"`
+	Backend        string `json:"backend"`
+	BackendAddress string `json:"backendAddress"`
+}
+
+// Record is one entry of a capture file. Records of concurrent sessions are
+// interleaved and told apart by Session.
+type Record struct {
+	Time    time.Time
+	Session uint64
+	Kind    Kind
+	Data    []byte
+}
+
+func (r Record) size() int64 {
+	return recordHeaderLength + int64(len(r.Data))
+}
+
+// Marshal encodes the record as it is stored in a capture file: the time in
+// nanoseconds since the epoch, the session, the kind and the length of the
+// data, big-endian, followed by the data.
+func (r Record) Marshal() []byte {
+	b := make([]byte, recordHeaderLength, r.size())
+	binary.BigEndian.PutUint64(b[0:], uint64(r.Time.UnixNano()))
+	binary.BigEndian.PutUint64(b[8:], r.Session)
+	b[16] = byte(r.Kind)
+	binary.BigEndian.PutUint32(b[17:], uint32(len(r.Data)))
+	return append(b, r.Data...)
+}
+
+// Reader reads the records of a capture file.
+type Reader struct {
+	r io.Reader
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	magic := make([]byte, len(Magic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != Magic {
+		return nil, errors.New("not a switchboard capture file")
+	}
+	return &Reader{r: r}, nil
+}
+
+// Next returns the next record, or io.EOF after the last one. A file that
+// ends within a record, for example because the proxy stopped while writing
+// it, returns an error wrapping io.ErrUnexpectedEOF.
+func (r *Reader) Next() (Record, error) {
+	var header [recordHeaderLength]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		return Record{}, err
+	}
+
+	record := Record{
+		Time:    time.Unix(0, int64(binary.BigEndian.Uint64(header[0:]))),
+		Session: binary.BigEndian.Uint64(header[8:]),
+		Kind:    Kind(header[16]),
+		Data:    make([]byte, binary.BigEndian.Uint32(header[17:])),
+	}
+	if _, err := io.ReadFull(r.r, record.Data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return Record{}, fmt.Errorf("reading record of session %d: %w", record.Session, err)
+	}
+
+	return record, nil
+}
@@ -0,0 +1,388 @@
+package capture
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+var (
+	// ErrInvalid wraps the errors of captures that cannot be started as
+	// requested.
+	ErrInvalid = errors.New("invalid capture")
+	// ErrRunning is returned when starting a capture while another runs.
+	ErrRunning = errors.New("a capture is already running")
+	// ErrNotRunning is returned when stopping a capture while none runs.
+	ErrNotRunning = errors.New("no capture is running")
+)
+
+// Filter selects the sessions a capture records. At least one field must be
+// set; a session has to match every field that is.
+type Filter struct {
+	// Client is the IP address of the client.
+	Client string `json:"client,omitempty"`
+	// Backend is the name of the backend.
+	Backend string `json:"backend,omitempty"`
+}
+
+func (f Filter) validate() error {
+	if f.Client == "" && f.Backend == "" {
+		return fmt.Errorf("%w: a client or a backend is required", ErrInvalid)
+	}
+	if f.Client != "" && net.ParseIP(f.Client) == nil {
+		return fmt.Errorf("%w: client %q is not an IP address", ErrInvalid, f.Client)
+	}
+	return nil
+}
+
+func (f Filter) matches(backendName string, client net.Addr) bool {
+	if f.Backend != "" && f.Backend != backendName {
+		return false
+	}
+	if f.Client != "" {
+		host, _, err := net.SplitHostPort(client.String())
+		if err != nil {
+			return false
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.Equal(net.ParseIP(f.Client))
+	}
+	return true
+}
+
+// StopReason records why a capture stopped.
+type StopReason string
+
+const (
+	StopReasonStopped     StopReason = "stopped"
+	StopReasonExpired     StopReason = "expired"
+	StopReasonMaxBytes    StopReason = "max_bytes"
+	StopReasonWriteFailed StopReason = "write_failed"
+)
+
+// Status describes the running or the last capture.
+type Status struct {
+	Running    bool       `json:"running"`
+	Path       string     `json:"path"`
+	Filter     Filter     `json:"filter"`
+	Started    time.Time  `json:"started"`
+	Deadline   time.Time  `json:"deadline"`
+	Stopped    *time.Time `json:"stopped,omitempty"`
+	StopReason StopReason `json:"stopReason,omitempty"`
+	MaxBytes   int64      `json:"maxBytes"`
+	Bytes      int64      `json:"bytes"`
+	Sessions   int        `json:"sessions"`
+}
+
+// Limits bound the captures a Recorder starts. Starting a capture deletes the
+// oldest capture files so that at most MaxFiles remain, including the new
+// one; zero keeps every file.
+type Limits struct {
+	MaxBytes    int64
+	MaxDuration time.Duration
+	MaxFiles    int
+}
+
+// Recorder runs one capture at a time and writes it to a new file in its
+// directory. It is the domain.Tap of every backend, and records new sessions
+// that match the filter of the running capture.
+type Recorder struct {
+	mutex     sync.Mutex
+	dir       string
+	limits    Limits
+	logger    lager.Logger
+	current   *recording
+	sessionID atomic.Uint64
+}
+
+func NewRecorder(dir string, limits Limits, logger lager.Logger) *Recorder {
+	return &Recorder{
+		dir:    dir,
+		limits: limits,
+		logger: logger,
+	}
+}
+
+// Start captures the sessions matching filter that begin within duration,
+// until maxBytes have been written. Zero duration or maxBytes stand for the
+// recorder's limits.
+func (r *Recorder) Start(filter Filter, duration time.Duration, maxBytes int64) (Status, error) {
+	if err := filter.validate(); err != nil {
+		return Status{}, err
+	}
+
+	if duration == 0 {
+		duration = r.limits.MaxDuration
+	}
+	if duration < 0 || duration > r.limits.MaxDuration {
+		return Status{}, fmt.Errorf("%w: duration must be at most %s", ErrInvalid, r.limits.MaxDuration)
+	}
+
+	if maxBytes == 0 {
+		maxBytes = r.limits.MaxBytes
+	}
+	if maxBytes < 0 || maxBytes > r.limits.MaxBytes {
+		return Status{}, fmt.Errorf("%w: maxBytes must be at most %d", ErrInvalid, r.limits.MaxBytes)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.current != nil && r.current.Status().Running {
+		return Status{}, ErrRunning
+	}
+
+	if err := os.MkdirAll(r.dir, 0700); err != nil {
+		return Status{}, err
+	}
+	r.prune()
+
+	started := time.Now()
+	file, err := createFile(r.dir, started)
+	if err != nil {
+		return Status{}, err
+	}
+	path := file.Name()
+	if _, err := file.Write([]byte(Magic)); err != nil {
+		file.Close()
+		return Status{}, err
+	}
+
+	rec := &recording{
+		filter: filter,
+		file:   file,
+		logger: r.logger,
+		status: Status{
+			Running:  true,
+			Path:     path,
+			Filter:   filter,
+			Started:  started,
+			Deadline: started.Add(duration),
+			MaxBytes: maxBytes,
+			Bytes:    int64(len(Magic)),
+		},
+	}
+	rec.timer = time.AfterFunc(duration, func() { rec.stop(StopReasonExpired) })
+	r.current = rec
+
+	r.logger.Info("Started capture", lager.Data{"path": path, "filter": filter, "duration": duration.String(), "maxBytes": maxBytes})
+
+	return rec.Status(), nil
+}
+
+// Stop ends the running capture.
+func (r *Recorder) Stop() (Status, error) {
+	r.mutex.Lock()
+	rec := r.current
+	r.mutex.Unlock()
+
+	if rec == nil || !rec.stop(StopReasonStopped) {
+		return Status{}, ErrNotRunning
+	}
+	return rec.Status(), nil
+}
+
+// Status returns the status of the running or the last capture, and false
+// when no capture has been started.
+func (r *Recorder) Status() (Status, bool) {
+	r.mutex.Lock()
+	rec := r.current
+	r.mutex.Unlock()
+
+	if rec == nil {
+		return Status{}, false
+	}
+	return rec.Status(), true
+}
+
+// Observe implements domain.Tap.
+func (r *Recorder) Observe(backendName string, client, backend net.Addr) domain.SessionTap {
+	r.mutex.Lock()
+	rec := r.current
+	r.mutex.Unlock()
+
+	if rec == nil || !rec.filter.matches(backendName, client) {
+		return nil
+	}
+
+	id := r.sessionID.Add(1)
+	data, _ := json.Marshal(Session{
+		Client:         client.String(),
+		Backend:        backendName,
+		BackendAddress: backend.String(),
+	})
+	if !rec.open(Record{Time: time.Now(), Session: id, Kind: KindOpen, Data: data}) {
+		return nil
+	}
+
+	return sessionTap{recording: rec, id: id}
+}
+
+// prune deletes the oldest capture files, leaving room for one more within
+// MaxFiles. Files that cannot be deleted are logged and left in place.
+func (r *Recorder) prune() {
+	if r.limits.MaxFiles <= 0 {
+		return
+	}
+
+	paths, err := filepath.Glob(filepath.Join(r.dir, "capture-*.swbcap"))
+	if err != nil || len(paths) < r.limits.MaxFiles {
+		return
+	}
+
+	type captureFile struct {
+		path    string
+		modTime time.Time
+	}
+	files := make([]captureFile, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		files = append(files, captureFile{path: path, modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].modTime.Equal(files[j].modTime) {
+			return files[i].modTime.Before(files[j].modTime)
+		}
+		return files[i].path < files[j].path
+	})
+
+	for len(files) >= r.limits.MaxFiles {
+		if err := os.Remove(files[0].path); err != nil {
+			r.logger.Error("Failed to delete old capture", err, lager.Data{"path": files[0].path})
+		} else {
+			r.logger.Info("Deleted old capture", lager.Data{"path": files[0].path})
+		}
+		files = files[1:]
+	}
+}
+
+// createFile creates the file of a capture started at started, named after
+// that time.
+func createFile(dir string, started time.Time) (*os.File, error) {
+	name := "capture-" + started.UTC().Format("20060102T150405Z")
+	for i := 0; ; i++ {
+		path := filepath.Join(dir, name+".swbcap")
+		if i > 0 {
+			path = filepath.Join(dir, fmt.Sprintf("%s-%d.swbcap", name, i))
+		}
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if !os.IsExist(err) {
+			return file, err
+		}
+	}
+}
+
+// recording is one capture and its file.
+type recording struct {
+	filter Filter
+	mutex  sync.Mutex
+	file   *os.File
+	timer  *time.Timer
+	logger lager.Logger
+	status Status
+}
+
+func (rec *recording) Status() Status {
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	return rec.status
+}
+
+// open writes the first record of a session, and reports whether the
+// session is recorded.
+func (rec *recording) open(r Record) bool {
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+
+	if !rec.unsafeWrite(r) {
+		return false
+	}
+	rec.status.Sessions++
+	return true
+}
+
+func (rec *recording) write(r Record) {
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+
+	rec.unsafeWrite(r)
+}
+
+func (rec *recording) unsafeWrite(r Record) bool {
+	if !rec.status.Running {
+		return false
+	}
+
+	if rec.status.Bytes+r.size() > rec.status.MaxBytes {
+		rec.unsafeStop(StopReasonMaxBytes)
+		return false
+	}
+
+	if _, err := rec.file.Write(r.Marshal()); err != nil {
+		rec.logger.Error("Failed to write capture", err, lager.Data{"path": rec.status.Path})
+		rec.unsafeStop(StopReasonWriteFailed)
+		return false
+	}
+	rec.status.Bytes += r.size()
+	return true
+}
+
+// stop ends the capture, and reports whether it was running.
+func (rec *recording) stop(reason StopReason) bool {
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+
+	return rec.unsafeStop(reason)
+}
+
+func (rec *recording) unsafeStop(reason StopReason) bool {
+	if !rec.status.Running {
+		return false
+	}
+
+	rec.timer.Stop()
+	if err := rec.file.Close(); err != nil {
+		rec.logger.Error("Failed to close capture", err, lager.Data{"path": rec.status.Path})
+	}
+
+	stopped := time.Now()
+	rec.status.Running = false
+	rec.status.Stopped = &stopped
+	rec.status.StopReason = reason
+
+	rec.logger.Info("Stopped capture", lager.Data{"path": rec.status.Path, "reason": reason, "bytes": rec.status.Bytes, "sessions": rec.status.Sessions})
+	return true
+}
+
+// sessionTap records one session of a capture.
+type sessionTap struct {
+	recording *recording
+	id        uint64
+}
+
+func (s sessionTap) FromClient(p []byte) {
+	s.recording.write(Record{Time: time.Now(), Session: s.id, Kind: KindFromClient, Data: p})
+}
+
+func (s sessionTap) FromBackend(p []byte) {
+	s.recording.write(Record{Time: time.Now(), Session: s.id, Kind: KindFromBackend, Data: p})
+}
+
+func (s sessionTap) Close() {
+	s.recording.write(Record{Time: time.Now(), Session: s.id, Kind: KindClose})
+}
@@ -0,0 +1,237 @@
+package capture_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/capture"
+)
+
+var _ = Describe("Recorder", func() {
+	var (
+		dir      string
+		recorder *capture.Recorder
+		client   net.Addr
+		backend  net.Addr
+	)
+
+	BeforeEach(func() {
+		dir = filepath.Join(GinkgoT().TempDir(), "captures")
+		recorder = capture.NewRecorder(dir, capture.Limits{MaxBytes: 1024 * 1024, MaxDuration: time.Minute}, lagertest.NewTestLogger("capture"))
+		client = &net.TCPAddr{IP: net.ParseIP("10.0.0.9"), Port: 50000}
+		backend = &net.TCPAddr{IP: net.ParseIP("10.0.16.10"), Port: 3306}
+	})
+
+	readRecords := func(path string) []capture.Record {
+		file, err := os.Open(path)
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+
+		reader, err := capture.NewReader(file)
+		Expect(err).NotTo(HaveOccurred())
+
+		var records []capture.Record
+		for {
+			record, err := reader.Next()
+			if errors.Is(err, io.EOF) {
+				return records
+			}
+			Expect(err).NotTo(HaveOccurred())
+			records = append(records, record)
+		}
+	}
+
+	It("records the sessions matching the filter to a new file", func() {
+		status, err := recorder.Start(capture.Filter{Client: "10.0.0.9"}, 0, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Running).To(BeTrue())
+		Expect(filepath.Dir(status.Path)).To(Equal(dir))
+		Expect(status.Deadline).To(Equal(status.Started.Add(time.Minute)))
+		Expect(status.MaxBytes).To(Equal(int64(1024 * 1024)))
+
+		other := &net.TCPAddr{IP: net.ParseIP("10.0.0.10"), Port: 50000}
+		Expect(recorder.Observe("mysql/0", other, backend)).To(BeNil())
+
+		session := recorder.Observe("mysql/0", client, backend)
+		Expect(session).NotTo(BeNil())
+		session.FromClient([]byte("query"))
+		session.FromBackend([]byte("result"))
+		session.Close()
+
+		status, err = recorder.Stop()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Running).To(BeFalse())
+		Expect(status.StopReason).To(Equal(capture.StopReasonStopped))
+		Expect(status.Sessions).To(Equal(1))
+
+		info, err := os.Stat(status.Path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Size()).To(Equal(status.Bytes))
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		records := readRecords(status.Path)
+		Expect(records).To(HaveLen(4))
+		for _, r := range records {
+			Expect(r.Session).To(Equal(records[0].Session))
+		}
+
+		Expect(records[0].Kind).To(Equal(capture.KindOpen))
+		var opened capture.Session
+		Expect(json.Unmarshal(records[0].Data, &opened)).To(Succeed())
+		Expect(opened).To(Equal(capture.Session{Client: "10.0.0.9:50000", Backend: "mysql/0", BackendAddress: "10.0.16.10:3306"}))
+
+		Expect(records[1].Kind).To(Equal(capture.KindFromClient))
+		Expect(records[1].Data).To(Equal([]byte("query")))
+		Expect(records[2].Kind).To(Equal(capture.KindFromBackend))
+		Expect(records[2].Data).To(Equal([]byte("result")))
+		Expect(records[3].Kind).To(Equal(capture.KindClose))
+	})
+
+	It("only records sessions of the backend named in the filter", func() {
+		_, err := recorder.Start(capture.Filter{Backend: "mysql/1"}, 0, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(recorder.Observe("mysql/0", client, backend)).To(BeNil())
+		Expect(recorder.Observe("mysql/1", client, backend)).NotTo(BeNil())
+	})
+
+	It("does not record sessions once the capture has stopped", func() {
+		_, err := recorder.Start(capture.Filter{Backend: "mysql/0"}, 0, 0)
+		Expect(err).NotTo(HaveOccurred())
+		session := recorder.Observe("mysql/0", client, backend)
+
+		status, err := recorder.Stop()
+		Expect(err).NotTo(HaveOccurred())
+
+		session.FromClient([]byte("query"))
+		Expect(recorder.Observe("mysql/0", client, backend)).To(BeNil())
+
+		Expect(readRecords(status.Path)).To(HaveLen(1))
+		latest, ok := recorder.Status()
+		Expect(ok).To(BeTrue())
+		Expect(latest.Bytes).To(Equal(status.Bytes))
+	})
+
+	It("stops once the capture would exceed its size", func() {
+		status, err := recorder.Start(capture.Filter{Backend: "mysql/0"}, 0, 200)
+		Expect(err).NotTo(HaveOccurred())
+
+		session := recorder.Observe("mysql/0", client, backend)
+		session.FromClient(make([]byte, 100))
+
+		status, _ = recorder.Status()
+		Expect(status.Running).To(BeFalse())
+		Expect(status.StopReason).To(Equal(capture.StopReasonMaxBytes))
+		Expect(status.Bytes).To(BeNumerically("<=", 200))
+		Expect(readRecords(status.Path)).To(HaveLen(1))
+	})
+
+	It("stops once the duration has passed", func() {
+		_, err := recorder.Start(capture.Filter{Backend: "mysql/0"}, 50*time.Millisecond, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() capture.StopReason {
+			status, _ := recorder.Status()
+			return status.StopReason
+		}).Should(Equal(capture.StopReasonExpired))
+	})
+
+	It("runs one capture at a time", func() {
+		_, err := recorder.Start(capture.Filter{Backend: "mysql/0"}, 0, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = recorder.Start(capture.Filter{Backend: "mysql/1"}, 0, 0)
+		Expect(err).To(MatchError(capture.ErrRunning))
+
+		_, err = recorder.Stop()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = recorder.Stop()
+		Expect(err).To(MatchError(capture.ErrNotRunning))
+
+		_, err = recorder.Start(capture.Filter{Backend: "mysql/1"}, 0, 0)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("when the directory holds as many captures as it may", func() {
+		var oldest, older, newest string
+
+		BeforeEach(func() {
+			recorder = capture.NewRecorder(dir, capture.Limits{MaxBytes: 1024 * 1024, MaxDuration: time.Minute, MaxFiles: 3}, lagertest.NewTestLogger("capture"))
+			Expect(os.MkdirAll(dir, 0700)).To(Succeed())
+
+			now := time.Now()
+			for i, name := range []string{"capture-20260101T000000Z.swbcap", "capture-20260101T000000Z-1.swbcap", "capture-20260102T000000Z.swbcap"} {
+				path := filepath.Join(dir, name)
+				Expect(os.WriteFile(path, []byte(capture.Magic), 0600)).To(Succeed())
+				modTime := now.Add(time.Duration(i-3) * time.Hour)
+				Expect(os.Chtimes(path, modTime, modTime)).To(Succeed())
+			}
+			oldest = filepath.Join(dir, "capture-20260101T000000Z.swbcap")
+			older = filepath.Join(dir, "capture-20260101T000000Z-1.swbcap")
+			newest = filepath.Join(dir, "capture-20260102T000000Z.swbcap")
+
+			Expect(os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0600)).To(Succeed())
+		})
+
+		It("deletes the oldest before starting another", func() {
+			status, err := recorder.Start(capture.Filter{Backend: "mysql/0"}, 0, 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(oldest).NotTo(BeAnExistingFile())
+			Expect(older).To(BeAnExistingFile())
+			Expect(newest).To(BeAnExistingFile())
+			Expect(status.Path).To(BeAnExistingFile())
+			Expect(filepath.Join(dir, "notes.txt")).To(BeAnExistingFile())
+
+			_, err = recorder.Stop()
+			Expect(err).NotTo(HaveOccurred())
+			status, err = recorder.Start(capture.Filter{Backend: "mysql/0"}, 0, 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(older).NotTo(BeAnExistingFile())
+			Expect(newest).To(BeAnExistingFile())
+			Expect(status.Path).To(BeAnExistingFile())
+		})
+	})
+
+	It("keeps every capture without a file limit", func() {
+		for i := 0; i < 3; i++ {
+			_, err := recorder.Start(capture.Filter{Backend: "mysql/0"}, 0, 0)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = recorder.Stop()
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		paths, err := filepath.Glob(filepath.Join(dir, "*.swbcap"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths).To(HaveLen(3))
+	})
+
+	It("rejects captures it cannot start as requested", func() {
+		for _, request := range []struct {
+			filter   capture.Filter
+			duration time.Duration
+			maxBytes int64
+		}{
+			{filter: capture.Filter{}},
+			{filter: capture.Filter{Client: "10.0.0.0/8"}},
+			{filter: capture.Filter{Backend: "mysql/0"}, duration: time.Hour},
+			{filter: capture.Filter{Backend: "mysql/0"}, maxBytes: 1024 * 1024 * 1024},
+		} {
+			_, err := recorder.Start(request.filter, request.duration, request.maxBytes)
+			Expect(err).To(MatchError(capture.ErrInvalid))
+		}
+
+		_, ok := recorder.Status()
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,192 @@
+// Package client is a Go client for the switchboard proxy API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/switchboard/api"
+	"github.com/cloudfoundry-incubator/switchboard/capture"
+)
+
+const maxResponseSize = 1 << 20
+
+type Client struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+	cluster    string
+}
+
+// APIError is returned when the proxy responds with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("switchboard API returned %d: %s", e.StatusCode, e.Body)
+}
+
+// New returns a client for the proxy API at baseURL, e.g. "https://10.0.0.5:8080".
+// A nil httpClient uses http.DefaultClient.
+func New(baseURL, username, password string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		username:   username,
+		password:   password,
+		httpClient: httpClient,
+	}
+}
+
+// ForCluster returns a client for one of the clusters a proxy serves, by name.
+func (c *Client) ForCluster(name string) *Client {
+	clusterClient := *c
+	clusterClient.cluster = name
+	return &clusterClient
+}
+
+func (c *Client) clusterPath() string {
+	if c.cluster == "" {
+		return "/v0/cluster"
+	}
+	return "/v0/clusters/" + url.PathEscape(c.cluster)
+}
+
+func (c *Client) backendsPath() string {
+	if c.cluster == "" {
+		return "/v0/backends"
+	}
+	return "/v0/clusters/" + url.PathEscape(c.cluster) + "/backends"
+}
+
+func (c *Client) Backends(ctx context.Context) ([]api.V0BackendResponse, error) {
+	var backends []api.V0BackendResponse
+	if err := c.do(ctx, http.MethodGet, c.backendsPath(), nil, &backends); err != nil {
+		return nil, err
+	}
+	return backends, nil
+}
+
+func (c *Client) Cluster(ctx context.Context) (api.ClusterJSON, error) {
+	var cluster api.ClusterJSON
+	err := c.do(ctx, http.MethodGet, c.clusterPath(), nil, &cluster)
+	return cluster, err
+}
+
+func (c *Client) EnableTraffic(ctx context.Context, message string) (api.ClusterJSON, error) {
+	return c.setTraffic(ctx, true, message)
+}
+
+// DisableTraffic requires a non-empty message explaining why traffic was disabled.
+func (c *Client) DisableTraffic(ctx context.Context, message string) (api.ClusterJSON, error) {
+	if message == "" {
+		return api.ClusterJSON{}, fmt.Errorf("a message is required to disable traffic")
+	}
+	return c.setTraffic(ctx, false, message)
+}
+
+func (c *Client) setTraffic(ctx context.Context, enabled bool, message string) (api.ClusterJSON, error) {
+	form := url.Values{}
+	form.Set("trafficEnabled", strconv.FormatBool(enabled))
+	form.Set("message", message)
+
+	var cluster api.ClusterJSON
+	err := c.do(ctx, http.MethodPatch, c.clusterPath(), form, &cluster)
+	return cluster, err
+}
+
+// Capture returns the running or the last capture of the proxy. Captures
+// cover every cluster the proxy serves.
+func (c *Client) Capture(ctx context.Context) (capture.Status, error) {
+	var status capture.Status
+	err := c.doJSON(ctx, http.MethodGet, "/v1/capture", nil, &status)
+	return status, err
+}
+
+// StartCapture records the traffic of the sessions filter selects. Zero
+// duration and maxBytes stand for the limits configured on the proxy.
+func (c *Client) StartCapture(ctx context.Context, filter capture.Filter, duration time.Duration, maxBytes int64) (capture.Status, error) {
+	request := api.V1CaptureRequest{
+		Client:          filter.Client,
+		Backend:         filter.Backend,
+		DurationSeconds: uint(duration.Round(time.Second) / time.Second),
+		MaxBytes:        maxBytes,
+	}
+
+	var status capture.Status
+	err := c.doJSON(ctx, http.MethodPost, "/v1/capture", request, &status)
+	return status, err
+}
+
+func (c *Client) StopCapture(ctx context.Context) (capture.Status, error) {
+	var status capture.Status
+	err := c.doJSON(ctx, http.MethodDelete, "/v1/capture", nil, &status)
+	return status, err
+}
+
+func (c *Client) do(ctx context.Context, method, path string, form url.Values, v interface{}) error {
+	if form == nil {
+		return c.send(ctx, method, path, nil, "", v)
+	}
+	return c.send(ctx, method, path, strings.NewReader(form.Encode()), "application/x-www-form-urlencoded", v)
+}
+
+func (c *Client) doJSON(ctx context.Context, method, path string, request, v interface{}) error {
+	if request == nil {
+		return c.send(ctx, method, path, nil, "", v)
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	return c.send(ctx, method, path, bytes.NewReader(body), "application/json", v)
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body io.Reader, contentType string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.SetBasicAuth(c.username, c.password)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("reading response from %s %s: %w", method, path, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(respBody)),
+		}
+	}
+
+	if err := json.Unmarshal(respBody, v); err != nil {
+		return fmt.Errorf("parsing response from %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing/fstest"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/api"
+	"github.com/cloudfoundry-incubator/switchboard/capture"
+	"github.com/cloudfoundry-incubator/switchboard/client"
+	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		server         *httptest.Server
+		cluster        *api.ClusterAPI
+		backends       []*domain.Backend
+		trafficEnabled chan bool
+		otherCluster   *api.ClusterAPI
+		captures       *capture.Recorder
+		c              *client.Client
+		ctx            context.Context
+	)
+
+	BeforeEach(func() {
+		logger := lagertest.NewTestLogger("client test")
+		ctx = context.Background()
+
+		backends = []*domain.Backend{
+			domain.NewBackend("backend-0", "10.0.0.1", 3306, 9200, "api/v1/status", logger),
+			domain.NewBackend("backend-1", "10.0.0.2", 3306, 9200, "api/v1/status", logger),
+		}
+		backends[0].SetHealthy()
+
+		trafficEnabled = make(chan bool, 10)
+		cluster = api.NewClusterAPI(logger)
+		cluster.RegisterTrafficEnabledChan(trafficEnabled)
+		go cluster.ListenForActiveBackend()
+		cluster.ActiveBackendChan <- backends[0]
+
+		otherCluster = api.NewClusterAPI(logger)
+		clusters := []api.Cluster{
+			{Name: "default", ClusterManager: cluster, Backends: domain.NewBackendSet(backends)},
+			{Name: "cluster-b", ClusterManager: otherCluster, Backends: domain.NewBackendSet([]*domain.Backend{
+				domain.NewBackend("backend-b-0", "10.0.1.1", 3306, 9200, "api/v1/status", logger),
+			})},
+		}
+
+		captures = capture.NewRecorder(GinkgoT().TempDir(), capture.Limits{MaxBytes: 1 << 20, MaxDuration: time.Hour}, logger)
+
+		server = httptest.NewServer(api.NewHandler(cluster, domain.NewBackendSet(backends), clusters, captures, nil, logger, config.API{
+			Username: "username",
+			Password: "password",
+		}, fstest.MapFS{}))
+
+		c = client.New(server.URL, "username", "password", nil)
+	})
+
+	AfterEach(func() {
+		server.Close()
+		close(cluster.ActiveBackendChan)
+	})
+
+	Describe("Backends", func() {
+		It("returns every backend", func() {
+			Eventually(func() ([]api.V0BackendResponse, error) { return c.Backends(ctx) }).Should(ConsistOf(
+				api.V0BackendResponse{Name: "backend-0", Host: "10.0.0.1", Port: 3306, Healthy: true, Active: true, TrafficEnabled: true},
+				api.V0BackendResponse{Name: "backend-1", Host: "10.0.0.2", Port: 3306, Healthy: false, Active: false, TrafficEnabled: true},
+			))
+		})
+	})
+
+	Describe("Cluster", func() {
+		It("returns the cluster state", func() {
+			Eventually(func() (*api.BackendJSON, error) {
+				cluster, err := c.Cluster(ctx)
+				return cluster.ActiveBackend, err
+			}).Should(Equal(&api.BackendJSON{Name: "backend-0", Host: "10.0.0.1", Port: 3306}))
+		})
+	})
+
+	Describe("DisableTraffic", func() {
+		It("disables traffic with the message", func() {
+			result, err := c.DisableTraffic(ctx, "data repair")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.TrafficEnabled).To(BeFalse())
+			Expect(result.Message).To(Equal("data repair"))
+
+			Expect(cluster.AsJSON().TrafficEnabled).To(BeFalse())
+			Expect(trafficEnabled).To(Receive(BeFalse()))
+		})
+
+		It("requires a message", func() {
+			_, err := c.DisableTraffic(ctx, "")
+			Expect(err).To(MatchError(ContainSubstring("message is required")))
+			Expect(cluster.AsJSON().TrafficEnabled).To(BeTrue())
+		})
+	})
+
+	Describe("EnableTraffic", func() {
+		It("enables traffic", func() {
+			cluster.DisableTraffic("data repair")
+
+			result, err := c.EnableTraffic(ctx, "repaired")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.TrafficEnabled).To(BeTrue())
+			Expect(result.Message).To(Equal("repaired"))
+			Expect(cluster.AsJSON().TrafficEnabled).To(BeTrue())
+		})
+	})
+
+	Describe("ForCluster", func() {
+		It("manages the named cluster", func() {
+			clusterClient := c.ForCluster("cluster-b")
+
+			backends, err := clusterClient.Backends(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backends).To(ConsistOf(
+				api.V0BackendResponse{Name: "backend-b-0", Host: "10.0.1.1", Port: 3306, TrafficEnabled: true},
+			))
+
+			result, err := clusterClient.DisableTraffic(ctx, "data repair")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.TrafficEnabled).To(BeFalse())
+			Expect(otherCluster.AsJSON().TrafficEnabled).To(BeFalse())
+			Expect(cluster.AsJSON().TrafficEnabled).To(BeTrue())
+		})
+	})
+
+	Describe("StartCapture", func() {
+		It("starts a capture that StopCapture stops", func() {
+			started, err := c.StartCapture(ctx, capture.Filter{Client: "10.0.0.9"}, time.Minute, 4096)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(started.Running).To(BeTrue())
+			Expect(started.Filter).To(Equal(capture.Filter{Client: "10.0.0.9"}))
+			Expect(started.MaxBytes).To(BeEquivalentTo(4096))
+			Expect(started.Deadline.Sub(started.Started)).To(Equal(time.Minute))
+
+			status, err := c.Capture(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Path).To(Equal(started.Path))
+
+			stopped, err := c.StopCapture(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stopped.Running).To(BeFalse())
+			Expect(stopped.StopReason).To(Equal(capture.StopReasonStopped))
+		})
+
+		It("returns an APIError when the capture is invalid", func() {
+			_, err := c.StartCapture(ctx, capture.Filter{}, 0, 0)
+
+			var apiErr *client.APIError
+			Expect(errors.As(err, &apiErr)).To(BeTrue())
+			Expect(apiErr.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	When("the credentials are wrong", func() {
+		BeforeEach(func() {
+			c = client.New(server.URL, "username", "wrong", &http.Client{})
+		})
+
+		It("returns an APIError with the status code", func() {
+			_, err := c.Cluster(ctx)
+
+			var apiErr *client.APIError
+			Expect(errors.As(err, &apiErr)).To(BeTrue())
+			Expect(apiErr.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+	})
+})
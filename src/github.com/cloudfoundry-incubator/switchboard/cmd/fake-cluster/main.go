@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/grouper"
+	"github.com/tedsuo/ifrit/http_server"
+	"github.com/tedsuo/ifrit/sigmon"
+	"gopkg.in/yaml.v3"
+
+	"github.com/cloudfoundry-incubator/switchboard/fakecluster"
+)
+
+const usage = `Usage: fake-cluster [options]
+
+Runs a fake Galera cluster on this machine for developing against switchboard.
+Each node serves a backend and a galera-agent compatible /api/v1/status
+endpoint. Change node states with the control API:
+
+  curl localhost:19100/nodes
+  curl -X PUT localhost:19100/nodes/fake-node-0 -d '{"state": "hung"}'
+
+or replay a scenario file with -scenario. States: synced, donor, joining,
+read_only, maintenance, hung, slow.
+
+Options:
+`
+
+func main() {
+	flags := flag.NewFlagSet("fake-cluster", flag.ExitOnError)
+	host := flags.String("host", "127.0.0.1", "address every listener binds to")
+	nodes := flags.Int("nodes", 3, "number of nodes")
+	backendPort := flags.Uint("backend-port", 13306, "backend port of the first node, incremented for each further node")
+	agentPort := flags.Uint("agent-port", 19200, "agent port of the first node, incremented for each further node")
+	controlPort := flags.Uint("control-port", 19100, "port of the control API")
+	protocol := flags.String("protocol", string(fakecluster.ProtocolMySQL), "what backends speak: mysql or echo")
+	slowDelay := flags.Duration("slow-delay", 5*time.Second, "how long a slow node takes to answer a status request")
+	scenarioPath := flags.String("scenario", "", "path to a YAML scenario of node state changes")
+	printBackends := flags.Bool("print-backends", false, "print the Backends section of a switchboard config for this cluster and exit")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[1:])
+
+	backendProtocol, err := fakecluster.ParseProtocol(*protocol)
+	if err != nil {
+		fail(err)
+	}
+	if *nodes < 1 {
+		fail(fmt.Errorf("-nodes must be at least 1"))
+	}
+
+	logger := lager.NewLogger("fake-cluster")
+	logger.RegisterSink(lager.NewPrettySink(os.Stdout, lager.INFO))
+
+	cluster := fakecluster.NewCluster(fakecluster.Config{
+		Host:        *host,
+		Nodes:       *nodes,
+		BackendPort: *backendPort,
+		AgentPort:   *agentPort,
+		Protocol:    backendProtocol,
+		SlowDelay:   *slowDelay,
+	}, logger)
+
+	if *printBackends {
+		out, err := yaml.Marshal(map[string]any{"Backends": cluster.Backends()})
+		if err != nil {
+			fail(err)
+		}
+		fmt.Print(string(out))
+		return
+	}
+
+	members := cluster.Members()
+	members = append(members, grouper.Member{
+		Name: "control-api",
+		Runner: http_server.New(
+			net.JoinHostPort(*host, strconv.Itoa(int(*controlPort))),
+			fakecluster.NewControlHandler(cluster, logger.Session("control")),
+		),
+	})
+
+	if *scenarioPath != "" {
+		scenario, err := fakecluster.LoadScenario(*scenarioPath)
+		if err != nil {
+			fail(err)
+		}
+		if err := scenario.Validate(cluster); err != nil {
+			fail(err)
+		}
+		members = append(members, grouper.Member{
+			Name:   "scenario",
+			Runner: fakecluster.NewScenarioRunner(scenario, cluster, logger.Session("scenario")),
+		})
+	}
+
+	group := grouper.NewOrdered(os.Interrupt, members)
+	process := ifrit.Invoke(sigmon.New(group))
+
+	logger.Info("fake-cluster-started", lager.Data{"nodes": *nodes, "control-port": *controlPort})
+
+	err = <-process.Wait()
+	if err != nil {
+		logger.Fatal("fake-cluster-exited-unexpectedly", err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "fake-cluster:", err)
+	os.Exit(1)
+}
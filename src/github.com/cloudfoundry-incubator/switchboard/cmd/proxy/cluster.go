@@ -0,0 +1,386 @@
+package main
+
+import (
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/tedsuo/ifrit/grouper"
+
+	"github.com/cloudfoundry-incubator/switchboard/api"
+	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/diagnostics"
+	"github.com/cloudfoundry-incubator/switchboard/discovery"
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/fencing"
+	"github.com/cloudfoundry-incubator/switchboard/history"
+	"github.com/cloudfoundry-incubator/switchboard/listener"
+	"github.com/cloudfoundry-incubator/switchboard/metrics"
+	"github.com/cloudfoundry-incubator/switchboard/routing"
+	"github.com/cloudfoundry-incubator/switchboard/runner/bridge"
+	"github.com/cloudfoundry-incubator/switchboard/runner/monitor"
+	"github.com/cloudfoundry-incubator/switchboard/runner/statuslogger"
+	"github.com/cloudfoundry-incubator/switchboard/sourcefilter"
+	"github.com/cloudfoundry-incubator/switchboard/userquota"
+	"github.com/cloudfoundry-incubator/switchboard/webhook"
+)
+
+// proxiedCluster is one cluster this process routes to: its backends,
+// monitors, listeners and traffic state.
+type proxiedCluster struct {
+	name                 string
+	backends             *domain.BackendSet
+	clusterAPI           *api.ClusterAPI
+	history              *history.Recorder
+	activeSourceFilter   *sourcefilter.Filter
+	inactiveSourceFilter *sourcefilter.Filter
+	monitors             map[string]diagnostics.Monitor
+	members              grouper.Members
+}
+
+func newProxiedCluster(
+	clusterConfig config.Cluster,
+	rootConfig *config.Config,
+	accessLog bridge.AccessLog,
+	tap domain.Tap,
+	metricsEmitter *metrics.Emitter,
+	notifier webhook.Notifier,
+	logger lager.Logger,
+) *proxiedCluster {
+	proxyConfig := clusterConfig.Proxy
+
+	socketOptions := domain.SocketOptions{
+		DialTimeout:   proxyConfig.Sockets.DialTimeout(),
+		KeepAlive:     proxyConfig.Sockets.KeepAlive(),
+		NoDelay:       proxyConfig.Sockets.NoDelay,
+		SendBuffer:    int(proxyConfig.Sockets.SendBufferBytes),
+		ReceiveBuffer: int(proxyConfig.Sockets.ReceiveBufferBytes),
+		IdleTimeout:   proxyConfig.Sockets.IdleTimeout(),
+	}
+	configureBackend := func(backend *domain.Backend) {
+		backend.SetSocketOptions(socketOptions)
+		if tap != nil {
+			backend.SetTap(tap)
+		}
+		if proxyConfig.CircuitBreaker.Enabled() {
+			backend.EnableCircuitBreaker(
+				int(proxyConfig.CircuitBreaker.DialFailures),
+				proxyConfig.CircuitBreaker.Window(),
+			)
+		}
+		if proxyConfig.FlapDamping.Enabled() {
+			backend.EnableFlapDamping(
+				int(proxyConfig.FlapDamping.Flaps),
+				proxyConfig.FlapDamping.Window(),
+				proxyConfig.FlapDamping.Quarantine(),
+				proxyConfig.FlapDamping.MaxQuarantine(),
+			)
+		}
+	}
+
+	// Discovered backends join the set once the discovery member resolves
+	// them, before the monitors start.
+	var discoveryRunner *discovery.Runner
+	backends := domain.NewBackendSet(nil)
+	if proxyConfig.Discovery.Enabled() {
+		discoveryRunner = discovery.NewRunner(
+			discovery.NewResolver(proxyConfig.Discovery.Server),
+			proxyConfig.Discovery,
+			backends,
+			configureBackend,
+			logger.Session("discovery"),
+		)
+	} else {
+		backends.Reconcile(proxyConfig.Backends, logger, configureBackend)
+	}
+	metricsEmitter.AddCluster(clusterConfig.Name, backends)
+
+	client := clusterConfig.HTTPClient()
+
+	activeNodeClusterMonitor := monitor.NewClusterMonitor(client, clusterConfig.GaleraAgentTLS.Enabled, backends, proxyConfig.HealthcheckTimeout(), logger.Session("active-monitor"), true)
+	activeNodeClusterMonitor.AvoidQuarantinedBackends()
+	if rootConfig.Locality.PrimaryZone != "" {
+		activeNodeClusterMonitor.PreferBackendsLabelled(rootConfig.Locality.ZoneLabel, rootConfig.Locality.PrimaryZone)
+	}
+
+	clusterStateManager := api.NewClusterAPI(logger)
+
+	if clusterConfig.TrafficState.Path != "" {
+		stateStore := api.NewFileStateStore(clusterConfig.TrafficState.Path)
+		if err := clusterStateManager.UseStateStore(stateStore, clusterConfig.RestoreTrafficState()); err != nil {
+			logger.Fatal("load-traffic-state", err)
+		}
+	}
+
+	trafficEnabled := clusterStateManager.AsJSON().TrafficEnabled
+
+	activeNodeAddresses := listener.TCP(rootConfig.ListenHosts(proxyConfig.BindAddresses), proxyConfig.Port)
+	if proxyConfig.UnixSocket != "" {
+		activeNodeAddresses = append(activeNodeAddresses, listener.Unix(proxyConfig.UnixSocket))
+	}
+	activeNodeAddress := listener.Name(activeNodeAddresses)
+	activeNodeAdmission := newAdmission(proxyConfig.Admission)
+	if activeNodeAdmission != nil {
+		metricsEmitter.AddAdmission(clusterConfig.Name, activeNodeAddress, activeNodeAdmission)
+	}
+
+	activeNodeSourceFilter, err := sourcefilter.New(proxyConfig.SourceFilter.Rules())
+	if err != nil {
+		logger.Fatal("source-filter", err)
+	}
+	metricsEmitter.AddSourceFilter(clusterConfig.Name, activeNodeAddress, activeNodeSourceFilter)
+
+	// Only the active port moves writes between backends, so only it fences.
+	var activeNodeFencer bridge.Fencer
+	if clusterConfig.WriterFencing.Enabled {
+		activeNodeFencer = fencing.NewClient(
+			client,
+			clusterConfig.GaleraAgentTLS.Enabled,
+			clusterConfig.WriterFencing.Username,
+			clusterConfig.WriterFencing.Password,
+			logger.Session("fencing"),
+		)
+	}
+
+	activeNodeBridgeRunner := bridge.NewRunner(
+		activeNodeAddresses,
+		proxyConfig.ShutdownDelay(),
+		trafficEnabled,
+		accessLog,
+		admissionOrNil(activeNodeAdmission),
+		activeNodeSourceFilter,
+		activeNodeFencer,
+		logger.Session("active-bridge-runner"),
+	)
+	activeNodeBridgeRunner.DescribeDisabledTraffic(clusterStateManager)
+	if activeNodeFencer != nil {
+		activeNodeBridgeRunner.LimitFenceWait(clusterConfig.WriterFencing.Timeout())
+	}
+
+	// Quotas are shared by both listeners, so that a user cannot exceed
+	// its quota by also connecting to the inactive port.
+	var userQuotas *userquota.Quotas
+	if proxyConfig.UserQuotas.Enabled() {
+		userQuotas = userquota.New(proxyConfig.UserQuotas.Limits())
+		metricsEmitter.AddUserQuotas(clusterConfig.Name, userQuotas)
+	}
+
+	// wsrep_sync_wait is only set on the inactive port, whose reader may
+	// lag behind the writer.
+	if proxyConfig.Routing.Enabled() || userQuotas != nil || proxyConfig.Reconnect.Enabled() {
+		activeNodeBridgeRunner.InspectHandshakes(proxyConfig.HandshakeTimeout())
+		if userQuotas != nil {
+			activeNodeBridgeRunner.LimitUserSessions(userQuotas)
+			if proxyConfig.UserQuotas.RefuseTLS {
+				activeNodeBridgeRunner.RefuseTLS()
+			}
+		}
+		if proxyConfig.Reconnect.Enabled() {
+			activeNodeBridgeRunner.ReconnectIdleSessions(proxyConfig.Reconnect.Users, proxyConfig.Reconnect.Timeout())
+		}
+	}
+
+	if proxyConfig.Routing.Enabled() {
+		byName := map[string]*domain.Backend{}
+		for _, backend := range backends.All() {
+			byName[backend.AsJSON().Name] = backend
+		}
+		router, err := bridge.NewRuleRouter(proxyConfig.Routing.RouterRules(), byName)
+		if err != nil {
+			logger.Fatal("routing", err)
+		}
+		activeNodeBridgeRunner.RouteByHandshake(router)
+	}
+
+	activeNodeClusterMonitor.RegisterBackendSubscriber(activeNodeBridgeRunner.ActiveBackendChan)
+	activeNodeClusterMonitor.RegisterBackendSubscriber(clusterStateManager.ActiveBackendChan)
+
+	clusterHistory := history.NewRecorder(backends, rootConfig.Dashboard.SampleInterval(), rootConfig.Dashboard.HistorySize())
+	activeNodeClusterMonitor.RegisterBackendSubscriber(clusterHistory.ActiveBackendChan)
+	activeNodeClusterMonitor.OnHealthcheck(clusterHistory.RecordHealthcheck)
+
+	var webhookWatcher *webhook.Watcher
+	if notifier != nil {
+		webhookWatcher = webhook.NewWatcher(clusterConfig.Name, notifier)
+		activeNodeClusterMonitor.RegisterBackendSubscriber(webhookWatcher.ActiveBackendChan)
+		activeNodeClusterMonitor.OnHealthcheck(webhookWatcher.RecordHealthcheck)
+		clusterStateManager.OnTrafficChange(webhookWatcher.RecordTraffic)
+	}
+
+	clusterStateManager.RegisterTrafficEnabledChan(activeNodeBridgeRunner.TrafficEnabledChan)
+	go clusterStateManager.ListenForActiveBackend()
+
+	var members grouper.Members
+	if discoveryRunner != nil {
+		members = append(members, grouper.Member{
+			Name:   "discovery",
+			Runner: discoveryRunner,
+		})
+	}
+
+	members = append(members, grouper.Members{
+		{
+			Name:   "active-node-bridge",
+			Runner: activeNodeBridgeRunner,
+		},
+		{
+			// The history and the webhook watcher start before the monitor,
+			// whose active backend they receive, and stop after it.
+			Name:   "history",
+			Runner: clusterHistory,
+		},
+	}...)
+
+	if webhookWatcher != nil {
+		members = append(members, grouper.Member{
+			Name:   "webhooks",
+			Runner: webhookWatcher,
+		})
+	}
+
+	members = append(members, grouper.Member{
+		Name:   "active-node-monitor",
+		Runner: monitor.NewRunner(activeNodeClusterMonitor, logger),
+	})
+
+	if rootConfig.StatusLog.Enabled {
+		activeStatusLogger := statuslogger.NewStatusLogger(
+			backends,
+			activeNodeClusterMonitor,
+			rootConfig.StatusLogInterval(),
+			logger.Session("status"),
+		)
+		members = append(members, grouper.Member{
+			Name:   "status-logger",
+			Runner: activeStatusLogger,
+		})
+	}
+
+	var inactiveNodeSourceFilter *sourcefilter.Filter
+	monitors := map[string]diagnostics.Monitor{"active": activeNodeClusterMonitor}
+
+	// Routing rules may send sessions from the active port to the backend
+	// the inactive port would use, even when that port is not exposed.
+	if proxyConfig.InactiveMysqlPort != 0 || routing.UsesReaders(proxyConfig.Routing.RouterRules()) {
+		inactiveNodeClusterMonitor := monitor.NewClusterMonitor(client, clusterConfig.GaleraAgentTLS.Enabled, backends, proxyConfig.HealthcheckTimeout(), logger.Session("inactive-monitor"), false)
+		if rootConfig.Locality.PreferLocalReaders {
+			inactiveNodeClusterMonitor.PreferBackendsLabelled(rootConfig.Locality.ZoneLabel, rootConfig.Locality.Zone())
+		}
+
+		if proxyConfig.Routing.Enabled() {
+			inactiveNodeClusterMonitor.RegisterBackendSubscriber(activeNodeBridgeRunner.ReaderBackendChan)
+		}
+
+		if proxyConfig.InactiveMysqlPort != 0 {
+			inactiveNodeAddresses := listener.TCP(rootConfig.ListenHosts(proxyConfig.BindAddresses), proxyConfig.InactiveMysqlPort)
+			if proxyConfig.InactiveUnixSocket != "" {
+				inactiveNodeAddresses = append(inactiveNodeAddresses, listener.Unix(proxyConfig.InactiveUnixSocket))
+			}
+			inactiveNodeAddress := listener.Name(inactiveNodeAddresses)
+			inactiveNodeAdmission := newAdmission(proxyConfig.InactiveAdmission)
+			if inactiveNodeAdmission != nil {
+				metricsEmitter.AddAdmission(clusterConfig.Name, inactiveNodeAddress, inactiveNodeAdmission)
+			}
+
+			inactiveNodeSourceFilter, err = sourcefilter.New(proxyConfig.InactiveSourceFilter.Rules())
+			if err != nil {
+				logger.Fatal("source-filter", err)
+			}
+			metricsEmitter.AddSourceFilter(clusterConfig.Name, inactiveNodeAddress, inactiveNodeSourceFilter)
+
+			inactiveNodeBridgeRunner := bridge.NewRunner(
+				inactiveNodeAddresses,
+				0,
+				trafficEnabled,
+				accessLog,
+				admissionOrNil(inactiveNodeAdmission),
+				inactiveNodeSourceFilter,
+				nil,
+				logger.Session("inactive-bridge-runner"),
+			)
+			inactiveNodeBridgeRunner.DescribeDisabledTraffic(clusterStateManager)
+
+			if userQuotas != nil || proxyConfig.InactiveWsrepSyncWait > 0 || proxyConfig.Reconnect.Enabled() {
+				inactiveNodeBridgeRunner.InspectHandshakes(proxyConfig.HandshakeTimeout())
+			}
+			if userQuotas != nil {
+				inactiveNodeBridgeRunner.LimitUserSessions(userQuotas)
+				if proxyConfig.UserQuotas.RefuseTLS {
+					inactiveNodeBridgeRunner.RefuseTLS()
+				}
+			}
+			if proxyConfig.InactiveWsrepSyncWait > 0 {
+				inactiveNodeBridgeRunner.EnforceSyncWait(proxyConfig.InactiveWsrepSyncWait)
+			}
+			if proxyConfig.Reconnect.Enabled() {
+				inactiveNodeBridgeRunner.ReconnectIdleSessions(proxyConfig.Reconnect.Users, proxyConfig.Reconnect.Timeout())
+			}
+
+			inactiveNodeClusterMonitor.RegisterBackendSubscriber(inactiveNodeBridgeRunner.ActiveBackendChan)
+			clusterStateManager.RegisterTrafficEnabledChan(inactiveNodeBridgeRunner.TrafficEnabledChan)
+
+			members = append(members, grouper.Member{
+				Name:   "inactive-node-bridge",
+				Runner: inactiveNodeBridgeRunner,
+			})
+		}
+
+		monitors["inactive"] = inactiveNodeClusterMonitor
+		members = append(members, grouper.Member{
+			Name:   "inactive-node-monitor",
+			Runner: monitor.NewRunner(inactiveNodeClusterMonitor, logger),
+		})
+
+		if rootConfig.StatusLog.Enabled {
+			inactiveStatusLogger := statuslogger.NewStatusLogger(
+				backends,
+				inactiveNodeClusterMonitor,
+				rootConfig.StatusLogInterval(),
+				logger.Session("inactive-node-status"),
+			)
+			members = append(members, grouper.Member{
+				Name:   "inactive-node-status-logger",
+				Runner: inactiveStatusLogger,
+			})
+		}
+	}
+
+	return &proxiedCluster{
+		name:                 clusterConfig.Name,
+		backends:             backends,
+		clusterAPI:           clusterStateManager,
+		history:              clusterHistory,
+		activeSourceFilter:   activeNodeSourceFilter,
+		inactiveSourceFilter: inactiveNodeSourceFilter,
+		monitors:             monitors,
+		members:              members,
+	}
+}
+
+func (c *proxiedCluster) apiCluster() api.Cluster {
+	return api.Cluster{
+		Name:           c.name,
+		ClusterManager: c.clusterAPI,
+		Backends:       c.backends,
+		History:        c.history,
+	}
+}
+
+func (c *proxiedCluster) diagnosticsCluster() diagnostics.Cluster {
+	return diagnostics.Cluster{
+		Name:     c.name,
+		Backends: c.backends,
+		Monitors: c.monitors,
+	}
+}
+
+// reloadSourceFilters applies the source filters of clusterConfig to the
+// cluster's running listeners.
+func (c *proxiedCluster) reloadSourceFilters(clusterConfig config.Cluster) error {
+	if err := c.activeSourceFilter.Update(clusterConfig.Proxy.SourceFilter.Rules()); err != nil {
+		return err
+	}
+	if c.inactiveSourceFilter != nil {
+		if err := c.inactiveSourceFilter.Update(clusterConfig.Proxy.InactiveSourceFilter.Rules()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,23 +3,29 @@ package main
 import (
 	"fmt"
 	"net/http"
-	_ "net/http/pprof"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"code.cloudfoundry.org/lager/v3"
 	"github.com/tedsuo/ifrit"
 	"github.com/tedsuo/ifrit/grouper"
 	"github.com/tedsuo/ifrit/sigmon"
 
+	"github.com/cloudfoundry-incubator/switchboard"
+	"github.com/cloudfoundry-incubator/switchboard/accesslog"
+	"github.com/cloudfoundry-incubator/switchboard/admission"
 	"github.com/cloudfoundry-incubator/switchboard/api"
 	"github.com/cloudfoundry-incubator/switchboard/apiaggregator"
+	"github.com/cloudfoundry-incubator/switchboard/capture"
 	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/diagnostics"
 	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/listener"
 	"github.com/cloudfoundry-incubator/switchboard/metrics"
 	"github.com/cloudfoundry-incubator/switchboard/runner/bridge"
 	httprunner "github.com/cloudfoundry-incubator/switchboard/runner/http"
-	"github.com/cloudfoundry-incubator/switchboard/runner/monitor"
-	"github.com/cloudfoundry-incubator/switchboard/runner/statuslogger"
+	"github.com/cloudfoundry-incubator/switchboard/webhook"
 )
 
 func main() {
@@ -36,8 +42,12 @@ func main() {
 		logger.Fatal("Error validating config:", err)
 	}
 
-	if _, err := os.Stat(rootConfig.StaticDir); os.IsNotExist(err) {
-		logger.Fatal(fmt.Sprintf("staticDir: %s does not exist", rootConfig.StaticDir), nil)
+	assets := switchboard.Static()
+	if rootConfig.StaticDir != "" {
+		if _, err := os.Stat(rootConfig.StaticDir); os.IsNotExist(err) {
+			logger.Fatal(fmt.Sprintf("staticDir: %s does not exist", rootConfig.StaticDir), nil)
+		}
+		assets = os.DirFS(rootConfig.StaticDir)
 	}
 
 	serverTLSConfig, err := rootConfig.ServerTLSConfig()
@@ -45,37 +55,101 @@ func main() {
 		logger.Fatal("load-tls-config", err)
 	}
 
-	backends := domain.NewBackends(rootConfig.Proxy.Backends, logger)
+	var accessLog bridge.AccessLog
+	if rootConfig.AccessLog.Enabled {
+		accessLogWriter, err := accesslog.New(
+			rootConfig.AccessLog.Path,
+			rootConfig.AccessLog.MaxSize(),
+			int(rootConfig.AccessLog.MaxBackups),
+		)
+		if err != nil {
+			logger.Fatal("open-access-log", err)
+		}
+		defer accessLogWriter.Close()
+		accessLog = accessLogWriter
+	}
 
-	client := rootConfig.HTTPClient()
+	// One recorder taps the backends of every cluster, so that a capture
+	// follows a client whichever cluster it connects to.
+	var (
+		captures api.Captures
+		tap      domain.Tap
+	)
+	if rootConfig.Capture.Enabled {
+		recorder := capture.NewRecorder(
+			rootConfig.Capture.Directory,
+			capture.Limits{
+				MaxBytes:    int64(rootConfig.Capture.MaxBytes),
+				MaxDuration: rootConfig.Capture.MaxDuration(),
+				MaxFiles:    int(rootConfig.Capture.MaxFiles),
+			},
+			logger.Session("capture"),
+		)
+		captures = recorder
+		tap = recorder
+	}
 
-	activeNodeClusterMonitor := monitor.NewClusterMonitor(client, rootConfig.GaleraAgentTLS.Enabled, backends, rootConfig.Proxy.HealthcheckTimeout(), logger.Session("active-monitor"), true)
+	metricsEmitter := metrics.New()
 
-	activeNodeBridgeRunner := bridge.NewRunner(
-		fmt.Sprintf("%s:%d", rootConfig.BindAddress, rootConfig.Proxy.Port),
-		rootConfig.Proxy.ShutdownDelay(),
-		logger.Session("active-bridge-runner"),
+	// One dispatcher sends the events of every cluster, so that each
+	// receiver gets them in order.
+	var (
+		notifier          webhook.Notifier
+		webhookDispatcher *webhook.Dispatcher
 	)
-	clusterStateManager := api.NewClusterAPI(logger)
+	if rootConfig.Webhooks.Enabled() {
+		webhookDispatcher = webhook.NewDispatcher(rootConfig.Webhooks, logger.Session("webhooks"))
+		notifier = webhookDispatcher
+	}
 
-	activeNodeClusterMonitor.RegisterBackendSubscriber(activeNodeBridgeRunner.ActiveBackendChan)
-	activeNodeClusterMonitor.RegisterBackendSubscriber(clusterStateManager.ActiveBackendChan)
+	var (
+		clusters            []*proxiedCluster
+		apiClusters         []api.Cluster
+		diagnosticsClusters []diagnostics.Cluster
+		clusterMembers      grouper.Members
+	)
+	for i, clusterConfig := range rootConfig.AllClusters() {
+		// Further clusters log the same messages as the default one, tagged
+		// with their name.
+		clusterLogger := logger
+		if i > 0 {
+			clusterLogger = logger.WithData(lager.Data{"cluster": clusterConfig.Name})
+		}
 
-	clusterStateManager.RegisterTrafficEnabledChan(activeNodeBridgeRunner.TrafficEnabledChan)
-	go clusterStateManager.ListenForActiveBackend()
+		cluster := newProxiedCluster(clusterConfig, rootConfig, accessLog, tap, metricsEmitter, notifier, clusterLogger)
+		clusters = append(clusters, cluster)
+		apiClusters = append(apiClusters, cluster.apiCluster())
+		diagnosticsClusters = append(diagnosticsClusters, cluster.diagnosticsCluster())
+		clusterMembers = append(clusterMembers, grouper.Member{
+			Name:   "cluster-" + cluster.name,
+			Runner: grouper.NewOrdered(os.Interrupt, cluster.members),
+		})
+	}
+	defaultCluster := clusters[0]
 
-	apiHandler := api.NewHandler(clusterStateManager, backends, logger, rootConfig.API, rootConfig.StaticDir)
-	aggregatorHandler := apiaggregator.NewHandler(logger, rootConfig.API)
+	apiHandler := api.NewHandler(defaultCluster.clusterAPI, defaultCluster.backends, apiClusters, captures, defaultCluster.history, logger, rootConfig.API, assets)
+	aggregatorHandler := apiaggregator.NewHandler(logger, rootConfig.API, rootConfig.AggregatorHTTPClient())
 
-	members := grouper.Members{
+	var members grouper.Members
+	if webhookDispatcher != nil {
+		// The dispatcher stops after the clusters, whose events it sends.
+		members = append(members, grouper.Member{
+			Name:   "webhooks",
+			Runner: webhookDispatcher,
+		})
+	}
+
+	members = append(members, grouper.Members{
 		{
-			Name:   "active-node-bridge",
-			Runner: activeNodeBridgeRunner,
+			// Clusters shut down in parallel, so that their shutdown delays
+			// do not add up.
+			Name:   "clusters",
+			Runner: grouper.NewParallel(os.Interrupt, clusterMembers),
 		},
 		{
 			Name: "api-aggregator",
 			Runner: httprunner.NewRunner(
-				fmt.Sprintf("%s:%d", rootConfig.BindAddress, rootConfig.API.AggregatorPort),
+				listener.TCP(rootConfig.ListenHosts(rootConfig.API.BindAddresses), rootConfig.API.AggregatorPort),
 				aggregatorHandler,
 				serverTLSConfig,
 				rootConfig.API.TLS.Enabled,
@@ -84,36 +158,36 @@ func main() {
 		{
 			Name: "api",
 			Runner: httprunner.NewRunner(
-				fmt.Sprintf("%s:%d", rootConfig.BindAddress, rootConfig.API.Port),
+				listener.TCP(rootConfig.ListenHosts(rootConfig.API.BindAddresses), rootConfig.API.Port),
 				apiHandler,
 				serverTLSConfig,
 				rootConfig.API.TLS.Enabled,
 			),
 		},
-		{
-			Name:   "active-node-monitor",
-			Runner: monitor.NewRunner(activeNodeClusterMonitor, logger),
-		},
-	}
+	}...)
 
 	if rootConfig.Metrics.Enabled {
-		metricsEmitter := metrics.New(backends)
 		members = append(members, grouper.Member{
 			Name:   "metrics",
-			Runner: httprunner.NewRunner(fmt.Sprintf("localhost:%d", rootConfig.Metrics.Port), metricsEmitter.Handler(), serverTLSConfig, rootConfig.API.TLS.Enabled),
+			Runner: httprunner.NewRunner(listener.TCP([]string{"localhost"}, rootConfig.Metrics.Port), metricsEmitter.Handler(), serverTLSConfig, rootConfig.API.TLS.Enabled),
 		})
 	}
 
-	if rootConfig.StatusLog.Enabled {
-		activeStatusLogger := statuslogger.NewStatusLogger(
-			backends,
-			activeNodeClusterMonitor,
-			rootConfig.StatusLogInterval(),
-			logger.Session("status"),
-		)
+	if rootConfig.Diagnostics.Enabled {
 		members = append(members, grouper.Member{
-			Name:   "status-logger",
-			Runner: activeStatusLogger,
+			Name: "diagnostics",
+			Runner: httprunner.NewRunner(
+				listener.TCP(rootConfig.Diagnostics.Hosts(), rootConfig.Diagnostics.Port),
+				diagnostics.NewHandler(
+					diagnosticsClusters,
+					rootConfig.LogSink,
+					rootConfig.Diagnostics.Username,
+					rootConfig.Diagnostics.Password,
+					logger.Session("diagnostics"),
+				),
+				serverTLSConfig,
+				rootConfig.API.TLS.Enabled,
+			),
 		})
 	}
 
@@ -121,7 +195,7 @@ func main() {
 		members = append(members, grouper.Member{
 			Name: "health",
 			Runner: httprunner.NewRunner(
-				fmt.Sprintf("%s:%d", rootConfig.BindAddress, rootConfig.HealthPort),
+				listener.TCP(rootConfig.ListenHosts(rootConfig.HealthBindAddresses), rootConfig.HealthPort),
 				http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}),
 				serverTLSConfig,
 				rootConfig.API.TLS.Enabled,
@@ -129,50 +203,81 @@ func main() {
 		})
 	}
 
-	if rootConfig.Proxy.InactiveMysqlPort != 0 {
-		inactiveNodeClusterMonitor := monitor.NewClusterMonitor(client, rootConfig.GaleraAgentTLS.Enabled, backends, rootConfig.Proxy.HealthcheckTimeout(), logger.Session("inactive-monitor"), false)
+	go reloadSourceFiltersOnSIGHUP(clusters, logger.Session("source-filter"))
 
-		inactiveNodeBridgeRunner := bridge.NewRunner(
-			fmt.Sprintf("%s:%d", rootConfig.BindAddress, rootConfig.Proxy.InactiveMysqlPort),
-			0,
-			logger.Session("inactive-bridge-runner"),
-		)
+	group := grouper.NewOrdered(os.Interrupt, members)
+	process := ifrit.Invoke(sigmon.New(group))
 
-		inactiveNodeClusterMonitor.RegisterBackendSubscriber(inactiveNodeBridgeRunner.ActiveBackendChan)
-		clusterStateManager.RegisterTrafficEnabledChan(inactiveNodeBridgeRunner.TrafficEnabledChan)
+	logger.Info("Proxy started", lager.Data{"proxyConfig": rootConfig.Proxy})
 
-		members = append(members,
-			grouper.Member{
-				Name:   "inactive-node-bridge",
-				Runner: inactiveNodeBridgeRunner,
-			},
-			grouper.Member{
-				Name:   "inactive-node-monitor",
-				Runner: monitor.NewRunner(inactiveNodeClusterMonitor, logger),
-			},
-		)
+	err = <-process.Wait()
+	if err != nil {
+		logger.Fatal("Switchboard exited unexpectedly", err, lager.Data{"proxyConfig": rootConfig.Proxy})
+	}
+}
+
+// reloadSourceFiltersOnSIGHUP re-reads the config file on SIGHUP and applies
+// its source filters to the running listeners of every cluster. Other config
+// changes still require a restart.
+func reloadSourceFiltersOnSIGHUP(clusters []*proxiedCluster, logger lager.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	for range hup {
+		newConfig, err := config.NewConfig(os.Args)
+		if err == nil {
+			err = newConfig.Validate()
+		}
+		if err != nil {
+			logger.Error("reload-failed", err)
+			continue
+		}
 
-		if rootConfig.StatusLog.Enabled {
-			inactiveStatusLogger := statuslogger.NewStatusLogger(
-				backends,
-				inactiveNodeClusterMonitor,
-				rootConfig.StatusLogInterval(),
-				logger.Session("inactive-node-status"),
-			)
-			members = append(members, grouper.Member{
-				Name:   "inactive-node-status-logger",
-				Runner: inactiveStatusLogger,
+		clusterConfigs := map[string]config.Cluster{}
+		for _, c := range newConfig.AllClusters() {
+			clusterConfigs[c.Name] = c
+		}
+
+		for _, cluster := range clusters {
+			clusterConfig, ok := clusterConfigs[cluster.name]
+			if !ok {
+				logger.Error("reload-failed", fmt.Errorf("cluster %q is no longer configured; restart to remove it", cluster.name))
+				continue
+			}
+
+			if err := cluster.reloadSourceFilters(clusterConfig); err != nil {
+				logger.Error("reload-failed", err, lager.Data{"cluster": cluster.name})
+				continue
+			}
+
+			logger.Info("reloaded", lager.Data{
+				"cluster":              cluster.name,
+				"sourceFilter":         clusterConfig.Proxy.SourceFilter,
+				"inactiveSourceFilter": clusterConfig.Proxy.InactiveSourceFilter,
 			})
 		}
 	}
+}
 
-	group := grouper.NewOrdered(os.Interrupt, members)
-	process := ifrit.Invoke(sigmon.New(group))
+func newAdmission(c config.Admission) *admission.Controller {
+	if !c.Enabled() {
+		return nil
+	}
 
-	logger.Info("Proxy started", lager.Data{"proxyConfig": rootConfig.Proxy})
+	return admission.New(admission.Limits{
+		MaxSessions:          int(c.MaxSessions),
+		MaxSessionsPerClient: int(c.MaxSessionsPerClient),
+		ConnectionsPerSecond: float64(c.ConnectionsPerSecond),
+		Burst:                int(c.Burst),
+		QueueTimeout:         c.QueueTimeout(),
+	})
+}
 
-	err = <-process.Wait()
-	if err != nil {
-		logger.Fatal("Switchboard exited unexpectedly", err, lager.Data{"proxyConfig": rootConfig.Proxy})
+// admissionOrNil avoids handing the runner a non-nil interface wrapping a nil
+// controller.
+func admissionOrNil(c *admission.Controller) bridge.Admission {
+	if c == nil {
+		return nil
 	}
+	return c
 }
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -24,8 +25,13 @@ import (
 
 	"github.com/cloudfoundry-incubator/switchboard/api"
 	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/diagnostics"
+	"github.com/cloudfoundry-incubator/switchboard/discovery/dnsstub"
 	"github.com/cloudfoundry-incubator/switchboard/dummies"
+	"github.com/cloudfoundry-incubator/switchboard/history"
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
 	"github.com/cloudfoundry-incubator/switchboard/testing"
+	"github.com/cloudfoundry-incubator/switchboard/webhook"
 	"gopkg.in/yaml.v3"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -94,6 +100,30 @@ func sendData(conn net.Conn, data string) (Response, error) {
 	}
 }
 
+// readRefusal connects to the proxy and returns the message of the MySQL
+// error it sends in place of a server greeting.
+func readRefusal(port uint) (string, error) {
+	conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	packet, err := mysqlproto.ReadPacket(conn)
+	if err != nil {
+		return "", err
+	}
+	_, sqlState, message, err := mysqlproto.ParseErrorPacket(packet.Payload)
+	if err != nil {
+		return "", fmt.Errorf("expected an error packet, got %q", packet.Payload)
+	}
+	if sqlState != "" {
+		return "", fmt.Errorf("expected no SQL state before the handshake, got %q", sqlState)
+	}
+	return message, nil
+}
+
 func verifyHeaderContains(header http.Header, key, valueSubstring string) {
 	found := false
 	for k, v := range header {
@@ -152,7 +182,6 @@ var _ = Describe("Switchboard", func() {
 		rootConfig                   config.Config
 		proxyConfig                  config.Proxy
 		apiConfig                    config.API
-		staticDir                    string
 		testServerTLSConfig          *tls.Config
 		testCert                     tls.Certificate
 
@@ -282,9 +311,6 @@ var _ = Describe("Switchboard", func() {
 			err    error
 			testCA []byte
 		)
-		staticDir, err = filepath.Abs("../../static")
-		Expect(err).NotTo(HaveOccurred())
-
 		testCA, testCert, err = testing.GenerateSelfSignedCertificate("localhost")
 		Expect(err).NotTo(HaveOccurred())
 
@@ -342,7 +368,6 @@ var _ = Describe("Switchboard", func() {
 			Proxy:       proxyConfig,
 			API:         apiConfig,
 			HealthPort:  switchboardHealthPort,
-			StaticDir:   staticDir,
 			GaleraAgentTLS: config.GaleraAgentTLS{
 				Enabled:    true,
 				ServerName: "localhost",
@@ -450,6 +475,212 @@ var _ = Describe("Switchboard", func() {
 			ginkgomon_v2.Interrupt(process, 10*time.Second)
 		})
 
+		When("backends are discovered through DNS", func() {
+			var dnsServer *dnsstub.Server
+
+			BeforeEach(func() {
+				dnsServer = dnsstub.NewServer()
+				dnsServer.SetIPs("mysql.switchboard.test", net.ParseIP("127.0.0.1"))
+
+				rootConfig.Proxy.Backends = nil
+				rootConfig.Proxy.Discovery = config.Discovery{
+					Name:           "mysql.switchboard.test",
+					Port:           backends[0].Port,
+					StatusPort:     backends[0].StatusPort,
+					StatusEndpoint: backends[0].StatusEndpoint,
+					IntervalMillis: 100,
+					Server:         dnsServer.Addr(),
+				}
+			})
+
+			AfterEach(func() {
+				dnsServer.Close()
+			})
+
+			It("proxies to the backends the records name", func() {
+				Eventually(func() ([]api.V1BackendResponse, error) {
+					req, err := http.NewRequest("GET", fmt.Sprintf("https://localhost:%d/v1/backends", switchboardAPIPort), nil)
+					Expect(err).NotTo(HaveOccurred())
+					req.SetBasicAuth("username", "password")
+
+					resp, err := httpClient.Do(req)
+					if err != nil {
+						return nil, err
+					}
+					defer func() { _ = resp.Body.Close() }()
+
+					var backends []api.V1BackendResponse
+					err = json.NewDecoder(resp.Body).Decode(&backends)
+					return backends, err
+				}, startupTimeout).Should(ConsistOf(And(
+					HaveField("Name", "127.0.0.1"),
+					HaveField("Active", true),
+				)))
+
+				Eventually(func() (uint, error) {
+					conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", proxyPort))
+					if err != nil {
+						return 0, err
+					}
+					defer func() { _ = conn.Close() }()
+
+					response, err := sendData(conn, "discovered")
+					return response.BackendPort, err
+				}, startupTimeout).Should(Equal(backends[0].Port))
+			})
+		})
+
+		When("diagnostics are enabled", func() {
+			var diagnosticsPort uint
+
+			BeforeEach(func() {
+				diagnosticsPort = uint(10700 + GinkgoParallelProcess())
+				rootConfig.Diagnostics = config.Diagnostics{
+					Enabled:  true,
+					Port:     diagnosticsPort,
+					Username: "diagnostics",
+					Password: "diagnostics-password",
+				}
+			})
+
+			It("reports the state of each monitor", func() {
+				Eventually(func() (diagnostics.StateResponse, error) {
+					var state diagnostics.StateResponse
+
+					req, err := http.NewRequest("GET", fmt.Sprintf("https://localhost:%d/debug/state", diagnosticsPort), nil)
+					Expect(err).NotTo(HaveOccurred())
+					req.SetBasicAuth("diagnostics", "diagnostics-password")
+
+					resp, err := httpClient.Do(req)
+					if err != nil {
+						return state, err
+					}
+					defer func() { _ = resp.Body.Close() }()
+
+					err = json.NewDecoder(resp.Body).Decode(&state)
+					return state, err
+				}, startupTimeout).Should(HaveField("Clusters", ConsistOf(And(
+					HaveField("Name", config.DefaultClusterName),
+					HaveField("Monitors", HaveKeyWithValue("active", HaveField("ActiveBackend", "backend-0"))),
+				))))
+			})
+		})
+
+		When("webhooks are configured", func() {
+			var (
+				receiver *httptest.Server
+				events   chan webhook.Event
+			)
+
+			BeforeEach(func() {
+				events = make(chan webhook.Event, 100)
+				receiver = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					body, _ := io.ReadAll(req.Body)
+					if req.Header.Get(webhook.HeaderSignature) != webhook.Sign([]byte("hook-secret"), body) {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+
+					var event webhook.Event
+					if err := json.Unmarshal(body, &event); err == nil {
+						events <- event
+					}
+				}))
+
+				rootConfig.Webhooks = config.Webhooks{URLs: []string{receiver.URL}, Secret: "hook-secret"}
+			})
+
+			AfterEach(func() {
+				receiver.Close()
+			})
+
+			It("notifies traffic changes and failovers", func() {
+				// The monitor's first choice of backend-0 is not a failover.
+				Eventually(func() (uint, error) {
+					conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", proxyPort))
+					if err != nil {
+						return 0, err
+					}
+					defer func() { _ = conn.Close() }()
+
+					response, err := sendData(conn, "before failover")
+					return response.BackendPort, err
+				}, startupTimeout).Should(Equal(backends[0].Port))
+
+				allowTraffic(httpClient, false, switchboardAPIPort)
+				Eventually(events).Should(Receive(And(
+					HaveField("Type", webhook.EventTrafficDisabled),
+					HaveField("Cluster", config.DefaultClusterName),
+					HaveField("Message", "main test is disabling traffic"),
+				)))
+
+				allowTraffic(httpClient, true, switchboardAPIPort)
+				Eventually(events).Should(Receive(HaveField("Type", webhook.EventTrafficEnabled)))
+
+				healthcheckRunners[0].SetStatusCode(http.StatusServiceUnavailable)
+				Eventually(events, startupTimeout).Should(Receive(And(
+					HaveField("Type", webhook.EventBackendUnhealthy),
+					HaveField("Backend", "backend-0"),
+				)))
+				Eventually(events, startupTimeout).Should(Receive(And(
+					HaveField("Type", webhook.EventActiveBackendChanged),
+					HaveField("From", "backend-0"),
+					HaveField("To", "backend-1"),
+				)))
+			})
+		})
+
+		When("listeners bind to several addresses", func() {
+			var socketPath string
+
+			BeforeEach(func() {
+				socketPath = filepath.Join(GinkgoT().TempDir(), "mysql.sock")
+
+				rootConfig.Proxy.BindAddresses = []string{"127.0.0.1", "::1"}
+				rootConfig.Proxy.UnixSocket = socketPath
+				rootConfig.API.BindAddresses = []string{"127.0.0.1", "::1"}
+			})
+
+			It("proxies clients of each address and the Unix domain socket", func() {
+				for _, a := range []struct{ network, address string }{
+					{"tcp", fmt.Sprintf("127.0.0.1:%d", proxyPort)},
+					{"tcp", fmt.Sprintf("[::1]:%d", proxyPort)},
+					{"unix", socketPath},
+				} {
+					Eventually(func() (uint, error) {
+						conn, err := net.Dial(a.network, a.address)
+						if err != nil {
+							return 0, err
+						}
+						defer func() { _ = conn.Close() }()
+
+						response, err := sendData(conn, "bound")
+						return response.BackendPort, err
+					}, startupTimeout).Should(Equal(backends[0].Port), a.address)
+				}
+			})
+
+			It("serves the API on IPv6", func() {
+				// The test certificate names localhost rather than ::1.
+				tlsConfig := httpClient.Transport.(*http.Transport).TLSClientConfig.Clone()
+				tlsConfig.ServerName = "localhost"
+				ipv6Client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+
+				Eventually(func() (int, error) {
+					req, err := http.NewRequest("GET", fmt.Sprintf("https://[::1]:%d/v1/backends", switchboardAPIPort), nil)
+					Expect(err).NotTo(HaveOccurred())
+					req.SetBasicAuth("username", "password")
+
+					resp, err := ipv6Client.Do(req)
+					if err != nil {
+						return 0, err
+					}
+					defer func() { _ = resp.Body.Close() }()
+					return resp.StatusCode, nil
+				}, startupTimeout).Should(Equal(http.StatusOK))
+			})
+		})
+
 		When("switchboard starts successfully with TLS", func() {
 			JustBeforeEach(func() {
 				waitForServersToBeReady("https")
@@ -558,11 +789,51 @@ var _ = Describe("Switchboard", func() {
 						body, err := io.ReadAll(resp.Body)
 						Expect(err).NotTo(HaveOccurred())
 						Expect(len(body)).To(BeNumerically(">", 0), "Expected body to not be empty")
+						Expect(string(body)).To(ContainSubstring("application.js"))
+					})
+				})
+
+				Context("when StaticDir is set", func() {
+					BeforeEach(func() {
+						rootConfig.StaticDir = GinkgoT().TempDir()
+						Expect(os.WriteFile(filepath.Join(rootConfig.StaticDir, "index.html"), []byte("custom dashboard"), 0o644)).To(Succeed())
+					})
+
+					It("serves the dashboard from it", func() {
+						req, err := http.NewRequest("GET", fmt.Sprintf("https://localhost:%d/", switchboardAPIPort), nil)
+						Expect(err).NotTo(HaveOccurred())
+
+						req.SetBasicAuth("username", "password")
+						resp, err := httpClient.Do(req)
+						Expect(err).NotTo(HaveOccurred())
+						defer func() { _ = resp.Body.Close() }()
+
+						body, err := io.ReadAll(resp.Body)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(string(body)).To(Equal("custom dashboard"))
 					})
 				})
 			})
 
 			Describe("api", func() {
+				Describe("/v1/history/sessions", func() {
+					It("returns the session counts of the backends", func() {
+						req, err := http.NewRequest("GET", fmt.Sprintf("https://localhost:%d/v1/history/sessions", switchboardAPIPort), nil)
+						Expect(err).NotTo(HaveOccurred())
+
+						req.SetBasicAuth("username", "password")
+						resp, err := httpClient.Do(req)
+						Expect(err).NotTo(HaveOccurred())
+						defer func() { _ = resp.Body.Close() }()
+						Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+						var samples []history.SessionSample
+						Expect(json.NewDecoder(resp.Body).Decode(&samples)).To(Succeed())
+						Expect(samples).NotTo(BeEmpty())
+						Expect(samples[0].Sessions).To(HaveKey("backend-0"))
+					})
+				})
+
 				Describe("/v0/backends/", func() {
 					var url string
 
@@ -817,7 +1088,7 @@ var _ = Describe("Switchboard", func() {
 								Expect(err).ToNot(HaveOccurred())
 								Expect(dataWhileHealthy.Message).To(Equal("data while healthy"))
 
-								if initialActiveBackend == backends[0] {
+								if initialActiveBackend.Name == backends[0].Name {
 									healthcheckRunners[0].SetStatusCode(http.StatusServiceUnavailable)
 								} else {
 									healthcheckRunners[1].SetStatusCode(http.StatusServiceUnavailable)
@@ -844,7 +1115,7 @@ var _ = Describe("Switchboard", func() {
 								Expect(err).ToNot(HaveOccurred())
 								Expect(data.Message).To(Equal("data before hang"))
 
-								if initialActiveBackend == backends[0] {
+								if initialActiveBackend.Name == backends[0].Name {
 									healthcheckRunners[0].SetHang(true)
 								} else {
 									healthcheckRunners[1].SetHang(true)
@@ -882,14 +1153,9 @@ var _ = Describe("Switchboard", func() {
 							})
 
 							It("rejects any new connections that are attempted", func() {
-								Eventually(func() error {
-									conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", proxyPort))
-									if err != nil {
-										return err
-									}
-									_, err = sendData(conn, "write that should fail")
-									return err
-								}, healthcheckWaitDuration, 200*time.Millisecond).Should(matchConnectionDisconnect())
+								Eventually(func() (string, error) {
+									return readRefusal(proxyPort)
+								}, healthcheckWaitDuration, 200*time.Millisecond).Should(Equal("switchboard: no healthy backend"))
 							})
 						})
 					})
@@ -918,17 +1184,9 @@ var _ = Describe("Switchboard", func() {
 
 						It("severs new connections", func() {
 							allowTraffic(httpClient, false, switchboardAPIPort)
-							Eventually(func() error {
-								conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", proxyPort))
-								if err != nil {
-									return err
-								}
-								defer func() { _ = conn.Close() }()
-
-								_, err = sendData(conn, "write that should fail")
-
-								return err
-							}).Should(matchConnectionDisconnect())
+							Eventually(func() (string, error) {
+								return readRefusal(proxyPort)
+							}).Should(Equal("switchboard: traffic disabled: main test is disabling traffic"))
 						})
 
 						It("permits new connections again after re-enabling traffic", func() {
@@ -1054,7 +1312,7 @@ var _ = Describe("Switchboard", func() {
 								Expect(err).ToNot(HaveOccurred())
 								Expect(dataWhileHealthy.Message).To(Equal("data while healthy"))
 
-								if initialInactiveBackend == backends[0] {
+								if initialInactiveBackend.Name == backends[0].Name {
 									healthcheckRunners[0].SetStatusCode(http.StatusServiceUnavailable)
 								} else {
 									healthcheckRunners[1].SetStatusCode(http.StatusServiceUnavailable)
@@ -1081,7 +1339,7 @@ var _ = Describe("Switchboard", func() {
 								Expect(err).ToNot(HaveOccurred())
 								Expect(data.Message).To(Equal("data before hang"))
 
-								if initialInactiveBackend == backends[0] {
+								if initialInactiveBackend.Name == backends[0].Name {
 									healthcheckRunners[0].SetHang(true)
 								} else {
 									healthcheckRunners[1].SetHang(true)
@@ -1119,15 +1377,9 @@ var _ = Describe("Switchboard", func() {
 							})
 
 							It("rejects any new connections that are attempted", func() {
-
-								Eventually(func() error {
-									conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", proxyInactiveNodePort))
-									if err != nil {
-										return err
-									}
-									_, err = sendData(conn, "write that should fail")
-									return err
-								}, healthcheckWaitDuration, 200*time.Millisecond).Should(matchConnectionDisconnect())
+								Eventually(func() (string, error) {
+									return readRefusal(proxyInactiveNodePort)
+								}, healthcheckWaitDuration, 200*time.Millisecond).Should(Equal("switchboard: no healthy backend"))
 							})
 						})
 					})
@@ -1156,17 +1408,9 @@ var _ = Describe("Switchboard", func() {
 
 						It("severs new connections", func() {
 							allowTraffic(httpClient, false, switchboardAPIPort)
-							Eventually(func() error {
-								conn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", proxyInactiveNodePort))
-								if err != nil {
-									return err
-								}
-								defer func() { _ = conn.Close() }()
-
-								_, err = sendData(conn, "write that should fail")
-
-								return err
-							}).Should(matchConnectionDisconnect())
+							Eventually(func() (string, error) {
+								return readRefusal(proxyInactiveNodePort)
+							}).Should(Equal("switchboard: traffic disabled: main test is disabling traffic"))
 						})
 
 						It("permits new connections again after re-enabling traffic", func() {
@@ -1219,8 +1463,8 @@ var _ = Describe("Switchboard", func() {
 						body := strings.Split(string(bodyBytes), "\n")
 						Expect(body).To(ContainElement("# HELP backend_sessions_total Gauge of the current sessions from this proxy to a mysql backend"))
 						Expect(body).To(ContainElement("# TYPE backend_sessions_total gauge"))
-						Expect(body).To(ContainElement(`backend_sessions_total{backend="backend-0"} 0`))
-						Expect(body).To(ContainElement(`backend_sessions_total{backend="backend-1"} 0`))
+						Expect(body).To(ContainElement(`backend_sessions_total{backend="backend-0",cluster="default"} 0`))
+						Expect(body).To(ContainElement(`backend_sessions_total{backend="backend-1",cluster="default"} 0`))
 					})
 				})
 
@@ -1301,7 +1545,7 @@ var _ = Describe("Switchboard", func() {
 
 					It("reports unhealthy backends", func() {
 						// Mark one backend unhealthy
-						if initialActiveBackend == backends[0] {
+						if initialActiveBackend.Name == backends[0].Name {
 							healthcheckRunners[1].SetStatusCode(http.StatusServiceUnavailable)
 						} else {
 							healthcheckRunners[0].SetStatusCode(http.StatusServiceUnavailable)
@@ -1324,7 +1568,7 @@ var _ = Describe("Switchboard", func() {
 						Expect(initialContent).NotTo(ContainSubstring("last_failover_at"))
 
 						// Trigger failover by marking active backend unhealthy
-						if initialActiveBackend == backends[0] {
+						if initialActiveBackend.Name == backends[0].Name {
 							healthcheckRunners[0].SetStatusCode(http.StatusServiceUnavailable)
 						} else {
 							healthcheckRunners[1].SetStatusCode(http.StatusServiceUnavailable)
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cloudfoundry-incubator/switchboard/capture"
+	"github.com/cloudfoundry-incubator/switchboard/client"
+)
+
+// captureCommand runs the capture subcommand named by the first of args.
+func captureCommand(ctx context.Context, c *client.Client, args []string, usage func(), out io.Writer) error {
+	if len(args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var (
+		status capture.Status
+		err    error
+	)
+
+	switch args[0] {
+	case "start":
+		cmdFlags := flag.NewFlagSet("capture start", flag.ExitOnError)
+		clientIP := cmdFlags.String("client", "", "IP address of the client whose sessions to record")
+		backend := cmdFlags.String("backend", "", "name of the backend whose sessions to record")
+		duration := cmdFlags.Duration("duration", 0, "how long to record; defaults to the proxy's limit")
+		maxBytes := cmdFlags.Int64("max-bytes", 0, "stop after writing this many bytes; defaults to the proxy's limit")
+		_ = cmdFlags.Parse(args[1:])
+		status, err = c.StartCapture(ctx, capture.Filter{Client: *clientIP, Backend: *backend}, *duration, *maxBytes)
+	case "stop":
+		status, err = c.StopCapture(ctx)
+	case "status":
+		status, err = c.Capture(ctx)
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		return err
+	}
+
+	printCapture(out, status)
+	return nil
+}
+
+func printCapture(out io.Writer, status capture.Status) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	state := "stopped"
+	if status.Running {
+		state = "running"
+	}
+	fmt.Fprintf(w, "capture:\t%s\n", state)
+	fmt.Fprintf(w, "file:\t%s\n", status.Path)
+	if status.Filter.Client != "" {
+		fmt.Fprintf(w, "client:\t%s\n", status.Filter.Client)
+	}
+	if status.Filter.Backend != "" {
+		fmt.Fprintf(w, "backend:\t%s\n", status.Filter.Backend)
+	}
+	fmt.Fprintf(w, "started:\t%s\n", status.Started.Format(time.RFC3339))
+	if status.Stopped != nil {
+		fmt.Fprintf(w, "stopped:\t%s (%s)\n", status.Stopped.Format(time.RFC3339), status.StopReason)
+	} else {
+		fmt.Fprintf(w, "deadline:\t%s\n", status.Deadline.Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "sessions:\t%d\n", status.Sessions)
+	fmt.Fprintf(w, "bytes:\t%d of %d\n", status.Bytes, status.MaxBytes)
+	_ = w.Flush()
+}
+
+// decodeCapture prints the packets of the capture file at path. Operators
+// copy capture files off the proxy VM, so this does not talk to the API.
+func decodeCapture(path string, out io.Writer) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(out)
+	if err := capture.Decode(bufio.NewReader(f), w); err != nil {
+		_ = w.Flush()
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cloudfoundry-incubator/switchboard/api"
+	"github.com/cloudfoundry-incubator/switchboard/client"
+)
+
+const usage = `Usage: switchboard-ctl [options] <command> [command options]
+
+Commands:
+  status                     show the cluster state and every backend
+  enable [-message MSG]      enable traffic through the proxy
+  disable -message MSG       disable traffic through the proxy
+  watch [-interval 2s]       print cluster and backend changes as they happen
+  capture start [-client IP] [-backend NAME] [-duration 1m] [-max-bytes N]
+                             record the traffic of matching new sessions
+  capture stop               stop the running capture
+  capture status             show the running or the last capture
+  decode-capture FILE        print the MySQL packets of a capture file;
+                             does not need the API
+
+Options:
+`
+
+func main() {
+	flags := flag.NewFlagSet("switchboard-ctl", flag.ExitOnError)
+	apiURL := flags.String("api", os.Getenv("SWITCHBOARD_API"), "proxy API URL, e.g. https://10.0.0.5:8080 (env SWITCHBOARD_API)")
+	username := flags.String("username", envOrDefault("SWITCHBOARD_USERNAME", "proxy"), "API username (env SWITCHBOARD_USERNAME)")
+	password := flags.String("password", os.Getenv("SWITCHBOARD_PASSWORD"), "API password (env SWITCHBOARD_PASSWORD)")
+	caCert := flags.String("ca-cert", "", "path to a PEM-encoded CA used to verify the API certificate")
+	skipTLSValidation := flags.Bool("skip-tls-validation", false, "do not verify the API certificate")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout for each API request")
+	cluster := flags.String("cluster", os.Getenv("SWITCHBOARD_CLUSTER"), "name of the cluster to manage when the proxy serves several (env SWITCHBOARD_CLUSTER)")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[1:])
+
+	if flags.Arg(0) == "decode-capture" {
+		if flags.NArg() != 2 {
+			flags.Usage()
+			os.Exit(2)
+		}
+		if err := decodeCapture(flags.Arg(1), os.Stdout); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	if *apiURL == "" || flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	httpClient, err := newHTTPClient(*caCert, *skipTLSValidation, *timeout)
+	if err != nil {
+		fail(err)
+	}
+	c := client.New(*apiURL, *username, *password, httpClient)
+	if *cluster != "" {
+		c = c.ForCluster(*cluster)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	command, args := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "status":
+		err = status(ctx, c, os.Stdout)
+	case "enable":
+		cmdFlags := flag.NewFlagSet("enable", flag.ExitOnError)
+		message := cmdFlags.String("message", "", "reason for enabling traffic")
+		_ = cmdFlags.Parse(args)
+		err = setTraffic(ctx, c, true, *message, os.Stdout)
+	case "disable":
+		cmdFlags := flag.NewFlagSet("disable", flag.ExitOnError)
+		message := cmdFlags.String("message", "", "reason for disabling traffic (required)")
+		_ = cmdFlags.Parse(args)
+		err = setTraffic(ctx, c, false, *message, os.Stdout)
+	case "watch":
+		cmdFlags := flag.NewFlagSet("watch", flag.ExitOnError)
+		interval := cmdFlags.Duration("interval", 2*time.Second, "how often to poll the API")
+		_ = cmdFlags.Parse(args)
+		err = watch(ctx, c, *interval, os.Stdout)
+	case "capture":
+		err = captureCommand(ctx, c, args, flags.Usage, os.Stdout)
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fail(err)
+	}
+}
+
+func envOrDefault(key, defaultValue string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return defaultValue
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+	os.Exit(1)
+}
+
+func newHTTPClient(caCertPath string, skipTLSValidation bool, timeout time.Duration) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: skipTLSValidation,
+	}
+
+	if caCertPath != "" {
+		caPEM, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA certificate %s: %w", caCertPath, err)
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("failed to parse CA certificate %s", caCertPath)
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	// Cloning the default transport keeps its proxy from the environment,
+	// timeouts and connection pooling.
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}, nil
+}
+
+func status(ctx context.Context, c *client.Client, out io.Writer) error {
+	cluster, err := c.Cluster(ctx)
+	if err != nil {
+		return err
+	}
+
+	backends, err := c.Backends(ctx)
+	if err != nil {
+		return err
+	}
+
+	printCluster(out, cluster)
+	fmt.Fprintln(out)
+	printBackends(out, backends)
+	return nil
+}
+
+func setTraffic(ctx context.Context, c *client.Client, enabled bool, message string, out io.Writer) error {
+	var (
+		cluster api.ClusterJSON
+		err     error
+	)
+
+	if enabled {
+		cluster, err = c.EnableTraffic(ctx, message)
+	} else {
+		cluster, err = c.DisableTraffic(ctx, message)
+	}
+	if err != nil {
+		return err
+	}
+
+	printCluster(out, cluster)
+	return nil
+}
+
+func printCluster(out io.Writer, cluster api.ClusterJSON) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "traffic:\t%s\n", trafficState(cluster.TrafficEnabled))
+	fmt.Fprintf(w, "active backend:\t%s\n", activeBackendName(cluster.ActiveBackend))
+	if cluster.Message != "" {
+		fmt.Fprintf(w, "message:\t%s\n", cluster.Message)
+	}
+	if !cluster.LastUpdated.IsZero() {
+		fmt.Fprintf(w, "last updated:\t%s\n", cluster.LastUpdated.Format(time.RFC3339))
+	}
+	_ = w.Flush()
+}
+
+func printBackends(out io.Writer, backends []api.V0BackendResponse) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tADDRESS\tHEALTHY\tACTIVE\tSESSIONS")
+	for _, b := range backends {
+		address := net.JoinHostPort(b.Host, strconv.FormatUint(uint64(b.Port), 10))
+		fmt.Fprintf(w, "%s\t%s\t%t\t%t\t%d\n", b.Name, address, b.Healthy, b.Active, b.CurrentSessionCount)
+	}
+	_ = w.Flush()
+}
+
+func trafficState(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+func activeBackendName(b *api.BackendJSON) string {
+	if b == nil {
+		return "none"
+	}
+	return b.Name
+}
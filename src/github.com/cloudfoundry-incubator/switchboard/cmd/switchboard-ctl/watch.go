@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/cloudfoundry-incubator/switchboard/api"
+	"github.com/cloudfoundry-incubator/switchboard/client"
+)
+
+type snapshot struct {
+	cluster  api.ClusterJSON
+	backends map[string]api.V0BackendResponse
+}
+
+// watch polls the API and prints one line per observed change until ctx is done.
+func watch(ctx context.Context, c *client.Client, interval time.Duration, out io.Writer) error {
+	var (
+		previous    *snapshot
+		lastFailure string
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		current, err := takeSnapshot(ctx, c)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err.Error() != lastFailure {
+				logChange(out, "API unreachable: %s", err)
+				lastFailure = err.Error()
+			}
+		} else {
+			if lastFailure != "" {
+				logChange(out, "API reachable again")
+				lastFailure = ""
+			}
+			printChanges(out, previous, current)
+			previous = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func takeSnapshot(ctx context.Context, c *client.Client) (*snapshot, error) {
+	cluster, err := c.Cluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	backends, err := c.Backends(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &snapshot{
+		cluster:  cluster,
+		backends: map[string]api.V0BackendResponse{},
+	}
+	for _, b := range backends {
+		s.backends[b.Name] = b
+	}
+	return s, nil
+}
+
+func (s *snapshot) names() []string {
+	var names []string
+	for name := range s.backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func printChanges(out io.Writer, previous, current *snapshot) {
+	if previous == nil {
+		logChange(out, "traffic %s, active backend %s", trafficState(current.cluster.TrafficEnabled), activeBackendName(current.cluster.ActiveBackend))
+		for _, name := range current.names() {
+			b := current.backends[name]
+			logChange(out, "backend %s healthy=%t sessions=%d", name, b.Healthy, b.CurrentSessionCount)
+		}
+		return
+	}
+
+	if previous.cluster.TrafficEnabled != current.cluster.TrafficEnabled {
+		logChange(out, "traffic %s: %q", trafficState(current.cluster.TrafficEnabled), current.cluster.Message)
+	}
+
+	if activeBackendName(previous.cluster.ActiveBackend) != activeBackendName(current.cluster.ActiveBackend) {
+		logChange(out, "active backend changed from %s to %s", activeBackendName(previous.cluster.ActiveBackend), activeBackendName(current.cluster.ActiveBackend))
+	}
+
+	for _, name := range current.names() {
+		b := current.backends[name]
+		before, ok := previous.backends[name]
+		if !ok {
+			logChange(out, "backend %s added healthy=%t", name, b.Healthy)
+			continue
+		}
+		if before.Healthy != b.Healthy {
+			logChange(out, "backend %s became %s", name, healthState(b.Healthy))
+		}
+	}
+
+	for _, name := range previous.names() {
+		if _, ok := current.backends[name]; !ok {
+			logChange(out, "backend %s removed", name)
+		}
+	}
+}
+
+func healthState(healthy bool) string {
+	if healthy {
+		return "healthy"
+	}
+	return "unhealthy"
+}
+
+func logChange(out io.Writer, format string, args ...interface{}) {
+	fmt.Fprintf(out, "%s %s\n", time.Now().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
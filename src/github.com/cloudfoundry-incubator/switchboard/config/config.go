@@ -6,9 +6,13 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
 
 	"code.cloudfoundry.org/lager/v3"
@@ -16,18 +20,89 @@ import (
 	"code.cloudfoundry.org/tlsconfig"
 	"gopkg.in/validator.v2"
 	"gopkg.in/yaml.v3"
+
+	"github.com/cloudfoundry-incubator/switchboard/routing"
+	"github.com/cloudfoundry-incubator/switchboard/sourcefilter"
+	"github.com/cloudfoundry-incubator/switchboard/userquota"
 )
 
 type Config struct {
 	BindAddress    string         `yaml:"BindAddress"`
 	Proxy          Proxy          `yaml:"Proxy" validate:"nonzero"`
 	API            API            `yaml:"API" validate:"nonzero"`
-	StaticDir      string         `yaml:"StaticDir" validate:"nonzero"`
+	StaticDir      string         `yaml:"StaticDir"`
 	HealthPort     uint           `yaml:"HealthPort" validate:"nonzero"`
 	StatusLog      StatusLog      `yaml:"StatusLog"`
 	GaleraAgentTLS GaleraAgentTLS `yaml:"GaleraAgentTLS"`
 	Logger         lager.Logger   `yaml:"-"`
 	Metrics        Metrics        `yaml:"Metrics"`
+	TrafficState   TrafficState   `yaml:"TrafficState"`
+	AccessLog      AccessLog      `yaml:"AccessLog"`
+	Capture        Capture        `yaml:"Capture"`
+	Dashboard      Dashboard      `yaml:"Dashboard"`
+	Locality       Locality       `yaml:"Locality"`
+	WriterFencing  WriterFencing  `yaml:"WriterFencing"`
+	// ClusterName names the cluster described by Proxy, GaleraAgentTLS,
+	// TrafficState and WriterFencing. It defaults to DefaultClusterName.
+	ClusterName string `yaml:"ClusterName"`
+	// Clusters are further clusters served by the same process, sharing its
+	// API, metrics and access log.
+	Clusters []Cluster `yaml:"Clusters"`
+	// HealthBindAddresses are the hosts HealthPort binds to. Each listener
+	// without bind addresses of its own binds to BindAddress, where empty
+	// means every IPv4 and IPv6 address.
+	HealthBindAddresses []string    `yaml:"HealthBindAddresses"`
+	Diagnostics         Diagnostics `yaml:"Diagnostics"`
+	// LogSink changes the minimum level Logger logs at while the proxy runs.
+	LogSink *lager.ReconfigurableSink `yaml:"-"`
+	// Webhooks are notified of failovers and traffic changes.
+	Webhooks Webhooks `yaml:"Webhooks"`
+}
+
+// Cluster holds the settings that differ between the clusters one proxy
+// process routes to.
+type Cluster struct {
+	Name           string         `yaml:"Name" validate:"nonzero"`
+	Proxy          Proxy          `yaml:"Proxy" validate:"nonzero"`
+	GaleraAgentTLS GaleraAgentTLS `yaml:"GaleraAgentTLS"`
+	TrafficState   TrafficState   `yaml:"TrafficState"`
+	WriterFencing  WriterFencing  `yaml:"WriterFencing"`
+}
+
+// UnmarshalYAML starts each cluster from the same defaults as the top-level
+// settings.
+func (c *Cluster) UnmarshalYAML(value *yaml.Node) error {
+	type plain Cluster
+	*c = defaultCluster()
+	return value.Decode((*plain)(c))
+}
+
+func (c Cluster) RestoreTrafficState() bool {
+	return c.TrafficState.OnStartup != TrafficStateReset
+}
+
+// clusterNamePattern keeps cluster names usable in API paths and metric labels.
+var clusterNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// DefaultCluster returns the cluster described by the top-level settings.
+func (c Config) DefaultCluster() Cluster {
+	name := c.ClusterName
+	if name == "" {
+		name = DefaultClusterName
+	}
+
+	return Cluster{
+		Name:           name,
+		Proxy:          c.Proxy,
+		GaleraAgentTLS: c.GaleraAgentTLS,
+		TrafficState:   c.TrafficState,
+		WriterFencing:  c.WriterFencing,
+	}
+}
+
+// AllClusters returns the default cluster followed by Clusters.
+func (c Config) AllClusters() []Cluster {
+	return append([]Cluster{c.DefaultCluster()}, c.Clusters...)
 }
 
 type StatusLog struct {
@@ -39,24 +114,468 @@ type Metrics struct {
 	Port    uint `yaml:"Port" validate:"nonzero"`
 }
 
+// Diagnostics serves profiles, goroutine dumps and the proxy's internal
+// state, and changes its log level, on a listener of its own.
+type Diagnostics struct {
+	Enabled  bool   `yaml:"Enabled"`
+	Port     uint   `yaml:"Port"`
+	Username string `yaml:"Username"`
+	Password string `yaml:"Password"`
+	// BindAddresses default to 127.0.0.1 rather than BindAddress, so that
+	// diagnostics are only reachable from the proxy's host unless asked.
+	BindAddresses []string `yaml:"BindAddresses"`
+}
+
+func (d Diagnostics) Hosts() []string {
+	if len(d.BindAddresses) == 0 {
+		return []string{"127.0.0.1"}
+	}
+	return d.BindAddresses
+}
+
+// Webhooks POSTs a JSON event to each of URLs when the active backend of a
+// cluster changes, a backend or every backend becomes unhealthy, or traffic
+// is enabled or disabled. Requests are signed with Secret when it is set.
+// Each URL queues up to QueueSize events, and each event is tried up to
+// MaxAttempts times, RetryMillis apart at first and twice as long after
+// each failure. Zero values stand for the defaults.
+type Webhooks struct {
+	URLs          []string `yaml:"URLs"`
+	Secret        string   `yaml:"Secret"`
+	QueueSize     uint     `yaml:"QueueSize"`
+	MaxAttempts   uint     `yaml:"MaxAttempts"`
+	RetryMillis   uint     `yaml:"RetryMillis"`
+	TimeoutMillis uint     `yaml:"TimeoutMillis"`
+}
+
+func (w Webhooks) Enabled() bool {
+	return len(w.URLs) > 0
+}
+
+func (w Webhooks) Queue() int {
+	if w.QueueSize == 0 {
+		return 100
+	}
+	return int(w.QueueSize)
+}
+
+func (w Webhooks) Attempts() int {
+	if w.MaxAttempts == 0 {
+		return 5
+	}
+	return int(w.MaxAttempts)
+}
+
+func (w Webhooks) RetryAfter() time.Duration {
+	if w.RetryMillis == 0 {
+		return time.Second
+	}
+	return time.Duration(w.RetryMillis) * time.Millisecond
+}
+
+// HTTPClient posts the events. Receivers over HTTPS are verified against the
+// system's certificate authorities.
+func (w Webhooks) HTTPClient() *http.Client {
+	timeout := 5 * time.Second
+	if w.TimeoutMillis != 0 {
+		timeout = time.Duration(w.TimeoutMillis) * time.Millisecond
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+func (w Webhooks) validate() string {
+	var errString string
+	for i, u := range w.URLs {
+		parsed, err := url.Parse(u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errString += fmt.Sprintf("%s : %s\n", fmt.Sprintf("Webhooks.URLs[%d]", i), "must be an http or https URL")
+		}
+	}
+	return errString
+}
+
+const (
+	TrafficStateRestore = "restore"
+	TrafficStateReset   = "reset"
+)
+
+// TrafficState configures where the cluster traffic state is persisted and
+// whether a restarted proxy restores it or resets to traffic enabled.
+type TrafficState struct {
+	Path      string `yaml:"Path"`
+	OnStartup string `yaml:"OnStartup"`
+}
+
+// Locality describes where this proxy runs and how it prefers backends in
+// the same zone. Zones are read from the ZoneLabel of the proxy's and the
+// backends' Labels.
+type Locality struct {
+	Labels    map[string]string `yaml:"Labels"`
+	ZoneLabel string            `yaml:"ZoneLabel"`
+	// PreferLocalReaders routes the inactive port to a healthy backend in
+	// this proxy's zone when there is one.
+	PreferLocalReaders bool `yaml:"PreferLocalReaders"`
+	// PrimaryZone routes the active port to a healthy backend in that zone
+	// when there is one. It must be the same for every proxy.
+	PrimaryZone string `yaml:"PrimaryZone"`
+}
+
+func (l Locality) Zone() string {
+	return l.Labels[l.ZoneLabel]
+}
+
+// AccessLog configures the per-session log of proxied MySQL connections.
+type AccessLog struct {
+	Enabled    bool   `yaml:"Enabled"`
+	Path       string `yaml:"Path"`
+	MaxSizeMB  uint   `yaml:"MaxSizeMB"`
+	MaxBackups uint   `yaml:"MaxBackups"`
+}
+
+func (a AccessLog) MaxSize() int64 {
+	return int64(a.MaxSizeMB) * 1024 * 1024
+}
+
+// Capture configures the traffic captures operators start through the API.
+// Directory keeps the last MaxFiles captures.
+type Capture struct {
+	Enabled            bool   `yaml:"Enabled"`
+	Directory          string `yaml:"Directory"`
+	MaxBytes           uint   `yaml:"MaxBytes"`
+	MaxDurationSeconds uint   `yaml:"MaxDurationSeconds"`
+	MaxFiles           uint   `yaml:"MaxFiles"`
+}
+
+func (c Capture) MaxDuration() time.Duration {
+	return time.Duration(c.MaxDurationSeconds) * time.Second
+}
+
+// Dashboard configures the history of each cluster the dashboard shows: the
+// session counts sampled every SampleIntervalMillis, and the last Samples
+// samples, failovers and healthchecks of each backend. Zero values stand for
+// the defaults.
+type Dashboard struct {
+	SampleIntervalMillis uint `yaml:"SampleIntervalMillis"`
+	Samples              uint `yaml:"Samples"`
+}
+
+func (d Dashboard) SampleInterval() time.Duration {
+	if d.SampleIntervalMillis == 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(d.SampleIntervalMillis) * time.Millisecond
+}
+
+func (d Dashboard) HistorySize() int {
+	if d.Samples == 0 {
+		return 360
+	}
+	return int(d.Samples)
+}
+
 type GaleraAgentTLS struct {
 	Enabled    bool   `yaml:"Enabled"`
 	ServerName string `yaml:"ServerName"`
 	CA         string `yaml:"CA"`
 }
 
+// WriterFencing makes the proxy ask a backend's galera-agent to make the node
+// read-only when the proxy stops routing writes to it, using the agent's
+// endpoint credentials.
+type WriterFencing struct {
+	Enabled  bool   `yaml:"Enabled"`
+	Username string `yaml:"Username"`
+	Password string `yaml:"Password"`
+	// TimeoutMillis bounds how long new clients are held after a failover
+	// while the galera-agents fence the previous backend and unfence the
+	// new one. 0 holds them until the agents answer or their client times
+	// out.
+	TimeoutMillis uint `yaml:"TimeoutMillis"`
+}
+
+func (w WriterFencing) Timeout() time.Duration {
+	return time.Duration(w.TimeoutMillis) * time.Millisecond
+}
+
 type SwitchboardApiTLS struct {
 	Enabled     bool   `yaml:"Enabled"`
 	Certificate string `yaml:"Certificate"`
 	PrivateKey  string `yaml:"PrivateKey"`
+	// CA verifies the certificates of the proxy APIs the api-aggregator
+	// queries. The system roots are used when it is empty.
+	CA string `yaml:"CA"`
 }
 
 type Proxy struct {
-	Port                     uint      `yaml:"Port" validate:"nonzero"`
-	InactiveMysqlPort        uint      `yaml:"InactiveMysqlPort"`
-	Backends                 []Backend `yaml:"Backends" validate:"min=1"`
-	HealthcheckTimeoutMillis uint      `yaml:"HealthcheckTimeoutMillis" validate:"nonzero"`
-	ShutdownDelaySeconds     uint      `yaml:"ShutdownDelaySeconds"`
+	Port                     uint           `yaml:"Port" validate:"nonzero"`
+	InactiveMysqlPort        uint           `yaml:"InactiveMysqlPort"`
+	Backends                 []Backend      `yaml:"Backends"`
+	HealthcheckTimeoutMillis uint           `yaml:"HealthcheckTimeoutMillis" validate:"nonzero"`
+	ShutdownDelaySeconds     uint           `yaml:"ShutdownDelaySeconds"`
+	Admission                Admission      `yaml:"Admission"`
+	InactiveAdmission        Admission      `yaml:"InactiveAdmission"`
+	SourceFilter             SourceFilter   `yaml:"SourceFilter"`
+	InactiveSourceFilter     SourceFilter   `yaml:"InactiveSourceFilter"`
+	CircuitBreaker           CircuitBreaker `yaml:"CircuitBreaker"`
+	FlapDamping              FlapDamping    `yaml:"FlapDamping"`
+	Sockets                  Sockets        `yaml:"Sockets"`
+	Routing                  Routing        `yaml:"Routing"`
+	UserQuotas               UserQuotas     `yaml:"UserQuotas"`
+	// InactiveWsrepSyncWait, when not zero, is the wsrep_sync_wait the proxy
+	// sets on every session of the inactive port before relaying the client's
+	// statements, so that reads from the reader see earlier writes.
+	InactiveWsrepSyncWait uint `yaml:"InactiveWsrepSyncWait"`
+	// Reconnect moves idle sessions to the new active backend on failover
+	// instead of closing them.
+	Reconnect Reconnect `yaml:"Reconnect"`
+	// HandshakeTimeoutMillis bounds how long a client may take to answer the
+	// server greeting when Routing, UserQuotas, InactiveWsrepSyncWait or
+	// Reconnect need its handshake.
+	HandshakeTimeoutMillis uint `yaml:"HandshakeTimeoutMillis"`
+	// Discovery finds the backends in DNS instead of Backends.
+	Discovery Discovery `yaml:"Discovery"`
+	// BindAddresses are the hosts Port and InactiveMysqlPort bind to.
+	BindAddresses []string `yaml:"BindAddresses"`
+	// UnixSocket and InactiveUnixSocket, when set, are the paths of Unix
+	// domain sockets that accept clients on the same host like Port and
+	// InactiveMysqlPort do.
+	UnixSocket         string `yaml:"UnixSocket"`
+	InactiveUnixSocket string `yaml:"InactiveUnixSocket"`
+}
+
+// InspectsHandshakes reports whether the proxy reads each client's MySQL
+// handshake on either port before choosing its backend.
+func (p Proxy) InspectsHandshakes() bool {
+	return p.Routing.Enabled() || p.UserQuotas.Enabled() || p.InactiveWsrepSyncWait > 0 || p.Reconnect.Enabled()
+}
+
+// maxWsrepSyncWait sets every check type Galera knows of: reads, updates and
+// deletes, inserts and replaces, and SHOW statements.
+const maxWsrepSyncWait = 15
+
+func (p Proxy) HandshakeTimeout() time.Duration {
+	return time.Duration(p.HandshakeTimeoutMillis) * time.Millisecond
+}
+
+// UserQuotas limits the concurrent sessions of each MySQL user across both
+// listeners of a cluster. Users sets the limit of single users, overriding
+// DefaultMaxSessions; zero leaves a user unlimited. Sessions that request
+// TLS hide their user and are not limited, unless RefuseTLS stops the
+// proxy from accepting them.
+type UserQuotas struct {
+	DefaultMaxSessions uint            `yaml:"DefaultMaxSessions"`
+	Users              map[string]uint `yaml:"Users"`
+	RefuseTLS          bool            `yaml:"RefuseTLS"`
+}
+
+func (q UserQuotas) Enabled() bool {
+	if q.DefaultMaxSessions > 0 {
+		return true
+	}
+	for _, limit := range q.Users {
+		if limit > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (q UserQuotas) Limits() userquota.Limits {
+	users := make(map[string]int, len(q.Users))
+	for user, limit := range q.Users {
+		users[user] = int(limit)
+	}
+	return userquota.Limits{
+		DefaultMaxSessions: int(q.DefaultMaxSessions),
+		Users:              users,
+	}
+}
+
+// The record types Discovery looks up.
+const (
+	DiscoveryTypeA    = "A"
+	DiscoveryTypeAAAA = "AAAA"
+	DiscoveryTypeSRV  = "SRV"
+)
+
+// Discovery resolves Name every IntervalMillis and makes each record a
+// backend. A and AAAA records are named after their address and listen on
+// Port; SRV records are named after their target and listen on the record's
+// port. Type defaults to A. It is disabled when Name is empty.
+type Discovery struct {
+	Name           string `yaml:"Name"`
+	Type           string `yaml:"Type"`
+	Port           uint   `yaml:"Port"`
+	StatusPort     uint   `yaml:"StatusPort"`
+	StatusEndpoint string `yaml:"StatusEndpoint"`
+	IntervalMillis uint   `yaml:"IntervalMillis"`
+	// Server, when set, is the host:port of the DNS server to query instead
+	// of the system's resolvers.
+	Server string `yaml:"Server"`
+}
+
+func (d Discovery) Enabled() bool {
+	return d.Name != ""
+}
+
+func (d Discovery) RecordType() string {
+	if d.Type == "" {
+		return DiscoveryTypeA
+	}
+	return d.Type
+}
+
+func (d Discovery) Interval() time.Duration {
+	return time.Duration(d.IntervalMillis) * time.Millisecond
+}
+
+// Reconnect moves the sessions of Users that are idle, outside a transaction
+// and without session state to the new active backend on failover. The proxy
+// authenticates them with the new backend using the passwords in Users. It is
+// disabled when Users is empty.
+type Reconnect struct {
+	Users map[string]string `yaml:"Users"`
+	// TimeoutMillis bounds how long a session waits for a new active
+	// backend before it is closed.
+	TimeoutMillis uint `yaml:"TimeoutMillis"`
+}
+
+func (r Reconnect) Enabled() bool {
+	return len(r.Users) > 0
+}
+
+func (r Reconnect) Timeout() time.Duration {
+	return time.Duration(r.TimeoutMillis) * time.Millisecond
+}
+
+// Routing makes the active port route each session by the user, schema or
+// connection attributes in its MySQL handshake. It is disabled when there
+// are no Rules.
+type Routing struct {
+	Rules []RoutingRule `yaml:"Rules"`
+}
+
+// RoutingRule sends the sessions matching all of its conditions to Pool,
+// "writer" or "readers", or to the backend named Backend.
+type RoutingRule struct {
+	User       string            `yaml:"User"`
+	Schema     string            `yaml:"Schema"`
+	Attributes map[string]string `yaml:"Attributes"`
+	Pool       string            `yaml:"Pool"`
+	Backend    string            `yaml:"Backend"`
+}
+
+func (r Routing) Enabled() bool {
+	return len(r.Rules) > 0
+}
+
+func (r Routing) RouterRules() []routing.Rule {
+	rules := make([]routing.Rule, 0, len(r.Rules))
+	for _, rule := range r.Rules {
+		rules = append(rules, routing.Rule{
+			User:       rule.User,
+			Schema:     rule.Schema,
+			Attributes: rule.Attributes,
+			Pool:       rule.Pool,
+			Backend:    rule.Backend,
+		})
+	}
+	return rules
+}
+
+// Sockets tunes the TCP connections to clients and backends.
+type Sockets struct {
+	DialTimeoutMillis  uint `yaml:"DialTimeoutMillis"`
+	KeepAliveSeconds   int  `yaml:"KeepAliveSeconds"`
+	NoDelay            bool `yaml:"NoDelay"`
+	SendBufferBytes    uint `yaml:"SendBufferBytes"`
+	ReceiveBufferBytes uint `yaml:"ReceiveBufferBytes"`
+	// IdleTimeoutSeconds closes sessions that send nothing in either
+	// direction for that long. 0 never closes them.
+	IdleTimeoutSeconds uint `yaml:"IdleTimeoutSeconds"`
+}
+
+func (s Sockets) DialTimeout() time.Duration {
+	return time.Duration(s.DialTimeoutMillis) * time.Millisecond
+}
+
+func (s Sockets) KeepAlive() time.Duration {
+	return time.Duration(s.KeepAliveSeconds) * time.Second
+}
+
+func (s Sockets) IdleTimeout() time.Duration {
+	return time.Duration(s.IdleTimeoutSeconds) * time.Second
+}
+
+// CircuitBreaker marks a backend unhealthy once DialFailures connections to
+// it fail within WindowMillis, instead of waiting for the next healthcheck.
+// It is disabled when DialFailures is zero.
+type CircuitBreaker struct {
+	DialFailures uint `yaml:"DialFailures"`
+	WindowMillis uint `yaml:"WindowMillis"`
+}
+
+func (b CircuitBreaker) Enabled() bool {
+	return b.DialFailures > 0
+}
+
+func (b CircuitBreaker) Window() time.Duration {
+	return time.Duration(b.WindowMillis) * time.Millisecond
+}
+
+// FlapDamping quarantines a backend from writer selection once it becomes
+// unhealthy Flaps times within WindowMillis. Each quarantine lasts twice as
+// long as the previous one, starting at QuarantineMillis and capped at
+// MaxQuarantineMillis. It is disabled when Flaps is zero.
+type FlapDamping struct {
+	Flaps               uint `yaml:"Flaps"`
+	WindowMillis        uint `yaml:"WindowMillis"`
+	QuarantineMillis    uint `yaml:"QuarantineMillis"`
+	MaxQuarantineMillis uint `yaml:"MaxQuarantineMillis"`
+}
+
+func (d FlapDamping) Enabled() bool {
+	return d.Flaps > 0
+}
+
+func (d FlapDamping) Window() time.Duration {
+	return time.Duration(d.WindowMillis) * time.Millisecond
+}
+
+func (d FlapDamping) Quarantine() time.Duration {
+	return time.Duration(d.QuarantineMillis) * time.Millisecond
+}
+
+func (d FlapDamping) MaxQuarantine() time.Duration {
+	return time.Duration(d.MaxQuarantineMillis) * time.Millisecond
+}
+
+// SourceFilter lists the client CIDRs one proxy listener accepts or rejects.
+type SourceFilter struct {
+	Allow []string `yaml:"Allow"`
+	Deny  []string `yaml:"Deny"`
+}
+
+func (f SourceFilter) Rules() sourcefilter.Rules {
+	return sourcefilter.Rules{Allow: f.Allow, Deny: f.Deny}
+}
+
+// Admission limits the client connections accepted by one proxy listener.
+// Zero values leave the corresponding limit disabled.
+type Admission struct {
+	MaxSessions          uint `yaml:"MaxSessions"`
+	MaxSessionsPerClient uint `yaml:"MaxSessionsPerClient"`
+	ConnectionsPerSecond uint `yaml:"ConnectionsPerSecond"`
+	Burst                uint `yaml:"Burst"`
+	QueueTimeoutMillis   uint `yaml:"QueueTimeoutMillis"`
+}
+
+func (a Admission) Enabled() bool {
+	return a.MaxSessions > 0 || a.MaxSessionsPerClient > 0 || a.ConnectionsPerSecond > 0
+}
+
+func (a Admission) QueueTimeout() time.Duration {
+	return time.Duration(a.QueueTimeoutMillis) * time.Millisecond
 }
 
 type API struct {
@@ -67,6 +586,8 @@ type API struct {
 	ForceHttps     bool              `yaml:"ForceHttps"`
 	ProxyURIs      []string          `yaml:"ProxyURIs"`
 	TLS            SwitchboardApiTLS `yaml:"TLS"`
+	// BindAddresses are the hosts Port and AggregatorPort bind to.
+	BindAddresses []string `yaml:"BindAddresses"`
 }
 
 type Backend struct {
@@ -75,6 +596,8 @@ type Backend struct {
 	StatusPort     uint   `yaml:"StatusPort" validate:"nonzero"`
 	StatusEndpoint string `yaml:"StatusEndpoint" validate:"nonzero"`
 	Name           string `yaml:"Name" validate:"nonzero"`
+
+	Labels map[string]string `yaml:"Labels,omitempty"`
 }
 
 func (p Proxy) HealthcheckTimeout() time.Duration {
@@ -97,10 +620,70 @@ func (c Config) StatusLogInterval() time.Duration {
 	return c.StatusLog.Interval
 }
 
+// ListenHosts returns the hosts a listener binds to: hosts, or BindAddress
+// when hosts is empty.
+func (c Config) ListenHosts(hosts []string) []string {
+	if len(hosts) == 0 {
+		return []string{c.BindAddress}
+	}
+	return hosts
+}
+
+func (c Config) RestoreTrafficState() bool {
+	return c.DefaultCluster().RestoreTrafficState()
+}
+
+const DefaultClusterName = "default"
+
+func defaultCluster() Cluster {
+	return Cluster{
+		Proxy: Proxy{
+			Sockets: Sockets{
+				DialTimeoutMillis: 5000,
+				NoDelay:           true,
+			},
+			HandshakeTimeoutMillis: 10000,
+			Reconnect: Reconnect{
+				TimeoutMillis: 5000,
+			},
+			Discovery: Discovery{
+				StatusEndpoint: "api/v1/status",
+				IntervalMillis: 10000,
+			},
+		},
+		TrafficState: TrafficState{
+			OnStartup: TrafficStateRestore,
+		},
+		WriterFencing: WriterFencing{
+			TimeoutMillis: 5000,
+		},
+	}
+}
+
 func defaultConfig() Config {
+	cluster := defaultCluster()
 	return Config{
-		Metrics:   Metrics{Port: 9999},
-		StatusLog: StatusLog{Interval: time.Minute},
+		Metrics:       Metrics{Port: 9999},
+		Proxy:         cluster.Proxy,
+		StatusLog:     StatusLog{Interval: time.Minute},
+		TrafficState:  cluster.TrafficState,
+		WriterFencing: cluster.WriterFencing,
+		Locality: Locality{
+			ZoneLabel: "az",
+		},
+		AccessLog: AccessLog{
+			MaxSizeMB:  100,
+			MaxBackups: 5,
+		},
+		Capture: Capture{
+			MaxBytes:           100 * 1024 * 1024,
+			MaxDurationSeconds: 600,
+			MaxFiles:           10,
+		},
+		Dashboard: Dashboard{
+			SampleIntervalMillis: 10000,
+			Samples:              360,
+		},
 	}
 }
 
@@ -127,7 +710,7 @@ func NewConfig(osArgs []string) (*Config, error) {
 		return nil, err
 	}
 
-	rootConfig.Logger, _ = lagerflags.NewFromConfig(binaryName, lagerflags.ConfigFromFlags())
+	rootConfig.Logger, rootConfig.LogSink = lagerflags.NewFromConfig(binaryName, lagerflags.ConfigFromFlags())
 
 	return &rootConfig, nil
 }
@@ -139,39 +722,325 @@ func (c Config) Validate() error {
 		errString = formatErrorString(rootConfigErr, "")
 	}
 
+	errString += c.DefaultCluster().validate("")
+	errString += c.validateClusters()
+
+	if !isHost(c.BindAddress) {
+		errString += fmt.Sprintf("%s : %s\n", "BindAddress", "must be a host name or IP address, without a port")
+	}
+	errString += validateHosts("", "HealthBindAddresses", c.HealthBindAddresses)
+	errString += validateHosts("", "API.BindAddresses", c.API.BindAddresses)
+
+	if c.Diagnostics.Enabled {
+		if c.Diagnostics.Port == 0 {
+			errString += fmt.Sprintf("%s : %s\n", "Diagnostics.Port", "zero value")
+		}
+		if c.Diagnostics.Username == "" {
+			errString += fmt.Sprintf("%s : %s\n", "Diagnostics.Username", "zero value")
+		}
+		if c.Diagnostics.Password == "" {
+			errString += fmt.Sprintf("%s : %s\n", "Diagnostics.Password", "zero value")
+		}
+		errString += validateHosts("", "Diagnostics.BindAddresses", c.Diagnostics.BindAddresses)
+	}
+
+	errString += c.Webhooks.validate()
+
+	if c.Locality.PreferLocalReaders && c.Locality.Zone() == "" {
+		errString += fmt.Sprintf("%s : %s\n", "Locality.Labels", fmt.Sprintf("must set %q to prefer local readers", c.Locality.ZoneLabel))
+	}
+
+	if c.AccessLog.Enabled {
+		if c.AccessLog.Path == "" {
+			errString += fmt.Sprintf("%s : %s\n", "AccessLog.Path", "zero value")
+		}
+		if c.AccessLog.MaxSizeMB == 0 {
+			errString += fmt.Sprintf("%s : %s\n", "AccessLog.MaxSizeMB", "zero value")
+		}
+	}
+
+	if c.Capture.Enabled {
+		if c.Capture.Directory == "" {
+			errString += fmt.Sprintf("%s : %s\n", "Capture.Directory", "zero value")
+		}
+		if c.Capture.MaxBytes == 0 {
+			errString += fmt.Sprintf("%s : %s\n", "Capture.MaxBytes", "zero value")
+		}
+		if c.Capture.MaxDurationSeconds == 0 {
+			errString += fmt.Sprintf("%s : %s\n", "Capture.MaxDurationSeconds", "zero value")
+		}
+		if c.Capture.MaxFiles == 0 {
+			errString += fmt.Sprintf("%s : %s\n", "Capture.MaxFiles", "zero value")
+		}
+	}
+
+	if c.API.TLS.Enabled {
+		_, err := tls.X509KeyPair([]byte(c.API.TLS.Certificate), []byte(c.API.TLS.PrivateKey))
+		if err != nil {
+			errString += fmt.Sprintf("%s%s : %s\n", "SwitchboardApi", ".Certificate/PrivateKey", "Failed to Parse Certificate or PrivateKey.")
+		}
+
+	}
+
+	if c.API.TLS.CA != "" {
+		certPool := x509.NewCertPool()
+		if ok := certPool.AppendCertsFromPEM([]byte(c.API.TLS.CA)); !ok {
+			errString += fmt.Sprintf("%s%s : %s\n", "SwitchboardApi", ".CA", "Failed to Parse CA.")
+		}
+	}
+
+	if len(errString) > 0 {
+		return errors.New(fmt.Sprintf("Validation errors: %s\n", errString))
+	}
+	return nil
+}
+
+func (c Cluster) validate(prefix string) string {
+	var errString string
+
 	// validator.Validate does not work on nested arrays
 	for i, backend := range c.Proxy.Backends {
 		backendsErr := validator.Validate(backend)
 		if backendsErr != nil {
 			errString += formatErrorString(
 				backendsErr,
-				fmt.Sprintf("Proxy.Backends[%d].", i),
+				fmt.Sprintf("%sProxy.Backends[%d].", prefix, i),
 			)
 		}
 	}
 
+	if c.Proxy.Discovery.Enabled() {
+		errString += c.Proxy.Discovery.validate(prefix)
+		if len(c.Proxy.Backends) > 0 {
+			errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.Backends", "cannot be set with Discovery")
+		}
+	} else if len(c.Proxy.Backends) == 0 {
+		errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.Backends", "less than min")
+	}
+
 	if c.GaleraAgentTLS.Enabled {
 		certPool := x509.NewCertPool()
 		if ok := certPool.AppendCertsFromPEM([]byte(c.GaleraAgentTLS.CA)); !ok {
-			errString += fmt.Sprintf("%s%s : %s\n", "", "GaleraAgentTLS.CA", "Failed to Parse CA.")
+			errString += fmt.Sprintf("%s%s : %s\n", prefix, "GaleraAgentTLS.CA", "Failed to Parse CA.")
 		}
 	}
 
-	if c.API.TLS.Enabled {
-		_, err := tls.X509KeyPair([]byte(c.API.TLS.Certificate), []byte(c.API.TLS.PrivateKey))
-		if err != nil {
-			errString += fmt.Sprintf("%s%s : %s\n", "SwitchboardApi", ".Certificate/PrivateKey", "Failed to Parse Certificate or PrivateKey.")
+	if c.TrafficState.Path != "" &&
+		c.TrafficState.OnStartup != TrafficStateRestore &&
+		c.TrafficState.OnStartup != TrafficStateReset {
+		errString += fmt.Sprintf("%s%s : must be one of %q or %q\n", prefix, "TrafficState.OnStartup", TrafficStateRestore, TrafficStateReset)
+	}
+
+	if c.Proxy.Admission.Burst > 0 && c.Proxy.Admission.ConnectionsPerSecond == 0 {
+		errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.Admission.Burst", "requires ConnectionsPerSecond")
+	}
+	if c.Proxy.InactiveAdmission.Burst > 0 && c.Proxy.InactiveAdmission.ConnectionsPerSecond == 0 {
+		errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.InactiveAdmission.Burst", "requires ConnectionsPerSecond")
+	}
+
+	if err := sourcefilter.Validate(c.Proxy.SourceFilter.Rules()); err != nil {
+		errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.SourceFilter", err)
+	}
+	if err := sourcefilter.Validate(c.Proxy.InactiveSourceFilter.Rules()); err != nil {
+		errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.InactiveSourceFilter", err)
+	}
+
+	if c.Proxy.CircuitBreaker.Enabled() && c.Proxy.CircuitBreaker.WindowMillis == 0 {
+		errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.CircuitBreaker.WindowMillis", "zero value")
+	}
+
+	if c.Proxy.FlapDamping.Enabled() {
+		if c.Proxy.FlapDamping.WindowMillis == 0 {
+			errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.FlapDamping.WindowMillis", "zero value")
 		}
+		if c.Proxy.FlapDamping.QuarantineMillis == 0 {
+			errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.FlapDamping.QuarantineMillis", "zero value")
+		}
+	}
 
+	if c.Proxy.Routing.Enabled() {
+		backendNames := make([]string, 0, len(c.Proxy.Backends))
+		for _, backend := range c.Proxy.Backends {
+			backendNames = append(backendNames, backend.Name)
+		}
+		if err := routing.Validate(c.Proxy.Routing.RouterRules(), backendNames); err != nil {
+			errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.Routing.Rules", err)
+		}
 	}
 
-	if len(errString) > 0 {
-		return errors.New(fmt.Sprintf("Validation errors: %s\n", errString))
+	if c.Proxy.InactiveWsrepSyncWait > 0 && c.Proxy.InactiveMysqlPort == 0 {
+		errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.InactiveWsrepSyncWait", "requires InactiveMysqlPort")
 	}
-	return nil
+	if c.Proxy.InactiveWsrepSyncWait > maxWsrepSyncWait {
+		errString += fmt.Sprintf("%s%s : must be at most %d\n", prefix, "Proxy.InactiveWsrepSyncWait", maxWsrepSyncWait)
+	}
+
+	if c.Proxy.Reconnect.Enabled() && c.Proxy.Reconnect.TimeoutMillis == 0 {
+		errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.Reconnect.TimeoutMillis", "zero value")
+	}
+
+	if c.Proxy.InspectsHandshakes() && c.Proxy.HandshakeTimeoutMillis == 0 {
+		errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.HandshakeTimeoutMillis", "zero value")
+	}
+
+	errString += validateHosts(prefix, "Proxy.BindAddresses", c.Proxy.BindAddresses)
+	if c.Proxy.UnixSocket != "" && !filepath.IsAbs(c.Proxy.UnixSocket) {
+		errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.UnixSocket", "must be an absolute path")
+	}
+	if c.Proxy.InactiveUnixSocket != "" {
+		if c.Proxy.InactiveMysqlPort == 0 {
+			errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.InactiveUnixSocket", "requires InactiveMysqlPort")
+		}
+		if !filepath.IsAbs(c.Proxy.InactiveUnixSocket) {
+			errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.InactiveUnixSocket", "must be an absolute path")
+		}
+	}
+
+	if c.WriterFencing.Enabled {
+		if c.WriterFencing.Username == "" {
+			errString += fmt.Sprintf("%s%s : %s\n", prefix, "WriterFencing.Username", "zero value")
+		}
+		if c.WriterFencing.Password == "" {
+			errString += fmt.Sprintf("%s%s : %s\n", prefix, "WriterFencing.Password", "zero value")
+		}
+	}
+
+	return errString
+}
+
+func (d Discovery) validate(prefix string) string {
+	var errString string
+
+	switch d.RecordType() {
+	case DiscoveryTypeA, DiscoveryTypeAAAA:
+		if d.Port == 0 {
+			errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.Discovery.Port", "zero value")
+		}
+	case DiscoveryTypeSRV:
+	default:
+		errString += fmt.Sprintf("%s%s : must be one of %q, %q or %q\n", prefix, "Proxy.Discovery.Type", DiscoveryTypeA, DiscoveryTypeAAAA, DiscoveryTypeSRV)
+	}
+
+	if d.StatusPort == 0 {
+		errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.Discovery.StatusPort", "zero value")
+	}
+	if d.StatusEndpoint == "" {
+		errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.Discovery.StatusEndpoint", "zero value")
+	}
+	if d.IntervalMillis == 0 {
+		errString += fmt.Sprintf("%s%s : %s\n", prefix, "Proxy.Discovery.IntervalMillis", "zero value")
+	}
+
+	return errString
+}
+
+// validateHosts checks that each of hosts can be bound to.
+func validateHosts(prefix, field string, hosts []string) string {
+	var errString string
+	for i, host := range hosts {
+		if !isHost(host) {
+			errString += fmt.Sprintf("%s%s[%d] : %s\n", prefix, field, i, "must be a host name or IP address, without a port")
+		}
+	}
+	return errString
 }
 
+// isHost reports whether host is empty, a host name or an IP address, so
+// that joining it with a port makes an address to listen on. IPv6 addresses
+// are written without brackets.
+func isHost(host string) bool {
+	if strings.ContainsAny(host, "[]/") {
+		return false
+	}
+	return !strings.Contains(host, ":") || net.ParseIP(host) != nil
+}
+
+// validateClusters checks the further clusters, and that no two clusters
+// share a name, a listener or a traffic state file.
+func (c Config) validateClusters() string {
+	var errString string
+
+	if c.ClusterName != "" && !clusterNamePattern.MatchString(c.ClusterName) {
+		errString += fmt.Sprintf("%s : %s\n", "ClusterName", "must contain only letters, digits, '.', '_' or '-'")
+	}
+
+	names := map[string]bool{}
+	ports := map[uint]bool{}
+	sockets := map[string]bool{}
+	statePaths := map[string]bool{}
+	for i, cluster := range c.AllClusters() {
+		var prefix string
+		if i > 0 {
+			prefix = fmt.Sprintf("Clusters[%d].", i-1)
+
+			// validator.Validate does not work on nested arrays
+			if err := validator.Validate(cluster); err != nil {
+				errString += formatErrorString(err, prefix)
+			}
+			errString += cluster.validate(prefix)
+
+			if cluster.Name != "" && !clusterNamePattern.MatchString(cluster.Name) {
+				errString += fmt.Sprintf("%s%s : %s\n", prefix, "Name", "must contain only letters, digits, '.', '_' or '-'")
+			}
+		}
+
+		if names[cluster.Name] {
+			errString += fmt.Sprintf("%s%s : %s\n", prefix, "Name", fmt.Sprintf("%q is already used by another cluster", cluster.Name))
+		}
+		names[cluster.Name] = true
+
+		listeners := []struct {
+			field string
+			port  uint
+		}{
+			{"Proxy.Port", cluster.Proxy.Port},
+			{"Proxy.InactiveMysqlPort", cluster.Proxy.InactiveMysqlPort},
+		}
+		for _, l := range listeners {
+			if l.port == 0 {
+				continue
+			}
+			if ports[l.port] {
+				errString += fmt.Sprintf("%s%s : %s\n", prefix, l.field, fmt.Sprintf("%d is already used by another listener", l.port))
+			}
+			ports[l.port] = true
+		}
+
+		unixSockets := []struct {
+			field string
+			path  string
+		}{
+			{"Proxy.UnixSocket", cluster.Proxy.UnixSocket},
+			{"Proxy.InactiveUnixSocket", cluster.Proxy.InactiveUnixSocket},
+		}
+		for _, l := range unixSockets {
+			if l.path == "" {
+				continue
+			}
+			if sockets[l.path] {
+				errString += fmt.Sprintf("%s%s : %s\n", prefix, l.field, fmt.Sprintf("%s is already used by another listener", l.path))
+			}
+			sockets[l.path] = true
+		}
+
+		if path := cluster.TrafficState.Path; path != "" {
+			if statePaths[path] {
+				errString += fmt.Sprintf("%s%s : %s\n", prefix, "TrafficState.Path", "is already used by another cluster")
+			}
+			statePaths[path] = true
+		}
+	}
+
+	return errString
+}
+
+// HTTPClient queries the galera-agents of the default cluster.
 func (c *Config) HTTPClient() *http.Client {
+	return c.DefaultCluster().HTTPClient()
+}
+
+// HTTPClient queries the cluster's galera-agents.
+func (c Cluster) HTTPClient() *http.Client {
 	httpClient := &http.Client{
 		Timeout: c.Proxy.HealthcheckTimeout(),
 	}
@@ -197,6 +1066,30 @@ func (c *Config) HTTPClient() *http.Client {
 	return httpClient
 }
 
+// AggregatorHTTPClient is used by the api-aggregator to query each proxy's API.
+func (c Config) AggregatorHTTPClient() *http.Client {
+	httpClient := &http.Client{
+		Timeout: c.Proxy.HealthcheckTimeout(),
+	}
+
+	if c.API.TLS.CA != "" {
+		certPool := x509.NewCertPool()
+		certPool.AppendCertsFromPEM([]byte(c.API.TLS.CA))
+
+		tlsClientCfg, _ := tlsconfig.Build(
+			tlsconfig.WithInternalServiceDefaults(),
+		).Client(
+			tlsconfig.WithAuthority(certPool),
+		)
+
+		httpClient.Transport = &http.Transport{
+			TLSClientConfig: tlsClientCfg,
+		}
+	}
+
+	return httpClient
+}
+
 func (c Config) ServerTLSConfig() (*tls.Config, error) {
 	if c.API.TLS.Enabled {
 		serverCert, _ := tls.X509KeyPair([]byte(c.API.TLS.Certificate), []byte(c.API.TLS.PrivateKey))
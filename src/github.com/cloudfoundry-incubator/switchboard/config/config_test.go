@@ -6,11 +6,13 @@ import (
 	"net/http"
 	"time"
 
+	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/tlsconfig/certtest"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	. "github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/userquota"
 )
 
 var _ = Describe("Config", func() {
@@ -28,6 +30,17 @@ var _ = Describe("Config", func() {
 		})
 	})
 
+	Describe("ListenHosts", func() {
+		It("returns the listener's own hosts", func() {
+			Expect(Config{BindAddress: "10.0.0.1"}.ListenHosts([]string{"0.0.0.0", "::"})).To(Equal([]string{"0.0.0.0", "::"}))
+		})
+
+		It("defaults to BindAddress", func() {
+			Expect(Config{BindAddress: "10.0.0.1"}.ListenHosts(nil)).To(Equal([]string{"10.0.0.1"}))
+			Expect(Config{}.ListenHosts(nil)).To(Equal([]string{""}))
+		})
+	})
+
 	Describe("Validate", func() {
 		var (
 			rootConfig    *Config
@@ -130,6 +143,14 @@ var _ = Describe("Config", func() {
 			})
 		})
 
+		When("SwitchboardApi CA is invalid", func() {
+			It("returns an error", func() {
+				rootConfig.API.TLS.CA = "not-a-PEM-encoded-CA"
+				err := rootConfig.Validate()
+				Expect(err).To(MatchError(errors.New(fmt.Sprintf("Validation errors: %s\n", fmt.Sprintf("%s%s : %s\n", "", "SwitchboardApi.CA", "Failed to Parse CA.")))))
+			})
+		})
+
 		It("configures GaleraAgentTLS properties", func() {
 			Expect(rootConfig.GaleraAgentTLS.Enabled).To(BeFalse(),
 				`Expected fixtures/validConfig.yml to unmarshal a GaleraAgentTLS.Enabled = true property, but it did not.  Are the struct tags correct?`)
@@ -245,11 +266,541 @@ var _ = Describe("Config", func() {
 			Expect(err.Error()).To(ContainSubstring("HealthPort"))
 		})
 
-		It("returns an error if StaticDir is blank", func() {
+		It("does not require StaticDir", func() {
 			rootConfig.StaticDir = ""
-			err := rootConfig.Validate()
-			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring("StaticDir"))
+			Expect(rootConfig.Validate()).To(Succeed())
+		})
+
+		It("samples the dashboard history every 10 seconds for an hour by default", func() {
+			Expect(rootConfig.Dashboard.SampleInterval()).To(Equal(10 * time.Second))
+			Expect(rootConfig.Dashboard.HistorySize()).To(Equal(360))
+
+			rootConfig.Dashboard = Dashboard{}
+			Expect(rootConfig.Dashboard.SampleInterval()).To(Equal(10 * time.Second))
+			Expect(rootConfig.Dashboard.HistorySize()).To(Equal(360))
+		})
+
+		When("TrafficState.Path is configured", func() {
+			BeforeEach(func() {
+				rootConfig.TrafficState.Path = "/var/vcap/data/proxy/traffic-state.json"
+			})
+
+			It("accepts the default startup behavior", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.RestoreTrafficState()).To(BeTrue())
+			})
+
+			It("accepts resetting the state on startup", func() {
+				rootConfig.TrafficState.OnStartup = TrafficStateReset
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.RestoreTrafficState()).To(BeFalse())
+			})
+
+			It("returns an error if TrafficState.OnStartup is unknown", func() {
+				rootConfig.TrafficState.OnStartup = "sometimes"
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("TrafficState.OnStartup"))
+			})
+		})
+
+		When("Proxy.FlapDamping is enabled", func() {
+			BeforeEach(func() {
+				rootConfig.Proxy.FlapDamping = FlapDamping{Flaps: 3, WindowMillis: 60000, QuarantineMillis: 30000}
+			})
+
+			It("accepts a window and quarantine", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.Proxy.FlapDamping.Quarantine()).To(Equal(30 * time.Second))
+			})
+
+			It("requires a quarantine", func() {
+				rootConfig.Proxy.FlapDamping.QuarantineMillis = 0
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.FlapDamping.QuarantineMillis"))
+			})
+		})
+
+		When("Proxy.Routing has rules", func() {
+			BeforeEach(func() {
+				rootConfig.Proxy.Routing.Rules = []RoutingRule{
+					{User: "reporting", Pool: "readers"},
+					{Schema: "batch_*", Backend: rootConfig.Proxy.Backends[0].Name},
+				}
+			})
+
+			It("accepts rules routing to pools and backends", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.Proxy.Routing.Enabled()).To(BeTrue())
+				Expect(rootConfig.Proxy.HandshakeTimeout()).To(Equal(10 * time.Second))
+			})
+
+			It("returns an error if a rule routes to an unknown backend", func() {
+				rootConfig.Proxy.Routing.Rules[1].Backend = "backend-9"
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(`Proxy.Routing.Rules : rule 1 routes to an unknown backend "backend-9"`))
+			})
+
+			It("returns an error if HandshakeTimeoutMillis is zero", func() {
+				rootConfig.Proxy.HandshakeTimeoutMillis = 0
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.HandshakeTimeoutMillis"))
+			})
+		})
+
+		When("Proxy.UserQuotas are set", func() {
+			BeforeEach(func() {
+				rootConfig.Proxy.UserQuotas = UserQuotas{
+					DefaultMaxSessions: 50,
+					Users:              map[string]uint{"reporting": 10, "admin": 0},
+				}
+			})
+
+			It("inspects handshakes to enforce them", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.Proxy.InspectsHandshakes()).To(BeTrue())
+				Expect(rootConfig.Proxy.UserQuotas.Limits()).To(Equal(userquota.Limits{
+					DefaultMaxSessions: 50,
+					Users:              map[string]int{"reporting": 10, "admin": 0},
+				}))
+			})
+
+			It("is disabled when every limit is zero", func() {
+				rootConfig.Proxy.UserQuotas = UserQuotas{Users: map[string]uint{"admin": 0}}
+				Expect(rootConfig.Proxy.InspectsHandshakes()).To(BeFalse())
+			})
+
+			It("returns an error if HandshakeTimeoutMillis is zero", func() {
+				rootConfig.Proxy.HandshakeTimeoutMillis = 0
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.HandshakeTimeoutMillis"))
+			})
+		})
+
+		When("Proxy.InactiveWsrepSyncWait is set", func() {
+			BeforeEach(func() {
+				rootConfig.Proxy.InactiveMysqlPort = 3307
+				rootConfig.Proxy.InactiveWsrepSyncWait = 1
+			})
+
+			It("inspects handshakes to set it", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.Proxy.InspectsHandshakes()).To(BeTrue())
+			})
+
+			It("returns an error if there is no inactive port", func() {
+				rootConfig.Proxy.InactiveMysqlPort = 0
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.InactiveWsrepSyncWait : requires InactiveMysqlPort"))
+			})
+
+			It("returns an error if it sets unknown checks", func() {
+				rootConfig.Proxy.InactiveWsrepSyncWait = 16
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.InactiveWsrepSyncWait : must be at most 15"))
+			})
+		})
+
+		When("Proxy.Reconnect.Users are set", func() {
+			BeforeEach(func() {
+				rootConfig.Proxy.Reconnect.Users = map[string]string{"app": "secret"}
+			})
+
+			It("inspects handshakes to track sessions, and waits 5 seconds for a new backend by default", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.Proxy.InspectsHandshakes()).To(BeTrue())
+				Expect(rootConfig.Proxy.Reconnect.Timeout()).To(Equal(5 * time.Second))
+			})
+
+			It("returns an error if TimeoutMillis is zero", func() {
+				rootConfig.Proxy.Reconnect.TimeoutMillis = 0
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.Reconnect.TimeoutMillis : zero value"))
+			})
+		})
+
+		When("Proxy.Discovery.Name is set", func() {
+			BeforeEach(func() {
+				rootConfig.Proxy.Backends = nil
+				rootConfig.Proxy.Discovery.Name = "mysql.service.internal"
+				rootConfig.Proxy.Discovery.Port = 3306
+				rootConfig.Proxy.Discovery.StatusPort = 9200
+			})
+
+			It("looks up A records every 10 seconds by default", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.Proxy.Discovery.RecordType()).To(Equal(DiscoveryTypeA))
+				Expect(rootConfig.Proxy.Discovery.Interval()).To(Equal(10 * time.Second))
+				Expect(rootConfig.Proxy.Discovery.StatusEndpoint).To(Equal("api/v1/status"))
+			})
+
+			It("does not need a Port for SRV records, which carry their own", func() {
+				rootConfig.Proxy.Discovery.Type = DiscoveryTypeSRV
+				rootConfig.Proxy.Discovery.Port = 0
+				Expect(rootConfig.Validate()).To(Succeed())
+			})
+
+			It("returns an error if Port is zero for A records", func() {
+				rootConfig.Proxy.Discovery.Port = 0
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.Discovery.Port : zero value"))
+			})
+
+			It("returns an error for unknown record types", func() {
+				rootConfig.Proxy.Discovery.Type = "CNAME"
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(`Proxy.Discovery.Type : must be one of "A", "AAAA" or "SRV"`))
+			})
+
+			It("returns an error if StatusPort is zero", func() {
+				rootConfig.Proxy.Discovery.StatusPort = 0
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.Discovery.StatusPort : zero value"))
+			})
+
+			It("returns an error if Backends are also set", func() {
+				rootConfig.Proxy.Backends = []Backend{{Name: "backend-0", Host: "10.0.0.1", Port: 3306, StatusPort: 9200, StatusEndpoint: "api/v1/status"}}
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.Backends : cannot be set with Discovery"))
+			})
+		})
+
+		When("bind addresses are set", func() {
+			It("accepts host names and IPv4 and IPv6 addresses", func() {
+				rootConfig.BindAddress = "::"
+				rootConfig.Proxy.BindAddresses = []string{"0.0.0.0", "::"}
+				rootConfig.API.BindAddresses = []string{"localhost", "fd00::1"}
+				rootConfig.HealthBindAddresses = []string{"10.0.0.1"}
+				Expect(rootConfig.Validate()).To(Succeed())
+			})
+
+			It("returns an error for addresses with a port", func() {
+				rootConfig.BindAddress = "0.0.0.0:3306"
+				rootConfig.Proxy.BindAddresses = []string{"::", "[::1]"}
+				rootConfig.API.BindAddresses = []string{"localhost:8080"}
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("BindAddress : must be a host name or IP address, without a port"))
+				Expect(err.Error()).To(ContainSubstring("Proxy.BindAddresses[1] : must be a host name or IP address, without a port"))
+				Expect(err.Error()).To(ContainSubstring("API.BindAddresses[0] : must be a host name or IP address, without a port"))
+			})
+		})
+
+		When("Unix domain sockets are set", func() {
+			BeforeEach(func() {
+				rootConfig.Proxy.InactiveMysqlPort = 3307
+				rootConfig.Proxy.UnixSocket = "/var/vcap/sys/run/proxy/mysql.sock"
+				rootConfig.Proxy.InactiveUnixSocket = "/var/vcap/sys/run/proxy/mysql-inactive.sock"
+			})
+
+			It("accepts absolute paths", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+			})
+
+			It("returns an error for relative paths", func() {
+				rootConfig.Proxy.UnixSocket = "mysql.sock"
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.UnixSocket : must be an absolute path"))
+			})
+
+			It("returns an error if InactiveUnixSocket is set without InactiveMysqlPort", func() {
+				rootConfig.Proxy.InactiveMysqlPort = 0
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.InactiveUnixSocket : requires InactiveMysqlPort"))
+			})
+
+			It("returns an error if both ports use the same socket", func() {
+				rootConfig.Proxy.InactiveUnixSocket = rootConfig.Proxy.UnixSocket
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.InactiveUnixSocket : /var/vcap/sys/run/proxy/mysql.sock is already used by another listener"))
+			})
+		})
+
+		When("Diagnostics is enabled", func() {
+			BeforeEach(func() {
+				rootConfig.Diagnostics = Diagnostics{Enabled: true, Port: 8083, Username: "diagnostics", Password: "secret"}
+			})
+
+			It("listens on 127.0.0.1 by default", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.Diagnostics.Hosts()).To(Equal([]string{"127.0.0.1"}))
+			})
+
+			It("requires a port and credentials", func() {
+				rootConfig.Diagnostics = Diagnostics{Enabled: true}
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Diagnostics.Port : zero value"))
+				Expect(err.Error()).To(ContainSubstring("Diagnostics.Username : zero value"))
+				Expect(err.Error()).To(ContainSubstring("Diagnostics.Password : zero value"))
+			})
+
+			It("returns an error for bind addresses with a port", func() {
+				rootConfig.Diagnostics.BindAddresses = []string{"127.0.0.1:8083"}
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Diagnostics.BindAddresses[0] : must be a host name or IP address, without a port"))
+			})
+		})
+
+		When("Webhooks are configured", func() {
+			BeforeEach(func() {
+				rootConfig.Webhooks = Webhooks{URLs: []string{"https://hooks.example.com/switchboard", "http://10.0.0.5:8080/"}}
+			})
+
+			It("accepts http and https URLs", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.Webhooks.Enabled()).To(BeTrue())
+			})
+
+			It("defaults the queue, attempts, retry interval and timeout", func() {
+				Expect(rootConfig.Webhooks.Queue()).To(Equal(100))
+				Expect(rootConfig.Webhooks.Attempts()).To(Equal(5))
+				Expect(rootConfig.Webhooks.RetryAfter()).To(Equal(time.Second))
+				Expect(rootConfig.Webhooks.HTTPClient().Timeout).To(Equal(5 * time.Second))
+			})
+
+			It("returns an error for URLs that are not http or https", func() {
+				rootConfig.Webhooks.URLs = append(rootConfig.Webhooks.URLs, "hooks.example.com/switchboard", "ftp://hooks.example.com/")
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Webhooks.URLs[2] : must be an http or https URL"))
+				Expect(err.Error()).To(ContainSubstring("Webhooks.URLs[3] : must be an http or https URL"))
+			})
+		})
+
+		When("WriterFencing is enabled", func() {
+			BeforeEach(func() {
+				rootConfig.WriterFencing = WriterFencing{Enabled: true, Username: "galera-agent", Password: "secret"}
+			})
+
+			It("accepts galera-agent credentials", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+			})
+
+			It("requires a password", func() {
+				rootConfig.WriterFencing.Password = ""
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("WriterFencing.Password"))
+			})
+		})
+
+		When("further Clusters are configured", func() {
+			BeforeEach(func() {
+				rootConfig.Clusters = []Cluster{
+					{
+						Name: "cluster-b",
+						Proxy: Proxy{
+							Port:                     4306,
+							InactiveMysqlPort:        4307,
+							HealthcheckTimeoutMillis: 5000,
+							Backends: []Backend{
+								{Host: "10.0.1.1", Port: 3306, StatusPort: 9200, StatusEndpoint: "api/v1/status", Name: "b-0"},
+							},
+						},
+					},
+				}
+			})
+
+			It("accepts a cluster with its own listeners", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.AllClusters()).To(HaveLen(2))
+				Expect(rootConfig.AllClusters()[0].Name).To(Equal(DefaultClusterName))
+			})
+
+			It("requires backends", func() {
+				rootConfig.Clusters[0].Proxy.Backends = nil
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Clusters[0].Proxy.Backends"))
+			})
+
+			It("validates the cluster's settings like the top-level ones", func() {
+				rootConfig.Clusters[0].Proxy.CircuitBreaker.DialFailures = 3
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Clusters[0].Proxy.CircuitBreaker.WindowMillis"))
+			})
+
+			It("rejects a name that is not usable in API paths", func() {
+				rootConfig.Clusters[0].Name = "cluster/b"
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Clusters[0].Name"))
+			})
+
+			It("rejects a name used by another cluster", func() {
+				rootConfig.Clusters[0].Name = DefaultClusterName
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(`Clusters[0].Name : "default" is already used by another cluster`))
+			})
+
+			It("rejects a port used by another cluster", func() {
+				rootConfig.Clusters[0].Proxy.InactiveMysqlPort = rootConfig.Proxy.Port
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Clusters[0].Proxy.InactiveMysqlPort"))
+			})
+
+			It("rejects a traffic state file used by another cluster", func() {
+				rootConfig.TrafficState.Path = "/tmp/traffic-state.json"
+				rootConfig.Clusters[0].TrafficState.Path = "/tmp/traffic-state.json"
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Clusters[0].TrafficState.Path"))
+			})
+		})
+
+		When("Locality.PreferLocalReaders is enabled", func() {
+			BeforeEach(func() {
+				rootConfig.Locality.PreferLocalReaders = true
+			})
+
+			It("accepts a proxy zone label", func() {
+				rootConfig.Locality.Labels = map[string]string{"az": "z1"}
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.Locality.Zone()).To(Equal("z1"))
+			})
+
+			It("reads the zone from the configured label", func() {
+				rootConfig.Locality.ZoneLabel = "rack"
+				rootConfig.Locality.Labels = map[string]string{"az": "z1", "rack": "r1"}
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.Locality.Zone()).To(Equal("r1"))
+			})
+
+			It("returns an error if the proxy has no zone label", func() {
+				rootConfig.Locality.Labels = map[string]string{"rack": "r1"}
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Locality.Labels"))
+			})
+		})
+
+		When("Proxy.Admission is configured", func() {
+			It("accepts a rate limit with a burst", func() {
+				rootConfig.Proxy.Admission = Admission{ConnectionsPerSecond: 50, Burst: 100, QueueTimeoutMillis: 250}
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.Proxy.Admission.Enabled()).To(BeTrue())
+				Expect(rootConfig.Proxy.Admission.QueueTimeout()).To(Equal(250 * time.Millisecond))
+			})
+
+			It("returns an error if Burst is set without ConnectionsPerSecond", func() {
+				rootConfig.Proxy.InactiveAdmission = Admission{MaxSessions: 10, Burst: 5}
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.InactiveAdmission.Burst"))
+			})
+
+			It("is disabled by default", func() {
+				Expect(rootConfig.Proxy.Admission.Enabled()).To(BeFalse())
+			})
+		})
+
+		When("Proxy.SourceFilter is configured", func() {
+			It("accepts CIDRs and IP addresses", func() {
+				rootConfig.Proxy.SourceFilter = SourceFilter{Allow: []string{"10.0.0.0/8", "192.168.1.5"}, Deny: []string{"10.1.0.0/16"}}
+				Expect(rootConfig.Validate()).To(Succeed())
+			})
+
+			It("returns an error if an entry is invalid", func() {
+				rootConfig.Proxy.InactiveSourceFilter = SourceFilter{Allow: []string{"10.0.0.0/40"}}
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.InactiveSourceFilter"))
+			})
+		})
+
+		When("Proxy.CircuitBreaker is enabled", func() {
+			BeforeEach(func() {
+				rootConfig.Proxy.CircuitBreaker.DialFailures = 5
+			})
+
+			It("accepts a window", func() {
+				rootConfig.Proxy.CircuitBreaker.WindowMillis = 10000
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.Proxy.CircuitBreaker.Window()).To(Equal(10 * time.Second))
+			})
+
+			It("returns an error if WindowMillis is zero", func() {
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Proxy.CircuitBreaker.WindowMillis"))
+			})
+		})
+
+		When("AccessLog is enabled", func() {
+			BeforeEach(func() {
+				rootConfig.AccessLog.Enabled = true
+				rootConfig.AccessLog.Path = "/var/vcap/sys/log/proxy/access.log"
+			})
+
+			It("accepts the default rotation settings", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.AccessLog.MaxSize()).To(Equal(int64(100 * 1024 * 1024)))
+			})
+
+			It("returns an error if AccessLog.Path is blank", func() {
+				rootConfig.AccessLog.Path = ""
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("AccessLog.Path"))
+			})
+
+			It("returns an error if AccessLog.MaxSizeMB is zero", func() {
+				rootConfig.AccessLog.MaxSizeMB = 0
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("AccessLog.MaxSizeMB"))
+			})
+		})
+
+		When("Capture is enabled", func() {
+			BeforeEach(func() {
+				rootConfig.Capture.Enabled = true
+				rootConfig.Capture.Directory = "/var/vcap/data/proxy/captures"
+			})
+
+			It("accepts the default limits", func() {
+				Expect(rootConfig.Validate()).To(Succeed())
+				Expect(rootConfig.Capture.MaxBytes).To(Equal(uint(100 * 1024 * 1024)))
+				Expect(rootConfig.Capture.MaxDuration()).To(Equal(10 * time.Minute))
+				Expect(rootConfig.Capture.MaxFiles).To(Equal(uint(10)))
+			})
+
+			It("returns an error if Capture.Directory is blank", func() {
+				rootConfig.Capture.Directory = ""
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Capture.Directory"))
+			})
+
+			It("returns an error if a limit is zero", func() {
+				rootConfig.Capture.MaxBytes = 0
+				rootConfig.Capture.MaxDurationSeconds = 0
+				rootConfig.Capture.MaxFiles = 0
+				err := rootConfig.Validate()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Capture.MaxBytes"))
+				Expect(err.Error()).To(ContainSubstring("Capture.MaxDurationSeconds"))
+				Expect(err.Error()).To(ContainSubstring("Capture.MaxFiles"))
+			})
 		})
 	})
 
@@ -312,6 +863,40 @@ var _ = Describe("Config", func() {
 		})
 	})
 
+	Describe("AggregatorHTTPClient", func() {
+		var rootConfig *Config
+		BeforeEach(func() {
+			var err error
+			rootConfig, err = NewConfig([]string{"switchboard", "-configPath=fixtures/validConfig.yml"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("uses the system CAs without an API CA", func() {
+			httpClient := rootConfig.AggregatorHTTPClient()
+
+			Expect(httpClient.Timeout).To(Equal(5 * time.Second))
+			Expect(httpClient.Transport).To(BeNil())
+		})
+
+		When("an API CA is configured", func() {
+			It("verifies the proxy APIs with the CA", func() {
+				authority, err := certtest.BuildCA("proxyCA")
+				Expect(err).NotTo(HaveOccurred())
+				caPEM, err := authority.CertificatePEM()
+				Expect(err).NotTo(HaveOccurred())
+				rootConfig.API.TLS.CA = string(caPEM)
+
+				httpClient := rootConfig.AggregatorHTTPClient()
+
+				Expect(httpClient.Transport).To(BeAssignableToTypeOf(&http.Transport{}))
+				transport := httpClient.Transport.(*http.Transport)
+				expectedPool, err := authority.CertPool()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(transport.TLSClientConfig.RootCAs.Equal(expectedPool)).To(BeTrue())
+			})
+		})
+	})
+
 	Describe("NewConfig", func() {
 		var rawConfig string
 
@@ -420,6 +1005,52 @@ var _ = Describe("Config", func() {
 			// Verify that defaults are preserved with empty config
 			Expect(resultConfig.Metrics.Port).To(Equal(uint(9999)))
 			Expect(resultConfig.StatusLog.Interval).To(Equal(time.Minute))
+			Expect(resultConfig.Proxy.Sockets.DialTimeout()).To(Equal(5 * time.Second))
+			Expect(resultConfig.Proxy.Sockets.NoDelay).To(BeTrue())
+			Expect(resultConfig.WriterFencing.Timeout()).To(Equal(5 * time.Second))
+			Expect(resultConfig.Locality.ZoneLabel).To(Equal("az"))
+		})
+
+		It("provides the sink that sets the log level", func() {
+			resultConfig, err := NewConfig([]string{"switchboard", "-config={}"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resultConfig.LogSink).NotTo(BeNil())
+			resultConfig.LogSink.SetMinLevel(lager.DEBUG)
+			Expect(resultConfig.LogSink.GetMinLevel()).To(Equal(lager.DEBUG))
+		})
+
+		It("applies the same defaults to further clusters", func() {
+			osArgs := []string{
+				"switchboard",
+				`-config={"Clusters": [{"Name": "cluster-b", "Proxy": {"Sockets": {"KeepAliveSeconds": 30}}}]}`,
+			}
+
+			resultConfig, err := NewConfig(osArgs)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resultConfig.DefaultCluster().Name).To(Equal(DefaultClusterName))
+			Expect(resultConfig.Clusters).To(HaveLen(1))
+			cluster := resultConfig.Clusters[0]
+			Expect(cluster.Name).To(Equal("cluster-b"))
+			Expect(cluster.Proxy.Sockets.KeepAlive()).To(Equal(30 * time.Second))
+			Expect(cluster.Proxy.Sockets.DialTimeout()).To(Equal(5 * time.Second))
+			Expect(cluster.RestoreTrafficState()).To(BeTrue())
+		})
+
+		It("preserves socket defaults when only some socket options are provided", func() {
+			osArgs := []string{
+				"switchboard",
+				`-config={"Proxy": {"Sockets": {"KeepAliveSeconds": 30}}}`,
+			}
+
+			resultConfig, err := NewConfig(osArgs)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(resultConfig.Proxy.Sockets.KeepAlive()).To(Equal(30 * time.Second))
+			Expect(resultConfig.Proxy.Sockets.DialTimeout()).To(Equal(5 * time.Second))
+			Expect(resultConfig.Proxy.Sockets.NoDelay).To(BeTrue())
+			Expect(resultConfig.Proxy.Sockets.IdleTimeout()).To(BeZero())
 		})
 	})
 })
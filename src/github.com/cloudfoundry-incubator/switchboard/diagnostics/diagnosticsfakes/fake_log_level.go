@@ -0,0 +1,138 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package diagnosticsfakes
+
+import (
+	"sync"
+
+	lager "code.cloudfoundry.org/lager/v3"
+	"github.com/cloudfoundry-incubator/switchboard/diagnostics"
+)
+
+type FakeLogLevel struct {
+	GetMinLevelStub        func() lager.LogLevel
+	getMinLevelMutex       sync.RWMutex
+	getMinLevelArgsForCall []struct {
+	}
+	getMinLevelReturns struct {
+		result1 lager.LogLevel
+	}
+	getMinLevelReturnsOnCall map[int]struct {
+		result1 lager.LogLevel
+	}
+	SetMinLevelStub        func(lager.LogLevel)
+	setMinLevelMutex       sync.RWMutex
+	setMinLevelArgsForCall []struct {
+		arg1 lager.LogLevel
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeLogLevel) GetMinLevel() lager.LogLevel {
+	fake.getMinLevelMutex.Lock()
+	ret, specificReturn := fake.getMinLevelReturnsOnCall[len(fake.getMinLevelArgsForCall)]
+	fake.getMinLevelArgsForCall = append(fake.getMinLevelArgsForCall, struct {
+	}{})
+	stub := fake.GetMinLevelStub
+	fakeReturns := fake.getMinLevelReturns
+	fake.recordInvocation("GetMinLevel", []interface{}{})
+	fake.getMinLevelMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLogLevel) GetMinLevelCallCount() int {
+	fake.getMinLevelMutex.RLock()
+	defer fake.getMinLevelMutex.RUnlock()
+	return len(fake.getMinLevelArgsForCall)
+}
+
+func (fake *FakeLogLevel) GetMinLevelCalls(stub func() lager.LogLevel) {
+	fake.getMinLevelMutex.Lock()
+	defer fake.getMinLevelMutex.Unlock()
+	fake.GetMinLevelStub = stub
+}
+
+func (fake *FakeLogLevel) GetMinLevelReturns(result1 lager.LogLevel) {
+	fake.getMinLevelMutex.Lock()
+	defer fake.getMinLevelMutex.Unlock()
+	fake.GetMinLevelStub = nil
+	fake.getMinLevelReturns = struct {
+		result1 lager.LogLevel
+	}{result1}
+}
+
+func (fake *FakeLogLevel) GetMinLevelReturnsOnCall(i int, result1 lager.LogLevel) {
+	fake.getMinLevelMutex.Lock()
+	defer fake.getMinLevelMutex.Unlock()
+	fake.GetMinLevelStub = nil
+	if fake.getMinLevelReturnsOnCall == nil {
+		fake.getMinLevelReturnsOnCall = make(map[int]struct {
+			result1 lager.LogLevel
+		})
+	}
+	fake.getMinLevelReturnsOnCall[i] = struct {
+		result1 lager.LogLevel
+	}{result1}
+}
+
+func (fake *FakeLogLevel) SetMinLevel(arg1 lager.LogLevel) {
+	fake.setMinLevelMutex.Lock()
+	fake.setMinLevelArgsForCall = append(fake.setMinLevelArgsForCall, struct {
+		arg1 lager.LogLevel
+	}{arg1})
+	stub := fake.SetMinLevelStub
+	fake.recordInvocation("SetMinLevel", []interface{}{arg1})
+	fake.setMinLevelMutex.Unlock()
+	if stub != nil {
+		fake.SetMinLevelStub(arg1)
+	}
+}
+
+func (fake *FakeLogLevel) SetMinLevelCallCount() int {
+	fake.setMinLevelMutex.RLock()
+	defer fake.setMinLevelMutex.RUnlock()
+	return len(fake.setMinLevelArgsForCall)
+}
+
+func (fake *FakeLogLevel) SetMinLevelCalls(stub func(lager.LogLevel)) {
+	fake.setMinLevelMutex.Lock()
+	defer fake.setMinLevelMutex.Unlock()
+	fake.SetMinLevelStub = stub
+}
+
+func (fake *FakeLogLevel) SetMinLevelArgsForCall(i int) lager.LogLevel {
+	fake.setMinLevelMutex.RLock()
+	defer fake.setMinLevelMutex.RUnlock()
+	argsForCall := fake.setMinLevelArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeLogLevel) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeLogLevel) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ diagnostics.LogLevel = new(FakeLogLevel)
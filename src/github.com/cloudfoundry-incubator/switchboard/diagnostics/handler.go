@@ -0,0 +1,158 @@
+// Package diagnostics serves what an operator needs to debug a running proxy:
+// pprof profiles, a goroutine dump, the state of its monitors and sessions,
+// and its log level.
+package diagnostics
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	"github.com/cloudfoundry-incubator/switchboard/api/middleware"
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/runner/monitor"
+)
+
+// maxLogLevelRequestSize bounds the body of PUT /debug/log-level.
+const maxLogLevelRequestSize = 1024
+
+// Monitor reports the state of a cluster monitor.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Monitor
+type Monitor interface {
+	State() monitor.State
+}
+
+// LogLevel gets and sets the minimum level the proxy logs at.
+// *lager.ReconfigurableSink implements it.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . LogLevel
+type LogLevel interface {
+	GetMinLevel() lager.LogLevel
+	SetMinLevel(level lager.LogLevel)
+}
+
+// Cluster is one cluster the proxy routes to.
+type Cluster struct {
+	Name     string
+	Backends *domain.BackendSet
+	// Monitors are the cluster's monitors by the port they choose the
+	// backend of, "active" or "inactive".
+	Monitors map[string]Monitor
+}
+
+type StateResponse struct {
+	Goroutines int            `json:"goroutines"`
+	Clusters   []ClusterState `json:"clusters"`
+}
+
+type ClusterState struct {
+	Name string `json:"name"`
+	// Sessions is how many sessions each backend's registry holds.
+	Sessions      map[string]uint          `json:"sessions"`
+	TotalSessions uint                     `json:"totalSessions"`
+	Monitors      map[string]monitor.State `json:"monitors"`
+}
+
+type LogLevelBody struct {
+	Level string `json:"level"`
+}
+
+// NewHandler serves the diagnostics of clusters to clients that authenticate
+// with username and password. Unlike the API, it logs no requests but those
+// changing the log level, as profiles and dumps are large.
+func NewHandler(clusters []Cluster, logLevel LogLevel, username, password string, logger lager.Logger) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/goroutines", GoroutinesEndpoint)
+	mux.Handle("/debug/state", StateEndpoint(clusters))
+	mux.Handle("/debug/log-level", LogLevelEndpoint(logLevel, logger))
+
+	return middleware.Chain{
+		middleware.NewPanicRecovery(logger),
+		middleware.NewLogger(logger, "/debug/log-level"),
+		middleware.NewBasicAuth(username, password),
+	}.Wrap(mux)
+}
+
+// GoroutinesEndpoint dumps the stack of every goroutine, as an unrecovered
+// panic would.
+var GoroutinesEndpoint = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+})
+
+var StateEndpoint = func(clusters []Cluster) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		response := StateResponse{
+			Goroutines: runtime.NumGoroutine(),
+			Clusters:   make([]ClusterState, 0, len(clusters)),
+		}
+
+		for _, c := range clusters {
+			state := ClusterState{
+				Name:     c.Name,
+				Sessions: map[string]uint{},
+				Monitors: map[string]monitor.State{},
+			}
+			for _, backend := range c.Backends.All() {
+				j := backend.AsJSON()
+				state.Sessions[j.Name] = j.CurrentSessionCount
+				state.TotalSessions += j.CurrentSessionCount
+			}
+			for role, m := range c.Monitors {
+				state.Monitors[role] = m.State()
+			}
+			response.Clusters = append(response.Clusters, state)
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	})
+}
+
+// LogLevelEndpoint reports the log level, and changes it until the proxy
+// restarts.
+var LogLevelEndpoint = func(logLevel LogLevel, logger lager.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var body LogLevelBody
+			if err := json.NewDecoder(io.LimitReader(req.Body, maxLogLevelRequestSize)).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			level, err := lager.LogLevelFromString(body.Level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			logger.Info("log-level-changed", lager.Data{"from": logLevel.GetMinLevel().String(), "to": level.String()})
+			logLevel.SetMinLevel(level)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, LogLevelBody{Level: logLevel.GetMinLevel().String()})
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,132 @@
+package diagnostics_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/diagnostics"
+	"github.com/cloudfoundry-incubator/switchboard/diagnostics/diagnosticsfakes"
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/runner/monitor"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		handler       http.Handler
+		logger        *lagertest.TestLogger
+		logLevel      *lager.ReconfigurableSink
+		activeMonitor *diagnosticsfakes.FakeMonitor
+		activeState   monitor.State
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("diagnostics")
+		logLevel = lager.NewReconfigurableSink(lagertest.NewTestSink(), lager.INFO)
+
+		activeMonitor = new(diagnosticsfakes.FakeMonitor)
+		activeState = monitor.State{
+			ActiveBackend: "backend-0",
+			Backends: []monitor.BackendState{
+				{Name: "backend-0", Index: 0, Healthy: true, Counters: map[string]uint64{"dial": 7}},
+			},
+			Publishing:  true,
+			Subscribers: []monitor.ChannelBacklog{{Length: 1, Capacity: 1}},
+		}
+		activeMonitor.StateReturns(activeState)
+
+		backends := domain.NewBackendSet(domain.NewBackends([]config.Backend{
+			{Name: "backend-0", Host: "10.0.0.1", Port: 3306, StatusPort: 9200, StatusEndpoint: "api/v1/status"},
+		}, logger))
+		handler = diagnostics.NewHandler(
+			[]diagnostics.Cluster{{
+				Name:     "default",
+				Backends: backends,
+				Monitors: map[string]diagnostics.Monitor{"active": activeMonitor},
+			}},
+			logLevel,
+			"diagnostics",
+			"secret",
+			logger,
+		)
+	})
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.SetBasicAuth("diagnostics", "secret")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("requires credentials", func() {
+		for _, path := range []string{"/debug/pprof/", "/debug/goroutines", "/debug/state", "/debug/log-level"} {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized), path)
+		}
+	})
+
+	It("serves pprof", func() {
+		recorder := serve(http.MethodGet, "/debug/pprof/heap?debug=1", "")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(ContainSubstring("heap profile"))
+	})
+
+	It("dumps every goroutine", func() {
+		recorder := serve(http.MethodGet, "/debug/goroutines", "")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(ContainSubstring("goroutine "))
+		Expect(recorder.Body.String()).To(ContainSubstring("diagnostics_test"))
+	})
+
+	It("reports the state of the monitors and sessions", func() {
+		recorder := serve(http.MethodGet, "/debug/state", "")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var state diagnostics.StateResponse
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &state)).To(Succeed())
+		Expect(state.Goroutines).To(BeNumerically(">", 0))
+		Expect(state.Clusters).To(HaveLen(1))
+		Expect(state.Clusters[0].Name).To(Equal("default"))
+		Expect(state.Clusters[0].Sessions).To(Equal(map[string]uint{"backend-0": 0}))
+		Expect(state.Clusters[0].TotalSessions).To(BeZero())
+		Expect(state.Clusters[0].Monitors).To(Equal(map[string]monitor.State{"active": activeState}))
+	})
+
+	Describe("log level", func() {
+		It("reports the log level", func() {
+			recorder := serve(http.MethodGet, "/debug/log-level", "")
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"level":"info"}`))
+		})
+
+		It("changes the log level", func() {
+			recorder := serve(http.MethodPut, "/debug/log-level", `{"level":"debug"}`)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.String()).To(MatchJSON(`{"level":"debug"}`))
+			Expect(logLevel.GetMinLevel()).To(Equal(lager.DEBUG))
+
+			Expect(logger).To(gbytes.Say("log-level-changed"))
+		})
+
+		It("rejects unknown levels", func() {
+			recorder := serve(http.MethodPut, "/debug/log-level", `{"level":"verbose"}`)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(logLevel.GetMinLevel()).To(Equal(lager.INFO))
+		})
+
+		It("rejects other methods", func() {
+			recorder := serve(http.MethodPost, "/debug/log-level", `{"level":"debug"}`)
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+	})
+})
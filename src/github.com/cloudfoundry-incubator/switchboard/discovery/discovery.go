@@ -0,0 +1,110 @@
+// Package discovery finds the backends of a cluster in DNS, so that BOSH DNS
+// or another resolver decides which nodes the proxy routes to.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/switchboard/config"
+)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Resolver
+
+// Resolver looks up DNS records. *net.Resolver implements it.
+type Resolver interface {
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+// NewResolver returns a resolver that queries the DNS server at address, a
+// host:port, or the system's resolvers when address is empty.
+func NewResolver(address string) Resolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
+
+// Resolve looks up the backends source describes, sorted by name. It fails
+// when DNS has no records for source, rather than return no backends.
+func Resolve(ctx context.Context, resolver Resolver, source config.Discovery) ([]config.Backend, error) {
+	var backends []config.Backend
+
+	switch source.RecordType() {
+	case config.DiscoveryTypeSRV:
+		_, srvs, err := resolver.LookupSRV(ctx, "", "", source.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		names := map[string]bool{}
+		sort.Slice(srvs, func(i, j int) bool {
+			if srvs[i].Target != srvs[j].Target {
+				return srvs[i].Target < srvs[j].Target
+			}
+			return srvs[i].Port < srvs[j].Port
+		})
+		for _, srv := range srvs {
+			host := strings.TrimSuffix(srv.Target, ".")
+
+			// A target listed with several ports is one backend per port.
+			name := host
+			if names[name] {
+				name = net.JoinHostPort(host, fmt.Sprint(srv.Port))
+			}
+			names[name] = true
+
+			backends = append(backends, backend(source, name, host, uint(srv.Port)))
+		}
+
+	default:
+		network := "ip4"
+		if source.RecordType() == config.DiscoveryTypeAAAA {
+			network = "ip6"
+		}
+
+		ips, err := resolver.LookupIP(ctx, network, source.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		names := map[string]bool{}
+		for _, ip := range ips {
+			if names[ip.String()] {
+				continue
+			}
+			names[ip.String()] = true
+
+			backends = append(backends, backend(source, ip.String(), ip.String(), source.Port))
+		}
+	}
+
+	if len(backends) == 0 {
+		return nil, errors.New("no " + source.RecordType() + " records for " + source.Name)
+	}
+
+	sort.Slice(backends, func(i, j int) bool { return backends[i].Name < backends[j].Name })
+	return backends, nil
+}
+
+func backend(source config.Discovery, name, host string, port uint) config.Backend {
+	return config.Backend{
+		Name:           name,
+		Host:           host,
+		Port:           port,
+		StatusPort:     source.StatusPort,
+		StatusEndpoint: source.StatusEndpoint,
+	}
+}
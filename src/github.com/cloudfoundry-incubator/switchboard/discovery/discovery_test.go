@@ -0,0 +1,110 @@
+package discovery_test
+
+import (
+	"context"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/discovery"
+	"github.com/cloudfoundry-incubator/switchboard/discovery/dnsstub"
+)
+
+var _ = Describe("Resolve", func() {
+	var (
+		server   *dnsstub.Server
+		resolver discovery.Resolver
+		source   config.Discovery
+		ctx      context.Context
+		cancel   context.CancelFunc
+	)
+
+	BeforeEach(func() {
+		server = dnsstub.NewServer()
+		resolver = discovery.NewResolver(server.Addr())
+		source = config.Discovery{
+			Name:           "mysql.service.internal",
+			Port:           3306,
+			StatusPort:     9200,
+			StatusEndpoint: "api/v1/status",
+		}
+		ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	})
+
+	AfterEach(func() {
+		cancel()
+		server.Close()
+	})
+
+	It("makes a backend of each A record, named after its address", func() {
+		server.SetIPs("mysql.service.internal", net.ParseIP("10.0.0.2"), net.ParseIP("10.0.0.1"), net.ParseIP("fd00::1"))
+
+		backends, err := discovery.Resolve(ctx, resolver, source)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backends).To(Equal([]config.Backend{
+			{Name: "10.0.0.1", Host: "10.0.0.1", Port: 3306, StatusPort: 9200, StatusEndpoint: "api/v1/status"},
+			{Name: "10.0.0.2", Host: "10.0.0.2", Port: 3306, StatusPort: 9200, StatusEndpoint: "api/v1/status"},
+		}))
+	})
+
+	It("looks up AAAA records instead when asked to", func() {
+		source.Type = config.DiscoveryTypeAAAA
+		server.SetIPs("mysql.service.internal", net.ParseIP("10.0.0.1"), net.ParseIP("fd00::1"))
+
+		backends, err := discovery.Resolve(ctx, resolver, source)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backends).To(Equal([]config.Backend{
+			{Name: "fd00::1", Host: "fd00::1", Port: 3306, StatusPort: 9200, StatusEndpoint: "api/v1/status"},
+		}))
+	})
+
+	It("makes a backend of each SRV record, named after its target and listening on its port", func() {
+		source.Type = config.DiscoveryTypeSRV
+		source.Name = "_mysql._tcp.mysql.service.internal"
+		server.SetSRVs("_mysql._tcp.mysql.service.internal",
+			net.SRV{Target: "mysql-1.mysql.service.internal.", Port: 3307},
+			net.SRV{Target: "mysql-0.mysql.service.internal.", Port: 3306},
+		)
+
+		backends, err := discovery.Resolve(ctx, resolver, source)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backends).To(Equal([]config.Backend{
+			{Name: "mysql-0.mysql.service.internal", Host: "mysql-0.mysql.service.internal", Port: 3306, StatusPort: 9200, StatusEndpoint: "api/v1/status"},
+			{Name: "mysql-1.mysql.service.internal", Host: "mysql-1.mysql.service.internal", Port: 3307, StatusPort: 9200, StatusEndpoint: "api/v1/status"},
+		}))
+	})
+
+	It("names the backends of a target listed with several ports by their port", func() {
+		source.Type = config.DiscoveryTypeSRV
+		server.SetSRVs("mysql.service.internal",
+			net.SRV{Target: "mysql-0.mysql.service.internal.", Port: 3307},
+			net.SRV{Target: "mysql-0.mysql.service.internal.", Port: 3306},
+		)
+
+		backends, err := discovery.Resolve(ctx, resolver, source)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backends).To(HaveLen(2))
+		Expect(backends[0].Name).To(Equal("mysql-0.mysql.service.internal"))
+		Expect(backends[0].Port).To(Equal(uint(3306)))
+		Expect(backends[1].Name).To(Equal("mysql-0.mysql.service.internal:3307"))
+	})
+
+	It("fails when the name does not exist", func() {
+		_, err := discovery.Resolve(ctx, resolver, source)
+		Expect(err).To(HaveOccurred())
+
+		var dnsErr *net.DNSError
+		Expect(err).To(BeAssignableToTypeOf(dnsErr))
+		Expect(err.(*net.DNSError).IsNotFound).To(BeTrue())
+	})
+
+	It("fails when the name has no records of the type", func() {
+		server.SetIPs("mysql.service.internal", net.ParseIP("fd00::1"))
+
+		_, err := discovery.Resolve(ctx, resolver, source)
+		Expect(err).To(HaveOccurred())
+	})
+})
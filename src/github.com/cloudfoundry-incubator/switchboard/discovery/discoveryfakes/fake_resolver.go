@@ -0,0 +1,208 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package discoveryfakes
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/cloudfoundry-incubator/switchboard/discovery"
+)
+
+type FakeResolver struct {
+	LookupIPStub        func(context.Context, string, string) ([]net.IP, error)
+	lookupIPMutex       sync.RWMutex
+	lookupIPArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	lookupIPReturns struct {
+		result1 []net.IP
+		result2 error
+	}
+	lookupIPReturnsOnCall map[int]struct {
+		result1 []net.IP
+		result2 error
+	}
+	LookupSRVStub        func(context.Context, string, string, string) (string, []*net.SRV, error)
+	lookupSRVMutex       sync.RWMutex
+	lookupSRVArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+	}
+	lookupSRVReturns struct {
+		result1 string
+		result2 []*net.SRV
+		result3 error
+	}
+	lookupSRVReturnsOnCall map[int]struct {
+		result1 string
+		result2 []*net.SRV
+		result3 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeResolver) LookupIP(arg1 context.Context, arg2 string, arg3 string) ([]net.IP, error) {
+	fake.lookupIPMutex.Lock()
+	ret, specificReturn := fake.lookupIPReturnsOnCall[len(fake.lookupIPArgsForCall)]
+	fake.lookupIPArgsForCall = append(fake.lookupIPArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.LookupIPStub
+	fakeReturns := fake.lookupIPReturns
+	fake.recordInvocation("LookupIP", []interface{}{arg1, arg2, arg3})
+	fake.lookupIPMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeResolver) LookupIPCallCount() int {
+	fake.lookupIPMutex.RLock()
+	defer fake.lookupIPMutex.RUnlock()
+	return len(fake.lookupIPArgsForCall)
+}
+
+func (fake *FakeResolver) LookupIPCalls(stub func(context.Context, string, string) ([]net.IP, error)) {
+	fake.lookupIPMutex.Lock()
+	defer fake.lookupIPMutex.Unlock()
+	fake.LookupIPStub = stub
+}
+
+func (fake *FakeResolver) LookupIPArgsForCall(i int) (context.Context, string, string) {
+	fake.lookupIPMutex.RLock()
+	defer fake.lookupIPMutex.RUnlock()
+	argsForCall := fake.lookupIPArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeResolver) LookupIPReturns(result1 []net.IP, result2 error) {
+	fake.lookupIPMutex.Lock()
+	defer fake.lookupIPMutex.Unlock()
+	fake.LookupIPStub = nil
+	fake.lookupIPReturns = struct {
+		result1 []net.IP
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeResolver) LookupIPReturnsOnCall(i int, result1 []net.IP, result2 error) {
+	fake.lookupIPMutex.Lock()
+	defer fake.lookupIPMutex.Unlock()
+	fake.LookupIPStub = nil
+	if fake.lookupIPReturnsOnCall == nil {
+		fake.lookupIPReturnsOnCall = make(map[int]struct {
+			result1 []net.IP
+			result2 error
+		})
+	}
+	fake.lookupIPReturnsOnCall[i] = struct {
+		result1 []net.IP
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeResolver) LookupSRV(arg1 context.Context, arg2 string, arg3 string, arg4 string) (string, []*net.SRV, error) {
+	fake.lookupSRVMutex.Lock()
+	ret, specificReturn := fake.lookupSRVReturnsOnCall[len(fake.lookupSRVArgsForCall)]
+	fake.lookupSRVArgsForCall = append(fake.lookupSRVArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.LookupSRVStub
+	fakeReturns := fake.lookupSRVReturns
+	fake.recordInvocation("LookupSRV", []interface{}{arg1, arg2, arg3, arg4})
+	fake.lookupSRVMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeResolver) LookupSRVCallCount() int {
+	fake.lookupSRVMutex.RLock()
+	defer fake.lookupSRVMutex.RUnlock()
+	return len(fake.lookupSRVArgsForCall)
+}
+
+func (fake *FakeResolver) LookupSRVCalls(stub func(context.Context, string, string, string) (string, []*net.SRV, error)) {
+	fake.lookupSRVMutex.Lock()
+	defer fake.lookupSRVMutex.Unlock()
+	fake.LookupSRVStub = stub
+}
+
+func (fake *FakeResolver) LookupSRVArgsForCall(i int) (context.Context, string, string, string) {
+	fake.lookupSRVMutex.RLock()
+	defer fake.lookupSRVMutex.RUnlock()
+	argsForCall := fake.lookupSRVArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeResolver) LookupSRVReturns(result1 string, result2 []*net.SRV, result3 error) {
+	fake.lookupSRVMutex.Lock()
+	defer fake.lookupSRVMutex.Unlock()
+	fake.LookupSRVStub = nil
+	fake.lookupSRVReturns = struct {
+		result1 string
+		result2 []*net.SRV
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeResolver) LookupSRVReturnsOnCall(i int, result1 string, result2 []*net.SRV, result3 error) {
+	fake.lookupSRVMutex.Lock()
+	defer fake.lookupSRVMutex.Unlock()
+	fake.LookupSRVStub = nil
+	if fake.lookupSRVReturnsOnCall == nil {
+		fake.lookupSRVReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 []*net.SRV
+			result3 error
+		})
+	}
+	fake.lookupSRVReturnsOnCall[i] = struct {
+		result1 string
+		result2 []*net.SRV
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeResolver) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeResolver) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ discovery.Resolver = new(FakeResolver)
@@ -0,0 +1,217 @@
+// Package dnsstub is a DNS server for tests of DNS discovery. It answers A,
+// AAAA and SRV queries over UDP from the records it is given.
+package dnsstub
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+const (
+	typeA    = 1
+	typeAAAA = 28
+	typeSRV  = 33
+	classIN  = 1
+
+	flagResponse           = 0x8000
+	flagAuthoritative      = 0x0400
+	flagRecursionDesired   = 0x0100
+	flagRecursionAvailable = 0x0080
+	rcodeFormatError       = 1
+	rcodeNameError         = 3
+	rcodeNotImplemented    = 4
+
+	headerSize = 12
+)
+
+// Server answers the queries for the names it has records for, and answers
+// NXDOMAIN for the others.
+type Server struct {
+	conn net.PacketConn
+
+	mutex sync.RWMutex
+	ips   map[string][]net.IP
+	srvs  map[string][]net.SRV
+}
+
+// NewServer starts a server on a random port of 127.0.0.1. It panics if it
+// cannot listen, like httptest.NewServer.
+func NewServer() *Server {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		panic("dnsstub: failed to listen: " + err.Error())
+	}
+
+	s := &Server{
+		conn: conn,
+		ips:  map[string][]net.IP{},
+		srvs: map[string][]net.SRV{},
+	}
+	go s.serve()
+	return s
+}
+
+// Addr is the host:port the server listens on.
+func (s *Server) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *Server) Close() error {
+	return s.conn.Close()
+}
+
+// SetIPs replaces the A and AAAA records of name. Without ips, name has no
+// address records.
+func (s *Server) SetIPs(name string, ips ...net.IP) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.ips[canonical(name)] = ips
+}
+
+// SetSRVs replaces the SRV records of name.
+func (s *Server) SetSRVs(name string, srvs ...net.SRV) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.srvs[canonical(name)] = srvs
+}
+
+func (s *Server) serve() {
+	buf := make([]byte, 4096)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		if response := s.answer(buf[:n]); response != nil {
+			_, _ = s.conn.WriteTo(response, addr)
+		}
+	}
+}
+
+// answer returns the response to query, or nil when query is too short to
+// answer at all.
+func (s *Server) answer(query []byte) []byte {
+	if len(query) < headerSize {
+		return nil
+	}
+
+	flags := flagResponse | flagAuthoritative | flagRecursionAvailable |
+		binary.BigEndian.Uint16(query[2:4])&flagRecursionDesired
+	reply := func(rcode uint16, question []byte, answers [][]byte) []byte {
+		response := binary.BigEndian.AppendUint16(nil, binary.BigEndian.Uint16(query[0:2]))
+		response = binary.BigEndian.AppendUint16(response, flags|rcode)
+		qdcount := 0
+		if question != nil {
+			qdcount = 1
+		}
+		response = binary.BigEndian.AppendUint16(response, uint16(qdcount))
+		response = binary.BigEndian.AppendUint16(response, uint16(len(answers)))
+		response = append(response, 0, 0, 0, 0)
+		response = append(response, question...)
+		for _, answer := range answers {
+			response = append(response, answer...)
+		}
+		return response
+	}
+
+	if binary.BigEndian.Uint16(query[4:6]) != 1 {
+		return reply(rcodeFormatError, nil, nil)
+	}
+
+	name, end, err := readName(query, headerSize)
+	if err != nil || end+4 > len(query) {
+		return reply(rcodeFormatError, nil, nil)
+	}
+	question := query[headerSize : end+4]
+	qtype := binary.BigEndian.Uint16(query[end : end+2])
+
+	s.mutex.RLock()
+	ips, hasIPs := s.ips[name]
+	srvs, hasSRVs := s.srvs[name]
+	s.mutex.RUnlock()
+
+	if !hasIPs && !hasSRVs {
+		return reply(rcodeNameError, question, nil)
+	}
+
+	var answers [][]byte
+	switch qtype {
+	case typeA:
+		for _, ip := range ips {
+			if ip4 := ip.To4(); ip4 != nil {
+				answers = append(answers, record(typeA, ip4))
+			}
+		}
+	case typeAAAA:
+		for _, ip := range ips {
+			if ip.To4() == nil && len(ip) == net.IPv6len {
+				answers = append(answers, record(typeAAAA, ip))
+			}
+		}
+	case typeSRV:
+		for _, srv := range srvs {
+			data := binary.BigEndian.AppendUint16(nil, srv.Priority)
+			data = binary.BigEndian.AppendUint16(data, srv.Weight)
+			data = binary.BigEndian.AppendUint16(data, srv.Port)
+			answers = append(answers, record(typeSRV, appendName(data, srv.Target)))
+		}
+	default:
+		return reply(rcodeNotImplemented, question, nil)
+	}
+
+	return reply(0, question, answers)
+}
+
+// record is an answer for the name of the question, which starts right
+// after the header.
+func record(rrtype uint16, data []byte) []byte {
+	r := binary.BigEndian.AppendUint16(nil, 0xc000|headerSize)
+	r = binary.BigEndian.AppendUint16(r, rrtype)
+	r = binary.BigEndian.AppendUint16(r, classIN)
+	r = binary.BigEndian.AppendUint32(r, 0)
+	r = binary.BigEndian.AppendUint16(r, uint16(len(data)))
+	return append(r, data...)
+}
+
+// readName reads the uncompressed name at offset of msg, and returns it with
+// the offset just after it.
+func readName(msg []byte, offset int) (string, int, error) {
+	var labels []string
+	for {
+		if offset >= len(msg) {
+			return "", 0, errors.New("name overflows message")
+		}
+		length := int(msg[offset])
+		offset++
+		if length == 0 {
+			break
+		}
+		if length > 63 || offset+length > len(msg) {
+			return "", 0, errors.New("malformed label")
+		}
+		labels = append(labels, string(msg[offset:offset+length]))
+		offset += length
+	}
+	return canonical(strings.Join(labels, ".")), offset, nil
+}
+
+func appendName(b []byte, name string) []byte {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" {
+			continue
+		}
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+func canonical(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, ".")) + "."
+}
@@ -0,0 +1,77 @@
+package discovery
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+// Runner resolves its source every interval and reconciles a cluster's
+// backends with the records. When a lookup fails, the backends stay as they
+// were.
+type Runner struct {
+	resolver  Resolver
+	source    config.Discovery
+	backends  *domain.BackendSet
+	configure func(*domain.Backend)
+	logger    lager.Logger
+}
+
+var _ ifrit.Runner = (*Runner)(nil)
+
+// NewRunner returns a runner that keeps backends in line with source, and
+// passes each backend it adds to configure.
+func NewRunner(resolver Resolver, source config.Discovery, backends *domain.BackendSet, configure func(*domain.Backend), logger lager.Logger) *Runner {
+	return &Runner{
+		resolver:  resolver,
+		source:    source,
+		backends:  backends,
+		configure: configure,
+		logger:    logger,
+	}
+}
+
+// Run resolves the backends once before it is ready, so that the monitors
+// started after it have backends to choose from.
+func (r *Runner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	ticker := time.NewTicker(r.source.Interval())
+	defer ticker.Stop()
+
+	r.reconcile()
+	close(ready)
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C:
+			r.reconcile()
+		}
+	}
+}
+
+func (r *Runner) reconcile() {
+	ctx, cancel := context.WithTimeout(context.Background(), r.source.Interval())
+	defer cancel()
+
+	backendConfigs, err := Resolve(ctx, r.resolver, r.source)
+	if err != nil {
+		r.logger.Error("resolve-failed", err, lager.Data{"name": r.source.Name, "type": r.source.RecordType()})
+		return
+	}
+
+	added, removed := r.backends.Reconcile(backendConfigs, r.logger, r.configure)
+	for _, backend := range added {
+		r.logger.Info("backend-added", lager.Data{"backend": backend.AsJSON()})
+	}
+	for _, backend := range removed {
+		r.logger.Info("backend-removed", lager.Data{"backend": backend.AsJSON()})
+		backend.SeverConnections(domain.CloseReasonBackendRemoved)
+	}
+}
@@ -0,0 +1,108 @@
+package discovery_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/discovery"
+	"github.com/cloudfoundry-incubator/switchboard/discovery/discoveryfakes"
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+var _ = Describe("Runner", func() {
+	var (
+		resolver   *discoveryfakes.FakeResolver
+		backends   *domain.BackendSet
+		configured chan string
+		logger     *lagertest.TestLogger
+		process    ifrit.Process
+	)
+
+	names := func() []string {
+		var names []string
+		for _, backend := range backends.All() {
+			names = append(names, backend.AsJSON().Name)
+		}
+		return names
+	}
+
+	resolves := func(ips ...string) {
+		var parsed []net.IP
+		for _, ip := range ips {
+			parsed = append(parsed, net.ParseIP(ip))
+		}
+		resolver.LookupIPReturns(parsed, nil)
+	}
+
+	BeforeEach(func() {
+		resolver = new(discoveryfakes.FakeResolver)
+		backends = domain.NewBackendSet(nil)
+		configured = make(chan string, 10)
+		logger = lagertest.NewTestLogger("discovery test")
+
+		resolves("10.0.0.1", "10.0.0.2")
+	})
+
+	JustBeforeEach(func() {
+		runner := discovery.NewRunner(resolver, config.Discovery{
+			Name:           "mysql.service.internal",
+			Port:           3306,
+			StatusPort:     9200,
+			StatusEndpoint: "api/v1/status",
+			IntervalMillis: 50,
+		}, backends, func(backend *domain.Backend) {
+			configured <- backend.AsJSON().Name
+		}, logger)
+		process = ifrit.Invoke(runner)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+	})
+
+	It("resolves the backends before it is ready, and configures them", func() {
+		Expect(names()).To(Equal([]string{"10.0.0.1", "10.0.0.2"}))
+		Expect(configured).To(Receive(Equal("10.0.0.1")))
+		Expect(configured).To(Receive(Equal("10.0.0.2")))
+
+		_, network, host := resolver.LookupIPArgsForCall(0)
+		Expect(network).To(Equal("ip4"))
+		Expect(host).To(Equal("mysql.service.internal"))
+	})
+
+	It("keeps the backends that stay, and replaces the others", func() {
+		first := backends.All()
+
+		resolves("10.0.0.2", "10.0.0.3")
+
+		Eventually(names).Should(Equal([]string{"10.0.0.2", "10.0.0.3"}))
+		Expect(backends.All()[0]).To(BeIdenticalTo(first[1]))
+		Eventually(logger).Should(gbytes.Say("backend-removed.*10.0.0.1"))
+	})
+
+	It("keeps the backends when a lookup fails", func() {
+		resolver.LookupIPReturns(nil, errors.New("server misbehaving"))
+
+		Eventually(logger).Should(gbytes.Say("resolve-failed"))
+		Expect(names()).To(Equal([]string{"10.0.0.1", "10.0.0.2"}))
+	})
+
+	It("keeps the backends when DNS has no records", func() {
+		resolver.LookupIPStub = func(context.Context, string, string) ([]net.IP, error) {
+			return nil, nil
+		}
+
+		Eventually(logger).Should(gbytes.Say("no A records for mysql.service.internal"))
+		Expect(names()).To(Equal([]string{"10.0.0.1", "10.0.0.2"}))
+	})
+})
@@ -4,7 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"code.cloudfoundry.org/lager/v3"
 )
@@ -22,6 +25,13 @@ type Backend struct {
 	bridges        Bridges
 	name           string
 	healthy        bool
+	breaker        *circuitBreaker
+	onCircuitOpen  []*func()
+	socketOptions  *SocketOptions
+	tap            Tap
+	dialStats      DialStats
+	labels         map[string]string
+	damper         *flapDamper
 }
 
 type BackendJSON struct {
@@ -31,6 +41,9 @@ type BackendJSON struct {
 	Healthy             bool   `json:"healthy"`
 	Name                string `json:"name"`
 	CurrentSessionCount uint   `json:"currentSessionCount"`
+
+	Labels           map[string]string `json:"labels,omitempty"`
+	QuarantinedUntil *time.Time        `json:"quarantinedUntil,omitempty"`
 }
 
 func NewBackend(
@@ -49,6 +62,7 @@ func NewBackend(
 		statusEndpoint: statusEndpoint,
 		logger:         logger,
 		bridges:        BridgesProvider(logger),
+		dialStats:      newDialStats(),
 	}
 }
 
@@ -57,30 +71,255 @@ func (b *Backend) HealthcheckUrls(useTLS bool) []string {
 	defer b.mutex.RUnlock()
 
 	if useTLS {
-		return []string{fmt.Sprintf("https://%s:%d/%s", b.host, b.statusPort, b.statusEndpoint),
-			fmt.Sprintf("http://%s:%d/%s", b.host, 9200, b.statusEndpoint)}
+		return []string{fmt.Sprintf("https://%s/%s", hostPort(b.host, b.statusPort), b.statusEndpoint),
+			fmt.Sprintf("http://%s/%s", hostPort(b.host, 9200), b.statusEndpoint)}
+	}
+	return []string{fmt.Sprintf("http://%s/%s", hostPort(b.host, b.statusPort), b.statusEndpoint)}
+}
+
+// AgentURL returns the URL of path on the backend's galera-agent. Unlike
+// HealthcheckUrls, it has no plaintext fallback when useTLS is set.
+func (b *Backend) AgentURL(useTLS bool, path string) string {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
 	}
-	return []string{fmt.Sprintf("http://%s:%d/%s", b.host, b.statusPort, b.statusEndpoint)}
+	return fmt.Sprintf("%s://%s/%s", scheme, hostPort(b.host, b.statusPort), path)
+}
+
+// hostPort joins host and port, bracketing IPv6 addresses.
+func hostPort(host string, port uint) string {
+	return net.JoinHostPort(host, strconv.FormatUint(uint64(port), 10))
+}
+
+// Bridge proxies clientConn to the backend until either side disconnects or
+// the session is severed, and returns the statistics of the session.
+func (b *Backend) Bridge(clientConn net.Conn) (SessionStats, error) {
+	backendConn, err := b.Connect()
+	if err != nil {
+		return SessionStats{}, err
+	}
+
+	return b.BridgeConnection(clientConn, backendConn), nil
 }
 
-func (b *Backend) Bridge(clientConn net.Conn) error {
-	backendAddr := fmt.Sprintf("%s:%d", b.host, b.port)
+// Connect dials the backend, counting the dial towards the backend's dial
+// statistics and circuit breaker.
+func (b *Backend) Connect() (net.Conn, error) {
+	backendAddr := hostPort(b.host, b.port)
 
-	backendConn, err := Dialer("tcp", backendAddr)
+	dial := Dialer
+	if socketOptions := b.configuredSocketOptions(); socketOptions != nil {
+		dial = socketOptions.Dial
+	}
+
+	start := time.Now()
+	backendConn, err := dial("tcp", backendAddr)
+	b.observeDial(time.Since(start), err)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error establishing connection to backend: %s", err))
+		b.recordDialFailure()
+		return nil, errors.New(fmt.Sprintf("Error establishing connection to backend: %s", err))
+	}
+	b.recordDialSuccess()
+
+	return backendConn, nil
+}
+
+// BridgeConnection proxies clientConn to backendConn, a connection obtained
+// from Connect, like Bridge.
+func (b *Backend) BridgeConnection(clientConn, backendConn net.Conn) SessionStats {
+	socketOptions := b.configuredSocketOptions()
+	if socketOptions != nil {
+		if err := socketOptions.Apply(clientConn); err != nil {
+			b.logger.Error("Failed to set client socket options", err)
+		}
+	}
+
+	if tap := b.configuredTap(); tap != nil {
+		if session := tap.Observe(b.name, clientConn.RemoteAddr(), backendConn.RemoteAddr()); session != nil {
+			defer session.Close()
+			clientConn = tappedConn{Conn: clientConn, observe: session.FromClient}
+			backendConn = tappedConn{Conn: backendConn, observe: session.FromBackend}
+		}
+	}
+
+	var lastRead *atomic.Int64
+	if socketOptions != nil && socketOptions.IdleTimeout > 0 {
+		lastRead = new(atomic.Int64)
+		lastRead.Store(time.Now().UnixNano())
+		clientConn = idleConn{Conn: clientConn, lastRead: lastRead}
+		backendConn = idleConn{Conn: backendConn, lastRead: lastRead}
 	}
 
 	bridge := b.bridges.Create(clientConn, backendConn)
-	bridge.Connect()
+	if lastRead != nil {
+		done := make(chan struct{})
+		defer close(done)
+		go closeWhenIdle(bridge, lastRead, socketOptions.IdleTimeout, done)
+	}
+	stats := bridge.Connect()
 	_ = b.bridges.Remove(bridge) //untested
 
-	return nil
+	return stats
 }
 
-func (b *Backend) SeverConnections() {
-	b.logger.Info(fmt.Sprintf("Severing all connections to %s at %s:%d", b.name, b.host, b.port))
-	b.bridges.RemoveAndCloseAll()
+// SetLabels records locality labels, such as the availability zone, that
+// backend selection can prefer.
+func (b *Backend) SetLabels(labels map[string]string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.labels = make(map[string]string, len(labels))
+	for k, v := range labels {
+		b.labels[k] = v
+	}
+}
+
+func (b *Backend) Label(key string) string {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.labels[key]
+}
+
+// SetSocketOptions makes Bridge dial the backend with o and apply o to the
+// client connection. Without options, Bridge dials with Dialer.
+func (b *Backend) SetSocketOptions(o SocketOptions) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.socketOptions = &o
+}
+
+func (b *Backend) configuredSocketOptions() *SocketOptions {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.socketOptions
+}
+
+// SetTap makes every new session bridged to the backend visible to t.
+func (b *Backend) SetTap(t Tap) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.tap = t
+}
+
+func (b *Backend) configuredTap() Tap {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.tap
+}
+
+// DialStats returns the latency and failures of dials to the backend.
+func (b *Backend) DialStats() DialStats {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.dialStats.copy()
+}
+
+func (b *Backend) observeDial(d time.Duration, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.dialStats.observe(d, err)
+}
+
+// EnableCircuitBreaker marks the backend unhealthy once failures dials to it
+// fail within window, without waiting for the next healthcheck.
+func (b *Backend) EnableCircuitBreaker(failures int, window time.Duration) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.breaker = newCircuitBreaker(failures, window)
+}
+
+// OnCircuitOpen registers f to be called whenever the circuit breaker opens,
+// until unregister is called.
+func (b *Backend) OnCircuitOpen(f func()) (unregister func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	callback := &f
+	b.onCircuitOpen = append(b.onCircuitOpen, callback)
+
+	return func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		// Copied rather than modified in place, as recordDialFailure calls
+		// the callbacks without holding the mutex.
+		callbacks := make([]*func(), 0, len(b.onCircuitOpen))
+		for _, c := range b.onCircuitOpen {
+			if c != callback {
+				callbacks = append(callbacks, c)
+			}
+		}
+		b.onCircuitOpen = callbacks
+	}
+}
+
+func (b *Backend) CircuitOpen() bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.breaker != nil && b.breaker.state == circuitOpen
+}
+
+// ProbeCircuit dials the backend once while its circuit breaker is open, as
+// a successful healthcheck only shows that the galera-agent answers. A
+// successful dial closes the breaker. It reports whether the breaker is
+// closed, counting a dial that takes longer than timeout as failed.
+func (b *Backend) ProbeCircuit(timeout time.Duration) bool {
+	if !b.CircuitOpen() {
+		return true
+	}
+
+	dialed := make(chan error, 1)
+	go func() {
+		conn, err := b.Connect()
+		if err == nil {
+			conn.Close()
+		}
+		dialed <- err
+	}()
+
+	select {
+	case err := <-dialed:
+		if err != nil {
+			return false
+		}
+		b.logger.Info("Circuit breaker closed after successful trial dial", lager.Data{"backend": b.name})
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (b *Backend) recordDialFailure() {
+	b.mutex.Lock()
+	if b.breaker == nil || !b.breaker.recordFailure(time.Now()) {
+		b.mutex.Unlock()
+		return
+	}
+	if b.healthy {
+		b.recordFlap()
+	}
+	b.healthy = false
+	callbacks := b.onCircuitOpen
+	b.mutex.Unlock()
+
+	b.logger.Info("Circuit breaker opened after repeated dial failures", lager.Data{"backend": b.name})
+	for _, f := range callbacks {
+		(*f)()
+	}
+}
+
+func (b *Backend) recordDialSuccess() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.breaker != nil {
+		b.breaker.recordSuccess()
+	}
+}
+
+func (b *Backend) SeverConnections(reason CloseReason) {
+	b.logger.Info(fmt.Sprintf("Severing all connections to %s at %s", b.name, hostPort(b.host, b.port)), lager.Data{"reason": reason})
+	b.bridges.RemoveAndCloseAll(reason)
 }
 
 func (b *Backend) SetHealthy() {
@@ -100,9 +339,64 @@ func (b *Backend) SetUnhealthy() {
 
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
+	if b.healthy {
+		b.recordFlap()
+	}
 	b.healthy = false
 }
 
+// EnableFlapDamping quarantines the backend once it becomes unhealthy flaps
+// times within window. The first quarantine lasts quarantine, and each
+// following one twice as long as the last, up to maxQuarantine.
+func (b *Backend) EnableFlapDamping(flaps int, window, quarantine, maxQuarantine time.Duration) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.damper = newFlapDamper(flaps, window, quarantine, maxQuarantine)
+}
+
+// Quarantined reports whether the backend is excluded from writer selection
+// because it has been flapping.
+func (b *Backend) Quarantined() bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return b.damper != nil && b.damper.quarantined(time.Now())
+}
+
+// ReleaseQuarantine ends the backend's quarantine early and resets its
+// backoff. It returns false if the backend was not quarantined.
+func (b *Backend) ReleaseQuarantine() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.damper == nil || !b.damper.quarantined(time.Now()) {
+		return false
+	}
+	b.damper.release()
+	b.logger.Info("Quarantine released", lager.Data{"backend": b.name})
+	return true
+}
+
+// Quarantines returns the number of times the backend has been quarantined.
+func (b *Backend) Quarantines() uint64 {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	if b.damper == nil {
+		return 0
+	}
+	return b.damper.quarantines
+}
+
+// recordFlap must be called with the mutex held.
+func (b *Backend) recordFlap() {
+	now := time.Now()
+	if b.damper == nil || !b.damper.recordFlap(now) {
+		return
+	}
+	b.logger.Info("Quarantined flapping backend", lager.Data{
+		"backend": b.name,
+		"until":   b.damper.quarantinedUntil.Format(time.RFC3339),
+	})
+}
+
 func (b *Backend) Healthy() bool {
 	b.mutex.RLock()
 	defer b.mutex.RUnlock()
@@ -113,6 +407,13 @@ func (b *Backend) Healthy() bool {
 func (b *Backend) AsJSON() BackendJSON {
 	b.mutex.RLock()
 	defer b.mutex.RUnlock()
+
+	var quarantinedUntil *time.Time
+	if b.damper != nil && b.damper.quarantined(time.Now()) {
+		until := b.damper.quarantinedUntil
+		quarantinedUntil = &until
+	}
+
 	return BackendJSON{
 		Host:                b.host,
 		Port:                b.port,
@@ -120,5 +421,7 @@ func (b *Backend) AsJSON() BackendJSON {
 		Name:                b.name,
 		Healthy:             b.healthy,
 		CurrentSessionCount: b.bridges.Size(),
+		Labels:              b.labels,
+		QuarantinedUntil:    quarantinedUntil,
 	}
 }
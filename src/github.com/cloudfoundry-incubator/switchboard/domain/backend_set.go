@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	"github.com/cloudfoundry-incubator/switchboard/config"
+)
+
+// BackendSet is the backends of a cluster. Backends discovered through DNS
+// join and leave the set while the proxy runs, so it is safe for concurrent
+// use.
+type BackendSet struct {
+	mutex    sync.RWMutex
+	backends []*Backend
+}
+
+func NewBackendSet(backends []*Backend) *BackendSet {
+	return &BackendSet{backends: backends}
+}
+
+// All returns the backends in the set.
+func (s *BackendSet) All() []*Backend {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return append([]*Backend(nil), s.backends...)
+}
+
+// Reconcile makes the set hold one backend for each of backendConfigs, in
+// their order. Backends whose name, host and ports are unchanged are kept, so
+// that their health and sessions carry over; the others are created like
+// NewBackends does and passed to configure. It returns the backends it added
+// and removed.
+func (s *BackendSet) Reconcile(backendConfigs []config.Backend, logger lager.Logger, configure func(*Backend)) (added, removed []*Backend) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	current := make(map[string]*Backend, len(s.backends))
+	for _, backend := range s.backends {
+		current[backend.AsJSON().Name] = backend
+	}
+
+	backends := make([]*Backend, 0, len(backendConfigs))
+	for _, bc := range backendConfigs {
+		if backend, ok := current[bc.Name]; ok && sameAddress(backend.AsJSON(), bc) {
+			delete(current, bc.Name)
+			backends = append(backends, backend)
+			continue
+		}
+
+		backend := NewBackends([]config.Backend{bc}, logger)[0]
+		configure(backend)
+		added = append(added, backend)
+		backends = append(backends, backend)
+	}
+
+	for _, backend := range s.backends {
+		if current[backend.AsJSON().Name] == backend {
+			removed = append(removed, backend)
+		}
+	}
+
+	s.backends = backends
+	return added, removed
+}
+
+func sameAddress(j BackendJSON, bc config.Backend) bool {
+	return j.Host == bc.Host && j.Port == bc.Port && j.StatusPort == bc.StatusPort
+}
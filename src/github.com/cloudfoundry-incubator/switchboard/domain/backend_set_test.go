@@ -0,0 +1,80 @@
+package domain_test
+
+import (
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+var _ = Describe("BackendSet", func() {
+	var (
+		logger     *lagertest.TestLogger
+		set        *domain.BackendSet
+		configured []string
+	)
+
+	backendConfig := func(name, host string) config.Backend {
+		return config.Backend{Name: name, Host: host, Port: 3306, StatusPort: 9200, StatusEndpoint: "api/v1/status"}
+	}
+
+	configure := func(backend *domain.Backend) {
+		configured = append(configured, backend.AsJSON().Name)
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("BackendSet test")
+		configured = nil
+		set = domain.NewBackendSet(nil)
+		set.Reconcile([]config.Backend{
+			backendConfig("backend-0", "10.0.0.1"),
+			backendConfig("backend-1", "10.0.0.2"),
+		}, logger, configure)
+	})
+
+	It("creates and configures the backends it did not hold", func() {
+		Expect(set.All()).To(HaveLen(2))
+		Expect(set.All()[0].AsJSON().Host).To(Equal("10.0.0.1"))
+		Expect(configured).To(Equal([]string{"backend-0", "backend-1"}))
+	})
+
+	It("keeps unchanged backends and replaces or removes the others", func() {
+		before := set.All()
+		configured = nil
+
+		added, removed := set.Reconcile([]config.Backend{
+			backendConfig("backend-1", "10.0.0.2"),
+			backendConfig("backend-2", "10.0.0.3"),
+		}, logger, configure)
+
+		after := set.All()
+		Expect(after).To(HaveLen(2))
+		Expect(after[0]).To(BeIdenticalTo(before[1]))
+		Expect(added).To(Equal([]*domain.Backend{after[1]}))
+		Expect(removed).To(Equal([]*domain.Backend{before[0]}))
+		Expect(configured).To(Equal([]string{"backend-2"}))
+	})
+
+	It("replaces a backend whose address changed", func() {
+		before := set.All()
+
+		added, removed := set.Reconcile([]config.Backend{
+			backendConfig("backend-0", "10.0.0.9"),
+			backendConfig("backend-1", "10.0.0.2"),
+		}, logger, configure)
+
+		Expect(removed).To(Equal([]*domain.Backend{before[0]}))
+		Expect(added).To(HaveLen(1))
+		Expect(added[0].AsJSON().Host).To(Equal("10.0.0.9"))
+		Expect(set.All()[0]).To(BeIdenticalTo(added[0]))
+	})
+
+	It("returns a copy of its backends", func() {
+		backends := set.All()
+		backends[0] = nil
+
+		Expect(set.All()[0]).NotTo(BeNil())
+	})
+})
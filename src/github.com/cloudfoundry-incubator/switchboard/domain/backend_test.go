@@ -1,7 +1,9 @@
 package domain_test
 
 import (
+	"errors"
 	"net"
+	"time"
 
 	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/lager/v3/lagertest"
@@ -53,12 +55,39 @@ var _ = Describe("Backend", func() {
 				Expect(healthcheckURLs[0]).To(Equal("http://1.2.3.4:9902/status"))
 			})
 		})
+		When("the backend has an IPv6 address", func() {
+			It("brackets the address", func() {
+				backend = domain.NewBackend("backend-0", "fd00::1", 3306, 9902, "status", lagertest.NewTestLogger("Backend test"))
+				Expect(backend.HealthcheckUrls(false)).To(Equal([]string{"http://[fd00::1]:9902/status"}))
+				Expect(backend.AgentURL(true, "api/v1/fence")).To(Equal("https://[fd00::1]:9902/api/v1/fence"))
+			})
+		})
+	})
+
+	Describe("AgentURL", func() {
+		It("uses the status port without a plaintext fallback", func() {
+			Expect(backend.AgentURL(true, "api/v1/fence")).To(Equal("https://1.2.3.4:9902/api/v1/fence"))
+			Expect(backend.AgentURL(false, "api/v1/fence")).To(Equal("http://1.2.3.4:9902/api/v1/fence"))
+		})
+	})
+
+	Describe("SetLabels", func() {
+		It("exposes the labels and includes them in the JSON", func() {
+			labels := map[string]string{"az": "z1"}
+			backend.SetLabels(labels)
+			labels["az"] = "z2"
+
+			Expect(backend.Label("az")).To(Equal("z1"))
+			Expect(backend.Label("rack")).To(BeEmpty())
+			Expect(backend.AsJSON().Labels).To(Equal(map[string]string{"az": "z1"}))
+		})
 	})
 
 	Describe("SeverConnections", func() {
-		It("removes and closes all bridges", func() {
-			backend.SeverConnections()
+		It("removes and closes all bridges with the reason", func() {
+			backend.SeverConnections(domain.CloseReasonSeveredByFailover)
 			Expect(bridges.RemoveAndCloseAllCallCount()).To(Equal(1))
+			Expect(bridges.RemoveAndCloseAllArgsForCall(0)).To(Equal(domain.CloseReasonSeveredByFailover))
 		})
 	})
 
@@ -77,10 +106,11 @@ var _ = Describe("Backend", func() {
 			connectReadyChan = make(chan interface{})
 			disconnectChan = make(chan interface{})
 
-			bridge.ConnectStub = func(connectReadyChan, disconnectChan chan interface{}) func() {
-				return func() {
+			bridge.ConnectStub = func(connectReadyChan, disconnectChan chan interface{}) func() domain.SessionStats {
+				return func() domain.SessionStats {
 					close(connectReadyChan)
 					<-disconnectChan
+					return domain.SessionStats{CloseReason: domain.CloseReasonClientClosed}
 				}
 			}(connectReadyChan, disconnectChan)
 
@@ -107,7 +137,7 @@ var _ = Describe("Backend", func() {
 			defer close(disconnectChan)
 
 			go func() {
-				err := backend.Bridge(clientConn)
+				_, err := backend.Bridge(clientConn)
 				Expect(err).NotTo(HaveOccurred())
 			}()
 
@@ -121,7 +151,7 @@ var _ = Describe("Backend", func() {
 			defer close(disconnectChan)
 
 			go func() {
-				err := backend.Bridge(clientConn)
+				_, err := backend.Bridge(clientConn)
 				Expect(err).NotTo(HaveOccurred())
 			}()
 
@@ -138,7 +168,7 @@ var _ = Describe("Backend", func() {
 		Context("when the bridge is disconnected", func() {
 			It("removes the bridge", func() {
 				go func() {
-					err := backend.Bridge(clientConn)
+					_, err := backend.Bridge(clientConn)
 					Expect(err).NotTo(HaveOccurred())
 				}()
 
@@ -151,6 +181,336 @@ var _ = Describe("Backend", func() {
 				Eventually(bridges.RemoveCallCount).Should(Equal(1))
 				Expect(bridges.RemoveArgsForCall(0)).To(Equal(bridge))
 			})
+
+			It("returns the session stats", func() {
+				close(disconnectChan)
+
+				stats, err := backend.Bridge(clientConn)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stats.CloseReason).To(Equal(domain.CloseReasonClientClosed))
+			})
+		})
+
+		Context("when a tap observes the session", func() {
+			var (
+				tap        *domainfakes.FakeTap
+				sessionTap *domainfakes.FakeSessionTap
+			)
+
+			BeforeEach(func() {
+				close(disconnectChan)
+
+				clientConn.RemoteAddrReturns(&net.TCPAddr{IP: net.ParseIP("10.0.0.9"), Port: 50000})
+				backendConn.RemoteAddrReturns(&net.TCPAddr{IP: net.ParseIP("1.2.3.4"), Port: 3306})
+				clientConn.ReadStub = func(p []byte) (int, error) { return copy(p, "query"), nil }
+				backendConn.ReadStub = func(p []byte) (int, error) { return copy(p, "result"), nil }
+
+				sessionTap = new(domainfakes.FakeSessionTap)
+				tap = new(domainfakes.FakeTap)
+				tap.ObserveReturns(sessionTap)
+				backend.SetTap(tap)
+			})
+
+			It("passes what either side sends to the tap and closes it with the session", func() {
+				_, err := backend.Bridge(clientConn)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(tap.ObserveCallCount()).To(Equal(1))
+				name, client, backendAddr := tap.ObserveArgsForCall(0)
+				Expect(name).To(Equal("backend-0"))
+				Expect(client.String()).To(Equal("10.0.0.9:50000"))
+				Expect(backendAddr.String()).To(Equal("1.2.3.4:3306"))
+
+				tappedClient, tappedBackend := bridges.CreateArgsForCall(0)
+				_, _ = tappedClient.Read(make([]byte, 16))
+				_, _ = tappedBackend.Read(make([]byte, 16))
+
+				Expect(sessionTap.FromClientCallCount()).To(Equal(1))
+				Expect(sessionTap.FromClientArgsForCall(0)).To(Equal([]byte("query")))
+				Expect(sessionTap.FromBackendCallCount()).To(Equal(1))
+				Expect(sessionTap.FromBackendArgsForCall(0)).To(Equal([]byte("result")))
+				Expect(sessionTap.CloseCallCount()).To(Equal(1))
+			})
+
+			It("leaves sessions the tap does not observe alone", func() {
+				tap.ObserveReturns(nil)
+
+				_, err := backend.Bridge(clientConn)
+				Expect(err).NotTo(HaveOccurred())
+
+				actualClientConn, actualBackendConn := bridges.CreateArgsForCall(0)
+				Expect(actualClientConn).To(Equal(clientConn))
+				Expect(actualBackendConn).To(Equal(backendConn))
+			})
+		})
+
+		Context("dial stats", func() {
+			BeforeEach(func() {
+				close(disconnectChan)
+			})
+
+			It("counts dials and failures", func() {
+				_, err := backend.Bridge(clientConn)
+				Expect(err).NotTo(HaveOccurred())
+
+				dialErr = errors.New("connection refused")
+				_, err = backend.Bridge(clientConn)
+				Expect(err).To(HaveOccurred())
+
+				stats := backend.DialStats()
+				Expect(stats.Count).To(Equal(uint64(2)))
+				Expect(stats.Failures).To(Equal(uint64(1)))
+				Expect(stats.Buckets[10]).To(Equal(uint64(2)))
+			})
+		})
+
+		Context("when the circuit breaker is enabled", func() {
+			var opened int
+
+			BeforeEach(func() {
+				close(disconnectChan)
+				opened = 0
+
+				backend.SetHealthy()
+				backend.EnableCircuitBreaker(3, time.Minute)
+				backend.OnCircuitOpen(func() { opened++ })
+			})
+
+			failDials := func(n int) {
+				dialErr = errors.New("connection refused")
+				for i := 0; i < n; i++ {
+					_, err := backend.Bridge(clientConn)
+					Expect(err).To(MatchError(ContainSubstring("Error establishing connection to backend")))
+				}
+			}
+
+			It("opens after repeated dial failures and marks the backend unhealthy", func() {
+				failDials(2)
+				Expect(backend.CircuitOpen()).To(BeFalse())
+				Expect(backend.Healthy()).To(BeTrue())
+
+				failDials(1)
+				Expect(backend.CircuitOpen()).To(BeTrue())
+				Expect(backend.Healthy()).To(BeFalse())
+				Expect(opened).To(Equal(1))
+
+				failDials(5)
+				Expect(opened).To(Equal(1))
+			})
+
+			It("forgets failures once a dial succeeds", func() {
+				failDials(2)
+
+				dialErr = nil
+				_, err := backend.Bridge(clientConn)
+				Expect(err).NotTo(HaveOccurred())
+
+				failDials(2)
+				Expect(backend.CircuitOpen()).To(BeFalse())
+			})
+
+			It("stays open while trial dials fail", func() {
+				failDials(3)
+
+				Expect(backend.ProbeCircuit(time.Second)).To(BeFalse())
+				Expect(backend.CircuitOpen()).To(BeTrue())
+				Expect(opened).To(Equal(1))
+			})
+
+			It("closes on a successful trial dial", func() {
+				failDials(3)
+
+				dialErr = nil
+				Expect(backend.ProbeCircuit(time.Second)).To(BeTrue())
+				Expect(backend.CircuitOpen()).To(BeFalse())
+
+				failDials(2)
+				Expect(backend.CircuitOpen()).To(BeFalse())
+			})
+
+			It("stops calling a callback once it is unregistered", func() {
+				var unregisteredOpened int
+				unregister := backend.OnCircuitOpen(func() { unregisteredOpened++ })
+				unregister()
+
+				failDials(3)
+				Expect(opened).To(Equal(1))
+				Expect(unregisteredOpened).To(BeZero())
+			})
+
+			It("does not dial while it is closed", func() {
+				dialErr = errors.New("connection refused")
+				Expect(backend.ProbeCircuit(time.Second)).To(BeTrue())
+				Expect(backend.DialStats().Count).To(BeZero())
+			})
+		})
+	})
+
+	Describe("circuit breaker window", func() {
+		AfterEach(func() {
+			domain.Dialer = net.Dial
+		})
+
+		It("only counts failures within the window", func() {
+			domain.Dialer = func(string, string) (net.Conn, error) {
+				return nil, errors.New("connection refused")
+			}
+			backend.EnableCircuitBreaker(2, 50*time.Millisecond)
+
+			_, _ = backend.Bridge(new(domainfakes.FakeConn))
+			time.Sleep(100 * time.Millisecond)
+			_, _ = backend.Bridge(new(domainfakes.FakeConn))
+			Expect(backend.CircuitOpen()).To(BeFalse())
+
+			_, _ = backend.Bridge(new(domainfakes.FakeConn))
+			Expect(backend.CircuitOpen()).To(BeTrue())
+		})
+	})
+
+	Describe("flap damping", func() {
+		flap := func(times int) {
+			for i := 0; i < times; i++ {
+				backend.SetHealthy()
+				backend.SetUnhealthy()
+			}
+		}
+
+		It("is off by default", func() {
+			flap(10)
+			Expect(backend.Quarantined()).To(BeFalse())
+			Expect(backend.AsJSON().QuarantinedUntil).To(BeNil())
+		})
+
+		Context("when enabled", func() {
+			BeforeEach(func() {
+				backend.EnableFlapDamping(3, time.Minute, 50*time.Millisecond, time.Second)
+			})
+
+			It("quarantines the backend once it has become unhealthy enough times", func() {
+				flap(2)
+				Expect(backend.Quarantined()).To(BeFalse())
+
+				flap(1)
+				Expect(backend.Quarantined()).To(BeTrue())
+				Expect(backend.Quarantines()).To(Equal(uint64(1)))
+				Expect(backend.AsJSON().QuarantinedUntil).NotTo(BeNil())
+			})
+
+			It("does not count repeated unhealthy healthchecks as flaps", func() {
+				backend.SetHealthy()
+				for i := 0; i < 5; i++ {
+					backend.SetUnhealthy()
+				}
+				Expect(backend.Quarantined()).To(BeFalse())
+			})
+
+			It("doubles the quarantine each time the backend is quarantined again", func() {
+				flap(3)
+				first := *backend.AsJSON().QuarantinedUntil
+				Eventually(backend.Quarantined).Should(BeFalse())
+
+				start := time.Now()
+				flap(3)
+				Expect(backend.AsJSON().QuarantinedUntil.Sub(start)).To(BeNumerically(">", 90*time.Millisecond))
+				Expect(backend.AsJSON().QuarantinedUntil.After(first)).To(BeTrue())
+			})
+
+			It("can be released early", func() {
+				Expect(backend.ReleaseQuarantine()).To(BeFalse())
+
+				flap(3)
+				Expect(backend.ReleaseQuarantine()).To(BeTrue())
+				Expect(backend.Quarantined()).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("with socket options", func() {
+		It("dials the backend with the options instead of Dialer", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+			go func() {
+				conn, err := listener.Accept()
+				if err == nil {
+					conn.Close()
+				}
+			}()
+
+			addr := listener.Addr().(*net.TCPAddr)
+			logger := lagertest.NewTestLogger("Backend test")
+			backend = domain.NewBackend("backend-0", "127.0.0.1", uint(addr.Port), 9902, "status", logger)
+			backend.SetSocketOptions(domain.SocketOptions{DialTimeout: time.Second, NoDelay: true})
+
+			domain.Dialer = func(string, string) (net.Conn, error) {
+				Fail("Dialer should not be used when socket options are set")
+				return nil, nil
+			}
+			defer func() { domain.Dialer = net.Dial }()
+
+			bridge := new(domainfakes.FakeBridge)
+			bridges.CreateReturns(bridge)
+
+			_, err = backend.Bridge(new(domainfakes.FakeConn))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, backendConn := bridges.CreateArgsForCall(0)
+			Expect(backendConn.RemoteAddr().String()).To(Equal(addr.String()))
+			backendConn.Close()
+		})
+
+		Context("when an idle timeout is set", func() {
+			var (
+				bridge *domainfakes.FakeBridge
+				closed chan domain.CloseReason
+			)
+
+			BeforeEach(func() {
+				closed = make(chan domain.CloseReason, 1)
+				bridge = new(domainfakes.FakeBridge)
+				bridge.CloseStub = func(reason domain.CloseReason) {
+					closed <- reason
+				}
+				bridge.ConnectStub = func() domain.SessionStats {
+					return domain.SessionStats{CloseReason: <-closed}
+				}
+				bridges.CreateReturns(bridge)
+
+				backend.SetSocketOptions(domain.SocketOptions{IdleTimeout: 200 * time.Millisecond})
+			})
+
+			It("closes sessions that stay idle for the timeout", func() {
+				stats := backend.BridgeConnection(new(domainfakes.FakeConn), new(domainfakes.FakeConn))
+				Expect(stats.CloseReason).To(Equal(domain.CloseReasonIdleTimeout))
+			})
+
+			It("keeps sessions open while either side sends data", func() {
+				clientConn := new(domainfakes.FakeConn)
+				clientConn.ReadStub = func(p []byte) (int, error) { return copy(p, "query"), nil }
+
+				created := make(chan net.Conn, 1)
+				bridges.CreateStub = func(client, _ net.Conn) domain.Bridge {
+					created <- client
+					return bridge
+				}
+
+				statsChan := make(chan domain.SessionStats, 1)
+				go func() {
+					defer GinkgoRecover()
+					statsChan <- backend.BridgeConnection(clientConn, new(domainfakes.FakeConn))
+				}()
+				idleClientConn := <-created
+
+				for i := 0; i < 8; i++ {
+					_, _ = idleClientConn.Read(make([]byte, 16))
+					time.Sleep(50 * time.Millisecond)
+				}
+				Expect(bridge.CloseCallCount()).To(BeZero())
+
+				var stats domain.SessionStats
+				Eventually(statsChan).Should(Receive(&stats))
+				Expect(stats.CloseReason).To(Equal(domain.CloseReasonIdleTimeout))
+			})
 		})
 	})
 })
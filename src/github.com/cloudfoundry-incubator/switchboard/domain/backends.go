@@ -9,14 +9,18 @@ var BackendProvider = NewBackend
 
 func NewBackends(backendConfigs []config.Backend, logger lager.Logger) (backends []*Backend) {
 	for _, bc := range backendConfigs {
-		backends = append(backends, BackendProvider(
+		backend := BackendProvider(
 			bc.Name,
 			bc.Host,
 			bc.Port,
 			bc.StatusPort,
 			bc.StatusEndpoint,
 			logger,
-		))
+		)
+		if len(bc.Labels) > 0 {
+			backend.SetLabels(bc.Labels)
+		}
+		backends = append(backends, backend)
 	}
 
 	return backends
@@ -4,18 +4,73 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"code.cloudfoundry.org/lager/v3"
 )
 
+// CloseReason records why a proxied session ended.
+type CloseReason string
+
+const (
+	CloseReasonClientClosed      CloseReason = "client_closed"
+	CloseReasonBackendClosed     CloseReason = "backend_closed"
+	CloseReasonSeveredByFailover CloseReason = "severed_by_failover"
+	CloseReasonTrafficDisabled   CloseReason = "traffic_disabled"
+	CloseReasonNoActiveBackend   CloseReason = "no_active_backend"
+	CloseReasonBackendDialFailed CloseReason = "backend_dial_failed"
+	CloseReasonSourceDenied      CloseReason = "source_address_denied"
+	CloseReasonHandshakeFailed   CloseReason = "handshake_failed"
+	CloseReasonUserQuotaExceeded CloseReason = "user_quota_exceeded"
+	CloseReasonSyncWaitFailed    CloseReason = "sync_wait_failed"
+	// CloseReasonTLSRefused ends a session that requested TLS, which would
+	// hide its user from the user quotas.
+	CloseReasonTLSRefused CloseReason = "tls_refused"
+	// CloseReasonMovedByFailover ends the part of a session bridged to the
+	// previous active backend, when the session continues on the new one.
+	CloseReasonMovedByFailover CloseReason = "moved_by_failover"
+	// CloseReasonReconnectFailed ends a session that could not continue on
+	// the new active backend.
+	CloseReasonReconnectFailed CloseReason = "reconnect_failed"
+	// CloseReasonBackendRemoved ends the sessions of a backend that DNS
+	// discovery no longer finds.
+	CloseReasonBackendRemoved CloseReason = "backend_removed"
+	// CloseReasonIdleTimeout ends a session that sent nothing in either
+	// direction for the idle timeout of its backend's socket options.
+	CloseReasonIdleTimeout CloseReason = "idle_timeout"
+)
+
+// Detachable is a client connection that can outlive its bridge. When the
+// bridge is severed by failover, Detach reports whether the session can
+// continue on another backend, in which case the client is not closed.
+type Detachable interface {
+	Detach() bool
+}
+
+// SessionStats describes a session once its bridge has disconnected.
+type SessionStats struct {
+	// Start is when the bridge connected. The bridge runner replaces it with
+	// when the client connection was accepted, so that the backend dial and
+	// the handshake count towards the session.
+	Start            time.Time
+	End              time.Time
+	BytesFromClient  int64
+	BytesFromBackend int64
+	CloseReason      CloseReason
+}
+
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Bridge
 type Bridge interface {
-	Connect()
-	Close()
+	Connect() SessionStats
+	Close(reason CloseReason)
 }
 
 type bridge struct {
 	done            chan struct{}
+	closeOnce       sync.Once
+	closeReason     CloseReason
 	client, backend net.Conn
 	logger          lager.Logger
 }
@@ -29,25 +84,54 @@ func NewBridge(client, backend net.Conn, logger lager.Logger) Bridge {
 	}
 }
 
-func (b bridge) Connect() {
+func (b *bridge) Connect() SessionStats {
 	b.logger.Debug(fmt.Sprintf("Session established %s", b))
 
-	defer b.logger.Debug(fmt.Sprintf("Session closed %s", b)) // defers are LIFO
-	defer b.client.Close()
-	defer b.backend.Close()
+	stats := SessionStats{Start: time.Now()}
+	var bytesFromClient, bytesFromBackend atomic.Int64
+
+	toBackend := b.safeCopy(b.backend, b.client, &bytesFromClient)
+	toClient := b.safeCopy(b.client, b.backend, &bytesFromBackend)
 
 	select {
-	case <-b.safeCopy(b.client, b.backend):
-	case <-b.safeCopy(b.backend, b.client):
+	case <-toBackend:
+		stats.CloseReason = CloseReasonClientClosed
+	case <-toClient:
+		stats.CloseReason = CloseReasonBackendClosed
 	case <-b.done:
+		stats.CloseReason = b.closeReason
+		if d, ok := b.client.(Detachable); ok && stats.CloseReason == CloseReasonSeveredByFailover && d.Detach() {
+			stats.CloseReason = CloseReasonMovedByFailover
+		}
+	}
+
+	b.backend.Close()
+	if stats.CloseReason != CloseReasonMovedByFailover {
+		b.client.Close()
 	}
+
+	// Closing both connections, or detaching the client, unblocks the
+	// remaining copy, so its byte count is final once it returns.
+	<-toBackend
+	<-toClient
+
+	stats.End = time.Now()
+	stats.BytesFromClient = bytesFromClient.Load()
+	stats.BytesFromBackend = bytesFromBackend.Load()
+
+	b.logger.Debug(fmt.Sprintf("Session closed %s", b), lager.Data{"reason": stats.CloseReason})
+
+	return stats
 }
 
-func (b bridge) Close() {
-	close(b.done)
+func (b *bridge) Close(reason CloseReason) {
+	b.closeOnce.Do(func() {
+		b.closeReason = reason
+		close(b.done)
+	})
 }
 
-func (b bridge) safeCopy(from, to net.Conn) chan struct{} {
+func (b *bridge) safeCopy(to, from net.Conn, counter *atomic.Int64) chan struct{} {
 	copyDone := make(chan struct{})
 	go func() {
 		// We don't want to capture the error because it's not meaningful -
@@ -58,13 +142,14 @@ func (b bridge) safeCopy(from, to net.Conn) chan struct{} {
 		// and correlating it to the (expected) closure of the other half of the
 		// channel. If it can't correlate then we have an actual error,
 		// otherwise we can safely ignore it.
-		_, _ = io.Copy(from, to)
+		n, _ := io.Copy(to, from)
+		counter.Add(n)
 
 		close(copyDone)
 	}()
 	return copyDone
 }
 
-func (b bridge) String() string {
+func (b *bridge) String() string {
 	return fmt.Sprintf("from client at %v to backend at %v", b.client.RemoteAddr(), b.backend.RemoteAddr())
 }
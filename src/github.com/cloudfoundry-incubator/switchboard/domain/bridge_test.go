@@ -3,6 +3,8 @@ package domain_test
 import (
 	"errors"
 	"io"
+	"net"
+	"time"
 
 	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/lager/v3/lagertest"
@@ -51,7 +53,7 @@ var _ = Describe("Bridge", func() {
 				}
 
 				go bridge.Connect()
-				defer bridge.Close()
+				defer bridge.Close(domain.CloseReasonTrafficDisabled)
 				Eventually(client.ReadCallCount).Should(Equal(2))
 				Eventually(backend.WriteCallCount).Should(Equal(1))
 				Expect(copiedToBackend).To(Equal(expectedText))
@@ -77,7 +79,7 @@ var _ = Describe("Bridge", func() {
 				}
 
 				go bridge.Connect()
-				defer bridge.Close()
+				defer bridge.Close(domain.CloseReasonTrafficDisabled)
 				Eventually(backend.ReadCallCount).Should(Equal(2))
 				Eventually(client.WriteCallCount).Should(Equal(1))
 				Expect(copiedToClient).To(Equal(expectedText))
@@ -135,10 +137,137 @@ var _ = Describe("Bridge", func() {
 		Context("When the connection is closed by calling Close()", func() {
 			It("Closes the client and backend", func() {
 				go bridge.Connect()
-				bridge.Close()
+				bridge.Close(domain.CloseReasonTrafficDisabled)
 				Eventually(backend.CloseCallCount).Should(Equal(1))
 				Eventually(client.CloseCallCount).Should(Equal(1))
 			})
 		})
 	})
+
+	Describe("session stats", func() {
+		var (
+			bridge                domain.Bridge
+			clientSide, proxySide net.Conn
+			backendSide, dialSide net.Conn
+			stats                 chan domain.SessionStats
+		)
+
+		BeforeEach(func() {
+			clientSide, proxySide = net.Pipe()
+			dialSide, backendSide = net.Pipe()
+
+			bridge = domain.NewBridge(proxySide, dialSide, lagertest.NewTestLogger("Bridge test"))
+
+			stats = make(chan domain.SessionStats, 1)
+			go func() { stats <- bridge.Connect() }()
+
+			_, err := clientSide.Write([]byte("hello"))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = io.ReadFull(backendSide, make([]byte, 5))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = backendSide.Write([]byte("hi!"))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = io.ReadFull(clientSide, make([]byte, 3))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("counts bytes in each direction", func() {
+			clientSide.Close()
+
+			var s domain.SessionStats
+			Eventually(stats).Should(Receive(&s))
+			Expect(s.BytesFromClient).To(BeEquivalentTo(5))
+			Expect(s.BytesFromBackend).To(BeEquivalentTo(3))
+			Expect(s.End).To(BeTemporally(">=", s.Start))
+		})
+
+		It("reports when the client closed the session", func() {
+			clientSide.Close()
+			Eventually(stats).Should(Receive(HaveField("CloseReason", domain.CloseReasonClientClosed)))
+		})
+
+		It("reports when the backend closed the session", func() {
+			backendSide.Close()
+			Eventually(stats).Should(Receive(HaveField("CloseReason", domain.CloseReasonBackendClosed)))
+		})
+
+		It("reports the reason the session was severed", func() {
+			bridge.Close(domain.CloseReasonSeveredByFailover)
+			bridge.Close(domain.CloseReasonTrafficDisabled)
+
+			Eventually(stats).Should(Receive(HaveField("CloseReason", domain.CloseReasonSeveredByFailover)))
+		})
+	})
+
+	Describe("detachable clients", func() {
+		var (
+			bridge                domain.Bridge
+			clientSide, proxySide net.Conn
+			backendSide, dialSide net.Conn
+			idle                  bool
+			stats                 chan domain.SessionStats
+		)
+
+		BeforeEach(func() {
+			clientSide, proxySide = net.Pipe()
+			dialSide, backendSide = net.Pipe()
+			idle = true
+
+			client := detachableConn{Conn: proxySide, idle: func() bool { return idle }}
+			bridge = domain.NewBridge(client, dialSide, lagertest.NewTestLogger("Bridge test"))
+
+			stats = make(chan domain.SessionStats, 1)
+			go func() { stats <- bridge.Connect() }()
+		})
+
+		AfterEach(func() {
+			clientSide.Close()
+			backendSide.Close()
+		})
+
+		It("stay open when they detach from a bridge severed by failover", func() {
+			bridge.Close(domain.CloseReasonSeveredByFailover)
+			Eventually(stats).Should(Receive(HaveField("CloseReason", domain.CloseReasonMovedByFailover)))
+
+			_, err := backendSide.Read(make([]byte, 1))
+			Expect(err).To(MatchError(io.EOF))
+
+			_ = proxySide.SetReadDeadline(time.Time{})
+			go func() { _, _ = clientSide.Write([]byte("x")) }()
+			_, err = proxySide.Read(make([]byte, 1))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("are closed when they cannot detach", func() {
+			idle = false
+			bridge.Close(domain.CloseReasonSeveredByFailover)
+			Eventually(stats).Should(Receive(HaveField("CloseReason", domain.CloseReasonSeveredByFailover)))
+
+			_, err := clientSide.Read(make([]byte, 1))
+			Expect(err).To(MatchError(io.EOF))
+		})
+
+		It("are closed when traffic is disabled", func() {
+			bridge.Close(domain.CloseReasonTrafficDisabled)
+			Eventually(stats).Should(Receive(HaveField("CloseReason", domain.CloseReasonTrafficDisabled)))
+
+			_, err := clientSide.Read(make([]byte, 1))
+			Expect(err).To(MatchError(io.EOF))
+		})
+	})
 })
+
+// detachableConn stops the bridge reading from it when it detaches.
+type detachableConn struct {
+	net.Conn
+	idle func() bool
+}
+
+func (c detachableConn) Detach() bool {
+	if !c.idle() {
+		return false
+	}
+	_ = c.Conn.SetReadDeadline(time.Now())
+	return true
+}
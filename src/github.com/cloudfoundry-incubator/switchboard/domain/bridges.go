@@ -14,7 +14,7 @@ var BridgeProvider = NewBridge
 type Bridges interface {
 	Create(clientConn, backendConn net.Conn) Bridge
 	Remove(bridge Bridge) error
-	RemoveAndCloseAll()
+	RemoveAndCloseAll(reason CloseReason)
 	Size() uint
 	Contains(bridge Bridge) bool
 }
@@ -55,12 +55,12 @@ func (b *concurrentBridges) Remove(bridge Bridge) error {
 	return nil
 }
 
-func (b *concurrentBridges) RemoveAndCloseAll() {
+func (b *concurrentBridges) RemoveAndCloseAll(reason CloseReason) {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
 	for _, bridge := range b.bridges {
-		bridge.Close()
+		bridge.Close(reason)
 	}
 	b.bridges = []Bridge{}
 }
@@ -66,7 +66,7 @@ var _ = Describe("Bridges", func() {
 
 			go func() {
 				<-readySetGo
-				bridges.RemoveAndCloseAll()
+				bridges.RemoveAndCloseAll(domain.CloseReasonTrafficDisabled)
 				close(doneChans[4])
 			}()
 
@@ -111,15 +111,16 @@ var _ = Describe("Bridges", func() {
 		})
 
 		It("closes all bridges", func() {
-			bridges.RemoveAndCloseAll()
+			bridges.RemoveAndCloseAll(domain.CloseReasonTrafficDisabled)
 
 			Expect(bridge1.(*domainfakes.FakeBridge).CloseCallCount()).To(Equal(1))
 			Expect(bridge2.(*domainfakes.FakeBridge).CloseCallCount()).To(Equal(1))
 			Expect(bridge3.(*domainfakes.FakeBridge).CloseCallCount()).To(Equal(1))
+			Expect(bridge1.(*domainfakes.FakeBridge).CloseArgsForCall(0)).To(Equal(domain.CloseReasonTrafficDisabled))
 		})
 
 		It("removes all bridges", func() {
-			bridges.RemoveAndCloseAll()
+			bridges.RemoveAndCloseAll(domain.CloseReasonTrafficDisabled)
 
 			Expect(bridges.Size()).To(BeNumerically("==", 0))
 		})
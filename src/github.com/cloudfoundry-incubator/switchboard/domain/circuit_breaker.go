@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+)
+
+// circuitBreaker opens after threshold dial failures within window, and
+// closes on the next successful dial. A healthy galera-agent does not close
+// it, as the agent may answer while MySQL refuses connections.
+type circuitBreaker struct {
+	threshold int
+	window    time.Duration
+	failures  []time.Time
+	state     circuitState
+}
+
+func newCircuitBreaker(threshold int, window time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		window:    window,
+	}
+}
+
+// recordFailure returns true if the failure opened the breaker.
+func (cb *circuitBreaker) recordFailure(now time.Time) bool {
+	if cb.state == circuitOpen {
+		return false
+	}
+
+	cutoff := now.Add(-cb.window)
+	recent := cb.failures[:0]
+	for _, t := range cb.failures {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	cb.failures = append(recent, now)
+
+	if len(cb.failures) >= cb.threshold {
+		cb.trip()
+		return true
+	}
+	return false
+}
+
+func (cb *circuitBreaker) recordSuccess() {
+	cb.state = circuitClosed
+	cb.failures = cb.failures[:0]
+}
+
+func (cb *circuitBreaker) trip() {
+	cb.state = circuitOpen
+	cb.failures = cb.failures[:0]
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// DialLatencyBuckets are the upper bounds, in seconds, of the dial latency
+// histogram kept for each backend.
+var DialLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// DialStats summarizes the dials to a backend since the proxy started.
+type DialStats struct {
+	Count      uint64
+	Failures   uint64
+	SumSeconds float64
+	// Buckets counts the dials that took at most each of DialLatencyBuckets.
+	Buckets map[float64]uint64
+}
+
+func newDialStats() DialStats {
+	return DialStats{Buckets: make(map[float64]uint64, len(DialLatencyBuckets))}
+}
+
+func (s *DialStats) observe(d time.Duration, err error) {
+	seconds := d.Seconds()
+
+	s.Count++
+	s.SumSeconds += seconds
+	if err != nil {
+		s.Failures++
+	}
+
+	for _, bound := range DialLatencyBuckets {
+		if seconds <= bound {
+			s.Buckets[bound]++
+		}
+	}
+}
+
+func (s DialStats) copy() DialStats {
+	c := s
+	c.Buckets = make(map[float64]uint64, len(s.Buckets))
+	for k, v := range s.Buckets {
+		c.Buckets[k] = v
+	}
+	return c
+}
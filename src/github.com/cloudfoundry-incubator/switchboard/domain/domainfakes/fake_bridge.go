@@ -8,26 +8,35 @@ import (
 )
 
 type FakeBridge struct {
-	CloseStub        func()
+	CloseStub        func(domain.CloseReason)
 	closeMutex       sync.RWMutex
 	closeArgsForCall []struct {
+		arg1 domain.CloseReason
 	}
-	ConnectStub        func()
+	ConnectStub        func() domain.SessionStats
 	connectMutex       sync.RWMutex
 	connectArgsForCall []struct {
 	}
+	connectReturns struct {
+		result1 domain.SessionStats
+	}
+	connectReturnsOnCall map[int]struct {
+		result1 domain.SessionStats
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeBridge) Close() {
+func (fake *FakeBridge) Close(arg1 domain.CloseReason) {
 	fake.closeMutex.Lock()
 	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
-	}{})
-	fake.recordInvocation("Close", []interface{}{})
+		arg1 domain.CloseReason
+	}{arg1})
+	stub := fake.CloseStub
+	fake.recordInvocation("Close", []interface{}{arg1})
 	fake.closeMutex.Unlock()
-	if fake.CloseStub != nil {
-		fake.CloseStub()
+	if stub != nil {
+		fake.CloseStub(arg1)
 	}
 }
 
@@ -37,21 +46,35 @@ func (fake *FakeBridge) CloseCallCount() int {
 	return len(fake.closeArgsForCall)
 }
 
-func (fake *FakeBridge) CloseCalls(stub func()) {
+func (fake *FakeBridge) CloseCalls(stub func(domain.CloseReason)) {
 	fake.closeMutex.Lock()
 	defer fake.closeMutex.Unlock()
 	fake.CloseStub = stub
 }
 
-func (fake *FakeBridge) Connect() {
+func (fake *FakeBridge) CloseArgsForCall(i int) domain.CloseReason {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	argsForCall := fake.closeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeBridge) Connect() domain.SessionStats {
 	fake.connectMutex.Lock()
+	ret, specificReturn := fake.connectReturnsOnCall[len(fake.connectArgsForCall)]
 	fake.connectArgsForCall = append(fake.connectArgsForCall, struct {
 	}{})
+	stub := fake.ConnectStub
+	fakeReturns := fake.connectReturns
 	fake.recordInvocation("Connect", []interface{}{})
 	fake.connectMutex.Unlock()
-	if fake.ConnectStub != nil {
-		fake.ConnectStub()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
 	}
+	return fakeReturns.result1
 }
 
 func (fake *FakeBridge) ConnectCallCount() int {
@@ -60,19 +83,38 @@ func (fake *FakeBridge) ConnectCallCount() int {
 	return len(fake.connectArgsForCall)
 }
 
-func (fake *FakeBridge) ConnectCalls(stub func()) {
+func (fake *FakeBridge) ConnectCalls(stub func() domain.SessionStats) {
 	fake.connectMutex.Lock()
 	defer fake.connectMutex.Unlock()
 	fake.ConnectStub = stub
 }
 
+func (fake *FakeBridge) ConnectReturns(result1 domain.SessionStats) {
+	fake.connectMutex.Lock()
+	defer fake.connectMutex.Unlock()
+	fake.ConnectStub = nil
+	fake.connectReturns = struct {
+		result1 domain.SessionStats
+	}{result1}
+}
+
+func (fake *FakeBridge) ConnectReturnsOnCall(i int, result1 domain.SessionStats) {
+	fake.connectMutex.Lock()
+	defer fake.connectMutex.Unlock()
+	fake.ConnectStub = nil
+	if fake.connectReturnsOnCall == nil {
+		fake.connectReturnsOnCall = make(map[int]struct {
+			result1 domain.SessionStats
+		})
+	}
+	fake.connectReturnsOnCall[i] = struct {
+		result1 domain.SessionStats
+	}{result1}
+}
+
 func (fake *FakeBridge) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.closeMutex.RLock()
-	defer fake.closeMutex.RUnlock()
-	fake.connectMutex.RLock()
-	defer fake.connectMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -43,9 +43,10 @@ type FakeBridges struct {
 	removeReturnsOnCall map[int]struct {
 		result1 error
 	}
-	RemoveAndCloseAllStub        func()
+	RemoveAndCloseAllStub        func(domain.CloseReason)
 	removeAndCloseAllMutex       sync.RWMutex
 	removeAndCloseAllArgsForCall []struct {
+		arg1 domain.CloseReason
 	}
 	SizeStub        func() uint
 	sizeMutex       sync.RWMutex
@@ -67,15 +68,16 @@ func (fake *FakeBridges) Contains(arg1 domain.Bridge) bool {
 	fake.containsArgsForCall = append(fake.containsArgsForCall, struct {
 		arg1 domain.Bridge
 	}{arg1})
+	stub := fake.ContainsStub
+	fakeReturns := fake.containsReturns
 	fake.recordInvocation("Contains", []interface{}{arg1})
 	fake.containsMutex.Unlock()
-	if fake.ContainsStub != nil {
-		return fake.ContainsStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.containsReturns
 	return fakeReturns.result1
 }
 
@@ -128,15 +130,16 @@ func (fake *FakeBridges) Create(arg1 net.Conn, arg2 net.Conn) domain.Bridge {
 		arg1 net.Conn
 		arg2 net.Conn
 	}{arg1, arg2})
+	stub := fake.CreateStub
+	fakeReturns := fake.createReturns
 	fake.recordInvocation("Create", []interface{}{arg1, arg2})
 	fake.createMutex.Unlock()
-	if fake.CreateStub != nil {
-		return fake.CreateStub(arg1, arg2)
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.createReturns
 	return fakeReturns.result1
 }
 
@@ -188,15 +191,16 @@ func (fake *FakeBridges) Remove(arg1 domain.Bridge) error {
 	fake.removeArgsForCall = append(fake.removeArgsForCall, struct {
 		arg1 domain.Bridge
 	}{arg1})
+	stub := fake.RemoveStub
+	fakeReturns := fake.removeReturns
 	fake.recordInvocation("Remove", []interface{}{arg1})
 	fake.removeMutex.Unlock()
-	if fake.RemoveStub != nil {
-		return fake.RemoveStub(arg1)
+	if stub != nil {
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.removeReturns
 	return fakeReturns.result1
 }
 
@@ -242,14 +246,16 @@ func (fake *FakeBridges) RemoveReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeBridges) RemoveAndCloseAll() {
+func (fake *FakeBridges) RemoveAndCloseAll(arg1 domain.CloseReason) {
 	fake.removeAndCloseAllMutex.Lock()
 	fake.removeAndCloseAllArgsForCall = append(fake.removeAndCloseAllArgsForCall, struct {
-	}{})
-	fake.recordInvocation("RemoveAndCloseAll", []interface{}{})
+		arg1 domain.CloseReason
+	}{arg1})
+	stub := fake.RemoveAndCloseAllStub
+	fake.recordInvocation("RemoveAndCloseAll", []interface{}{arg1})
 	fake.removeAndCloseAllMutex.Unlock()
-	if fake.RemoveAndCloseAllStub != nil {
-		fake.RemoveAndCloseAllStub()
+	if stub != nil {
+		fake.RemoveAndCloseAllStub(arg1)
 	}
 }
 
@@ -259,26 +265,34 @@ func (fake *FakeBridges) RemoveAndCloseAllCallCount() int {
 	return len(fake.removeAndCloseAllArgsForCall)
 }
 
-func (fake *FakeBridges) RemoveAndCloseAllCalls(stub func()) {
+func (fake *FakeBridges) RemoveAndCloseAllCalls(stub func(domain.CloseReason)) {
 	fake.removeAndCloseAllMutex.Lock()
 	defer fake.removeAndCloseAllMutex.Unlock()
 	fake.RemoveAndCloseAllStub = stub
 }
 
+func (fake *FakeBridges) RemoveAndCloseAllArgsForCall(i int) domain.CloseReason {
+	fake.removeAndCloseAllMutex.RLock()
+	defer fake.removeAndCloseAllMutex.RUnlock()
+	argsForCall := fake.removeAndCloseAllArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeBridges) Size() uint {
 	fake.sizeMutex.Lock()
 	ret, specificReturn := fake.sizeReturnsOnCall[len(fake.sizeArgsForCall)]
 	fake.sizeArgsForCall = append(fake.sizeArgsForCall, struct {
 	}{})
+	stub := fake.SizeStub
+	fakeReturns := fake.sizeReturns
 	fake.recordInvocation("Size", []interface{}{})
 	fake.sizeMutex.Unlock()
-	if fake.SizeStub != nil {
-		return fake.SizeStub()
+	if stub != nil {
+		return stub()
 	}
 	if specificReturn {
 		return ret.result1
 	}
-	fakeReturns := fake.sizeReturns
 	return fakeReturns.result1
 }
 
@@ -320,16 +334,6 @@ func (fake *FakeBridges) SizeReturnsOnCall(i int, result1 uint) {
 func (fake *FakeBridges) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.containsMutex.RLock()
-	defer fake.containsMutex.RUnlock()
-	fake.createMutex.RLock()
-	defer fake.createMutex.RUnlock()
-	fake.removeMutex.RLock()
-	defer fake.removeMutex.RUnlock()
-	fake.removeAndCloseAllMutex.RLock()
-	defer fake.removeAndCloseAllMutex.RUnlock()
-	fake.sizeMutex.RLock()
-	defer fake.sizeMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
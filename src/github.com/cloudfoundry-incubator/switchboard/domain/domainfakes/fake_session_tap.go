@@ -0,0 +1,149 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package domainfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+type FakeSessionTap struct {
+	CloseStub        func()
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct {
+	}
+	FromBackendStub        func([]byte)
+	fromBackendMutex       sync.RWMutex
+	fromBackendArgsForCall []struct {
+		arg1 []byte
+	}
+	FromClientStub        func([]byte)
+	fromClientMutex       sync.RWMutex
+	fromClientArgsForCall []struct {
+		arg1 []byte
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSessionTap) Close() {
+	fake.closeMutex.Lock()
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct {
+	}{})
+	stub := fake.CloseStub
+	fake.recordInvocation("Close", []interface{}{})
+	fake.closeMutex.Unlock()
+	if stub != nil {
+		fake.CloseStub()
+	}
+}
+
+func (fake *FakeSessionTap) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *FakeSessionTap) CloseCalls(stub func()) {
+	fake.closeMutex.Lock()
+	defer fake.closeMutex.Unlock()
+	fake.CloseStub = stub
+}
+
+func (fake *FakeSessionTap) FromBackend(arg1 []byte) {
+	var arg1Copy []byte
+	if arg1 != nil {
+		arg1Copy = make([]byte, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.fromBackendMutex.Lock()
+	fake.fromBackendArgsForCall = append(fake.fromBackendArgsForCall, struct {
+		arg1 []byte
+	}{arg1Copy})
+	stub := fake.FromBackendStub
+	fake.recordInvocation("FromBackend", []interface{}{arg1Copy})
+	fake.fromBackendMutex.Unlock()
+	if stub != nil {
+		fake.FromBackendStub(arg1)
+	}
+}
+
+func (fake *FakeSessionTap) FromBackendCallCount() int {
+	fake.fromBackendMutex.RLock()
+	defer fake.fromBackendMutex.RUnlock()
+	return len(fake.fromBackendArgsForCall)
+}
+
+func (fake *FakeSessionTap) FromBackendCalls(stub func([]byte)) {
+	fake.fromBackendMutex.Lock()
+	defer fake.fromBackendMutex.Unlock()
+	fake.FromBackendStub = stub
+}
+
+func (fake *FakeSessionTap) FromBackendArgsForCall(i int) []byte {
+	fake.fromBackendMutex.RLock()
+	defer fake.fromBackendMutex.RUnlock()
+	argsForCall := fake.fromBackendArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSessionTap) FromClient(arg1 []byte) {
+	var arg1Copy []byte
+	if arg1 != nil {
+		arg1Copy = make([]byte, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.fromClientMutex.Lock()
+	fake.fromClientArgsForCall = append(fake.fromClientArgsForCall, struct {
+		arg1 []byte
+	}{arg1Copy})
+	stub := fake.FromClientStub
+	fake.recordInvocation("FromClient", []interface{}{arg1Copy})
+	fake.fromClientMutex.Unlock()
+	if stub != nil {
+		fake.FromClientStub(arg1)
+	}
+}
+
+func (fake *FakeSessionTap) FromClientCallCount() int {
+	fake.fromClientMutex.RLock()
+	defer fake.fromClientMutex.RUnlock()
+	return len(fake.fromClientArgsForCall)
+}
+
+func (fake *FakeSessionTap) FromClientCalls(stub func([]byte)) {
+	fake.fromClientMutex.Lock()
+	defer fake.fromClientMutex.Unlock()
+	fake.FromClientStub = stub
+}
+
+func (fake *FakeSessionTap) FromClientArgsForCall(i int) []byte {
+	fake.fromClientMutex.RLock()
+	defer fake.fromClientMutex.RUnlock()
+	argsForCall := fake.fromClientArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSessionTap) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeSessionTap) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ domain.SessionTap = new(FakeSessionTap)
@@ -0,0 +1,114 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package domainfakes
+
+import (
+	"net"
+	"sync"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+type FakeTap struct {
+	ObserveStub        func(string, net.Addr, net.Addr) domain.SessionTap
+	observeMutex       sync.RWMutex
+	observeArgsForCall []struct {
+		arg1 string
+		arg2 net.Addr
+		arg3 net.Addr
+	}
+	observeReturns struct {
+		result1 domain.SessionTap
+	}
+	observeReturnsOnCall map[int]struct {
+		result1 domain.SessionTap
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeTap) Observe(arg1 string, arg2 net.Addr, arg3 net.Addr) domain.SessionTap {
+	fake.observeMutex.Lock()
+	ret, specificReturn := fake.observeReturnsOnCall[len(fake.observeArgsForCall)]
+	fake.observeArgsForCall = append(fake.observeArgsForCall, struct {
+		arg1 string
+		arg2 net.Addr
+		arg3 net.Addr
+	}{arg1, arg2, arg3})
+	stub := fake.ObserveStub
+	fakeReturns := fake.observeReturns
+	fake.recordInvocation("Observe", []interface{}{arg1, arg2, arg3})
+	fake.observeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTap) ObserveCallCount() int {
+	fake.observeMutex.RLock()
+	defer fake.observeMutex.RUnlock()
+	return len(fake.observeArgsForCall)
+}
+
+func (fake *FakeTap) ObserveCalls(stub func(string, net.Addr, net.Addr) domain.SessionTap) {
+	fake.observeMutex.Lock()
+	defer fake.observeMutex.Unlock()
+	fake.ObserveStub = stub
+}
+
+func (fake *FakeTap) ObserveArgsForCall(i int) (string, net.Addr, net.Addr) {
+	fake.observeMutex.RLock()
+	defer fake.observeMutex.RUnlock()
+	argsForCall := fake.observeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeTap) ObserveReturns(result1 domain.SessionTap) {
+	fake.observeMutex.Lock()
+	defer fake.observeMutex.Unlock()
+	fake.ObserveStub = nil
+	fake.observeReturns = struct {
+		result1 domain.SessionTap
+	}{result1}
+}
+
+func (fake *FakeTap) ObserveReturnsOnCall(i int, result1 domain.SessionTap) {
+	fake.observeMutex.Lock()
+	defer fake.observeMutex.Unlock()
+	fake.ObserveStub = nil
+	if fake.observeReturnsOnCall == nil {
+		fake.observeReturnsOnCall = make(map[int]struct {
+			result1 domain.SessionTap
+		})
+	}
+	fake.observeReturnsOnCall[i] = struct {
+		result1 domain.SessionTap
+	}{result1}
+}
+
+func (fake *FakeTap) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeTap) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ domain.Tap = new(FakeTap)
@@ -0,0 +1,80 @@
+package domain
+
+import "time"
+
+// flapDamper quarantines a backend that becomes unhealthy threshold times
+// within window. Each quarantine lasts twice as long as the previous one, up
+// to maxQuarantine. The backoff starts over once the backend has gone
+// maxQuarantine after its last quarantine without being quarantined again.
+type flapDamper struct {
+	threshold        int
+	window           time.Duration
+	quarantine       time.Duration
+	maxQuarantine    time.Duration
+	flaps            []time.Time
+	quarantinedUntil time.Time
+	consecutive      int
+	quarantines      uint64
+}
+
+func newFlapDamper(threshold int, window, quarantine, maxQuarantine time.Duration) *flapDamper {
+	if maxQuarantine < quarantine {
+		maxQuarantine = quarantine
+	}
+
+	return &flapDamper{
+		threshold:     threshold,
+		window:        window,
+		quarantine:    quarantine,
+		maxQuarantine: maxQuarantine,
+	}
+}
+
+// recordFlap returns true if the flap quarantined the backend.
+func (d *flapDamper) recordFlap(now time.Time) bool {
+	if d.quarantined(now) {
+		return false
+	}
+
+	cutoff := now.Add(-d.window)
+	recent := d.flaps[:0]
+	for _, t := range d.flaps {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	d.flaps = append(recent, now)
+
+	if len(d.flaps) < d.threshold {
+		return false
+	}
+
+	if !d.quarantinedUntil.IsZero() && now.After(d.quarantinedUntil.Add(d.maxQuarantine)) {
+		d.consecutive = 0
+	}
+
+	duration := d.quarantine
+	for i := 0; i < d.consecutive && duration < d.maxQuarantine; i++ {
+		duration *= 2
+	}
+	if duration > d.maxQuarantine {
+		duration = d.maxQuarantine
+	}
+
+	d.quarantinedUntil = now.Add(duration)
+	d.consecutive++
+	d.quarantines++
+	d.flaps = d.flaps[:0]
+	return true
+}
+
+func (d *flapDamper) quarantined(now time.Time) bool {
+	return now.Before(d.quarantinedUntil)
+}
+
+// release ends the current quarantine and resets the backoff.
+func (d *flapDamper) release() {
+	d.quarantinedUntil = time.Time{}
+	d.consecutive = 0
+	d.flaps = d.flaps[:0]
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// idleConn records in lastRead when data was last read from Conn. Both sides
+// of a bridge share lastRead, so it tells when the session was last active.
+type idleConn struct {
+	net.Conn
+	lastRead *atomic.Int64
+}
+
+func (c idleConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.lastRead.Store(time.Now().UnixNano())
+	}
+	return n, err
+}
+
+// Detach detaches the wrapped connection, when it is Detachable.
+func (c idleConn) Detach() bool {
+	d, ok := c.Conn.(Detachable)
+	return ok && d.Detach()
+}
+
+// NetConn returns the wrapped connection.
+func (c idleConn) NetConn() net.Conn {
+	return c.Conn
+}
+
+// closeWhenIdle closes bridge with CloseReasonIdleTimeout once nothing has
+// been read for timeout, as recorded in lastRead, unless done is closed first.
+func closeWhenIdle(bridge Bridge, lastRead *atomic.Int64, timeout time.Duration, done <-chan struct{}) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			idle := time.Since(time.Unix(0, lastRead.Load()))
+			if idle >= timeout {
+				bridge.Close(CloseReasonIdleTimeout)
+				return
+			}
+			timer.Reset(timeout - idle)
+		case <-done:
+			return
+		}
+	}
+}
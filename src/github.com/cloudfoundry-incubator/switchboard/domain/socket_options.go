@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"net"
+	"time"
+)
+
+// SocketOptions tune the TCP connections on both sides of a bridge.
+type SocketOptions struct {
+	// DialTimeout bounds connecting to a backend. Zero leaves it to the
+	// operating system.
+	DialTimeout time.Duration
+	// KeepAlive is the TCP keepalive period. Zero uses the Go default and a
+	// negative value disables keepalives.
+	KeepAlive     time.Duration
+	NoDelay       bool
+	SendBuffer    int
+	ReceiveBuffer int
+	// IdleTimeout closes a bridged session once nothing has passed in
+	// either direction for that long. Zero never closes idle sessions.
+	IdleTimeout time.Duration
+}
+
+// Dial connects to address and applies the options to the connection.
+func (o SocketOptions) Dial(network, address string) (net.Conn, error) {
+	dialer := net.Dialer{
+		Timeout:   o.DialTimeout,
+		KeepAlive: o.KeepAlive,
+	}
+
+	conn, err := dialer.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := o.apply(conn, false); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Apply sets the options on an accepted connection. Connections other than
+// TCP are left unchanged.
+func (o SocketOptions) Apply(conn net.Conn) error {
+	return o.apply(conn, true)
+}
+
+func (o SocketOptions) apply(conn net.Conn, keepAlive bool) error {
+	// Connections wrapped to observe or track sessions expose what they
+	// wrap, as tls.Conn does.
+	for {
+		wrapped, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			break
+		}
+		conn = wrapped.NetConn()
+	}
+
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+
+	if err := tcpConn.SetNoDelay(o.NoDelay); err != nil {
+		return err
+	}
+
+	// Dialed connections get their keepalive settings from net.Dialer.
+	if keepAlive && o.KeepAlive != 0 {
+		if err := tcpConn.SetKeepAlive(o.KeepAlive > 0); err != nil {
+			return err
+		}
+		if o.KeepAlive > 0 {
+			if err := tcpConn.SetKeepAlivePeriod(o.KeepAlive); err != nil {
+				return err
+			}
+		}
+	}
+
+	if o.SendBuffer > 0 {
+		if err := tcpConn.SetWriteBuffer(o.SendBuffer); err != nil {
+			return err
+		}
+	}
+	if o.ReceiveBuffer > 0 {
+		if err := tcpConn.SetReadBuffer(o.ReceiveBuffer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
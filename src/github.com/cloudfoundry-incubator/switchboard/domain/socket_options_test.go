@@ -0,0 +1,115 @@
+package domain_test
+
+import (
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+var _ = Describe("SocketOptions", func() {
+	var (
+		listener net.Listener
+		accepted chan net.Conn
+	)
+
+	BeforeEach(func() {
+		var err error
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+
+		accepted = make(chan net.Conn, 1)
+		go func(listener net.Listener, accepted chan<- net.Conn) {
+			conn, err := listener.Accept()
+			if err == nil {
+				accepted <- conn
+			}
+		}(listener, accepted)
+	})
+
+	AfterEach(func() {
+		listener.Close()
+	})
+
+	options := domain.SocketOptions{
+		DialTimeout:   time.Second,
+		KeepAlive:     30 * time.Second,
+		NoDelay:       true,
+		SendBuffer:    64 * 1024,
+		ReceiveBuffer: 64 * 1024,
+	}
+
+	Describe("Dial", func() {
+		It("connects with the options applied", func() {
+			conn, err := options.Dial("tcp", listener.Addr().String())
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			Eventually(accepted).Should(Receive())
+		})
+
+		It("gives up once the dial timeout expires", func() {
+			o := options
+			o.DialTimeout = time.Nanosecond
+
+			_, err := o.Dial("tcp", listener.Addr().String())
+			Expect(err).To(HaveOccurred())
+
+			netErr, ok := err.(net.Error)
+			Expect(ok).To(BeTrue())
+			Expect(netErr.Timeout()).To(BeTrue())
+		})
+	})
+
+	Describe("Apply", func() {
+		It("applies the options to an accepted TCP connection", func() {
+			client, err := net.Dial("tcp", listener.Addr().String())
+			Expect(err).NotTo(HaveOccurred())
+			defer client.Close()
+
+			var server net.Conn
+			Eventually(accepted).Should(Receive(&server))
+			defer server.Close()
+
+			Expect(options.Apply(server)).To(Succeed())
+		})
+
+		It("can disable keepalives", func() {
+			client, err := net.Dial("tcp", listener.Addr().String())
+			Expect(err).NotTo(HaveOccurred())
+			defer client.Close()
+
+			o := options
+			o.KeepAlive = -1
+			Expect(o.Apply(client)).To(Succeed())
+		})
+
+		It("applies the options to the connection a wrapper exposes", func() {
+			client, err := net.Dial("tcp", listener.Addr().String())
+			Expect(err).NotTo(HaveOccurred())
+			client.Close()
+
+			// Options cannot be set on a closed connection.
+			Expect(options.Apply(wrappedConn{Conn: client})).To(HaveOccurred())
+		})
+
+		It("ignores connections that are not TCP", func() {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+
+			Expect(options.Apply(client)).To(Succeed())
+		})
+	})
+})
+
+type wrappedConn struct {
+	net.Conn
+}
+
+func (c wrappedConn) NetConn() net.Conn {
+	return c.Conn
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "net"
+
+// Tap observes the traffic of the sessions bridged to a backend, for example
+// to capture it.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Tap
+type Tap interface {
+	// Observe returns the observer of a new session, or nil when the session
+	// is not observed.
+	Observe(backendName string, client, backend net.Addr) SessionTap
+}
+
+// SessionTap receives a copy of everything the client and the backend of a
+// session send, until it is closed.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . SessionTap
+type SessionTap interface {
+	FromClient(p []byte)
+	FromBackend(p []byte)
+	Close()
+}
+
+// tappedConn passes everything read from Conn to observe.
+type tappedConn struct {
+	net.Conn
+	observe func(p []byte)
+}
+
+func (c tappedConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.observe(p[:n])
+	}
+	return n, err
+}
+
+// Detach detaches the tapped connection, when it is Detachable.
+func (c tappedConn) Detach() bool {
+	d, ok := c.Conn.(Detachable)
+	return ok && d.Detach()
+}
+
+// NetConn returns the tapped connection.
+func (c tappedConn) NetConn() net.Conn {
+	return c.Conn
+}
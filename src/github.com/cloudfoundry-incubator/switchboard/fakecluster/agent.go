@@ -0,0 +1,54 @@
+package fakecluster
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// NewAgentHandler serves the parts of the galera-agent API that switchboard
+// uses. A hung node never answers a status request until its state changes;
+// a slow node answers after slowDelay.
+func NewAgentHandler(node *Node, slowDelay time.Duration, logger lager.Logger) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/v1/status", func(w http.ResponseWriter, req *http.Request) {
+		for {
+			status, changed := node.snapshot()
+
+			switch status.State {
+			case StateHung:
+				select {
+				case <-changed:
+					continue
+				case <-req.Context().Done():
+					return
+				}
+			case StateSlow:
+				select {
+				case <-time.After(slowDelay):
+				case <-req.Context().Done():
+					return
+				}
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(status.AgentStatus())
+			return
+		}
+	})
+
+	mux.HandleFunc("PUT /api/v1/fence", func(w http.ResponseWriter, req *http.Request) {
+		node.SetFenced(true)
+		logger.Info("fenced", lager.Data{"node": node.Name()})
+	})
+
+	mux.HandleFunc("DELETE /api/v1/fence", func(w http.ResponseWriter, req *http.Request) {
+		node.SetFenced(false)
+		logger.Info("unfenced", lager.Data{"node": node.Name()})
+	})
+
+	return mux
+}
@@ -0,0 +1,97 @@
+package fakecluster_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/fakecluster"
+)
+
+var _ = Describe("AgentHandler", func() {
+	var (
+		node   *fakecluster.Node
+		server *httptest.Server
+		client *http.Client
+	)
+
+	BeforeEach(func() {
+		node = fakecluster.NewNode("fake-node-0", 0)
+		handler := fakecluster.NewAgentHandler(node, 200*time.Millisecond, lagertest.NewTestLogger("agent"))
+		server = httptest.NewServer(handler)
+		client = &http.Client{Timeout: time.Second}
+	})
+
+	AfterEach(func() {
+		server.CloseClientConnections()
+		server.Close()
+	})
+
+	status := func() (fakecluster.AgentStatus, error) {
+		var body fakecluster.AgentStatus
+		resp, err := client.Get(server.URL + "/api/v1/status")
+		if err != nil {
+			return body, err
+		}
+		defer resp.Body.Close()
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		return body, err
+	}
+
+	It("serves the node's status", func() {
+		node.SetState(fakecluster.StateDonor)
+
+		body, err := status()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal(fakecluster.AgentStatus{
+			WsrepLocalState:        2,
+			WsrepLocalStateComment: "Donor/Desynced",
+			WsrepLocalIndex:        0,
+			Healthy:                true,
+		}))
+	})
+
+	It("delays the status of a slow node", func() {
+		node.SetState(fakecluster.StateSlow)
+
+		start := time.Now()
+		_, err := status()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically(">=", 200*time.Millisecond))
+	})
+
+	It("does not answer while the node is hung", func() {
+		node.SetState(fakecluster.StateHung)
+
+		_, err := status()
+		Expect(err).To(MatchError(ContainSubstring("Timeout")))
+	})
+
+	It("answers a hung request once the node recovers", func() {
+		node.SetState(fakecluster.StateHung)
+		time.AfterFunc(100*time.Millisecond, func() { node.SetState(fakecluster.StateSynced) })
+
+		body, err := status()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body.Healthy).To(BeTrue())
+	})
+
+	It("fences and unfences the node", func() {
+		req, _ := http.NewRequest(http.MethodPut, server.URL+"/api/v1/fence", nil)
+		resp, err := client.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(node.Status().Fenced).To(BeTrue())
+
+		req, _ = http.NewRequest(http.MethodDelete, server.URL+"/api/v1/fence", nil)
+		resp, err = client.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(node.Status().Fenced).To(BeFalse())
+	})
+})
@@ -0,0 +1,245 @@
+package fakecluster
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+)
+
+// Protocol is what a fake backend speaks to the clients switchboard proxies.
+type Protocol string
+
+const (
+	// ProtocolEcho writes back everything a client sends.
+	ProtocolEcho Protocol = "echo"
+	// ProtocolMySQL sends a MySQL handshake, accepts any credentials and
+	// answers every command with an OK packet.
+	ProtocolMySQL Protocol = "mysql"
+)
+
+func ParseProtocol(s string) (Protocol, error) {
+	switch Protocol(s) {
+	case ProtocolEcho, ProtocolMySQL:
+		return Protocol(s), nil
+	}
+	return "", fmt.Errorf("unknown protocol %q, must be %q or %q", s, ProtocolEcho, ProtocolMySQL)
+}
+
+type BackendRunner struct {
+	address  string
+	node     *Node
+	protocol Protocol
+	logger   lager.Logger
+
+	connectionIDs atomic.Uint32
+	mutex         sync.Mutex
+	conns         map[net.Conn]struct{}
+}
+
+func NewBackendRunner(address string, node *Node, protocol Protocol, logger lager.Logger) *BackendRunner {
+	return &BackendRunner{
+		address:  address,
+		node:     node,
+		protocol: protocol,
+		logger:   logger,
+		conns:    make(map[net.Conn]struct{}),
+	}
+}
+
+func (r *BackendRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	listener, err := net.Listen("tcp", r.address)
+	if err != nil {
+		return err
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				errChan <- err
+				return
+			}
+			go r.serve(conn)
+		}
+	}()
+
+	r.logger.Info("backend-listening", lager.Data{"node": r.node.Name(), "address": r.address, "protocol": r.protocol})
+	close(ready)
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-signals:
+		listener.Close()
+		r.closeConnections()
+		return nil
+	}
+}
+
+func (r *BackendRunner) serve(conn net.Conn) {
+	r.track(conn)
+	defer r.untrack(conn)
+	defer conn.Close()
+
+	// A hung node accepts connections but says nothing until it recovers.
+	for {
+		status, changed := r.node.snapshot()
+		if status.State != StateHung {
+			break
+		}
+		<-changed
+	}
+
+	switch r.protocol {
+	case ProtocolMySQL:
+		err := serveMySQL(conn, r.connectionIDs.Add(1))
+		if err != nil && err != io.EOF {
+			r.logger.Debug("mysql-connection-closed", lager.Data{"node": r.node.Name(), "error": err.Error()})
+		}
+	default:
+		io.Copy(conn, conn)
+	}
+}
+
+func (r *BackendRunner) track(conn net.Conn) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.conns[conn] = struct{}{}
+}
+
+func (r *BackendRunner) untrack(conn net.Conn) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.conns, conn)
+}
+
+func (r *BackendRunner) closeConnections() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for conn := range r.conns {
+		conn.Close()
+	}
+}
+
+const (
+	comQuit = 0x01
+
+	clientLongPassword     = 0x00000001
+	clientConnectWithDB    = 0x00000008
+	clientProtocol41       = 0x00000200
+	clientTransactions     = 0x00002000
+	clientSecureConnection = 0x00008000
+	clientPluginAuth       = 0x00080000
+
+	serverStatusAutocommit = 0x0002
+
+	mysqlNativePassword = "mysql_native_password"
+)
+
+var scramble = []byte("fakeclusterscramble!")
+
+// serveMySQL speaks just enough of the MySQL protocol for a client to connect
+// and ping: it accepts any credentials and answers every command with OK.
+func serveMySQL(conn net.Conn, connectionID uint32) error {
+	reader := bufio.NewReader(conn)
+
+	if err := writePacket(conn, 0, handshake(connectionID)); err != nil {
+		return err
+	}
+
+	for {
+		sequence, payload, err := readPacket(reader)
+		if err != nil {
+			return err
+		}
+		// The handshake response has sequence 1; every command starts at 0.
+		if sequence == 0 && len(payload) > 0 && payload[0] == comQuit {
+			return nil
+		}
+		// Like a real server, ask clients that chose another auth plugin to
+		// switch to the one the account uses.
+		if sequence == 1 && requestsOtherAuthPlugin(payload) {
+			if err := writePacket(conn, sequence+1, authSwitchRequest()); err != nil {
+				return err
+			}
+			if sequence, _, err = readPacket(reader); err != nil {
+				return err
+			}
+		}
+		if err := writePacket(conn, sequence+1, okPacket()); err != nil {
+			return err
+		}
+	}
+}
+
+func requestsOtherAuthPlugin(handshakeResponse []byte) bool {
+	response, err := mysqlproto.ParseHandshakeResponse(handshakeResponse)
+	return err == nil &&
+		response.Capabilities&mysqlproto.ClientPluginAuth != 0 &&
+		response.AuthPluginName != mysqlNativePassword
+}
+
+func authSwitchRequest() []byte {
+	p := []byte{0xfe}
+	p = append(p, mysqlNativePassword...)
+	p = append(p, 0)
+	p = append(p, scramble...)
+	return append(p, 0)
+}
+
+func handshake(connectionID uint32) []byte {
+	capabilities := uint32(clientLongPassword | clientConnectWithDB | clientProtocol41 |
+		clientTransactions | clientSecureConnection | clientPluginAuth)
+	p := []byte{10}
+	p = append(p, "8.0.0-fake-cluster"...)
+	p = append(p, 0)
+	p = binary.LittleEndian.AppendUint32(p, connectionID)
+	p = append(p, scramble[:8]...)
+	p = append(p, 0)
+	p = binary.LittleEndian.AppendUint16(p, uint16(capabilities))
+	p = append(p, 0xff) // utf8mb4_0900_ai_ci
+	p = binary.LittleEndian.AppendUint16(p, serverStatusAutocommit)
+	p = binary.LittleEndian.AppendUint16(p, uint16(capabilities>>16))
+	p = append(p, byte(len(scramble)+1))
+	p = append(p, make([]byte, 10)...)
+	p = append(p, scramble[8:]...)
+	p = append(p, 0)
+	p = append(p, mysqlNativePassword...)
+	return append(p, 0)
+}
+
+func okPacket() []byte {
+	p := []byte{0x00, 0x00, 0x00}
+	p = binary.LittleEndian.AppendUint16(p, serverStatusAutocommit)
+	return binary.LittleEndian.AppendUint16(p, 0)
+}
+
+func writePacket(w io.Writer, sequence byte, payload []byte) error {
+	header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), sequence}
+	_, err := w.Write(append(header, payload...))
+	return err
+}
+
+func readPacket(r io.Reader) (byte, []byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[3], payload, nil
+}
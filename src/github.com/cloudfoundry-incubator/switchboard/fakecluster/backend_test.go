@@ -0,0 +1,105 @@
+package fakecluster_test
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	_ "github.com/go-sql-driver/mysql"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+	ginkgomon "github.com/tedsuo/ifrit/ginkgomon_v2"
+
+	"github.com/cloudfoundry-incubator/switchboard/fakecluster"
+)
+
+var _ = Describe("BackendRunner", func() {
+	var (
+		node     *fakecluster.Node
+		address  string
+		protocol fakecluster.Protocol
+		process  ifrit.Process
+	)
+
+	BeforeEach(func() {
+		node = fakecluster.NewNode("fake-node-0", 0)
+		address = freeAddress()
+	})
+
+	JustBeforeEach(func() {
+		runner := fakecluster.NewBackendRunner(address, node, protocol, lagertest.NewTestLogger("backend"))
+		process = ginkgomon.Invoke(runner)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	Context("speaking echo", func() {
+		BeforeEach(func() {
+			protocol = fakecluster.ProtocolEcho
+		})
+
+		It("writes back what the client sends", func() {
+			conn, err := net.Dial("tcp", address)
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			_, err = conn.Write([]byte("hello"))
+			Expect(err).NotTo(HaveOccurred())
+
+			buf := make([]byte, 5)
+			_, err = io.ReadFull(conn, buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(buf)).To(Equal("hello"))
+		})
+
+		It("stays silent while the node is hung", func() {
+			node.SetState(fakecluster.StateHung)
+
+			conn, err := net.Dial("tcp", address)
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			_, err = conn.Write([]byte("hello"))
+			Expect(err).NotTo(HaveOccurred())
+
+			conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+			_, err = conn.Read(make([]byte, 5))
+			Expect(err).To(MatchError(ContainSubstring("timeout")))
+
+			node.SetState(fakecluster.StateSynced)
+
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			buf := make([]byte, 5)
+			_, err = io.ReadFull(conn, buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(buf)).To(Equal("hello"))
+		})
+	})
+
+	Context("speaking mysql", func() {
+		BeforeEach(func() {
+			protocol = fakecluster.ProtocolMySQL
+		})
+
+		It("lets a MySQL client connect and ping", func() {
+			db, err := sql.Open("mysql", fmt.Sprintf("anyone:anything@tcp(%s)/?timeout=1s", address))
+			Expect(err).NotTo(HaveOccurred())
+			defer db.Close()
+
+			Expect(db.Ping()).To(Succeed())
+		})
+	})
+})
+
+func freeAddress() string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	defer listener.Close()
+	return listener.Addr().String()
+}
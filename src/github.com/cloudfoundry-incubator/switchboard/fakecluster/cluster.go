@@ -0,0 +1,98 @@
+package fakecluster
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/tedsuo/ifrit/grouper"
+	"github.com/tedsuo/ifrit/http_server"
+
+	"github.com/cloudfoundry-incubator/switchboard/config"
+)
+
+type Config struct {
+	Host  string
+	Nodes int
+	// Node i listens on BackendPort+i and serves its agent on AgentPort+i.
+	BackendPort uint
+	AgentPort   uint
+	Protocol    Protocol
+	SlowDelay   time.Duration
+}
+
+type Cluster struct {
+	config Config
+	nodes  []*Node
+	logger lager.Logger
+}
+
+func NewCluster(cfg Config, logger lager.Logger) *Cluster {
+	nodes := make([]*Node, cfg.Nodes)
+	for i := range nodes {
+		nodes[i] = NewNode(fmt.Sprintf("fake-node-%d", i), uint(i))
+	}
+
+	return &Cluster{
+		config: cfg,
+		nodes:  nodes,
+		logger: logger,
+	}
+}
+
+func (c *Cluster) Nodes() []*Node {
+	return c.nodes
+}
+
+func (c *Cluster) Node(name string) *Node {
+	for _, n := range c.nodes {
+		if n.Name() == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// Members returns a backend and an agent runner for every node.
+func (c *Cluster) Members() grouper.Members {
+	var members grouper.Members
+	for i, n := range c.nodes {
+		backendAddress := c.address(c.config.BackendPort + uint(i))
+		agentAddress := c.address(c.config.AgentPort + uint(i))
+		logger := c.logger.Session(n.Name())
+
+		members = append(members,
+			grouper.Member{
+				Name:   n.Name() + "-backend",
+				Runner: NewBackendRunner(backendAddress, n, c.config.Protocol, logger),
+			},
+			grouper.Member{
+				Name:   n.Name() + "-agent",
+				Runner: http_server.New(agentAddress, NewAgentHandler(n, c.config.SlowDelay, logger)),
+			},
+		)
+	}
+	return members
+}
+
+// Backends describes the cluster in the form switchboard's Proxy.Backends
+// config expects.
+func (c *Cluster) Backends() []config.Backend {
+	backends := make([]config.Backend, len(c.nodes))
+	for i, n := range c.nodes {
+		backends[i] = config.Backend{
+			Host:           c.config.Host,
+			Port:           c.config.BackendPort + uint(i),
+			StatusPort:     c.config.AgentPort + uint(i),
+			StatusEndpoint: "api/v1/status",
+			Name:           n.Name(),
+		}
+	}
+	return backends
+}
+
+func (c *Cluster) address(port uint) string {
+	return net.JoinHostPort(c.config.Host, strconv.Itoa(int(port)))
+}
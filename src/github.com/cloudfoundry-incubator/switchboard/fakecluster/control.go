@@ -0,0 +1,76 @@
+package fakecluster
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// NodeUpdate is the body of PUT /nodes/{name}. Omitted fields are left alone.
+type NodeUpdate struct {
+	State *State `json:"state" yaml:"state"`
+	Index *uint  `json:"wsrep_local_index" yaml:"wsrep_local_index"`
+}
+
+func (u NodeUpdate) apply(n *Node) {
+	if u.State != nil {
+		n.SetState(*u.State)
+	}
+	if u.Index != nil {
+		n.SetIndex(*u.Index)
+	}
+}
+
+// NewControlHandler lets developers inspect and change node states while the
+// cluster runs:
+//
+//	GET /nodes
+//	GET /nodes/{name}
+//	PUT /nodes/{name}   {"state": "donor", "wsrep_local_index": 2}
+func NewControlHandler(cluster *Cluster, logger lager.Logger) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /nodes", func(w http.ResponseWriter, req *http.Request) {
+		statuses := make([]NodeStatus, 0, len(cluster.Nodes()))
+		for _, n := range cluster.Nodes() {
+			statuses = append(statuses, n.Status())
+		}
+		writeJSON(w, http.StatusOK, statuses)
+	})
+
+	mux.HandleFunc("GET /nodes/{name}", func(w http.ResponseWriter, req *http.Request) {
+		n := cluster.Node(req.PathValue("name"))
+		if n == nil {
+			http.NotFound(w, req)
+			return
+		}
+		writeJSON(w, http.StatusOK, n.Status())
+	})
+
+	mux.HandleFunc("PUT /nodes/{name}", func(w http.ResponseWriter, req *http.Request) {
+		n := cluster.Node(req.PathValue("name"))
+		if n == nil {
+			http.NotFound(w, req)
+			return
+		}
+
+		var update NodeUpdate
+		if err := json.NewDecoder(req.Body).Decode(&update); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		update.apply(n)
+		logger.Info("node-updated", lager.Data{"node": n.Status()})
+		writeJSON(w, http.StatusOK, n.Status())
+	})
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,74 @@
+package fakecluster_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/fakecluster"
+)
+
+var _ = Describe("ControlHandler", func() {
+	var (
+		cluster *fakecluster.Cluster
+		handler http.Handler
+	)
+
+	BeforeEach(func() {
+		logger := lagertest.NewTestLogger("control")
+		cluster = fakecluster.NewCluster(fakecluster.Config{Host: "127.0.0.1", Nodes: 2}, logger)
+		handler = fakecluster.NewControlHandler(cluster, logger)
+	})
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return recorder
+	}
+
+	It("lists every node", func() {
+		recorder := serve(http.MethodGet, "/nodes", "")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var statuses []fakecluster.NodeStatus
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &statuses)).To(Succeed())
+		Expect(statuses).To(Equal([]fakecluster.NodeStatus{
+			{Name: "fake-node-0", State: fakecluster.StateSynced, Index: 0},
+			{Name: "fake-node-1", State: fakecluster.StateSynced, Index: 1},
+		}))
+	})
+
+	It("updates a node", func() {
+		recorder := serve(http.MethodPut, "/nodes/fake-node-1", `{"state": "maintenance", "wsrep_local_index": 0}`)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		Expect(cluster.Node("fake-node-1").Status()).To(Equal(fakecluster.NodeStatus{
+			Name:  "fake-node-1",
+			State: fakecluster.StateMaintenance,
+			Index: 0,
+		}))
+	})
+
+	It("leaves fields missing from an update alone", func() {
+		serve(http.MethodPut, "/nodes/fake-node-1", `{"state": "donor"}`)
+
+		Expect(cluster.Node("fake-node-1").Status().Index).To(Equal(uint(1)))
+	})
+
+	It("rejects unknown states", func() {
+		recorder := serve(http.MethodPut, "/nodes/fake-node-1", `{"state": "bogus"}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(cluster.Node("fake-node-1").Status().State).To(Equal(fakecluster.StateSynced))
+	})
+
+	It("returns 404 for unknown nodes", func() {
+		Expect(serve(http.MethodGet, "/nodes/fake-node-9", "").Code).To(Equal(http.StatusNotFound))
+		Expect(serve(http.MethodPut, "/nodes/fake-node-9", `{"state": "donor"}`).Code).To(Equal(http.StatusNotFound))
+	})
+})
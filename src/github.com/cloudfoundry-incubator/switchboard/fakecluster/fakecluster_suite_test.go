@@ -0,0 +1,13 @@
+package fakecluster_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFakeCluster(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "FakeCluster Suite")
+}
@@ -0,0 +1,163 @@
+package fakecluster
+
+import (
+	"fmt"
+	"sync"
+)
+
+// State is the condition a fake node reports through its agent endpoint.
+type State string
+
+const (
+	StateSynced      State = "synced"
+	StateDonor       State = "donor"
+	StateJoining     State = "joining"
+	StateReadOnly    State = "read_only"
+	StateMaintenance State = "maintenance"
+	StateHung        State = "hung"
+	StateSlow        State = "slow"
+)
+
+var states = []State{
+	StateSynced,
+	StateDonor,
+	StateJoining,
+	StateReadOnly,
+	StateMaintenance,
+	StateHung,
+	StateSlow,
+}
+
+func ParseState(s string) (State, error) {
+	for _, state := range states {
+		if string(state) == s {
+			return state, nil
+		}
+	}
+	return "", fmt.Errorf("unknown state %q, must be one of %v", s, states)
+}
+
+// UnmarshalText lets scenario files and control requests use state names.
+func (s *State) UnmarshalText(text []byte) error {
+	state, err := ParseState(string(text))
+	if err != nil {
+		return err
+	}
+	*s = state
+	return nil
+}
+
+// Node is one member of the fake cluster. Its state decides what the node's
+// agent reports and whether its backend answers new connections.
+type Node struct {
+	mutex   sync.RWMutex
+	name    string
+	index   uint
+	state   State
+	fenced  bool
+	changed chan struct{}
+}
+
+func NewNode(name string, index uint) *Node {
+	return &Node{
+		name:    name,
+		index:   index,
+		state:   StateSynced,
+		changed: make(chan struct{}),
+	}
+}
+
+func (n *Node) Name() string {
+	return n.name
+}
+
+func (n *Node) SetState(state State) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if n.state == state {
+		return
+	}
+	n.state = state
+	n.notify()
+}
+
+func (n *Node) SetIndex(index uint) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.index = index
+}
+
+func (n *Node) SetFenced(fenced bool) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.fenced = fenced
+}
+
+func (n *Node) Status() NodeStatus {
+	status, _ := n.snapshot()
+	return status
+}
+
+// snapshot returns the node's status together with a channel that is closed
+// the next time its state changes, so that hung requests can be released.
+func (n *Node) snapshot() (NodeStatus, <-chan struct{}) {
+	n.mutex.RLock()
+	defer n.mutex.RUnlock()
+
+	return NodeStatus{
+		Name:   n.name,
+		State:  n.state,
+		Index:  n.index,
+		Fenced: n.fenced,
+	}, n.changed
+}
+
+func (n *Node) notify() {
+	close(n.changed)
+	n.changed = make(chan struct{})
+}
+
+type NodeStatus struct {
+	Name   string `json:"name"`
+	State  State  `json:"state"`
+	Index  uint   `json:"wsrep_local_index"`
+	Fenced bool   `json:"fenced"`
+}
+
+// AgentStatus is the body galera-agent serves on /api/v1/status.
+type AgentStatus struct {
+	WsrepLocalState        uint   `json:"wsrep_local_state"`
+	WsrepLocalStateComment string `json:"wsrep_local_state_comment"`
+	WsrepLocalIndex        uint   `json:"wsrep_local_index"`
+	Healthy                bool   `json:"healthy"`
+}
+
+// AgentStatus mirrors galera-agent's default settings: donors are available,
+// read-only nodes are not unless a proxy fenced them.
+func (s NodeStatus) AgentStatus() AgentStatus {
+	status := AgentStatus{
+		WsrepLocalState:        4,
+		WsrepLocalStateComment: "Synced",
+		WsrepLocalIndex:        s.Index,
+		Healthy:                true,
+	}
+
+	switch s.State {
+	case StateDonor:
+		status.WsrepLocalState = 2
+		status.WsrepLocalStateComment = "Donor/Desynced"
+	case StateJoining:
+		status.WsrepLocalState = 1
+		status.WsrepLocalStateComment = "Joining"
+		status.Healthy = false
+	case StateReadOnly:
+		status.Healthy = s.Fenced
+	case StateMaintenance:
+		status.Healthy = false
+	}
+
+	return status
+}
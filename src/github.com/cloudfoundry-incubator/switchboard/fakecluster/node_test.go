@@ -0,0 +1,63 @@
+package fakecluster_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/fakecluster"
+)
+
+var _ = Describe("Node", func() {
+	var node *fakecluster.Node
+
+	BeforeEach(func() {
+		node = fakecluster.NewNode("fake-node-1", 1)
+	})
+
+	It("starts synced", func() {
+		Expect(node.Status()).To(Equal(fakecluster.NodeStatus{
+			Name:  "fake-node-1",
+			State: fakecluster.StateSynced,
+			Index: 1,
+		}))
+	})
+
+	DescribeTable("reports the agent status of each state",
+		func(state fakecluster.State, wsrepState uint, comment string, healthy bool) {
+			node.SetState(state)
+
+			Expect(node.Status().AgentStatus()).To(Equal(fakecluster.AgentStatus{
+				WsrepLocalState:        wsrepState,
+				WsrepLocalStateComment: comment,
+				WsrepLocalIndex:        1,
+				Healthy:                healthy,
+			}))
+		},
+		Entry("synced", fakecluster.StateSynced, uint(4), "Synced", true),
+		Entry("donor", fakecluster.StateDonor, uint(2), "Donor/Desynced", true),
+		Entry("joining", fakecluster.StateJoining, uint(1), "Joining", false),
+		Entry("read only", fakecluster.StateReadOnly, uint(4), "Synced", false),
+		Entry("maintenance", fakecluster.StateMaintenance, uint(4), "Synced", false),
+		Entry("slow", fakecluster.StateSlow, uint(4), "Synced", true),
+	)
+
+	It("keeps a fenced read-only node healthy", func() {
+		node.SetState(fakecluster.StateReadOnly)
+		node.SetFenced(true)
+
+		Expect(node.Status().AgentStatus().Healthy).To(BeTrue())
+	})
+
+	It("reports a changed index", func() {
+		node.SetIndex(2)
+
+		Expect(node.Status().AgentStatus().WsrepLocalIndex).To(Equal(uint(2)))
+	})
+
+	Describe("ParseState", func() {
+		It("rejects unknown states", func() {
+			_, err := fakecluster.ParseState("bogus")
+			Expect(err).To(MatchError(ContainSubstring(`unknown state "bogus"`)))
+		})
+	})
+})
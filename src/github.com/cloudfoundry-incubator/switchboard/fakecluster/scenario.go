@@ -0,0 +1,109 @@
+package fakecluster
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is a script of node changes, e.g.
+//
+//	loop: true
+//	steps:
+//	- after: 30s
+//	  node: fake-node-0
+//	  state: hung
+//	- after: 10s
+//	  node: fake-node-0
+//	  state: synced
+type Scenario struct {
+	Loop  bool   `yaml:"loop"`
+	Steps []Step `yaml:"steps"`
+}
+
+// Step applies its update to Node once After has passed since the previous
+// step.
+type Step struct {
+	After      time.Duration `yaml:"after"`
+	Node       string        `yaml:"node"`
+	NodeUpdate `yaml:",inline"`
+}
+
+func LoadScenario(path string) (Scenario, error) {
+	var scenario Scenario
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return scenario, err
+	}
+
+	err = yaml.Unmarshal(contents, &scenario)
+	if err != nil {
+		return scenario, fmt.Errorf("parsing scenario %s: %w", path, err)
+	}
+
+	return scenario, nil
+}
+
+func (s Scenario) Validate(cluster *Cluster) error {
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("scenario has no steps")
+	}
+
+	var total time.Duration
+	for i, step := range s.Steps {
+		if cluster.Node(step.Node) == nil {
+			return fmt.Errorf("step %d: unknown node %q", i, step.Node)
+		}
+		total += step.After
+	}
+
+	if s.Loop && total == 0 {
+		return fmt.Errorf("a looping scenario must wait between steps")
+	}
+
+	return nil
+}
+
+type ScenarioRunner struct {
+	scenario Scenario
+	cluster  *Cluster
+	logger   lager.Logger
+}
+
+func NewScenarioRunner(scenario Scenario, cluster *Cluster, logger lager.Logger) *ScenarioRunner {
+	return &ScenarioRunner{
+		scenario: scenario,
+		cluster:  cluster,
+		logger:   logger,
+	}
+}
+
+func (r *ScenarioRunner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+
+	for {
+		for i, step := range r.scenario.Steps {
+			select {
+			case <-time.After(step.After):
+			case <-signals:
+				return nil
+			}
+
+			n := r.cluster.Node(step.Node)
+			step.apply(n)
+			r.logger.Info("step-applied", lager.Data{"step": i, "node": n.Status()})
+		}
+
+		if !r.scenario.Loop {
+			break
+		}
+	}
+
+	r.logger.Info("scenario-finished")
+	<-signals
+	return nil
+}
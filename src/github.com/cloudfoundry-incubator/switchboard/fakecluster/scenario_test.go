@@ -0,0 +1,121 @@
+package fakecluster_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+	ginkgomon "github.com/tedsuo/ifrit/ginkgomon_v2"
+
+	"github.com/cloudfoundry-incubator/switchboard/fakecluster"
+)
+
+var _ = Describe("Scenario", func() {
+	var (
+		cluster *fakecluster.Cluster
+		path    string
+	)
+
+	BeforeEach(func() {
+		cluster = fakecluster.NewCluster(fakecluster.Config{Host: "127.0.0.1", Nodes: 2}, lagertest.NewTestLogger("scenario"))
+		path = filepath.Join(GinkgoT().TempDir(), "scenario.yml")
+	})
+
+	writeScenario := func(contents string) {
+		Expect(os.WriteFile(path, []byte(contents), 0o644)).To(Succeed())
+	}
+
+	It("loads steps from YAML", func() {
+		writeScenario(`
+loop: true
+steps:
+- after: 30s
+  node: fake-node-0
+  state: hung
+- after: 1m
+  node: fake-node-1
+  wsrep_local_index: 0
+`)
+
+		scenario, err := fakecluster.LoadScenario(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scenario.Loop).To(BeTrue())
+		Expect(scenario.Steps).To(HaveLen(2))
+		Expect(scenario.Steps[0].After).To(Equal(30 * time.Second))
+		Expect(*scenario.Steps[0].State).To(Equal(fakecluster.StateHung))
+		Expect(scenario.Steps[1].State).To(BeNil())
+		Expect(*scenario.Steps[1].Index).To(Equal(uint(0)))
+		Expect(scenario.Validate(cluster)).To(Succeed())
+	})
+
+	It("rejects unknown states", func() {
+		writeScenario(`
+steps:
+- node: fake-node-0
+  state: sleepy
+`)
+
+		_, err := fakecluster.LoadScenario(path)
+		Expect(err).To(MatchError(ContainSubstring(`unknown state "sleepy"`)))
+	})
+
+	It("rejects unknown nodes", func() {
+		writeScenario(`
+steps:
+- node: fake-node-7
+  state: donor
+`)
+
+		scenario, err := fakecluster.LoadScenario(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scenario.Validate(cluster)).To(MatchError(`step 0: unknown node "fake-node-7"`))
+	})
+
+	It("rejects a looping scenario that never waits", func() {
+		writeScenario(`
+loop: true
+steps:
+- node: fake-node-0
+  state: donor
+`)
+
+		scenario, err := fakecluster.LoadScenario(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(scenario.Validate(cluster)).To(MatchError("a looping scenario must wait between steps"))
+	})
+
+	Describe("ScenarioRunner", func() {
+		var process ifrit.Process
+
+		AfterEach(func() {
+			ginkgomon.Interrupt(process)
+		})
+
+		It("applies each step in turn", func() {
+			writeScenario(`
+steps:
+- after: 50ms
+  node: fake-node-0
+  state: joining
+- after: 50ms
+  node: fake-node-0
+  state: synced
+- node: fake-node-1
+  state: maintenance
+`)
+			scenario, err := fakecluster.LoadScenario(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			process = ginkgomon.Invoke(fakecluster.NewScenarioRunner(scenario, cluster, lagertest.NewTestLogger("scenario")))
+
+			node0, node1 := cluster.Node("fake-node-0"), cluster.Node("fake-node-1")
+			Eventually(func() fakecluster.State { return node0.Status().State }).Should(Equal(fakecluster.StateJoining))
+			Eventually(func() fakecluster.State { return node1.Status().State }).Should(Equal(fakecluster.StateMaintenance))
+			Expect(node0.Status().State).To(Equal(fakecluster.StateSynced))
+		})
+	})
+})
@@ -0,0 +1,70 @@
+package fencing
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+const fencePath = "api/v1/fence"
+
+// Client asks a backend's galera-agent to make the node read-only, or
+// writable again.
+type Client struct {
+	httpClient *http.Client
+	useTLS     bool
+	username   string
+	password   string
+	logger     lager.Logger
+}
+
+func NewClient(httpClient *http.Client, useTLS bool, username, password string, logger lager.Logger) *Client {
+	return &Client{
+		httpClient: httpClient,
+		useTLS:     useTLS,
+		username:   username,
+		password:   password,
+		logger:     logger,
+	}
+}
+
+// Fence makes backend read-only.
+func (c *Client) Fence(backend *domain.Backend) error {
+	return c.do(http.MethodPut, backend)
+}
+
+// Unfence makes backend writable again if it was fenced. It leaves a backend
+// made read-only by an operator unchanged.
+func (c *Client) Unfence(backend *domain.Backend) error {
+	return c.do(http.MethodDelete, backend)
+}
+
+func (c *Client) do(method string, backend *domain.Backend) error {
+	req, err := http.NewRequest(method, backend.AgentURL(c.useTLS, fencePath), nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.username, c.password)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("galera-agent responded %s: %s", resp.Status, body)
+	}
+
+	c.logger.Debug("galera-agent fencing request succeeded", lager.Data{
+		"backend": backend.AsJSON().Name,
+		"method":  method,
+		"resp":    string(body),
+	})
+	return nil
+}
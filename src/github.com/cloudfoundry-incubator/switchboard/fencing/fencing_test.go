@@ -0,0 +1,72 @@
+package fencing_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/fencing"
+)
+
+var _ = Describe("Client", func() {
+	var (
+		agent    *httptest.Server
+		requests []*http.Request
+		status   int
+		backend  *domain.Backend
+		client   *fencing.Client
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		status = http.StatusOK
+		agent = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			requests = append(requests, req)
+			w.WriteHeader(status)
+		}))
+
+		host, port, err := net.SplitHostPort(agent.Listener.Addr().String())
+		Expect(err).NotTo(HaveOccurred())
+		statusPort, err := strconv.Atoi(port)
+		Expect(err).NotTo(HaveOccurred())
+
+		logger := lagertest.NewTestLogger("fencing test")
+		backend = domain.NewBackend("backend-0", host, 3306, uint(statusPort), "api/v1/status", logger)
+		client = fencing.NewClient(agent.Client(), false, "agent-user", "agent-password", logger)
+	})
+
+	AfterEach(func() {
+		agent.Close()
+	})
+
+	It("fences the backend through its galera-agent", func() {
+		Expect(client.Fence(backend)).To(Succeed())
+
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Method).To(Equal(http.MethodPut))
+		Expect(requests[0].URL.Path).To(Equal("/api/v1/fence"))
+		username, password, ok := requests[0].BasicAuth()
+		Expect(ok).To(BeTrue())
+		Expect(username).To(Equal("agent-user"))
+		Expect(password).To(Equal("agent-password"))
+	})
+
+	It("unfences the backend through its galera-agent", func() {
+		Expect(client.Unfence(backend)).To(Succeed())
+
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Method).To(Equal(http.MethodDelete))
+		Expect(requests[0].URL.Path).To(Equal("/api/v1/fence"))
+	})
+
+	It("returns an error when the galera-agent rejects the request", func() {
+		status = http.StatusUnauthorized
+		Expect(client.Fence(backend)).To(MatchError(ContainSubstring("401")))
+	})
+})
@@ -0,0 +1,192 @@
+// Package history keeps the recent history of a cluster shown on the
+// dashboard: session counts over time, failovers and healthchecks.
+package history
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+// SessionSample is the session count of each backend, by name, at Time.
+type SessionSample struct {
+	Time     time.Time       `json:"time"`
+	Sessions map[string]uint `json:"sessions"`
+}
+
+// Failover is a change of the active backend. From or To is empty when there
+// was no active backend before or after it.
+type Failover struct {
+	Time time.Time `json:"time"`
+	From string    `json:"from,omitempty"`
+	To   string    `json:"to,omitempty"`
+}
+
+// Healthcheck is the outcome of one healthcheck of a backend.
+type Healthcheck struct {
+	Time          time.Time `json:"time"`
+	Healthy       bool      `json:"healthy"`
+	LatencyMillis float64   `json:"latencyMillis"`
+}
+
+// Recorder records the history of one cluster. It samples the session
+// counts of the cluster's backends every interval, and keeps the last
+// capacity samples, failovers and healthchecks of each backend. The
+// healthchecks of a backend that leaves the cluster are dropped at the next
+// sample.
+type Recorder struct {
+	backends *domain.BackendSet
+	interval time.Duration
+	capacity int
+
+	// ActiveBackendChan receives the active backend from a cluster monitor.
+	ActiveBackendChan chan *domain.Backend
+
+	mutex        sync.RWMutex
+	sessions     *Ring[SessionSample]
+	failovers    *Ring[Failover]
+	healthchecks map[string]*Ring[Healthcheck]
+	active       *domain.Backend
+	published    bool
+}
+
+var _ ifrit.Runner = (*Recorder)(nil)
+
+func NewRecorder(backends *domain.BackendSet, interval time.Duration, capacity int) *Recorder {
+	r := &Recorder{
+		backends:          backends,
+		interval:          interval,
+		capacity:          capacity,
+		ActiveBackendChan: make(chan *domain.Backend),
+		sessions:          NewRing[SessionSample](capacity),
+		failovers:         NewRing[Failover](capacity),
+		healthchecks:      map[string]*Ring[Healthcheck]{},
+	}
+	r.trackBackends(backends.All())
+	return r
+}
+
+func (r *Recorder) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.sample(time.Now())
+	close(ready)
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case backend := <-r.ActiveBackendChan:
+			r.recordActiveBackend(backend, time.Now())
+		case now := <-ticker.C:
+			r.sample(now)
+		}
+	}
+}
+
+// RecordHealthcheck records a healthcheck of backend that took latency.
+func (r *Recorder) RecordHealthcheck(backend *domain.Backend, healthy bool, latency time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ring, ok := r.healthchecks[backend.AsJSON().Name]
+	if !ok {
+		return
+	}
+
+	ring.Add(Healthcheck{
+		Time:          time.Now(),
+		Healthy:       healthy,
+		LatencyMillis: float64(latency) / float64(time.Millisecond),
+	})
+}
+
+// Sessions returns the session count samples, oldest first.
+func (r *Recorder) Sessions() []SessionSample {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.sessions.Items()
+}
+
+// Failovers returns the failovers, oldest first.
+func (r *Recorder) Failovers() []Failover {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.failovers.Items()
+}
+
+// Healthchecks returns the healthchecks of each backend, by name, oldest
+// first.
+func (r *Recorder) Healthchecks() map[string][]Healthcheck {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	healthchecks := make(map[string][]Healthcheck, len(r.healthchecks))
+	for name, ring := range r.healthchecks {
+		healthchecks[name] = ring.Items()
+	}
+	return healthchecks
+}
+
+func (r *Recorder) sample(now time.Time) {
+	backends := r.backends.All()
+	sessions := make(map[string]uint, len(backends))
+	for _, backend := range backends {
+		j := backend.AsJSON()
+		sessions[j.Name] = j.CurrentSessionCount
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sessions.Add(SessionSample{Time: now, Sessions: sessions})
+	r.trackBackends(backends)
+}
+
+// trackBackends keeps a healthcheck ring for each of backends, and only for
+// them. The caller holds the mutex, or is the constructor.
+func (r *Recorder) trackBackends(backends []*domain.Backend) {
+	names := make(map[string]bool, len(backends))
+	for _, backend := range backends {
+		name := backend.AsJSON().Name
+		names[name] = true
+		if _, ok := r.healthchecks[name]; !ok {
+			r.healthchecks[name] = NewRing[Healthcheck](r.capacity)
+		}
+	}
+
+	for name := range r.healthchecks {
+		if !names[name] {
+			delete(r.healthchecks, name)
+		}
+	}
+}
+
+// recordActiveBackend records a failover for every change of the active
+// backend but the monitor's first choice.
+func (r *Recorder) recordActiveBackend(backend *domain.Backend, now time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	previous, published := r.active, r.published
+	r.active, r.published = backend, true
+	if !published || previous == backend {
+		return
+	}
+
+	r.failovers.Add(Failover{Time: now, From: name(previous), To: name(backend)})
+}
+
+func name(backend *domain.Backend) string {
+	if backend == nil {
+		return ""
+	}
+	return backend.AsJSON().Name
+}
@@ -0,0 +1,96 @@
+package history_test
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/history"
+)
+
+var _ = Describe("Recorder", func() {
+	var (
+		backends   []*domain.Backend
+		backendSet *domain.BackendSet
+		recorder   *history.Recorder
+		process    ifrit.Process
+	)
+
+	BeforeEach(func() {
+		backends = domain.NewBackends([]config.Backend{
+			{Name: "backend-0", Host: "127.0.0.1", Port: 3306, StatusPort: 9200},
+			{Name: "backend-1", Host: "127.0.0.2", Port: 3306, StatusPort: 9200},
+		}, lagertest.NewTestLogger("history test"))
+
+		backendSet = domain.NewBackendSet(backends)
+		recorder = history.NewRecorder(backendSet, 50*time.Millisecond, 3)
+		process = ifrit.Invoke(recorder)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+	})
+
+	It("samples the session count of each backend every interval", func() {
+		Expect(recorder.Sessions()).To(HaveLen(1))
+		Expect(recorder.Sessions()[0].Sessions).To(Equal(map[string]uint{"backend-0": 0, "backend-1": 0}))
+
+		Eventually(recorder.Sessions).Should(HaveLen(3))
+		Consistently(recorder.Sessions, 200*time.Millisecond).Should(HaveLen(3))
+	})
+
+	It("records changes of the active backend after the first one", func() {
+		recorder.ActiveBackendChan <- backends[0]
+		recorder.ActiveBackendChan <- backends[0]
+		recorder.ActiveBackendChan <- backends[1]
+		recorder.ActiveBackendChan <- nil
+		recorder.ActiveBackendChan <- backends[0]
+
+		Eventually(recorder.Failovers).Should(HaveLen(3))
+		var changes [][2]string
+		for _, failover := range recorder.Failovers() {
+			Expect(failover.Time).NotTo(BeZero())
+			changes = append(changes, [2]string{failover.From, failover.To})
+		}
+		Expect(changes).To(Equal([][2]string{
+			{"backend-0", "backend-1"},
+			{"backend-1", ""},
+			{"", "backend-0"},
+		}))
+	})
+
+	It("records the healthchecks of each backend", func() {
+		recorder.RecordHealthcheck(backends[0], true, 1500*time.Microsecond)
+		recorder.RecordHealthcheck(backends[0], false, 3*time.Millisecond)
+
+		healthchecks := recorder.Healthchecks()
+		Expect(healthchecks["backend-1"]).To(BeEmpty())
+		Expect(healthchecks["backend-0"]).To(HaveLen(2))
+		Expect(healthchecks["backend-0"][0].Healthy).To(BeTrue())
+		Expect(healthchecks["backend-0"][0].LatencyMillis).To(Equal(1.5))
+		Expect(healthchecks["backend-0"][1].Healthy).To(BeFalse())
+	})
+
+	It("follows the backends joining and leaving the cluster", func() {
+		recorder.RecordHealthcheck(backends[0], true, time.Millisecond)
+
+		backendSet.Reconcile([]config.Backend{
+			{Name: "backend-1", Host: "127.0.0.2", Port: 3306, StatusPort: 9200},
+			{Name: "backend-2", Host: "127.0.0.3", Port: 3306, StatusPort: 9200},
+		}, lagertest.NewTestLogger("history test"), func(*domain.Backend) {})
+
+		Eventually(func() map[string]uint {
+			sessions := recorder.Sessions()
+			return sessions[len(sessions)-1].Sessions
+		}).Should(Equal(map[string]uint{"backend-1": 0, "backend-2": 0}))
+		Expect(recorder.Healthchecks()).To(HaveKey("backend-2"))
+		Expect(recorder.Healthchecks()).NotTo(HaveKey("backend-0"))
+	})
+})
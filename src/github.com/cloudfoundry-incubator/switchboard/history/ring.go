@@ -0,0 +1,37 @@
+package history
+
+// Ring keeps the last items added to it, up to its capacity. It is not safe
+// for concurrent use.
+type Ring[T any] struct {
+	items []T
+	next  int
+	full  bool
+}
+
+func NewRing[T any](capacity int) *Ring[T] {
+	return &Ring[T]{items: make([]T, capacity)}
+}
+
+// Add appends item, dropping the oldest item once the ring is full.
+func (r *Ring[T]) Add(item T) {
+	if len(r.items) == 0 {
+		return
+	}
+
+	r.items[r.next] = item
+	r.next = (r.next + 1) % len(r.items)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Items returns the items in the ring, oldest first.
+func (r *Ring[T]) Items() []T {
+	if !r.full {
+		return append(make([]T, 0, r.next), r.items[:r.next]...)
+	}
+
+	items := make([]T, 0, len(r.items))
+	items = append(items, r.items[r.next:]...)
+	return append(items, r.items[:r.next]...)
+}
@@ -0,0 +1,45 @@
+package history_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/history"
+)
+
+var _ = Describe("Ring", func() {
+	It("returns the items added, oldest first", func() {
+		ring := history.NewRing[int](3)
+		Expect(ring.Items()).To(BeEmpty())
+
+		ring.Add(1)
+		ring.Add(2)
+		Expect(ring.Items()).To(Equal([]int{1, 2}))
+	})
+
+	It("drops the oldest items once full", func() {
+		ring := history.NewRing[int](3)
+		for i := 1; i <= 5; i++ {
+			ring.Add(i)
+		}
+		Expect(ring.Items()).To(Equal([]int{3, 4, 5}))
+
+		ring.Add(6)
+		Expect(ring.Items()).To(Equal([]int{4, 5, 6}))
+	})
+
+	It("does not share its storage with the items it returns", func() {
+		ring := history.NewRing[int](2)
+		ring.Add(1)
+		items := ring.Items()
+		ring.Add(2)
+		ring.Add(3)
+		Expect(items).To(Equal([]int{1}))
+	})
+
+	It("keeps nothing without capacity", func() {
+		ring := history.NewRing[int](0)
+		ring.Add(1)
+		Expect(ring.Items()).To(BeEmpty())
+	})
+})
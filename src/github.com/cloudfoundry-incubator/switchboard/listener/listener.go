@@ -0,0 +1,118 @@
+// Package listener opens the sockets the proxy accepts connections on: TCP
+// on IPv4 and IPv6 addresses, and Unix domain sockets for clients on the
+// same host.
+package listener
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	NetworkTCP  = "tcp"
+	NetworkTCP4 = "tcp4"
+	NetworkTCP6 = "tcp6"
+	NetworkUnix = "unix"
+)
+
+// unixSocketMode lets clients of any user connect, as mysqld's own socket
+// does. MySQL authenticates them.
+const unixSocketMode = 0666
+
+// Address is where a listener accepts connections, in the terms of
+// net.Listen.
+type Address struct {
+	Network string
+	Address string
+}
+
+// TCP returns the addresses of port on each of hosts. IPv4 literals listen
+// on IPv4 only and IPv6 literals on IPv6 only, so that "0.0.0.0" and "::"
+// can be listed together; an empty host or a host name listens on both.
+func TCP(hosts []string, port uint) []Address {
+	addresses := make([]Address, 0, len(hosts))
+	for _, host := range hosts {
+		network := NetworkTCP
+		if ip := net.ParseIP(host); ip != nil {
+			network = NetworkTCP6
+			if ip.To4() != nil {
+				network = NetworkTCP4
+			}
+		}
+
+		addresses = append(addresses, Address{
+			Network: network,
+			Address: net.JoinHostPort(host, strconv.FormatUint(uint64(port), 10)),
+		})
+	}
+	return addresses
+}
+
+// Unix returns the address of a Unix domain socket at path.
+func Unix(path string) Address {
+	return Address{Network: NetworkUnix, Address: path}
+}
+
+func (a Address) String() string {
+	if a.Network == NetworkUnix {
+		return "unix:" + a.Address
+	}
+	return a.Address
+}
+
+// Name describes addresses in logs, the access log and metric labels. A
+// single TCP address is its host:port.
+func Name(addresses []Address) string {
+	names := make([]string, 0, len(addresses))
+	for _, a := range addresses {
+		names = append(names, a.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// Listen listens on a. A Unix domain socket left behind by a previous
+// process is replaced; any other file at its path is an error.
+func Listen(a Address) (net.Listener, error) {
+	if a.Network != NetworkUnix {
+		return net.Listen(a.Network, a.Address)
+	}
+
+	if info, err := os.Lstat(a.Address); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("listen unix %s: file exists and is not a socket", a.Address)
+		}
+		if err := os.Remove(a.Address); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen(NetworkUnix, a.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Chmod(a.Address, unixSocketMode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// ListenAll listens on every address, or on none of them when one fails.
+func ListenAll(addresses []Address) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(addresses))
+	for _, a := range addresses {
+		l, err := Listen(a)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+	return listeners, nil
+}
@@ -0,0 +1,118 @@
+package listener_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/listener"
+)
+
+var _ = Describe("Listener", func() {
+	Describe("TCP", func() {
+		It("joins each host with the port", func() {
+			Expect(listener.TCP([]string{"", "localhost", "10.0.0.1", "::", "fd00::1"}, 3306)).To(Equal([]listener.Address{
+				{Network: "tcp", Address: ":3306"},
+				{Network: "tcp", Address: "localhost:3306"},
+				{Network: "tcp4", Address: "10.0.0.1:3306"},
+				{Network: "tcp6", Address: "[::]:3306"},
+				{Network: "tcp6", Address: "[fd00::1]:3306"},
+			}))
+		})
+	})
+
+	Describe("Name", func() {
+		It("is the host:port of a single TCP address", func() {
+			Expect(listener.Name(listener.TCP([]string{"0.0.0.0"}, 3306))).To(Equal("0.0.0.0:3306"))
+		})
+
+		It("lists every address", func() {
+			addresses := append(listener.TCP([]string{"0.0.0.0", "::"}, 3306), listener.Unix("/tmp/mysql.sock"))
+			Expect(listener.Name(addresses)).To(Equal("0.0.0.0:3306,[::]:3306,unix:/tmp/mysql.sock"))
+		})
+	})
+
+	Describe("ListenAll", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "listener")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("listens on IPv4 and IPv6 wildcards with the same port", func() {
+			listeners, err := listener.ListenAll(listener.TCP([]string{"0.0.0.0"}, 0))
+			Expect(err).NotTo(HaveOccurred())
+			port := uint(listeners[0].Addr().(*net.TCPAddr).Port)
+			listeners[0].Close()
+
+			listeners, err = listener.ListenAll(listener.TCP([]string{"0.0.0.0", "::"}, port))
+			Expect(err).NotTo(HaveOccurred())
+			defer listeners[0].Close()
+			defer listeners[1].Close()
+
+			Expect(listeners[0].Addr().(*net.TCPAddr).IP.To4()).NotTo(BeNil())
+			Expect(listeners[1].Addr().(*net.TCPAddr).IP.To4()).To(BeNil())
+		})
+
+		It("replaces a stale Unix domain socket and lets anyone connect", func() {
+			path := filepath.Join(dir, "mysql.sock")
+
+			stale, err := net.Listen("unix", path)
+			Expect(err).NotTo(HaveOccurred())
+			stale.(*net.UnixListener).SetUnlinkOnClose(false)
+			stale.Close()
+
+			listeners, err := listener.ListenAll([]listener.Address{listener.Unix(path)})
+			Expect(err).NotTo(HaveOccurred())
+			defer listeners[0].Close()
+
+			info, err := os.Stat(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0666)))
+
+			conn, err := net.Dial("unix", path)
+			Expect(err).NotTo(HaveOccurred())
+			conn.Close()
+		})
+
+		It("does not replace a file that is not a socket", func() {
+			path := filepath.Join(dir, "mysql.sock")
+			Expect(os.WriteFile(path, []byte("data"), 0644)).To(Succeed())
+
+			_, err := listener.ListenAll([]listener.Address{listener.Unix(path)})
+			Expect(err).To(MatchError(ContainSubstring("is not a socket")))
+			Expect(os.ReadFile(path)).To(Equal([]byte("data")))
+		})
+
+		It("closes the listeners it opened when one fails", func() {
+			first, err := net.Listen("tcp4", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer first.Close()
+			taken := first.Addr().String()
+
+			free, err := net.Listen("tcp4", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			freeAddress := free.Addr().String()
+			free.Close()
+
+			_, err = listener.ListenAll([]listener.Address{
+				{Network: "tcp4", Address: freeAddress},
+				{Network: "tcp4", Address: taken},
+			})
+			Expect(err).To(HaveOccurred())
+
+			l, err := net.Listen("tcp4", freeAddress)
+			Expect(err).NotTo(HaveOccurred())
+			l.Close()
+		})
+	})
+})
@@ -6,23 +6,124 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/cloudfoundry-incubator/switchboard/admission"
 	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/userquota"
 )
 
+// AdmissionStats reports how many client connections a listener admitted or
+// rejected.
+type AdmissionStats interface {
+	Outcomes() map[admission.Outcome]uint64
+}
+
+// SourceFilterStats reports how many client connections a listener rejected
+// because of their source address.
+type SourceFilterStats interface {
+	Rejected() uint64
+}
+
+// UserQuotaStats reports the sessions of each MySQL user, and how many of
+// their connections were rejected for exceeding the user's quota, as well as
+// the sessions whose user could not be read.
+type UserQuotaStats interface {
+	Usage() map[string]userquota.Usage
+	Uninspected() int
+}
+
 type Emitter struct {
-	backendSessions *prometheus.Desc
-	backends        []*domain.Backend
-	registry        *prometheus.Registry
+	backendSessions      *prometheus.Desc
+	backendDialDuration  *prometheus.Desc
+	backendDialFailures  *prometheus.Desc
+	backendQuarantined   *prometheus.Desc
+	backendQuarantines   *prometheus.Desc
+	admissionConnections *prometheus.Desc
+	sourceFilterRejected *prometheus.Desc
+	userSessions         *prometheus.Desc
+	userQuotaRejected    *prometheus.Desc
+	userQuotaUninspected *prometheus.Desc
+	clusters             map[string]*domain.BackendSet
+	admission            map[string]admissionListener
+	sourceFilters        map[string]sourceFilterListener
+	userQuotas           map[string]UserQuotaStats
+	registry             *prometheus.Registry
 }
 
-func New(backends []*domain.Backend) *Emitter {
+type admissionListener struct {
+	cluster string
+	stats   AdmissionStats
+}
+
+type sourceFilterListener struct {
+	cluster string
+	stats   SourceFilterStats
+}
+
+func New() *Emitter {
 	e := &Emitter{
-		registry: prometheus.NewRegistry(),
-		backends: backends,
+		registry:      prometheus.NewRegistry(),
+		clusters:      map[string]*domain.BackendSet{},
+		admission:     map[string]admissionListener{},
+		sourceFilters: map[string]sourceFilterListener{},
+		userQuotas:    map[string]UserQuotaStats{},
 		backendSessions: prometheus.NewDesc(
 			"backend_sessions_total",
 			"Gauge of the current sessions from this proxy to a mysql backend",
-			[]string{"backend"},
+			[]string{"cluster", "backend"},
+			nil,
+		),
+		backendDialDuration: prometheus.NewDesc(
+			"backend_dial_duration_seconds",
+			"Histogram of the time this proxy took to connect to a mysql backend",
+			[]string{"cluster", "backend"},
+			nil,
+		),
+		backendDialFailures: prometheus.NewDesc(
+			"backend_dial_failures_total",
+			"Counter of failed connection attempts from this proxy to a mysql backend",
+			[]string{"cluster", "backend"},
+			nil,
+		),
+		backendQuarantined: prometheus.NewDesc(
+			"backend_quarantined",
+			"Whether a mysql backend is quarantined from writer selection for flapping (1) or not (0)",
+			[]string{"cluster", "backend"},
+			nil,
+		),
+		backendQuarantines: prometheus.NewDesc(
+			"backend_quarantines_total",
+			"Counter of the times a mysql backend was quarantined for flapping",
+			[]string{"cluster", "backend"},
+			nil,
+		),
+		admissionConnections: prometheus.NewDesc(
+			"admission_connections_total",
+			"Counter of client connections admitted or rejected by a proxy listener",
+			[]string{"cluster", "listener", "outcome"},
+			nil,
+		),
+		sourceFilterRejected: prometheus.NewDesc(
+			"source_filter_rejected_connections_total",
+			"Counter of client connections a proxy listener rejected because of their source address",
+			[]string{"cluster", "listener"},
+			nil,
+		),
+		userSessions: prometheus.NewDesc(
+			"user_sessions",
+			"Gauge of the current sessions of a mysql user through this proxy",
+			[]string{"cluster", "user"},
+			nil,
+		),
+		userQuotaRejected: prometheus.NewDesc(
+			"user_quota_rejected_connections_total",
+			"Counter of client connections rejected because their mysql user exceeded its session quota",
+			[]string{"cluster", "user"},
+			nil,
+		),
+		userQuotaUninspected: prometheus.NewDesc(
+			"user_quota_uninspected_sessions",
+			"Gauge of the current sessions not counted against any user quota because they use TLS",
+			[]string{"cluster"},
 			nil,
 		),
 	}
@@ -30,14 +131,79 @@ func New(backends []*domain.Backend) *Emitter {
 	e.registry.MustRegister(e)
 	return e
 }
+
+// AddCluster reports the backends of a cluster.
+// It must be called before the handler is served.
+func (e *Emitter) AddCluster(cluster string, backends *domain.BackendSet) {
+	e.clusters[cluster] = backends
+}
+
+// AddAdmission reports the admission outcomes of a cluster's listener.
+// It must be called before the handler is served.
+func (e *Emitter) AddAdmission(cluster, listener string, stats AdmissionStats) {
+	e.admission[listener] = admissionListener{cluster: cluster, stats: stats}
+}
+
+// AddSourceFilter reports the connections rejected by the source filter of a
+// cluster's listener. It must be called before the handler is served.
+func (e *Emitter) AddSourceFilter(cluster, listener string, stats SourceFilterStats) {
+	e.sourceFilters[listener] = sourceFilterListener{cluster: cluster, stats: stats}
+}
+
+// AddUserQuotas reports the per-user sessions of a cluster.
+// It must be called before the handler is served.
+func (e *Emitter) AddUserQuotas(cluster string, stats UserQuotaStats) {
+	e.userQuotas[cluster] = stats
+}
+
 func (e *Emitter) Describe(desc chan<- *prometheus.Desc) {
 	desc <- e.backendSessions
+	desc <- e.backendDialDuration
+	desc <- e.backendDialFailures
+	desc <- e.backendQuarantined
+	desc <- e.backendQuarantines
+	desc <- e.admissionConnections
+	desc <- e.sourceFilterRejected
+	desc <- e.userSessions
+	desc <- e.userQuotaRejected
+	desc <- e.userQuotaUninspected
 }
 
 func (e *Emitter) Collect(metrics chan<- prometheus.Metric) {
-	for _, b := range e.backends {
-		j := b.AsJSON()
-		metrics <- prometheus.MustNewConstMetric(e.backendSessions, prometheus.GaugeValue, float64(j.CurrentSessionCount), j.Name)
+	for cluster, backends := range e.clusters {
+		for _, b := range backends.All() {
+			j := b.AsJSON()
+			metrics <- prometheus.MustNewConstMetric(e.backendSessions, prometheus.GaugeValue, float64(j.CurrentSessionCount), cluster, j.Name)
+
+			dials := b.DialStats()
+			metrics <- prometheus.MustNewConstHistogram(e.backendDialDuration, dials.Count, dials.SumSeconds, dials.Buckets, cluster, j.Name)
+			metrics <- prometheus.MustNewConstMetric(e.backendDialFailures, prometheus.CounterValue, float64(dials.Failures), cluster, j.Name)
+
+			quarantined := 0.0
+			if j.QuarantinedUntil != nil {
+				quarantined = 1
+			}
+			metrics <- prometheus.MustNewConstMetric(e.backendQuarantined, prometheus.GaugeValue, quarantined, cluster, j.Name)
+			metrics <- prometheus.MustNewConstMetric(e.backendQuarantines, prometheus.CounterValue, float64(b.Quarantines()), cluster, j.Name)
+		}
+	}
+
+	for listener, l := range e.admission {
+		for outcome, count := range l.stats.Outcomes() {
+			metrics <- prometheus.MustNewConstMetric(e.admissionConnections, prometheus.CounterValue, float64(count), l.cluster, listener, string(outcome))
+		}
+	}
+
+	for listener, l := range e.sourceFilters {
+		metrics <- prometheus.MustNewConstMetric(e.sourceFilterRejected, prometheus.CounterValue, float64(l.stats.Rejected()), l.cluster, listener)
+	}
+
+	for cluster, stats := range e.userQuotas {
+		for user, usage := range stats.Usage() {
+			metrics <- prometheus.MustNewConstMetric(e.userSessions, prometheus.GaugeValue, float64(usage.Sessions), cluster, user)
+			metrics <- prometheus.MustNewConstMetric(e.userQuotaRejected, prometheus.CounterValue, float64(usage.Rejected), cluster, user)
+		}
+		metrics <- prometheus.MustNewConstMetric(e.userQuotaUninspected, prometheus.GaugeValue, float64(stats.Uninspected()), cluster)
 	}
 }
 
@@ -2,18 +2,23 @@ package metrics
 
 import (
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/lager/v3/lagertest"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/cloudfoundry-incubator/switchboard/admission"
 	"github.com/cloudfoundry-incubator/switchboard/domain"
 	"github.com/cloudfoundry-incubator/switchboard/domain/domainfakes"
+	"github.com/cloudfoundry-incubator/switchboard/sourcefilter"
+	"github.com/cloudfoundry-incubator/switchboard/userquota"
 )
 
 func TestMetrics(t *testing.T) {
@@ -39,7 +44,8 @@ var _ = Describe("Emitter", func() {
 			backend1 := domain.NewBackend("backend-1", "1.2.3.4", 3306, 9902, "status", logger)
 			backend2 := domain.NewBackend("backend-2", "1.2.3.4", 3306, 9902, "status", logger)
 
-			emitter = New([]*domain.Backend{backend0, backend1, backend2})
+			emitter = New()
+			emitter.AddCluster("default", domain.NewBackendSet([]*domain.Backend{backend0, backend1, backend2}))
 		})
 
 		AfterEach(func() {
@@ -62,9 +68,111 @@ var _ = Describe("Emitter", func() {
 			body := strings.Split(string(bodyBytes), "\n")
 			Expect(body).To(ContainElement("# HELP backend_sessions_total Gauge of the current sessions from this proxy to a mysql backend"))
 			Expect(body).To(ContainElement("# TYPE backend_sessions_total gauge"))
-			Expect(body).To(ContainElement(`backend_sessions_total{backend="backend-0"} 19`))
-			Expect(body).To(ContainElement(`backend_sessions_total{backend="backend-1"} 11`))
-			Expect(body).To(ContainElement(`backend_sessions_total{backend="backend-2"} 216`))
+			Expect(body).To(ContainElement(`backend_sessions_total{backend="backend-0",cluster="default"} 19`))
+			Expect(body).To(ContainElement(`backend_sessions_total{backend="backend-1",cluster="default"} 11`))
+			Expect(body).To(ContainElement(`backend_sessions_total{backend="backend-2",cluster="default"} 216`))
+
+			Expect(body).To(ContainElement("# TYPE backend_dial_duration_seconds histogram"))
+			Expect(body).To(ContainElement(`backend_dial_duration_seconds_count{backend="backend-0",cluster="default"} 0`))
+			Expect(body).To(ContainElement(`backend_dial_failures_total{backend="backend-0",cluster="default"} 0`))
+		})
+
+		It("Labels the backends of each cluster", func() {
+			logger := lagertest.NewTestLogger("Backend test")
+			emitter.AddCluster("cluster-b", domain.NewBackendSet([]*domain.Backend{
+				domain.NewBackend("backend-0", "10.0.1.1", 3306, 9902, "status", logger),
+			}))
+
+			responseRecorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "", nil)
+			emitter.Handler().ServeHTTP(responseRecorder, request)
+
+			bodyBytes, err := io.ReadAll(responseRecorder.Result().Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			body := strings.Split(string(bodyBytes), "\n")
+			Expect(body).To(ContainElement(`backend_dial_failures_total{backend="backend-0",cluster="default"} 0`))
+			Expect(body).To(ContainElement(`backend_dial_failures_total{backend="backend-0",cluster="cluster-b"} 0`))
+		})
+
+		It("Responds with quarantine metrics", func() {
+			logger := lagertest.NewTestLogger("Backend test")
+			flapping := domain.NewBackend("backend-flapping", "1.2.3.4", 3306, 9902, "status", logger)
+			flapping.EnableFlapDamping(1, time.Minute, time.Minute, time.Hour)
+			flapping.SetHealthy()
+			flapping.SetUnhealthy()
+
+			responseRecorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "", nil)
+			emitter = New()
+			emitter.AddCluster("default", domain.NewBackendSet([]*domain.Backend{flapping}))
+			emitter.Handler().ServeHTTP(responseRecorder, request)
+
+			bodyBytes, err := io.ReadAll(responseRecorder.Result().Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			body := strings.Split(string(bodyBytes), "\n")
+			Expect(body).To(ContainElement(`backend_quarantined{backend="backend-flapping",cluster="default"} 1`))
+			Expect(body).To(ContainElement(`backend_quarantines_total{backend="backend-flapping",cluster="default"} 1`))
+		})
+
+		It("Responds with admission metrics for each listener", func() {
+			controller := admission.New(admission.Limits{MaxSessions: 1})
+			controller.Admit("10.0.0.1")
+			controller.Admit("10.0.0.2")
+			emitter.AddAdmission("default", "0.0.0.0:3306", controller)
+
+			responseRecorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "", nil)
+			emitter.Handler().ServeHTTP(responseRecorder, request)
+
+			bodyBytes, err := io.ReadAll(responseRecorder.Result().Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			body := strings.Split(string(bodyBytes), "\n")
+			Expect(body).To(ContainElement("# TYPE admission_connections_total counter"))
+			Expect(body).To(ContainElement(`admission_connections_total{cluster="default",listener="0.0.0.0:3306",outcome="admitted"} 1`))
+			Expect(body).To(ContainElement(`admission_connections_total{cluster="default",listener="0.0.0.0:3306",outcome="rejected_max_sessions"} 1`))
+			Expect(body).To(ContainElement(`admission_connections_total{cluster="default",listener="0.0.0.0:3306",outcome="queued"} 0`))
+		})
+
+		It("Responds with source filter metrics for each listener", func() {
+			filter, err := sourcefilter.New(sourcefilter.Rules{Deny: []string{"10.0.0.0/8"}})
+			Expect(err).NotTo(HaveOccurred())
+			filter.Allows(&net.TCPAddr{IP: net.ParseIP("10.0.0.1")})
+			emitter.AddSourceFilter("default", "0.0.0.0:3307", filter)
+
+			responseRecorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "", nil)
+			emitter.Handler().ServeHTTP(responseRecorder, request)
+
+			bodyBytes, err := io.ReadAll(responseRecorder.Result().Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			body := strings.Split(string(bodyBytes), "\n")
+			Expect(body).To(ContainElement("# TYPE source_filter_rejected_connections_total counter"))
+			Expect(body).To(ContainElement(`source_filter_rejected_connections_total{cluster="default",listener="0.0.0.0:3307"} 1`))
+		})
+
+		It("Responds with per-user session metrics", func() {
+			quotas := userquota.New(userquota.Limits{DefaultMaxSessions: 1})
+			quotas.Acquire("reporting")
+			quotas.Acquire("reporting")
+			quotas.AcquireUninspected()
+			emitter.AddUserQuotas("default", quotas)
+
+			responseRecorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("GET", "", nil)
+			emitter.Handler().ServeHTTP(responseRecorder, request)
+
+			bodyBytes, err := io.ReadAll(responseRecorder.Result().Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			body := strings.Split(string(bodyBytes), "\n")
+			Expect(body).To(ContainElement("# TYPE user_sessions gauge"))
+			Expect(body).To(ContainElement(`user_sessions{cluster="default",user="reporting"} 1`))
+			Expect(body).To(ContainElement(`user_quota_rejected_connections_total{cluster="default",user="reporting"} 1`))
+			Expect(body).To(ContainElement(`user_quota_uninspected_sessions{cluster="default"} 1`))
 		})
 	})
 })
@@ -0,0 +1,77 @@
+package mysqlproto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Authentication plugins switchboard can authenticate with.
+const (
+	NativePassword      = "mysql_native_password"
+	CachingSha2Password = "caching_sha2_password"
+)
+
+// ScramblePassword returns the auth response plugin sends for password,
+// given the scramble from the server's greeting or auth switch request.
+func ScramblePassword(plugin, password string, scramble []byte) ([]byte, error) {
+	if password == "" {
+		return nil, nil
+	}
+
+	switch plugin {
+	case NativePassword:
+		// SHA1(password) XOR SHA1(scramble + SHA1(SHA1(password)))
+		stage1 := sha1.Sum([]byte(password))
+		stage2 := sha1.Sum(stage1[:])
+		h := sha1.New()
+		h.Write(scramble[:min(len(scramble), 20)])
+		h.Write(stage2[:])
+		return xor(stage1[:], h.Sum(nil)), nil
+	case CachingSha2Password:
+		// SHA256(password) XOR SHA256(SHA256(SHA256(password)) + scramble)
+		stage1 := sha256.Sum256([]byte(password))
+		stage2 := sha256.Sum256(stage1[:])
+		h := sha256.New()
+		h.Write(stage2[:])
+		h.Write(scramble[:min(len(scramble), 20)])
+		return xor(stage1[:], h.Sum(nil)), nil
+	}
+	return nil, fmt.Errorf("unsupported authentication plugin %q", plugin)
+}
+
+// EncryptPassword returns password encrypted with the PEM encoded RSA public
+// key a server sends caching_sha2_password clients that authenticate without
+// TLS.
+func EncryptPassword(password string, scramble, publicKey []byte) ([]byte, error) {
+	block, _ := pem.Decode(publicKey)
+	if block == nil {
+		return nil, errors.New("no PEM encoded public key")
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an RSA key")
+	}
+
+	plain := xor(append([]byte(password), 0), scramble[:min(len(scramble), 20)])
+	return rsa.EncryptOAEP(sha1.New(), rand.Reader, rsaKey, plain, nil)
+}
+
+// xor returns a with every byte XORed with the byte of b at the same
+// position, repeating b as needed.
+func xor(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i%len(b)]
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+package mysqlproto_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+)
+
+func xorBytes(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	for i := range a {
+		out[i] = a[i] ^ b[i%len(b)]
+	}
+	return out
+}
+
+var _ = Describe("ScramblePassword", func() {
+	scramble := []byte("0123456789abcdefghij")
+
+	It("answers mysql_native_password challenges the way the server checks them", func() {
+		response, err := mysqlproto.ScramblePassword(mysqlproto.NativePassword, "secret", scramble)
+		Expect(err).NotTo(HaveOccurred())
+
+		// The server stores SHA1(SHA1(password)), recovers SHA1(password)
+		// from the response and compares its hash.
+		stage1 := sha1.Sum([]byte("secret"))
+		stored := sha1.Sum(stage1[:])
+		h := sha1.New()
+		h.Write(scramble)
+		h.Write(stored[:])
+		recovered := xorBytes(response, h.Sum(nil))
+		Expect(sha1.Sum(recovered)).To(Equal(stored))
+	})
+
+	It("answers caching_sha2_password challenges the way the server checks them", func() {
+		response, err := mysqlproto.ScramblePassword(mysqlproto.CachingSha2Password, "secret", scramble)
+		Expect(err).NotTo(HaveOccurred())
+
+		stage1 := sha256.Sum256([]byte("secret"))
+		cached := sha256.Sum256(stage1[:])
+		h := sha256.New()
+		h.Write(cached[:])
+		h.Write(scramble)
+		recovered := xorBytes(response, h.Sum(nil))
+		Expect(sha256.Sum256(recovered)).To(Equal(cached))
+	})
+
+	It("sends no response for an empty password", func() {
+		response, err := mysqlproto.ScramblePassword(mysqlproto.NativePassword, "", scramble)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response).To(BeEmpty())
+	})
+
+	It("rejects other plugins", func() {
+		_, err := mysqlproto.ScramblePassword("sha256_password", "secret", scramble)
+		Expect(err).To(MatchError(ContainSubstring("sha256_password")))
+	})
+})
+
+var _ = Describe("EncryptPassword", func() {
+	It("encrypts the password with the server's public key", func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+		publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+		scramble := []byte("0123456789abcdefghij")
+		encrypted, err := mysqlproto.EncryptPassword("secret", scramble, publicKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		plain, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, key, encrypted, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(xorBytes(plain, scramble)).To(Equal([]byte("secret\x00")))
+	})
+
+	It("rejects keys that are not PEM encoded", func() {
+		_, err := mysqlproto.EncryptPassword("secret", []byte("0123456789abcdefghij"), []byte("not a key"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Status flags", func() {
+	It("are read from OK packets", func() {
+		status, err := mysqlproto.ParseOKStatus([]byte{0x00, 0x01, 0xfc, 0x00, 0x01, 0x01, 0x40, 0x00, 0x00})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(Equal(mysqlproto.ServerStatusInTrans | mysqlproto.ServerSessionStateChanged))
+	})
+
+	It("are read from the OK packets that end result sets", func() {
+		status, err := mysqlproto.ParseOKStatus([]byte{0xfe, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(Equal(mysqlproto.ServerMoreResultsExists))
+	})
+
+	It("are read from EOF packets", func() {
+		status, err := mysqlproto.ParseEOFStatus([]byte{0xfe, 0x00, 0x00, 0x01, 0x00})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(Equal(mysqlproto.ServerStatusInTrans))
+	})
+
+	It("are not read from other packets", func() {
+		_, err := mysqlproto.ParseOKStatus([]byte{0xff, 0x10, 0x04})
+		Expect(err).To(MatchError(mysqlproto.ErrMalformedPacket))
+		_, err = mysqlproto.ParseEOFStatus([]byte{0x00, 0x00, 0x00, 0x02, 0x00})
+		Expect(err).To(MatchError(mysqlproto.ErrMalformedPacket))
+	})
+})
@@ -0,0 +1,68 @@
+package mysqlproto
+
+import "fmt"
+
+// Commands a client sends once it has authenticated, identified by the first
+// byte of the packet.
+const (
+	ComQuit             byte = 0x01
+	ComInitDB           byte = 0x02
+	ComQuery            byte = 0x03
+	ComFieldList        byte = 0x04
+	ComCreateDB         byte = 0x05
+	ComDropDB           byte = 0x06
+	ComRefresh          byte = 0x07
+	ComStatistics       byte = 0x09
+	ComProcessInfo      byte = 0x0a
+	ComProcessKill      byte = 0x0c
+	ComDebug            byte = 0x0d
+	ComPing             byte = 0x0e
+	ComChangeUser       byte = 0x11
+	ComBinlogDump       byte = 0x12
+	ComRegisterSlave    byte = 0x15
+	ComStmtPrepare      byte = 0x16
+	ComStmtExecute      byte = 0x17
+	ComStmtSendLongData byte = 0x18
+	ComStmtClose        byte = 0x19
+	ComStmtReset        byte = 0x1a
+	ComSetOption        byte = 0x1b
+	ComStmtFetch        byte = 0x1c
+	ComBinlogDumpGTID   byte = 0x1e
+	ComResetConnection  byte = 0x1f
+)
+
+var commandNames = map[byte]string{
+	ComQuit:             "COM_QUIT",
+	ComInitDB:           "COM_INIT_DB",
+	ComQuery:            "COM_QUERY",
+	ComFieldList:        "COM_FIELD_LIST",
+	ComCreateDB:         "COM_CREATE_DB",
+	ComDropDB:           "COM_DROP_DB",
+	ComRefresh:          "COM_REFRESH",
+	ComStatistics:       "COM_STATISTICS",
+	ComProcessInfo:      "COM_PROCESS_INFO",
+	ComProcessKill:      "COM_PROCESS_KILL",
+	ComDebug:            "COM_DEBUG",
+	ComPing:             "COM_PING",
+	ComChangeUser:       "COM_CHANGE_USER",
+	ComBinlogDump:       "COM_BINLOG_DUMP",
+	ComRegisterSlave:    "COM_REGISTER_SLAVE",
+	ComStmtPrepare:      "COM_STMT_PREPARE",
+	ComStmtExecute:      "COM_STMT_EXECUTE",
+	ComStmtSendLongData: "COM_STMT_SEND_LONG_DATA",
+	ComStmtClose:        "COM_STMT_CLOSE",
+	ComStmtReset:        "COM_STMT_RESET",
+	ComSetOption:        "COM_SET_OPTION",
+	ComStmtFetch:        "COM_STMT_FETCH",
+	ComBinlogDumpGTID:   "COM_BINLOG_DUMP_GTID",
+	ComResetConnection:  "COM_RESET_CONNECTION",
+}
+
+// CommandName returns the name of the command identified by c, such as
+// COM_QUERY.
+func CommandName(c byte) string {
+	if name, ok := commandNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown command 0x%02x", c)
+}
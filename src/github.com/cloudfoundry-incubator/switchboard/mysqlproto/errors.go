@@ -0,0 +1,53 @@
+package mysqlproto
+
+// Server error codes switchboard sends to clients.
+const (
+	// CodeConCount is ER_CON_COUNT_ERROR ("Too many connections").
+	CodeConCount uint16 = 1040
+	// CodeHostNotPrivileged is ER_HOST_NOT_PRIVILEGED.
+	CodeHostNotPrivileged uint16 = 1130
+	// CodeTooManyUserConnections is ER_TOO_MANY_USER_CONNECTIONS.
+	CodeTooManyUserConnections uint16 = 1203
+	// CodeServerOfflineMode is ER_SERVER_OFFLINE_MODE.
+	CodeServerOfflineMode uint16 = 3032
+	// CodeServerIsntAvailable is ER_SERVER_ISNT_AVAILABLE.
+	CodeServerIsntAvailable uint16 = 3168
+)
+
+// ErrorPacket returns the payload of an ERR packet. sqlState must be five
+// characters long.
+func ErrorPacket(code uint16, sqlState, message string) []byte {
+	p := []byte{0xff, byte(code), byte(code >> 8), '#'}
+	p = append(p, sqlState...)
+	return append(p, message...)
+}
+
+// PreHandshakeErrorPacket returns the payload of an ERR packet sent in place
+// of the server greeting. Clients have not announced protocol 4.1 support at
+// that point, so they read the packet without a SQL state, and would show one
+// as part of the message.
+func PreHandshakeErrorPacket(code uint16, message string) []byte {
+	p := []byte{0xff, byte(code), byte(code >> 8)}
+	return append(p, message...)
+}
+
+// ParseErrorPacket returns the code, SQL state and message of the payload of
+// an ERR packet.
+func ParseErrorPacket(payload []byte) (code uint16, sqlState, message string, err error) {
+	r := &reader{buf: payload}
+	if header := r.byte(); r.err == nil && header != 0xff {
+		return 0, "", "", ErrMalformedPacket
+	}
+	code = r.uint16()
+	if r.err != nil {
+		return 0, "", "", r.err
+	}
+
+	// The SQL state is missing from errors sent before the client announced
+	// protocol 4.1 support.
+	if len(r.buf) >= 6 && r.buf[0] == '#' {
+		sqlState = string(r.buf[1:6])
+		r.buf = r.buf[6:]
+	}
+	return code, sqlState, string(r.buf), nil
+}
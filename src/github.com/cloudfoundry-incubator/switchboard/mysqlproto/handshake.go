@@ -0,0 +1,212 @@
+package mysqlproto
+
+import (
+	"fmt"
+)
+
+// Handshake is the initial packet a server sends to every new client
+// (protocol version 10).
+type Handshake struct {
+	ServerVersion  string
+	ConnectionID   uint32
+	Capabilities   uint32
+	CharacterSet   byte
+	StatusFlags    uint16
+	AuthPluginData []byte
+	AuthPluginName string
+}
+
+func ParseHandshake(payload []byte) (Handshake, error) {
+	var h Handshake
+
+	r := &reader{buf: payload}
+	if version := r.byte(); r.err == nil && version != 10 {
+		return h, fmt.Errorf("unsupported protocol version %d", version)
+	}
+
+	h.ServerVersion = r.nulString()
+	h.ConnectionID = r.uint32()
+	h.AuthPluginData = append(h.AuthPluginData, r.bytes(8)...)
+	r.bytes(1) // filler
+	h.Capabilities = uint32(r.uint16())
+	if r.err != nil {
+		return h, r.err
+	}
+
+	// Servers that predate protocol 4.1 end the packet here.
+	if len(r.buf) == 0 {
+		return h, nil
+	}
+
+	h.CharacterSet = r.byte()
+	h.StatusFlags = r.uint16()
+	h.Capabilities |= uint32(r.uint16()) << 16
+	authPluginDataLength := int(r.byte())
+	r.bytes(10) // reserved
+
+	if h.Capabilities&ClientSecureConnection != 0 {
+		// The second part is at least 13 bytes, the last of which is a NUL.
+		n := max(13, authPluginDataLength-8)
+		part := r.bytes(n)
+		if len(part) > 0 {
+			h.AuthPluginData = append(h.AuthPluginData, part[:len(part)-1]...)
+		}
+	}
+
+	if h.Capabilities&ClientPluginAuth != 0 && r.err == nil {
+		// Some servers omit the terminating NUL of the plugin name.
+		name := r.buf
+		for i, c := range name {
+			if c == 0 {
+				name = name[:i]
+				break
+			}
+		}
+		h.AuthPluginName = string(name)
+	}
+
+	return h, r.err
+}
+
+// WithoutSSL returns a copy of the payload of a server greeting that does not
+// offer TLS, so that clients which would prefer it authenticate in clear
+// text, and clients which require it give up.
+func WithoutSSL(greeting []byte) ([]byte, error) {
+	r := &reader{buf: greeting}
+	r.byte()      // protocol version
+	r.nulString() // server version
+	r.bytes(13)   // connection id, auth plugin data part 1 and filler
+	if r.err == nil && len(r.buf) < 2 {
+		r.err = ErrMalformedPacket
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	p := append([]byte(nil), greeting...)
+	offset := len(greeting) - len(r.buf)
+	p[offset+1] &^= byte(ClientSSL >> 8)
+	return p, nil
+}
+
+// IsSSLRequest reports whether payload is the short handshake response a
+// client sends to upgrade the connection to TLS before authenticating.
+func IsSSLRequest(payload []byte) bool {
+	if len(payload) != 32 {
+		return false
+	}
+	r := &reader{buf: payload}
+	return r.uint32()&ClientSSL != 0
+}
+
+// HandshakeResponse is the client's answer to the server's Handshake
+// (HandshakeResponse41). It can be modified and marshalled again without
+// losing the fields it does not expose.
+type HandshakeResponse struct {
+	Capabilities   uint32
+	MaxPacketSize  uint32
+	CharacterSet   byte
+	Username       string
+	AuthResponse   []byte
+	Database       string
+	AuthPluginName string
+	// Attributes are the connection attributes the client sent, such as
+	// program_name or _client_name.
+	Attributes map[string]string
+
+	attributes []byte
+	trailer    []byte
+}
+
+func ParseHandshakeResponse(payload []byte) (HandshakeResponse, error) {
+	var h HandshakeResponse
+
+	r := &reader{buf: payload}
+	h.Capabilities = r.uint32()
+	if r.err == nil && h.Capabilities&ClientProtocol41 == 0 {
+		return h, fmt.Errorf("unsupported pre-4.1 handshake response")
+	}
+
+	h.MaxPacketSize = r.uint32()
+	h.CharacterSet = r.byte()
+	r.bytes(23) // filler
+	h.Username = r.nulString()
+
+	switch {
+	case h.Capabilities&ClientPluginAuthLenencClientData != 0:
+		h.AuthResponse = r.lenencBytes()
+	case h.Capabilities&ClientSecureConnection != 0:
+		h.AuthResponse = r.bytes(int(r.byte()))
+	default:
+		h.AuthResponse = []byte(r.nulString())
+	}
+
+	if h.Capabilities&ClientConnectWithDB != 0 && len(r.buf) > 0 {
+		h.Database = r.nulString()
+	}
+
+	if h.Capabilities&ClientPluginAuth != 0 && len(r.buf) > 0 {
+		h.AuthPluginName = r.nulString()
+	}
+
+	if h.Capabilities&ClientConnectAttrs != 0 && len(r.buf) > 0 {
+		h.attributes = r.lenencBytes()
+		h.Attributes = map[string]string{}
+		attrs := &reader{buf: h.attributes}
+		for len(attrs.buf) > 0 && attrs.err == nil {
+			key := attrs.lenencBytes()
+			value := attrs.lenencBytes()
+			h.Attributes[string(key)] = string(value)
+		}
+		if attrs.err != nil {
+			return h, attrs.err
+		}
+	}
+
+	if r.err != nil {
+		return h, r.err
+	}
+
+	// Newer clients may append fields, such as the zstd compression level.
+	h.trailer = r.buf
+
+	return h, nil
+}
+
+// Marshal encodes the response as the payload of a packet. Attributes are
+// sent as they were received.
+func (h HandshakeResponse) Marshal() []byte {
+	b := appendUint32(nil, h.Capabilities)
+	b = appendUint32(b, h.MaxPacketSize)
+	b = append(b, h.CharacterSet)
+	b = append(b, make([]byte, 23)...)
+	b = append(b, h.Username...)
+	b = append(b, 0)
+
+	switch {
+	case h.Capabilities&ClientPluginAuthLenencClientData != 0:
+		b = appendLenencBytes(b, h.AuthResponse)
+	case h.Capabilities&ClientSecureConnection != 0:
+		b = append(b, byte(len(h.AuthResponse)))
+		b = append(b, h.AuthResponse...)
+	default:
+		b = append(b, h.AuthResponse...)
+		b = append(b, 0)
+	}
+
+	if h.Capabilities&ClientConnectWithDB != 0 {
+		b = append(b, h.Database...)
+		b = append(b, 0)
+	}
+
+	if h.Capabilities&ClientPluginAuth != 0 {
+		b = append(b, h.AuthPluginName...)
+		b = append(b, 0)
+	}
+
+	if h.Capabilities&ClientConnectAttrs != 0 {
+		b = appendLenencBytes(b, h.attributes)
+	}
+
+	return append(b, h.trailer...)
+}
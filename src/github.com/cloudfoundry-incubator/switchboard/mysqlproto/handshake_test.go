@@ -0,0 +1,281 @@
+package mysqlproto_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"net"
+
+	"github.com/go-sql-driver/mysql"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+)
+
+func greeting() []byte {
+	capabilities := mysqlproto.ClientLongPassword | mysqlproto.ClientConnectWithDB | mysqlproto.ClientProtocol41 |
+		mysqlproto.ClientTransactions | mysqlproto.ClientSecureConnection | mysqlproto.ClientPluginAuth |
+		mysqlproto.ClientConnectAttrs | mysqlproto.ClientPluginAuthLenencClientData
+	scramble := []byte("0123456789abcdefghij")
+
+	p := []byte{10}
+	p = append(p, "8.0.36"...)
+	p = append(p, 0)
+	p = binary.LittleEndian.AppendUint32(p, 42)
+	p = append(p, scramble[:8]...)
+	p = append(p, 0)
+	p = binary.LittleEndian.AppendUint16(p, uint16(capabilities))
+	p = append(p, 0xff)
+	p = binary.LittleEndian.AppendUint16(p, 0x0002)
+	p = binary.LittleEndian.AppendUint16(p, uint16(capabilities>>16))
+	p = append(p, byte(len(scramble)+1))
+	p = append(p, make([]byte, 10)...)
+	p = append(p, scramble[8:]...)
+	p = append(p, 0)
+	p = append(p, "mysql_native_password"...)
+	return append(p, 0)
+}
+
+// captureHandshakeResponse connects a go-sql-driver client with dsnParams to
+// a server that only sends a greeting, and returns the client's response.
+func captureHandshakeResponse(dsnParams string) mysqlproto.Packet {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Expect(err).NotTo(HaveOccurred())
+	defer listener.Close()
+
+	responses := make(chan mysqlproto.Packet, 1)
+	go func() {
+		defer GinkgoRecover()
+		conn, err := listener.Accept()
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		Expect(mysqlproto.WritePacket(conn, mysqlproto.Packet{Payload: greeting()})).To(Succeed())
+		response, err := mysqlproto.ReadPacket(conn)
+		Expect(err).NotTo(HaveOccurred())
+		responses <- response
+	}()
+
+	_ = mysql.SetLogger(&mysql.NopLogger{})
+	db, err := sql.Open("mysql", "reporting:secret@tcp("+listener.Addr().String()+")/"+dsnParams)
+	Expect(err).NotTo(HaveOccurred())
+	defer db.Close()
+	_ = db.Ping()
+
+	var response mysqlproto.Packet
+	Eventually(responses).Should(Receive(&response))
+	return response
+}
+
+var _ = Describe("Handshake", func() {
+	It("parses a server greeting", func() {
+		handshake, err := mysqlproto.ParseHandshake(greeting())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(handshake.ServerVersion).To(Equal("8.0.36"))
+		Expect(handshake.ConnectionID).To(Equal(uint32(42)))
+		Expect(handshake.Capabilities & mysqlproto.ClientPluginAuth).NotTo(BeZero())
+		Expect(handshake.CharacterSet).To(Equal(byte(0xff)))
+		Expect(string(handshake.AuthPluginData)).To(Equal("0123456789abcdefghij"))
+		Expect(handshake.AuthPluginName).To(Equal("mysql_native_password"))
+	})
+
+	It("rejects other protocol versions", func() {
+		_, err := mysqlproto.ParseHandshake([]byte{9, '5', 0})
+		Expect(err).To(MatchError(ContainSubstring("unsupported protocol version 9")))
+	})
+
+	It("rejects truncated greetings", func() {
+		_, err := mysqlproto.ParseHandshake(greeting()[:12])
+		Expect(err).To(MatchError(mysqlproto.ErrMalformedPacket))
+	})
+})
+
+var _ = Describe("HandshakeResponse", func() {
+	It("parses the user, schema and attributes a client sends", func() {
+		packet := captureHandshakeResponse("batch_orders?connectionAttributes=program_name:nightly")
+		Expect(packet.Sequence).To(Equal(byte(1)))
+
+		response, err := mysqlproto.ParseHandshakeResponse(packet.Payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(response.Username).To(Equal("reporting"))
+		Expect(response.Database).To(Equal("batch_orders"))
+		Expect(response.AuthPluginName).To(Equal("mysql_native_password"))
+		Expect(response.AuthResponse).To(HaveLen(20))
+		Expect(response.Attributes).To(HaveKeyWithValue("program_name", "nightly"))
+		Expect(response.Attributes).To(HaveKeyWithValue("_client_name", "Go-MySQL-Driver"))
+	})
+
+	It("marshals an unmodified response to the bytes it was parsed from", func() {
+		packet := captureHandshakeResponse("orders?connectionAttributes=program_name:nightly")
+
+		response, err := mysqlproto.ParseHandshakeResponse(packet.Payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(response.Marshal()).To(Equal(packet.Payload))
+	})
+
+	It("marshals modified authentication fields", func() {
+		packet := captureHandshakeResponse("")
+
+		response, err := mysqlproto.ParseHandshakeResponse(packet.Payload)
+		Expect(err).NotTo(HaveOccurred())
+		response.AuthResponse = nil
+		response.AuthPluginName = "other_plugin"
+
+		reparsed, err := mysqlproto.ParseHandshakeResponse(response.Marshal())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reparsed.Username).To(Equal("reporting"))
+		Expect(reparsed.AuthResponse).To(BeEmpty())
+		Expect(reparsed.AuthPluginName).To(Equal("other_plugin"))
+		Expect(reparsed.Attributes).To(Equal(response.Attributes))
+	})
+
+	It("rejects truncated responses", func() {
+		packet := captureHandshakeResponse("")
+
+		_, err := mysqlproto.ParseHandshakeResponse(packet.Payload[:40])
+		Expect(err).To(MatchError(mysqlproto.ErrMalformedPacket))
+	})
+
+	It("tells TLS upgrade requests apart from full responses", func() {
+		sslRequest := binary.LittleEndian.AppendUint32(nil, mysqlproto.ClientProtocol41|mysqlproto.ClientSSL)
+		sslRequest = append(sslRequest, make([]byte, 28)...)
+		Expect(mysqlproto.IsSSLRequest(sslRequest)).To(BeTrue())
+
+		packet := captureHandshakeResponse("")
+		Expect(mysqlproto.IsSSLRequest(packet.Payload)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Packets", func() {
+	It("reads the packets it writes", func() {
+		var buf bytes.Buffer
+		Expect(mysqlproto.WritePacket(&buf, mysqlproto.Packet{Sequence: 3, Payload: []byte("payload")})).To(Succeed())
+		Expect(buf.Bytes()[:4]).To(Equal([]byte{7, 0, 0, 3}))
+
+		packet, err := mysqlproto.ReadPacket(&buf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(packet).To(Equal(mysqlproto.Packet{Sequence: 3, Payload: []byte("payload")}))
+	})
+})
+
+var _ = Describe("WithoutSSL", func() {
+	It("stops offering TLS and leaves the rest of the greeting as it was", func() {
+		withSSL := greeting()
+		// The upper byte of the lower capability flags, after the server
+		// version, connection id, scramble and filler.
+		withSSL[len("\x0a8.0.36\x00")+13+1] |= byte(mysqlproto.ClientSSL >> 8)
+		original, err := mysqlproto.ParseHandshake(withSSL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(original.Capabilities & mysqlproto.ClientSSL).NotTo(BeZero())
+
+		payload, err := mysqlproto.WithoutSSL(withSSL)
+		Expect(err).NotTo(HaveOccurred())
+
+		handshake, err := mysqlproto.ParseHandshake(payload)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handshake.Capabilities).To(Equal(original.Capabilities &^ mysqlproto.ClientSSL))
+		handshake.Capabilities = original.Capabilities
+		Expect(handshake).To(Equal(original))
+	})
+
+	It("rejects truncated greetings", func() {
+		_, err := mysqlproto.WithoutSSL([]byte{10, '8', 0, 1, 2})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ErrorPacket", func() {
+	It("is reported by clients as a server error", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			conn, err := listener.Accept()
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			Expect(mysqlproto.WritePacket(conn, mysqlproto.Packet{Payload: greeting()})).To(Succeed())
+			response, err := mysqlproto.ReadPacket(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mysqlproto.WritePacket(conn, mysqlproto.Packet{
+				Sequence: response.Sequence + 1,
+				Payload:  mysqlproto.ErrorPacket(mysqlproto.CodeTooManyUserConnections, "42000", "too many sessions"),
+			})).To(Succeed())
+		}()
+
+		db, err := sql.Open("mysql", "reporting:secret@tcp("+listener.Addr().String()+")/")
+		Expect(err).NotTo(HaveOccurred())
+		defer db.Close()
+
+		var mysqlErr *mysql.MySQLError
+		Expect(errors.As(db.Ping(), &mysqlErr)).To(BeTrue())
+		Expect(mysqlErr.Number).To(Equal(mysqlproto.CodeTooManyUserConnections))
+		Expect(string(mysqlErr.SQLState[:])).To(Equal("42000"))
+		Expect(mysqlErr.Message).To(Equal("too many sessions"))
+	})
+})
+
+var _ = Describe("PreHandshakeErrorPacket", func() {
+	It("has no SQL state, which clients would otherwise show in the message", func() {
+		Expect(mysqlproto.PreHandshakeErrorPacket(mysqlproto.CodeServerOfflineMode, "offline")).To(Equal(
+			[]byte{0xff, 0xd8, 0x0b, 'o', 'f', 'f', 'l', 'i', 'n', 'e'},
+		))
+	})
+
+	It("is reported by clients as a server error in place of the greeting", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		defer listener.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			conn, err := listener.Accept()
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			Expect(mysqlproto.WritePacket(conn, mysqlproto.Packet{
+				Payload: mysqlproto.PreHandshakeErrorPacket(mysqlproto.CodeServerOfflineMode, "switchboard: traffic disabled"),
+			})).To(Succeed())
+		}()
+
+		db, err := sql.Open("mysql", "reporting:secret@tcp("+listener.Addr().String()+")/")
+		Expect(err).NotTo(HaveOccurred())
+		defer db.Close()
+
+		var mysqlErr *mysql.MySQLError
+		Expect(errors.As(db.Ping(), &mysqlErr)).To(BeTrue())
+		Expect(mysqlErr.Number).To(Equal(mysqlproto.CodeServerOfflineMode))
+		Expect(mysqlErr.SQLState).To(BeZero())
+		Expect(mysqlErr.Message).To(Equal("switchboard: traffic disabled"))
+	})
+})
+
+var _ = Describe("ParseErrorPacket", func() {
+	It("returns the fields of an error packet", func() {
+		code, sqlState, message, err := mysqlproto.ParseErrorPacket(mysqlproto.ErrorPacket(mysqlproto.CodeConCount, "08004", "Too many connections"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code).To(Equal(mysqlproto.CodeConCount))
+		Expect(sqlState).To(Equal("08004"))
+		Expect(message).To(Equal("Too many connections"))
+	})
+
+	It("accepts errors without a SQL state", func() {
+		code, sqlState, message, err := mysqlproto.ParseErrorPacket([]byte{0xff, 0x10, 0x04, 'T', 'o', 'o'})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(code).To(Equal(uint16(1040)))
+		Expect(sqlState).To(BeEmpty())
+		Expect(message).To(Equal("Too"))
+	})
+
+	It("rejects other packets", func() {
+		_, _, _, err := mysqlproto.ParseErrorPacket([]byte{0x00, 0x00, 0x00})
+		Expect(err).To(MatchError(mysqlproto.ErrMalformedPacket))
+	})
+})
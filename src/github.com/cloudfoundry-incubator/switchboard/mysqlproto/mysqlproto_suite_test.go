@@ -0,0 +1,13 @@
+package mysqlproto_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMySQLProto(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "MySQL Protocol Suite")
+}
@@ -0,0 +1,188 @@
+// Package mysqlproto reads and writes the parts of the MySQL client/server
+// protocol that switchboard inspects. Everything else is relayed as bytes.
+package mysqlproto
+
+import (
+	"errors"
+	"io"
+)
+
+// Capability flags exchanged in the handshake.
+const (
+	ClientLongPassword               uint32 = 0x00000001
+	ClientConnectWithDB              uint32 = 0x00000008
+	ClientCompress                   uint32 = 0x00000020
+	ClientProtocol41                 uint32 = 0x00000200
+	ClientSSL                        uint32 = 0x00000800
+	ClientTransactions               uint32 = 0x00002000
+	ClientSecureConnection           uint32 = 0x00008000
+	ClientPluginAuth                 uint32 = 0x00080000
+	ClientConnectAttrs               uint32 = 0x00100000
+	ClientPluginAuthLenencClientData uint32 = 0x00200000
+	ClientSessionTrack               uint32 = 0x00800000
+	ClientDeprecateEOF               uint32 = 0x01000000
+	ClientOptionalResultsetMetadata  uint32 = 0x02000000
+)
+
+// MaxPayloadLength is the largest payload a single packet carries. Longer
+// payloads are split into several packets.
+const MaxPayloadLength = 1<<24 - 1
+
+var ErrMalformedPacket = errors.New("malformed packet")
+
+// Packet is one MySQL protocol packet.
+type Packet struct {
+	Sequence byte
+	Payload  []byte
+}
+
+func ReadPacket(r io.Reader) (Packet, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Packet{}, err
+	}
+
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return Packet{}, err
+	}
+
+	return Packet{Sequence: header[3], Payload: payload}, nil
+}
+
+func WritePacket(w io.Writer, p Packet) error {
+	if len(p.Payload) > MaxPayloadLength {
+		return errors.New("payload too long for a single packet")
+	}
+
+	length := len(p.Payload)
+	buf := make([]byte, 0, 4+length)
+	buf = append(buf, byte(length), byte(length>>8), byte(length>>16), p.Sequence)
+	buf = append(buf, p.Payload...)
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// reader consumes the fields of a packet payload. Once a read fails, every
+// following read fails too, so callers only need to check err at the end.
+type reader struct {
+	buf []byte
+	err error
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.buf) {
+		r.err = ErrMalformedPacket
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *reader) byte() byte {
+	b := r.bytes(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *reader) uint16() uint16 {
+	b := r.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return uint16(b[0]) | uint16(b[1])<<8
+}
+
+func (r *reader) uint32() uint32 {
+	b := r.bytes(4)
+	if b == nil {
+		return 0
+	}
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+}
+
+func (r *reader) nulString() string {
+	if r.err != nil {
+		return ""
+	}
+	for i, c := range r.buf {
+		if c == 0 {
+			s := string(r.buf[:i])
+			r.buf = r.buf[i+1:]
+			return s
+		}
+	}
+	r.err = ErrMalformedPacket
+	return ""
+}
+
+func (r *reader) lenencInt() uint64 {
+	first := r.byte()
+	switch {
+	case first < 0xfb:
+		return uint64(first)
+	case first == 0xfc:
+		b := r.bytes(2)
+		if b == nil {
+			return 0
+		}
+		return uint64(b[0]) | uint64(b[1])<<8
+	case first == 0xfd:
+		b := r.bytes(3)
+		if b == nil {
+			return 0
+		}
+		return uint64(b[0]) | uint64(b[1])<<8 | uint64(b[2])<<16
+	case first == 0xfe:
+		b := r.bytes(8)
+		var n uint64
+		for i := len(b) - 1; i >= 0; i-- {
+			n = n<<8 | uint64(b[i])
+		}
+		return n
+	}
+	r.err = ErrMalformedPacket
+	return 0
+}
+
+func (r *reader) lenencBytes() []byte {
+	n := r.lenencInt()
+	if n > uint64(len(r.buf)) {
+		r.err = ErrMalformedPacket
+		return nil
+	}
+	return r.bytes(int(n))
+}
+
+func appendUint32(b []byte, n uint32) []byte {
+	return append(b, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+}
+
+func appendLenencInt(b []byte, n uint64) []byte {
+	switch {
+	case n < 0xfb:
+		return append(b, byte(n))
+	case n < 1<<16:
+		return append(b, 0xfc, byte(n), byte(n>>8))
+	case n < 1<<24:
+		return append(b, 0xfd, byte(n), byte(n>>8), byte(n>>16))
+	}
+	b = append(b, 0xfe)
+	for i := 0; i < 8; i++ {
+		b = append(b, byte(n>>(8*i)))
+	}
+	return b
+}
+
+func appendLenencBytes(b, s []byte) []byte {
+	b = appendLenencInt(b, uint64(len(s)))
+	return append(b, s...)
+}
@@ -0,0 +1,33 @@
+package mysqlproto
+
+// Server status flags carried by OK and EOF packets.
+const (
+	ServerStatusInTrans       uint16 = 0x0001
+	ServerMoreResultsExists   uint16 = 0x0008
+	ServerSessionStateChanged uint16 = 0x4000
+)
+
+// ParseOKStatus returns the server status flags of an OK packet, including
+// the 0xfe OK packet that ends a result set for clients with
+// ClientDeprecateEOF.
+func ParseOKStatus(payload []byte) (uint16, error) {
+	r := &reader{buf: payload}
+	if header := r.byte(); r.err == nil && header != 0x00 && header != 0xfe {
+		return 0, ErrMalformedPacket
+	}
+	r.lenencInt() // affected rows
+	r.lenencInt() // last insert id
+	status := r.uint16()
+	return status, r.err
+}
+
+// ParseEOFStatus returns the server status flags of an EOF packet.
+func ParseEOFStatus(payload []byte) (uint16, error) {
+	r := &reader{buf: payload}
+	if header := r.byte(); r.err == nil && header != 0xfe {
+		return 0, ErrMalformedPacket
+	}
+	r.uint16() // warnings
+	status := r.uint16()
+	return status, r.err
+}
@@ -0,0 +1,121 @@
+package reconnect
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+)
+
+// Packets of the authentication exchange that follows the handshake
+// response.
+const (
+	authMoreData      byte = 0x01
+	authSwitchRequest byte = 0xfe
+
+	// caching_sha2_password sends these as more data.
+	fastAuthSuccess  byte = 0x03
+	performFullAuth  byte = 0x04
+	requestPublicKey byte = 0x02
+)
+
+// maxAuthenticationRounds bounds the packets a backend may exchange before
+// it accepts or rejects the session.
+const maxAuthenticationRounds = 8
+
+// Authenticate logs in to the backend conn is connected to as the user of
+// handshake, with password in place of the client's own auth response.
+// Capabilities the session relies on must be offered by the backend.
+func Authenticate(conn net.Conn, handshake mysqlproto.HandshakeResponse, capabilities uint32, password string) error {
+	greeting, err := mysqlproto.ReadPacket(conn)
+	if err != nil {
+		return fmt.Errorf("reading backend greeting: %w", err)
+	}
+	server, err := mysqlproto.ParseHandshake(greeting.Payload)
+	if err != nil {
+		return fmt.Errorf("parsing backend greeting: %w", backendError(greeting.Payload, err))
+	}
+	if missing := capabilities &^ server.Capabilities; missing != 0 {
+		return fmt.Errorf("backend does not support capabilities 0x%x the session negotiated", missing)
+	}
+
+	plugin := server.AuthPluginName
+	if plugin != mysqlproto.CachingSha2Password {
+		// The backend asks for another plugin if the account needs one.
+		plugin = mysqlproto.NativePassword
+	}
+	scramble := server.AuthPluginData
+
+	handshake.AuthPluginName = plugin
+	handshake.AuthResponse, err = mysqlproto.ScramblePassword(plugin, password, scramble)
+	if err != nil {
+		return err
+	}
+
+	sequence := greeting.Sequence + 1
+	if err := mysqlproto.WritePacket(conn, mysqlproto.Packet{Sequence: sequence, Payload: handshake.Marshal()}); err != nil {
+		return err
+	}
+
+	requestedKey := false
+	for i := 0; i < maxAuthenticationRounds; i++ {
+		p, err := mysqlproto.ReadPacket(conn)
+		if err != nil {
+			return fmt.Errorf("reading backend authentication: %w", err)
+		}
+		if len(p.Payload) == 0 {
+			return mysqlproto.ErrMalformedPacket
+		}
+		sequence = p.Sequence + 1
+
+		var reply []byte
+		switch header := p.Payload[0]; {
+		case header == 0x00:
+			return nil
+		case header == 0xff:
+			return backendError(p.Payload, nil)
+		case header == authSwitchRequest:
+			name, data, _ := bytes.Cut(p.Payload[1:], []byte{0})
+			plugin = string(name)
+			scramble = bytes.TrimSuffix(data, []byte{0})
+			if reply, err = mysqlproto.ScramblePassword(plugin, password, scramble); err != nil {
+				return err
+			}
+			if reply == nil {
+				reply = []byte{}
+			}
+		case header == authMoreData && plugin == mysqlproto.CachingSha2Password && len(p.Payload) == 2 && p.Payload[1] == fastAuthSuccess:
+			continue
+		case header == authMoreData && plugin == mysqlproto.CachingSha2Password && len(p.Payload) == 2 && p.Payload[1] == performFullAuth:
+			// Without TLS, the password is sent encrypted with the backend's
+			// public key.
+			reply = []byte{requestPublicKey}
+			requestedKey = true
+		case header == authMoreData && requestedKey:
+			if reply, err = mysqlproto.EncryptPassword(password, scramble, p.Payload[1:]); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unexpected authentication packet 0x%x from backend", header)
+		}
+
+		if err := mysqlproto.WritePacket(conn, mysqlproto.Packet{Sequence: sequence, Payload: reply}); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("backend did not finish authenticating after %d exchanges", maxAuthenticationRounds)
+}
+
+// backendError describes the error packet payload, or returns err when
+// payload is not one.
+func backendError(payload []byte, err error) error {
+	code, _, message, parseErr := mysqlproto.ParseErrorPacket(payload)
+	if parseErr != nil {
+		if err != nil {
+			return err
+		}
+		return parseErr
+	}
+	return fmt.Errorf("backend error %d: %s", code, message)
+}
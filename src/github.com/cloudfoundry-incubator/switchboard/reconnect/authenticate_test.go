@@ -0,0 +1,171 @@
+package reconnect_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+	"github.com/cloudfoundry-incubator/switchboard/reconnect"
+)
+
+var scramble = []byte("0123456789abcdefghij")
+
+func greeting(capabilities uint32, plugin string) []byte {
+	p := []byte{10}
+	p = append(p, "8.0.36"...)
+	p = append(p, 0)
+	p = binary.LittleEndian.AppendUint32(p, 42)
+	p = append(p, scramble[:8]...)
+	p = append(p, 0)
+	p = binary.LittleEndian.AppendUint16(p, uint16(capabilities))
+	p = append(p, 0xff)
+	p = binary.LittleEndian.AppendUint16(p, 0x0002)
+	p = binary.LittleEndian.AppendUint16(p, uint16(capabilities>>16))
+	p = append(p, byte(len(scramble)+1))
+	p = append(p, make([]byte, 10)...)
+	p = append(p, scramble[8:]...)
+	p = append(p, 0)
+	p = append(p, plugin...)
+	return append(p, 0)
+}
+
+var _ = Describe("Authenticate", func() {
+	var (
+		proxyConn, backendConn net.Conn
+		handshake              mysqlproto.HandshakeResponse
+		authenticated          chan error
+	)
+
+	BeforeEach(func() {
+		proxyConn, backendConn = net.Pipe()
+		handshake = mysqlproto.HandshakeResponse{
+			Capabilities: trackedCapabilities | mysqlproto.ClientConnectWithDB,
+			Username:     "app",
+			Database:     "orders",
+		}
+		authenticated = make(chan error, 1)
+	})
+
+	AfterEach(func() {
+		proxyConn.Close()
+		backendConn.Close()
+	})
+
+	authenticate := func() {
+		go func() {
+			authenticated <- reconnect.Authenticate(proxyConn, handshake, trackedCapabilities, "secret")
+		}()
+	}
+
+	greet := func(capabilities uint32, plugin string) mysqlproto.HandshakeResponse {
+		Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{Payload: greeting(capabilities, plugin)})).To(Succeed())
+		p, err := mysqlproto.ReadPacket(backendConn)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Sequence).To(Equal(byte(1)))
+		response, err := mysqlproto.ParseHandshakeResponse(p.Payload)
+		Expect(err).NotTo(HaveOccurred())
+		return response
+	}
+
+	It("logs in as the session's user with the password", func() {
+		authenticate()
+		response := greet(trackedCapabilities|mysqlproto.ClientConnectWithDB, mysqlproto.NativePassword)
+
+		Expect(response.Username).To(Equal("app"))
+		Expect(response.Database).To(Equal("orders"))
+		Expect(response.AuthPluginName).To(Equal(mysqlproto.NativePassword))
+		expected, err := mysqlproto.ScramblePassword(mysqlproto.NativePassword, "secret", scramble)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.AuthResponse).To(Equal(expected))
+
+		Expect(mysqlproto.WritePacket(backendConn, ok(2, 0x0002))).To(Succeed())
+		Eventually(authenticated).Should(Receive(BeNil()))
+	})
+
+	It("switches to the plugin the backend asks for", func() {
+		authenticate()
+		greet(trackedCapabilities, mysqlproto.CachingSha2Password)
+
+		switchScramble := []byte("abcdefghij0123456789")
+		Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{
+			Sequence: 2,
+			Payload:  append(append([]byte("\xfemysql_native_password\x00"), switchScramble...), 0),
+		})).To(Succeed())
+
+		p, err := mysqlproto.ReadPacket(backendConn)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Sequence).To(Equal(byte(3)))
+		expected, err := mysqlproto.ScramblePassword(mysqlproto.NativePassword, "secret", switchScramble)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Payload).To(Equal(expected))
+
+		Expect(mysqlproto.WritePacket(backendConn, ok(4, 0x0002))).To(Succeed())
+		Eventually(authenticated).Should(Receive(BeNil()))
+	})
+
+	It("sends caching_sha2_password full authentication encrypted with the backend's key", func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		Expect(err).NotTo(HaveOccurred())
+
+		authenticate()
+		greet(trackedCapabilities, mysqlproto.CachingSha2Password)
+
+		Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{Sequence: 2, Payload: []byte{0x01, 0x04}})).To(Succeed())
+		p, err := mysqlproto.ReadPacket(backendConn)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p).To(Equal(mysqlproto.Packet{Sequence: 3, Payload: []byte{0x02}}))
+
+		publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+		Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{Sequence: 4, Payload: append([]byte{0x01}, publicKey...)})).To(Succeed())
+		p, err = mysqlproto.ReadPacket(backendConn)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Sequence).To(Equal(byte(5)))
+
+		plain, err := rsa.DecryptOAEP(sha1.New(), rand.Reader, key, p.Payload, nil)
+		Expect(err).NotTo(HaveOccurred())
+		for i := range plain {
+			plain[i] ^= scramble[i%len(scramble)]
+		}
+		Expect(plain).To(Equal([]byte("secret\x00")))
+
+		Expect(mysqlproto.WritePacket(backendConn, ok(6, 0x0002))).To(Succeed())
+		Eventually(authenticated).Should(Receive(BeNil()))
+	})
+
+	It("returns the error the backend rejects the session with", func() {
+		authenticate()
+		greet(trackedCapabilities, mysqlproto.NativePassword)
+
+		Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{
+			Sequence: 2,
+			Payload:  mysqlproto.ErrorPacket(1045, "28000", "Access denied for user 'app'"),
+		})).To(Succeed())
+		Eventually(authenticated).Should(Receive(MatchError(ContainSubstring("Access denied"))))
+	})
+
+	It("refuses backends without the capabilities the session relies on", func() {
+		authenticate()
+		Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{
+			Payload: greeting(trackedCapabilities&^mysqlproto.ClientSessionTrack, mysqlproto.NativePassword),
+		})).To(Succeed())
+		Eventually(authenticated).Should(Receive(MatchError(ContainSubstring("capabilities"))))
+	})
+
+	It("refuses backends that cannot be logged in to", func() {
+		authenticate()
+		Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{
+			Payload: mysqlproto.ErrorPacket(1040, "08004", "Too many connections"),
+		})).To(Succeed())
+		Eventually(authenticated).Should(Receive(MatchError(ContainSubstring("backend error 1040: Too many connections"))))
+	})
+})
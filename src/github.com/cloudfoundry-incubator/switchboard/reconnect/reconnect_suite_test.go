@@ -0,0 +1,13 @@
+package reconnect_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestReconnect(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Reconnect Suite")
+}
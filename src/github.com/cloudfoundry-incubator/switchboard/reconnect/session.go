@@ -0,0 +1,368 @@
+// Package reconnect moves idle MySQL sessions to another backend. It follows
+// the commands a client sends and the results it receives, so that it knows
+// when a session is between statements, outside a transaction and without
+// session state that only its backend holds.
+package reconnect
+
+import (
+	"errors"
+	"net"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+)
+
+// Capabilities a client must have negotiated for its session to be tracked.
+// Session state tracking reports the state that cannot be restored on
+// another backend.
+const requiredCapabilities = mysqlproto.ClientProtocol41 |
+	mysqlproto.ClientSecureConnection |
+	mysqlproto.ClientPluginAuth |
+	mysqlproto.ClientSessionTrack
+
+// maxQueryLength is the longest statement checked for locks. Longer
+// statements are assumed to take one.
+const maxQueryLength = 64 << 10
+
+// maxResultHeader is enough of a result packet for its header and status
+// flags.
+const maxResultHeader = 64
+
+// locking matches statements that take locks that outlive them, which
+// session state tracking does not report.
+var locking = regexp.MustCompile(`(?i)\b(lock|get_lock)\b`)
+
+var errDetached = errors.New("session detached from its backend")
+
+// Supports reports whether the session of a client that sent handshake can be
+// tracked. Sessions using TLS, compression or optional result set metadata
+// cannot.
+func Supports(handshake mysqlproto.HandshakeResponse) bool {
+	return handshake.Capabilities&requiredCapabilities == requiredCapabilities &&
+		handshake.Capabilities&(mysqlproto.ClientSSL|mysqlproto.ClientCompress|mysqlproto.ClientOptionalResultsetMetadata) == 0
+}
+
+type resultState int
+
+const (
+	awaitingResult resultState = iota
+	awaitingColumns
+	awaitingColumnsEnd
+	awaitingRows
+)
+
+type command struct {
+	code   byte
+	schema string
+}
+
+// Session tracks one client session across the backends it is bridged to.
+type Session struct {
+	mutex        sync.Mutex
+	client       net.Conn
+	handshake    mysqlproto.HandshakeResponse
+	capabilities uint32
+
+	fromClient  packetStream
+	fromBackend packetStream
+
+	pending       *command
+	result        resultState
+	columns       uint64
+	inTransaction bool
+	// stateful is set once the session holds state another backend would
+	// not have. COM_RESET_CONNECTION clears it.
+	stateful bool
+	// untracked is set once the session does something the tracker cannot
+	// follow. It is never cleared.
+	untracked bool
+
+	detached bool
+	stash    []byte
+	stashErr error
+}
+
+// NewSession starts tracking the session of client, which sent handshake to
+// a backend offering serverCapabilities, once the client has authenticated.
+func NewSession(client net.Conn, handshake mysqlproto.HandshakeResponse, serverCapabilities uint32) *Session {
+	s := &Session{
+		client:       client,
+		handshake:    handshake,
+		capabilities: handshake.Capabilities & serverCapabilities,
+	}
+	s.fromClient = newPacketStream(maxQueryLength, s.clientPacket)
+	s.fromBackend = newPacketStream(maxResultHeader, s.backendPacket)
+	return s
+}
+
+// Client returns the client connection to bridge. On failover, it detaches
+// from the bridge instead of being closed when the session is idle.
+func (s *Session) Client() net.Conn {
+	return clientConn{Conn: s.client, session: s}
+}
+
+// Backend returns conn, to be bridged to the session's client.
+func (s *Session) Backend(conn net.Conn) net.Conn {
+	return backendConn{Conn: conn, session: s}
+}
+
+// Handshake returns the client's handshake response, with the schema the
+// client last selected.
+func (s *Session) Handshake() mysqlproto.HandshakeResponse {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.handshake
+}
+
+// Capabilities returns the capabilities the client and its first backend
+// agreed on. Another backend must offer all of them.
+func (s *Session) Capabilities() uint32 {
+	return s.capabilities
+}
+
+// Resume lets the client be bridged again after it has detached, replaying
+// whatever it sent in the meantime.
+func (s *Session) Resume() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.detached = false
+	_ = s.client.SetReadDeadline(time.Time{})
+}
+
+// Idle reports whether the session could move to another backend now.
+func (s *Session) Idle() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.idle()
+}
+
+func (s *Session) idle() bool {
+	return !s.untracked && !s.stateful && !s.inTransaction && s.pending == nil &&
+		s.fromClient.idle() && s.fromBackend.idle()
+}
+
+func (s *Session) detach() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.idle() {
+		return false
+	}
+
+	// Interrupts the bridge's pending read. Anything it reads from now on
+	// is kept for the next backend.
+	s.detached = true
+	_ = s.client.SetReadDeadline(time.Now())
+	return true
+}
+
+func (s *Session) clientPacket(p packet) {
+	if s.untracked || p.continued {
+		return
+	}
+
+	// Only commands answered without further client packets are tracked,
+	// one at a time.
+	if p.sequence != 0 || s.pending != nil || len(p.payload) == 0 {
+		s.untracked = true
+		return
+	}
+
+	switch code := p.payload[0]; code {
+	case mysqlproto.ComQuit:
+	case mysqlproto.ComQuery:
+		if p.truncated() || locking.Match(p.payload[1:]) {
+			s.stateful = true
+		}
+		s.pending = &command{code: code}
+	case mysqlproto.ComPing, mysqlproto.ComResetConnection:
+		s.pending = &command{code: code}
+	case mysqlproto.ComInitDB:
+		if p.truncated() {
+			s.untracked = true
+			return
+		}
+		s.pending = &command{code: code, schema: string(p.payload[1:])}
+	default:
+		s.untracked = true
+	}
+}
+
+func (s *Session) backendPacket(p packet) {
+	if s.untracked || p.continued {
+		return
+	}
+	if s.pending == nil || len(p.payload) == 0 {
+		s.untracked = true
+		return
+	}
+
+	header := p.payload[0]
+	switch s.result {
+	case awaitingResult:
+		switch {
+		case header == 0x00:
+			s.endResult(mysqlproto.ParseOKStatus(p.payload))
+		case header == 0xff:
+			s.complete(false)
+		case s.pending.code == mysqlproto.ComQuery && header != 0xfb:
+			// The column count of the text result set that follows.
+			s.columns = lenencInt(p.payload)
+			s.result = awaitingColumns
+		default:
+			// Including LOCAL INFILE requests, which the client answers.
+			s.untracked = true
+		}
+	case awaitingColumns:
+		s.columns--
+		if s.columns == 0 {
+			s.result = awaitingColumnsEnd
+			if s.capabilities&mysqlproto.ClientDeprecateEOF != 0 {
+				s.result = awaitingRows
+			}
+		}
+	case awaitingColumnsEnd:
+		if header != 0xfe || p.length >= 9 {
+			s.untracked = true
+			return
+		}
+		s.result = awaitingRows
+	case awaitingRows:
+		switch {
+		case header == 0xff:
+			s.complete(false)
+		case header == 0xfe && s.capabilities&mysqlproto.ClientDeprecateEOF != 0 && p.length < mysqlproto.MaxPayloadLength:
+			s.endResult(mysqlproto.ParseOKStatus(p.payload))
+		case header == 0xfe && p.length < 9:
+			s.endResult(mysqlproto.ParseEOFStatus(p.payload))
+		}
+	}
+}
+
+// endResult applies the status flags of the packet that ended a result, and
+// completes the command unless more results follow.
+func (s *Session) endResult(status uint16, err error) {
+	if err != nil {
+		s.untracked = true
+		return
+	}
+
+	s.inTransaction = status&mysqlproto.ServerStatusInTrans != 0
+	// The tracker restores the schema COM_INIT_DB selects itself.
+	if status&mysqlproto.ServerSessionStateChanged != 0 && s.pending.code != mysqlproto.ComInitDB {
+		s.stateful = true
+	}
+
+	if status&mysqlproto.ServerMoreResultsExists != 0 {
+		s.result = awaitingResult
+		return
+	}
+	s.complete(true)
+}
+
+func (s *Session) complete(ok bool) {
+	if ok {
+		switch s.pending.code {
+		case mysqlproto.ComInitDB:
+			s.handshake.Database = s.pending.schema
+			s.handshake.Capabilities |= mysqlproto.ClientConnectWithDB
+		case mysqlproto.ComResetConnection:
+			s.stateful = false
+		}
+	}
+	s.pending = nil
+	s.result = awaitingResult
+}
+
+// lenencInt decodes the length-encoded integer that payload starts with.
+func lenencInt(payload []byte) uint64 {
+	var size int
+	switch payload[0] {
+	case 0xfc:
+		size = 2
+	case 0xfd:
+		size = 3
+	case 0xfe:
+		size = 8
+	default:
+		return uint64(payload[0])
+	}
+
+	var n uint64
+	for i := min(size, len(payload)-1); i >= 1; i-- {
+		n = n<<8 | uint64(payload[i])
+	}
+	return n
+}
+
+// clientConn is the client side of a tracked session.
+type clientConn struct {
+	net.Conn
+	session *Session
+}
+
+func (c clientConn) Read(p []byte) (int, error) {
+	s := c.session
+
+	s.mutex.Lock()
+	if s.detached {
+		s.mutex.Unlock()
+		return 0, errDetached
+	}
+	if len(s.stash) > 0 {
+		n := copy(p, s.stash)
+		s.stash = s.stash[n:]
+		s.fromClient.write(p[:n])
+		s.mutex.Unlock()
+		return n, nil
+	}
+	if err := s.stashErr; err != nil {
+		s.mutex.Unlock()
+		return 0, err
+	}
+	s.mutex.Unlock()
+
+	n, err := c.Conn.Read(p)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.detached {
+		// Read while detaching; replayed once the session resumes.
+		s.stash = append(s.stash, p[:n]...)
+		if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+			s.stashErr = err
+		}
+		return 0, errDetached
+	}
+	s.fromClient.write(p[:n])
+	return n, err
+}
+
+// Detach implements domain.Detachable.
+func (c clientConn) Detach() bool {
+	return c.session.detach()
+}
+
+// NetConn returns the client's connection, for its socket options.
+func (c clientConn) NetConn() net.Conn {
+	return c.Conn
+}
+
+// backendConn is the backend side of a tracked session.
+type backendConn struct {
+	net.Conn
+	session *Session
+}
+
+func (c backendConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+
+	c.session.mutex.Lock()
+	c.session.fromBackend.write(p[:n])
+	c.session.mutex.Unlock()
+
+	return n, err
+}
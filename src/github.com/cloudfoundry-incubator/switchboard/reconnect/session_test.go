@@ -0,0 +1,215 @@
+package reconnect_test
+
+import (
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+	"github.com/cloudfoundry-incubator/switchboard/reconnect"
+)
+
+const trackedCapabilities = mysqlproto.ClientProtocol41 | mysqlproto.ClientSecureConnection |
+	mysqlproto.ClientPluginAuth | mysqlproto.ClientSessionTrack | mysqlproto.ClientTransactions
+
+// relay writes packets to one end of a pipe and reads them from conn, the
+// other end as seen by the session.
+func relay(end, conn net.Conn, packets ...mysqlproto.Packet) {
+	go func() {
+		defer GinkgoRecover()
+		for _, p := range packets {
+			Expect(mysqlproto.WritePacket(end, p)).To(Succeed())
+		}
+	}()
+	for range packets {
+		_, err := mysqlproto.ReadPacket(conn)
+		Expect(err).NotTo(HaveOccurred())
+	}
+}
+
+func command(code byte, arg string) mysqlproto.Packet {
+	return mysqlproto.Packet{Payload: append([]byte{code}, arg...)}
+}
+
+func ok(sequence byte, status uint16) mysqlproto.Packet {
+	return mysqlproto.Packet{Sequence: sequence, Payload: []byte{0x00, 0x00, 0x00, byte(status), byte(status >> 8), 0x00, 0x00}}
+}
+
+func eof(sequence byte, status uint16) mysqlproto.Packet {
+	return mysqlproto.Packet{Sequence: sequence, Payload: []byte{0xfe, 0x00, 0x00, byte(status), byte(status >> 8)}}
+}
+
+func resultSet(status uint16) []mysqlproto.Packet {
+	return []mysqlproto.Packet{
+		{Sequence: 1, Payload: []byte{0x01}},
+		{Sequence: 2, Payload: []byte("\x03def\x00\x00\x00\x01a\x00\x0c\x3f\x00\x01\x00\x00\x00\x03\x00\x00\x00\x00\x00")},
+		eof(3, 0),
+		{Sequence: 4, Payload: []byte{0x01, '1'}},
+		{Sequence: 5, Payload: []byte{0xfb}},
+		eof(6, status),
+	}
+}
+
+var _ = Describe("Session", func() {
+	var (
+		session                   *reconnect.Session
+		clientEnd, backendEnd     net.Conn
+		client, backend           net.Conn
+		capabilities              uint32
+		proxyClient, proxyBackend net.Conn
+	)
+
+	BeforeEach(func() {
+		capabilities = trackedCapabilities
+	})
+
+	JustBeforeEach(func() {
+		clientEnd, proxyClient = net.Pipe()
+		backendEnd, proxyBackend = net.Pipe()
+
+		handshake := mysqlproto.HandshakeResponse{Capabilities: capabilities, Username: "app"}
+		Expect(reconnect.Supports(handshake)).To(BeTrue())
+		session = reconnect.NewSession(proxyClient, handshake, capabilities)
+		client = session.Client()
+		backend = session.Backend(proxyBackend)
+	})
+
+	AfterEach(func() {
+		clientEnd.Close()
+		backendEnd.Close()
+		proxyClient.Close()
+		proxyBackend.Close()
+	})
+
+	It("is idle once the backend has answered each command", func() {
+		Expect(session.Idle()).To(BeTrue())
+
+		relay(clientEnd, client, command(mysqlproto.ComQuery, "INSERT INTO t VALUES (1)"))
+		Expect(session.Idle()).To(BeFalse())
+
+		relay(backendEnd, backend, ok(1, 0x0002))
+		Expect(session.Idle()).To(BeTrue())
+	})
+
+	It("is not idle within a transaction", func() {
+		relay(clientEnd, client, command(mysqlproto.ComQuery, "BEGIN"))
+		relay(backendEnd, backend, ok(1, mysqlproto.ServerStatusInTrans))
+		Expect(session.Idle()).To(BeFalse())
+
+		relay(clientEnd, client, command(mysqlproto.ComQuery, "COMMIT"))
+		relay(backendEnd, backend, ok(1, 0x0002))
+		Expect(session.Idle()).To(BeTrue())
+	})
+
+	It("follows result sets to their end", func() {
+		relay(clientEnd, client, command(mysqlproto.ComQuery, "SELECT 1"))
+		packets := resultSet(0x0002)
+		relay(backendEnd, backend, packets[:5]...)
+		Expect(session.Idle()).To(BeFalse())
+
+		relay(backendEnd, backend, packets[5])
+		Expect(session.Idle()).To(BeTrue())
+	})
+
+	It("follows multiple result sets", func() {
+		relay(clientEnd, client, command(mysqlproto.ComQuery, "SELECT 1; SELECT 1"))
+		relay(backendEnd, backend, resultSet(mysqlproto.ServerMoreResultsExists)...)
+		Expect(session.Idle()).To(BeFalse())
+
+		relay(backendEnd, backend, resultSet(0x0002)...)
+		Expect(session.Idle()).To(BeTrue())
+	})
+
+	Context("when the client does not expect EOF packets", func() {
+		BeforeEach(func() {
+			capabilities |= mysqlproto.ClientDeprecateEOF
+		})
+
+		It("follows result sets to the OK packet that ends them", func() {
+			relay(clientEnd, client, command(mysqlproto.ComQuery, "SELECT 1"))
+			packets := resultSet(0)
+			relay(backendEnd, backend, packets[0], packets[1], packets[3])
+			Expect(session.Idle()).To(BeFalse())
+
+			relay(backendEnd, backend, mysqlproto.Packet{Sequence: 4, Payload: []byte{0xfe, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00}})
+			Expect(session.Idle()).To(BeTrue())
+		})
+	})
+
+	It("is not idle once the session state changed, until the connection is reset", func() {
+		relay(clientEnd, client, command(mysqlproto.ComQuery, "SET @a = 1"))
+		relay(backendEnd, backend, ok(1, 0x0002|mysqlproto.ServerSessionStateChanged))
+		Expect(session.Idle()).To(BeFalse())
+
+		relay(clientEnd, client, command(mysqlproto.ComResetConnection, ""))
+		relay(backendEnd, backend, ok(1, 0x0002|mysqlproto.ServerSessionStateChanged))
+		Expect(session.Idle()).To(BeTrue())
+	})
+
+	It("is not idle once a statement may have taken a lock", func() {
+		relay(clientEnd, client, command(mysqlproto.ComQuery, "SELECT GET_LOCK('job', 10)"))
+		relay(backendEnd, backend, resultSet(0x0002)...)
+		Expect(session.Idle()).To(BeFalse())
+	})
+
+	It("stops tracking sessions that use other commands", func() {
+		relay(clientEnd, client, command(mysqlproto.ComStmtPrepare, "SELECT ?"))
+		Expect(session.Idle()).To(BeFalse())
+
+		relay(clientEnd, client, command(mysqlproto.ComResetConnection, ""))
+		relay(backendEnd, backend, ok(1, 0x0002))
+		Expect(session.Idle()).To(BeFalse())
+	})
+
+	It("stops tracking sessions that load local files", func() {
+		relay(clientEnd, client, command(mysqlproto.ComQuery, "LOAD DATA LOCAL INFILE 'x' INTO TABLE t"))
+		relay(backendEnd, backend, mysqlproto.Packet{Sequence: 1, Payload: []byte("\xfbx")})
+		Expect(session.Idle()).To(BeFalse())
+	})
+
+	It("keeps the schema the client selects for the next backend", func() {
+		relay(clientEnd, client, command(mysqlproto.ComInitDB, "orders"))
+		relay(backendEnd, backend, ok(1, 0x0002|mysqlproto.ServerSessionStateChanged))
+		Expect(session.Idle()).To(BeTrue())
+
+		handshake := session.Handshake()
+		Expect(handshake.Database).To(Equal("orders"))
+		Expect(handshake.Capabilities & mysqlproto.ClientConnectWithDB).NotTo(BeZero())
+	})
+
+	It("detaches idle clients until the session resumes", func() {
+		read := make(chan error, 1)
+		go func() {
+			_, err := client.Read(make([]byte, 16))
+			read <- err
+		}()
+
+		Expect(client.(domain.Detachable).Detach()).To(BeTrue())
+		Eventually(read).Should(Receive(HaveOccurred()))
+
+		go func() {
+			defer GinkgoRecover()
+			Expect(mysqlproto.WritePacket(clientEnd, command(mysqlproto.ComPing, ""))).To(Succeed())
+		}()
+		Consistently(session.Idle).Should(BeTrue())
+
+		session.Resume()
+		p, err := mysqlproto.ReadPacket(client)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p).To(Equal(command(mysqlproto.ComPing, "")))
+		Expect(session.Idle()).To(BeFalse())
+	})
+
+	It("does not detach busy clients", func() {
+		relay(clientEnd, client, command(mysqlproto.ComQuery, "SELECT SLEEP(10)"))
+		Expect(client.(domain.Detachable).Detach()).To(BeFalse())
+	})
+
+	It("does not track clients using TLS or compression", func() {
+		Expect(reconnect.Supports(mysqlproto.HandshakeResponse{Capabilities: trackedCapabilities | mysqlproto.ClientSSL})).To(BeFalse())
+		Expect(reconnect.Supports(mysqlproto.HandshakeResponse{Capabilities: trackedCapabilities | mysqlproto.ClientCompress})).To(BeFalse())
+		Expect(reconnect.Supports(mysqlproto.HandshakeResponse{Capabilities: trackedCapabilities &^ mysqlproto.ClientSessionTrack})).To(BeFalse())
+	})
+})
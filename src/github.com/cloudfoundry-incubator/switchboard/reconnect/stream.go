@@ -0,0 +1,77 @@
+package reconnect
+
+import "github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+
+// packet is a packet of a stream, with at most the stream's max bytes of
+// its payload.
+type packet struct {
+	sequence byte
+	length   int
+	payload  []byte
+	// continued is set on the packets that carry the rest of a payload of
+	// MaxPayloadLength bytes or more.
+	continued bool
+}
+
+func (p packet) truncated() bool {
+	return len(p.payload) < p.length
+}
+
+// packetStream splits the bytes one side of a session sends into packets,
+// however they are split across reads.
+type packetStream struct {
+	max       int
+	header    []byte
+	length    int
+	remaining int
+	payload   []byte
+	continued bool
+	onPacket  func(packet)
+}
+
+func newPacketStream(max int, onPacket func(packet)) packetStream {
+	return packetStream{max: max, header: make([]byte, 0, 4), onPacket: onPacket}
+}
+
+func (s *packetStream) write(b []byte) {
+	for len(b) > 0 {
+		if len(s.header) < 4 {
+			n := min(4-len(s.header), len(b))
+			s.header = append(s.header, b[:n]...)
+			b = b[n:]
+			if len(s.header) < 4 {
+				return
+			}
+
+			s.length = int(s.header[0]) | int(s.header[1])<<8 | int(s.header[2])<<16
+			s.remaining = s.length
+			s.payload = s.payload[:0]
+			if s.remaining == 0 {
+				s.emit()
+			}
+			continue
+		}
+
+		n := min(s.remaining, len(b))
+		if keep := min(n, s.max-len(s.payload)); keep > 0 {
+			s.payload = append(s.payload, b[:keep]...)
+		}
+		s.remaining -= n
+		b = b[n:]
+		if s.remaining == 0 {
+			s.emit()
+		}
+	}
+}
+
+func (s *packetStream) emit() {
+	p := packet{sequence: s.header[3], length: s.length, payload: s.payload, continued: s.continued}
+	s.continued = s.length == mysqlproto.MaxPayloadLength
+	s.header = s.header[:0]
+	s.onPacket(p)
+}
+
+// idle reports whether the stream ended with a complete packet.
+func (s *packetStream) idle() bool {
+	return len(s.header) == 0 && !s.continued
+}
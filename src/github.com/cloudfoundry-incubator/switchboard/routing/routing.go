@@ -0,0 +1,102 @@
+// Package routing picks the backend of a MySQL session from the user, schema
+// and connection attributes in its handshake.
+package routing
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+)
+
+// Pools a rule can route sessions to.
+const (
+	// PoolWriter is the active backend, where sessions go by default.
+	PoolWriter = "writer"
+	// PoolReaders is the backend the inactive port routes to.
+	PoolReaders = "readers"
+)
+
+// Rule routes the sessions matching all of its non-empty conditions to Pool
+// or to the backend named Backend. User, Schema and attribute values are
+// patterns as accepted by path.Match, such as "batch_*".
+type Rule struct {
+	User       string
+	Schema     string
+	Attributes map[string]string
+	Pool       string
+	Backend    string
+}
+
+// Matches reports whether session meets every condition of r.
+func (r Rule) Matches(session mysqlproto.HandshakeResponse) bool {
+	if r.User != "" && !match(r.User, session.Username) {
+		return false
+	}
+	if r.Schema != "" && !match(r.Schema, session.Database) {
+		return false
+	}
+	for key, pattern := range r.Attributes {
+		value, ok := session.Attributes[key]
+		if !ok || !match(pattern, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func match(pattern, s string) bool {
+	matched, _ := path.Match(pattern, s)
+	return matched
+}
+
+// UsesReaders reports whether any of rules routes to PoolReaders.
+func UsesReaders(rules []Rule) bool {
+	for _, rule := range rules {
+		if rule.Pool == PoolReaders {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate reports whether every rule has a condition, valid patterns and a
+// single target among pools and backendNames.
+func Validate(rules []Rule, backendNames []string) error {
+	for i, rule := range rules {
+		if rule.User == "" && rule.Schema == "" && len(rule.Attributes) == 0 {
+			return fmt.Errorf("rule %d must match on User, Schema or Attributes", i)
+		}
+
+		patterns := []string{rule.User, rule.Schema}
+		for _, pattern := range rule.Attributes {
+			patterns = append(patterns, pattern)
+		}
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("rule %d has an invalid pattern %q", i, pattern)
+			}
+		}
+
+		switch {
+		case rule.Pool != "" && rule.Backend != "":
+			return fmt.Errorf("rule %d must set either Pool or Backend, not both", i)
+		case rule.Pool == "" && rule.Backend == "":
+			return fmt.Errorf("rule %d must set Pool or Backend", i)
+		case rule.Pool != "" && rule.Pool != PoolWriter && rule.Pool != PoolReaders:
+			return fmt.Errorf("rule %d has an unknown pool %q, must be %q or %q", i, rule.Pool, PoolWriter, PoolReaders)
+		case rule.Backend != "" && !contains(backendNames, rule.Backend):
+			return fmt.Errorf("rule %d routes to an unknown backend %q", i, rule.Backend)
+		}
+	}
+	return nil
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
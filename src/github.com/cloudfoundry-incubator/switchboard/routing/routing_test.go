@@ -0,0 +1,31 @@
+package routing_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/routing"
+)
+
+var _ = Describe("Validate", func() {
+	names := []string{"backend-0", "backend-1"}
+
+	DescribeTable("rejects invalid rules",
+		func(rule routing.Rule, message string) {
+			Expect(routing.Validate([]routing.Rule{rule}, names)).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("without conditions", routing.Rule{Pool: routing.PoolReaders}, "must match on User, Schema or Attributes"),
+		Entry("without target", routing.Rule{User: "a"}, "must set Pool or Backend"),
+		Entry("with two targets", routing.Rule{User: "a", Pool: routing.PoolReaders, Backend: "backend-0"}, "not both"),
+		Entry("with an unknown pool", routing.Rule{User: "a", Pool: "replicas"}, `unknown pool "replicas"`),
+		Entry("with an unknown backend", routing.Rule{User: "a", Backend: "backend-9"}, `unknown backend "backend-9"`),
+		Entry("with an invalid pattern", routing.Rule{Schema: "batch_[", Pool: routing.PoolReaders}, `invalid pattern "batch_["`),
+	)
+
+	It("accepts valid rules", func() {
+		Expect(routing.Validate([]routing.Rule{
+			{User: "reporting", Pool: routing.PoolReaders},
+			{Schema: "batch_*", Backend: "backend-1"},
+		}, names)).To(Succeed())
+	})
+})
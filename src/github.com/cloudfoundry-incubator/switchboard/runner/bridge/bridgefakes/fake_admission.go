@@ -0,0 +1,115 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package bridgefakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/switchboard/admission"
+	"github.com/cloudfoundry-incubator/switchboard/runner/bridge"
+)
+
+type FakeAdmission struct {
+	AdmitStub        func(string) (func(), admission.Outcome)
+	admitMutex       sync.RWMutex
+	admitArgsForCall []struct {
+		arg1 string
+	}
+	admitReturns struct {
+		result1 func()
+		result2 admission.Outcome
+	}
+	admitReturnsOnCall map[int]struct {
+		result1 func()
+		result2 admission.Outcome
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeAdmission) Admit(arg1 string) (func(), admission.Outcome) {
+	fake.admitMutex.Lock()
+	ret, specificReturn := fake.admitReturnsOnCall[len(fake.admitArgsForCall)]
+	fake.admitArgsForCall = append(fake.admitArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.AdmitStub
+	fakeReturns := fake.admitReturns
+	fake.recordInvocation("Admit", []interface{}{arg1})
+	fake.admitMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAdmission) AdmitCallCount() int {
+	fake.admitMutex.RLock()
+	defer fake.admitMutex.RUnlock()
+	return len(fake.admitArgsForCall)
+}
+
+func (fake *FakeAdmission) AdmitCalls(stub func(string) (func(), admission.Outcome)) {
+	fake.admitMutex.Lock()
+	defer fake.admitMutex.Unlock()
+	fake.AdmitStub = stub
+}
+
+func (fake *FakeAdmission) AdmitArgsForCall(i int) string {
+	fake.admitMutex.RLock()
+	defer fake.admitMutex.RUnlock()
+	argsForCall := fake.admitArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeAdmission) AdmitReturns(result1 func(), result2 admission.Outcome) {
+	fake.admitMutex.Lock()
+	defer fake.admitMutex.Unlock()
+	fake.AdmitStub = nil
+	fake.admitReturns = struct {
+		result1 func()
+		result2 admission.Outcome
+	}{result1, result2}
+}
+
+func (fake *FakeAdmission) AdmitReturnsOnCall(i int, result1 func(), result2 admission.Outcome) {
+	fake.admitMutex.Lock()
+	defer fake.admitMutex.Unlock()
+	fake.AdmitStub = nil
+	if fake.admitReturnsOnCall == nil {
+		fake.admitReturnsOnCall = make(map[int]struct {
+			result1 func()
+			result2 admission.Outcome
+		})
+	}
+	fake.admitReturnsOnCall[i] = struct {
+		result1 func()
+		result2 admission.Outcome
+	}{result1, result2}
+}
+
+func (fake *FakeAdmission) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeAdmission) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ bridge.Admission = new(FakeAdmission)
@@ -0,0 +1,182 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package bridgefakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/runner/bridge"
+)
+
+type FakeFencer struct {
+	FenceStub        func(*domain.Backend) error
+	fenceMutex       sync.RWMutex
+	fenceArgsForCall []struct {
+		arg1 *domain.Backend
+	}
+	fenceReturns struct {
+		result1 error
+	}
+	fenceReturnsOnCall map[int]struct {
+		result1 error
+	}
+	UnfenceStub        func(*domain.Backend) error
+	unfenceMutex       sync.RWMutex
+	unfenceArgsForCall []struct {
+		arg1 *domain.Backend
+	}
+	unfenceReturns struct {
+		result1 error
+	}
+	unfenceReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeFencer) Fence(arg1 *domain.Backend) error {
+	fake.fenceMutex.Lock()
+	ret, specificReturn := fake.fenceReturnsOnCall[len(fake.fenceArgsForCall)]
+	fake.fenceArgsForCall = append(fake.fenceArgsForCall, struct {
+		arg1 *domain.Backend
+	}{arg1})
+	stub := fake.FenceStub
+	fakeReturns := fake.fenceReturns
+	fake.recordInvocation("Fence", []interface{}{arg1})
+	fake.fenceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeFencer) FenceCallCount() int {
+	fake.fenceMutex.RLock()
+	defer fake.fenceMutex.RUnlock()
+	return len(fake.fenceArgsForCall)
+}
+
+func (fake *FakeFencer) FenceCalls(stub func(*domain.Backend) error) {
+	fake.fenceMutex.Lock()
+	defer fake.fenceMutex.Unlock()
+	fake.FenceStub = stub
+}
+
+func (fake *FakeFencer) FenceArgsForCall(i int) *domain.Backend {
+	fake.fenceMutex.RLock()
+	defer fake.fenceMutex.RUnlock()
+	argsForCall := fake.fenceArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeFencer) FenceReturns(result1 error) {
+	fake.fenceMutex.Lock()
+	defer fake.fenceMutex.Unlock()
+	fake.FenceStub = nil
+	fake.fenceReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFencer) FenceReturnsOnCall(i int, result1 error) {
+	fake.fenceMutex.Lock()
+	defer fake.fenceMutex.Unlock()
+	fake.FenceStub = nil
+	if fake.fenceReturnsOnCall == nil {
+		fake.fenceReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.fenceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFencer) Unfence(arg1 *domain.Backend) error {
+	fake.unfenceMutex.Lock()
+	ret, specificReturn := fake.unfenceReturnsOnCall[len(fake.unfenceArgsForCall)]
+	fake.unfenceArgsForCall = append(fake.unfenceArgsForCall, struct {
+		arg1 *domain.Backend
+	}{arg1})
+	stub := fake.UnfenceStub
+	fakeReturns := fake.unfenceReturns
+	fake.recordInvocation("Unfence", []interface{}{arg1})
+	fake.unfenceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeFencer) UnfenceCallCount() int {
+	fake.unfenceMutex.RLock()
+	defer fake.unfenceMutex.RUnlock()
+	return len(fake.unfenceArgsForCall)
+}
+
+func (fake *FakeFencer) UnfenceCalls(stub func(*domain.Backend) error) {
+	fake.unfenceMutex.Lock()
+	defer fake.unfenceMutex.Unlock()
+	fake.UnfenceStub = stub
+}
+
+func (fake *FakeFencer) UnfenceArgsForCall(i int) *domain.Backend {
+	fake.unfenceMutex.RLock()
+	defer fake.unfenceMutex.RUnlock()
+	argsForCall := fake.unfenceArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeFencer) UnfenceReturns(result1 error) {
+	fake.unfenceMutex.Lock()
+	defer fake.unfenceMutex.Unlock()
+	fake.UnfenceStub = nil
+	fake.unfenceReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFencer) UnfenceReturnsOnCall(i int, result1 error) {
+	fake.unfenceMutex.Lock()
+	defer fake.unfenceMutex.Unlock()
+	fake.UnfenceStub = nil
+	if fake.unfenceReturnsOnCall == nil {
+		fake.unfenceReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.unfenceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFencer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeFencer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ bridge.Fencer = new(FakeFencer)
@@ -0,0 +1,115 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package bridgefakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+	"github.com/cloudfoundry-incubator/switchboard/runner/bridge"
+)
+
+type FakeRouter struct {
+	RouteStub        func(mysqlproto.HandshakeResponse, *domain.Backend, *domain.Backend) *domain.Backend
+	routeMutex       sync.RWMutex
+	routeArgsForCall []struct {
+		arg1 mysqlproto.HandshakeResponse
+		arg2 *domain.Backend
+		arg3 *domain.Backend
+	}
+	routeReturns struct {
+		result1 *domain.Backend
+	}
+	routeReturnsOnCall map[int]struct {
+		result1 *domain.Backend
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeRouter) Route(arg1 mysqlproto.HandshakeResponse, arg2 *domain.Backend, arg3 *domain.Backend) *domain.Backend {
+	fake.routeMutex.Lock()
+	ret, specificReturn := fake.routeReturnsOnCall[len(fake.routeArgsForCall)]
+	fake.routeArgsForCall = append(fake.routeArgsForCall, struct {
+		arg1 mysqlproto.HandshakeResponse
+		arg2 *domain.Backend
+		arg3 *domain.Backend
+	}{arg1, arg2, arg3})
+	stub := fake.RouteStub
+	fakeReturns := fake.routeReturns
+	fake.recordInvocation("Route", []interface{}{arg1, arg2, arg3})
+	fake.routeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRouter) RouteCallCount() int {
+	fake.routeMutex.RLock()
+	defer fake.routeMutex.RUnlock()
+	return len(fake.routeArgsForCall)
+}
+
+func (fake *FakeRouter) RouteCalls(stub func(mysqlproto.HandshakeResponse, *domain.Backend, *domain.Backend) *domain.Backend) {
+	fake.routeMutex.Lock()
+	defer fake.routeMutex.Unlock()
+	fake.RouteStub = stub
+}
+
+func (fake *FakeRouter) RouteArgsForCall(i int) (mysqlproto.HandshakeResponse, *domain.Backend, *domain.Backend) {
+	fake.routeMutex.RLock()
+	defer fake.routeMutex.RUnlock()
+	argsForCall := fake.routeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeRouter) RouteReturns(result1 *domain.Backend) {
+	fake.routeMutex.Lock()
+	defer fake.routeMutex.Unlock()
+	fake.RouteStub = nil
+	fake.routeReturns = struct {
+		result1 *domain.Backend
+	}{result1}
+}
+
+func (fake *FakeRouter) RouteReturnsOnCall(i int, result1 *domain.Backend) {
+	fake.routeMutex.Lock()
+	defer fake.routeMutex.Unlock()
+	fake.RouteStub = nil
+	if fake.routeReturnsOnCall == nil {
+		fake.routeReturnsOnCall = make(map[int]struct {
+			result1 *domain.Backend
+		})
+	}
+	fake.routeReturnsOnCall[i] = struct {
+		result1 *domain.Backend
+	}{result1}
+}
+
+func (fake *FakeRouter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeRouter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ bridge.Router = new(FakeRouter)
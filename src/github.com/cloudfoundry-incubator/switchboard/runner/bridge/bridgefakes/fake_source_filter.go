@@ -0,0 +1,110 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package bridgefakes
+
+import (
+	"net"
+	"sync"
+
+	"github.com/cloudfoundry-incubator/switchboard/runner/bridge"
+)
+
+type FakeSourceFilter struct {
+	AllowsStub        func(net.Addr) bool
+	allowsMutex       sync.RWMutex
+	allowsArgsForCall []struct {
+		arg1 net.Addr
+	}
+	allowsReturns struct {
+		result1 bool
+	}
+	allowsReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSourceFilter) Allows(arg1 net.Addr) bool {
+	fake.allowsMutex.Lock()
+	ret, specificReturn := fake.allowsReturnsOnCall[len(fake.allowsArgsForCall)]
+	fake.allowsArgsForCall = append(fake.allowsArgsForCall, struct {
+		arg1 net.Addr
+	}{arg1})
+	stub := fake.AllowsStub
+	fakeReturns := fake.allowsReturns
+	fake.recordInvocation("Allows", []interface{}{arg1})
+	fake.allowsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSourceFilter) AllowsCallCount() int {
+	fake.allowsMutex.RLock()
+	defer fake.allowsMutex.RUnlock()
+	return len(fake.allowsArgsForCall)
+}
+
+func (fake *FakeSourceFilter) AllowsCalls(stub func(net.Addr) bool) {
+	fake.allowsMutex.Lock()
+	defer fake.allowsMutex.Unlock()
+	fake.AllowsStub = stub
+}
+
+func (fake *FakeSourceFilter) AllowsArgsForCall(i int) net.Addr {
+	fake.allowsMutex.RLock()
+	defer fake.allowsMutex.RUnlock()
+	argsForCall := fake.allowsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSourceFilter) AllowsReturns(result1 bool) {
+	fake.allowsMutex.Lock()
+	defer fake.allowsMutex.Unlock()
+	fake.AllowsStub = nil
+	fake.allowsReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeSourceFilter) AllowsReturnsOnCall(i int, result1 bool) {
+	fake.allowsMutex.Lock()
+	defer fake.allowsMutex.Unlock()
+	fake.AllowsStub = nil
+	if fake.allowsReturnsOnCall == nil {
+		fake.allowsReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.allowsReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeSourceFilter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeSourceFilter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ bridge.SourceFilter = new(FakeSourceFilter)
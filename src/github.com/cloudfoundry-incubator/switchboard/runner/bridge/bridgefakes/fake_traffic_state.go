@@ -0,0 +1,100 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package bridgefakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/switchboard/runner/bridge"
+)
+
+type FakeTrafficState struct {
+	TrafficMessageStub        func() string
+	trafficMessageMutex       sync.RWMutex
+	trafficMessageArgsForCall []struct {
+	}
+	trafficMessageReturns struct {
+		result1 string
+	}
+	trafficMessageReturnsOnCall map[int]struct {
+		result1 string
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeTrafficState) TrafficMessage() string {
+	fake.trafficMessageMutex.Lock()
+	ret, specificReturn := fake.trafficMessageReturnsOnCall[len(fake.trafficMessageArgsForCall)]
+	fake.trafficMessageArgsForCall = append(fake.trafficMessageArgsForCall, struct {
+	}{})
+	stub := fake.TrafficMessageStub
+	fakeReturns := fake.trafficMessageReturns
+	fake.recordInvocation("TrafficMessage", []interface{}{})
+	fake.trafficMessageMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTrafficState) TrafficMessageCallCount() int {
+	fake.trafficMessageMutex.RLock()
+	defer fake.trafficMessageMutex.RUnlock()
+	return len(fake.trafficMessageArgsForCall)
+}
+
+func (fake *FakeTrafficState) TrafficMessageCalls(stub func() string) {
+	fake.trafficMessageMutex.Lock()
+	defer fake.trafficMessageMutex.Unlock()
+	fake.TrafficMessageStub = stub
+}
+
+func (fake *FakeTrafficState) TrafficMessageReturns(result1 string) {
+	fake.trafficMessageMutex.Lock()
+	defer fake.trafficMessageMutex.Unlock()
+	fake.TrafficMessageStub = nil
+	fake.trafficMessageReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeTrafficState) TrafficMessageReturnsOnCall(i int, result1 string) {
+	fake.trafficMessageMutex.Lock()
+	defer fake.trafficMessageMutex.Unlock()
+	fake.TrafficMessageStub = nil
+	if fake.trafficMessageReturnsOnCall == nil {
+		fake.trafficMessageReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.trafficMessageReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *FakeTrafficState) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeTrafficState) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ bridge.TrafficState = new(FakeTrafficState)
@@ -0,0 +1,177 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package bridgefakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/switchboard/runner/bridge"
+)
+
+type FakeUserQuotas struct {
+	AcquireStub        func(string) (func(), bool)
+	acquireMutex       sync.RWMutex
+	acquireArgsForCall []struct {
+		arg1 string
+	}
+	acquireReturns struct {
+		result1 func()
+		result2 bool
+	}
+	acquireReturnsOnCall map[int]struct {
+		result1 func()
+		result2 bool
+	}
+	AcquireUninspectedStub        func() func()
+	acquireUninspectedMutex       sync.RWMutex
+	acquireUninspectedArgsForCall []struct {
+	}
+	acquireUninspectedReturns struct {
+		result1 func()
+	}
+	acquireUninspectedReturnsOnCall map[int]struct {
+		result1 func()
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeUserQuotas) Acquire(arg1 string) (func(), bool) {
+	fake.acquireMutex.Lock()
+	ret, specificReturn := fake.acquireReturnsOnCall[len(fake.acquireArgsForCall)]
+	fake.acquireArgsForCall = append(fake.acquireArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.AcquireStub
+	fakeReturns := fake.acquireReturns
+	fake.recordInvocation("Acquire", []interface{}{arg1})
+	fake.acquireMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeUserQuotas) AcquireCallCount() int {
+	fake.acquireMutex.RLock()
+	defer fake.acquireMutex.RUnlock()
+	return len(fake.acquireArgsForCall)
+}
+
+func (fake *FakeUserQuotas) AcquireCalls(stub func(string) (func(), bool)) {
+	fake.acquireMutex.Lock()
+	defer fake.acquireMutex.Unlock()
+	fake.AcquireStub = stub
+}
+
+func (fake *FakeUserQuotas) AcquireArgsForCall(i int) string {
+	fake.acquireMutex.RLock()
+	defer fake.acquireMutex.RUnlock()
+	argsForCall := fake.acquireArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeUserQuotas) AcquireReturns(result1 func(), result2 bool) {
+	fake.acquireMutex.Lock()
+	defer fake.acquireMutex.Unlock()
+	fake.AcquireStub = nil
+	fake.acquireReturns = struct {
+		result1 func()
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *FakeUserQuotas) AcquireReturnsOnCall(i int, result1 func(), result2 bool) {
+	fake.acquireMutex.Lock()
+	defer fake.acquireMutex.Unlock()
+	fake.AcquireStub = nil
+	if fake.acquireReturnsOnCall == nil {
+		fake.acquireReturnsOnCall = make(map[int]struct {
+			result1 func()
+			result2 bool
+		})
+	}
+	fake.acquireReturnsOnCall[i] = struct {
+		result1 func()
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *FakeUserQuotas) AcquireUninspected() func() {
+	fake.acquireUninspectedMutex.Lock()
+	ret, specificReturn := fake.acquireUninspectedReturnsOnCall[len(fake.acquireUninspectedArgsForCall)]
+	fake.acquireUninspectedArgsForCall = append(fake.acquireUninspectedArgsForCall, struct {
+	}{})
+	stub := fake.AcquireUninspectedStub
+	fakeReturns := fake.acquireUninspectedReturns
+	fake.recordInvocation("AcquireUninspected", []interface{}{})
+	fake.acquireUninspectedMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeUserQuotas) AcquireUninspectedCallCount() int {
+	fake.acquireUninspectedMutex.RLock()
+	defer fake.acquireUninspectedMutex.RUnlock()
+	return len(fake.acquireUninspectedArgsForCall)
+}
+
+func (fake *FakeUserQuotas) AcquireUninspectedCalls(stub func() func()) {
+	fake.acquireUninspectedMutex.Lock()
+	defer fake.acquireUninspectedMutex.Unlock()
+	fake.AcquireUninspectedStub = stub
+}
+
+func (fake *FakeUserQuotas) AcquireUninspectedReturns(result1 func()) {
+	fake.acquireUninspectedMutex.Lock()
+	defer fake.acquireUninspectedMutex.Unlock()
+	fake.AcquireUninspectedStub = nil
+	fake.acquireUninspectedReturns = struct {
+		result1 func()
+	}{result1}
+}
+
+func (fake *FakeUserQuotas) AcquireUninspectedReturnsOnCall(i int, result1 func()) {
+	fake.acquireUninspectedMutex.Lock()
+	defer fake.acquireUninspectedMutex.Unlock()
+	fake.AcquireUninspectedStub = nil
+	if fake.acquireUninspectedReturnsOnCall == nil {
+		fake.acquireUninspectedReturnsOnCall = make(map[int]struct {
+			result1 func()
+		})
+	}
+	fake.acquireUninspectedReturnsOnCall[i] = struct {
+		result1 func()
+	}{result1}
+}
+
+func (fake *FakeUserQuotas) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeUserQuotas) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ bridge.UserQuotas = new(FakeUserQuotas)
@@ -0,0 +1,244 @@
+package bridge
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+)
+
+// reauthenticationPlugin is sent as the client's auth plugin when a session
+// moves to a backend other than the one that greeted the client. No account
+// uses it, so the backend answers with an auth switch request carrying its
+// own scramble, and the client authenticates again against that backend.
+const reauthenticationPlugin = "switchboard_reauthentication"
+
+var (
+	errUserQuotaExceeded = errors.New("user quota exceeded")
+	errSyncWaitFailed    = errors.New("backend rejected wsrep_sync_wait")
+	errTLSRefused        = errors.New("client requested TLS")
+)
+
+// negotiation is the outcome of a client's handshake: the backend the rest of
+// the session is bridged to, and the connection to it.
+type negotiation struct {
+	backend *domain.Backend
+	conn    net.Conn
+	// release ends the session's claim on its user's quota.
+	release func()
+	// session is the client's handshake response, when it was sent to conn
+	// in clear text so that the authentication exchange follows.
+	session *mysqlproto.HandshakeResponse
+	// serverCapabilities are those of the backend that greeted the client.
+	serverCapabilities uint32
+}
+
+// routeByHandshake relays the active backend's greeting to the client, reads
+// the client's handshake response and bridges the session to the backend
+// picked for it.
+func (r Runner) routeByHandshake(clientConn net.Conn, accepted time.Time, writer, reader *domain.Backend) {
+	writerConn, err := writer.Connect()
+	if err != nil {
+		r.logger.Error("Error routing to backend", err)
+		r.refuse(clientConn, accepted, writer, domain.CloseReasonBackendDialFailed)
+		return
+	}
+
+	n, err := r.negotiate(clientConn, writer, writerConn, reader)
+	if err != nil {
+		clientConn.Close()
+		writerConn.Close()
+		r.recordRejected(clientConn, accepted, writer, r.handshakeFailure(clientConn, err))
+		return
+	}
+	defer n.release()
+
+	if r.syncWait == 0 && r.passwords == nil {
+		stats := n.backend.BridgeConnection(clientConn, n.conn)
+		stats.Start = accepted
+		r.record(clientConn, n.backend, stats)
+		return
+	}
+
+	session, err := r.setUpSession(clientConn, n, writer)
+	if err != nil {
+		clientConn.Close()
+		n.conn.Close()
+		r.recordRejected(clientConn, accepted, n.backend, r.handshakeFailure(clientConn, err))
+		return
+	}
+	if session == nil {
+		stats := n.backend.BridgeConnection(clientConn, n.conn)
+		stats.Start = accepted
+		r.record(clientConn, n.backend, stats)
+		return
+	}
+
+	r.bridgeMovable(clientConn, accepted, n.backend, n.conn, session)
+}
+
+func (r Runner) handshakeFailure(clientConn net.Conn, err error) domain.CloseReason {
+	switch err {
+	case errUserQuotaExceeded:
+		return domain.CloseReasonUserQuotaExceeded
+	case errSyncWaitFailed:
+		return domain.CloseReasonSyncWaitFailed
+	case errTLSRefused:
+		return domain.CloseReasonTLSRefused
+	}
+	r.logger.Debug("Failed to relay the client handshake", lager.Data{"client": clientConn.RemoteAddr().String(), "error": err.Error()})
+	return domain.CloseReasonHandshakeFailed
+}
+
+// negotiate relays the handshake until the runner knows where the session
+// goes. TLS upgrade requests and responses that cannot be parsed are passed
+// on to the writer untouched, unless the runner refuses TLS.
+func (r Runner) negotiate(clientConn net.Conn, writer *domain.Backend, writerConn net.Conn, reader *domain.Backend) (negotiation, error) {
+	deadline := time.Now().Add(r.handshakeTimeout)
+	_ = clientConn.SetDeadline(deadline)
+	_ = writerConn.SetDeadline(deadline)
+	defer func() {
+		_ = clientConn.SetDeadline(time.Time{})
+		_ = writerConn.SetDeadline(time.Time{})
+	}()
+
+	toWriter := negotiation{backend: writer, conn: writerConn, release: func() {}}
+
+	greeting, err := mysqlproto.ReadPacket(writerConn)
+	if err != nil {
+		return negotiation{}, fmt.Errorf("reading backend greeting: %w", err)
+	}
+
+	handshake, parseErr := mysqlproto.ParseHandshake(greeting.Payload)
+	if parseErr == nil && r.refuseTLS {
+		if payload, err := mysqlproto.WithoutSSL(greeting.Payload); err == nil {
+			greeting.Payload = payload
+		}
+	}
+	if err := mysqlproto.WritePacket(clientConn, greeting); err != nil {
+		return negotiation{}, err
+	}
+	if parseErr != nil {
+		// Most likely an error packet, such as "Too many connections", after
+		// which the backend closes the connection.
+		return toWriter, nil
+	}
+
+	response, err := mysqlproto.ReadPacket(clientConn)
+	if err != nil {
+		return negotiation{}, fmt.Errorf("reading client handshake response: %w", err)
+	}
+
+	if mysqlproto.IsSSLRequest(response.Payload) {
+		if r.refuseTLS {
+			// The client expects a TLS handshake next, and could not read an
+			// error packet.
+			r.logger.Info("Refused session requesting TLS", lager.Data{"client": clientConn.RemoteAddr().String()})
+			return negotiation{}, errTLSRefused
+		}
+		if r.userQuotas != nil {
+			toWriter.release = r.userQuotas.AcquireUninspected()
+		}
+		return toWriter, mysqlproto.WritePacket(writerConn, response)
+	}
+
+	session, err := mysqlproto.ParseHandshakeResponse(response.Payload)
+	if err != nil {
+		r.logger.Debug("Failed to parse client handshake response", lager.Data{"error": err.Error()})
+		if r.userQuotas != nil {
+			toWriter.release = r.userQuotas.AcquireUninspected()
+		}
+		return toWriter, mysqlproto.WritePacket(writerConn, response)
+	}
+
+	if r.userQuotas != nil {
+		release, ok := r.userQuotas.Acquire(session.Username)
+		if !ok {
+			r.logger.Info("Rejected session of user over its quota", lager.Data{"client": clientConn.RemoteAddr().String(), "user": session.Username})
+			_ = mysqlproto.WritePacket(clientConn, mysqlproto.Packet{
+				Sequence: response.Sequence + 1,
+				Payload: mysqlproto.ErrorPacket(
+					mysqlproto.CodeTooManyUserConnections,
+					"42000",
+					fmt.Sprintf("User '%s' has exceeded its quota of active connections through the proxy", session.Username),
+				),
+			})
+			return negotiation{}, errUserQuotaExceeded
+		}
+		toWriter.release = release
+	}
+
+	if r.router != nil {
+		if target := r.router.Route(session, writer, reader); target != nil && target != writer {
+			targetConn, err := r.reauthenticate(target, handshake, session, response.Sequence)
+			if err == nil {
+				writerConn.Close()
+				r.logger.Debug("Routed session by handshake", lager.Data{
+					"user":    session.Username,
+					"schema":  session.Database,
+					"backend": target.AsJSON().Name,
+				})
+				return negotiation{backend: target, conn: targetConn, release: toWriter.release, session: &session}, nil
+			}
+			r.logger.Error("Failed to route session by handshake, using the active backend", err, lager.Data{
+				"user":    session.Username,
+				"backend": target.AsJSON().Name,
+			})
+		}
+	}
+
+	if err := mysqlproto.WritePacket(writerConn, response); err != nil {
+		toWriter.release()
+		return negotiation{}, err
+	}
+	toWriter.session = &session
+	toWriter.serverCapabilities = handshake.Capabilities
+	return toWriter, nil
+}
+
+// reauthenticate connects to backend and sends it the client's handshake
+// response, modified so that the backend makes the client authenticate again.
+// writerHandshake is the greeting the client chose its capabilities from.
+func (r Runner) reauthenticate(backend *domain.Backend, writerHandshake mysqlproto.Handshake, session mysqlproto.HandshakeResponse, sequence byte) (net.Conn, error) {
+	if session.Capabilities&mysqlproto.ClientPluginAuth == 0 {
+		return nil, errors.New("client does not support switching authentication plugins")
+	}
+
+	conn, err := backend.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	err = func() error {
+		_ = conn.SetDeadline(time.Now().Add(r.handshakeTimeout))
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+
+		greeting, err := mysqlproto.ReadPacket(conn)
+		if err != nil {
+			return fmt.Errorf("reading backend greeting: %w", err)
+		}
+		handshake, err := mysqlproto.ParseHandshake(greeting.Payload)
+		if err != nil {
+			return fmt.Errorf("parsing backend greeting: %w", err)
+		}
+
+		if missing := session.Capabilities & writerHandshake.Capabilities &^ handshake.Capabilities; missing != 0 {
+			return fmt.Errorf("backend does not support capabilities 0x%x the client negotiated", missing)
+		}
+
+		session.AuthPluginName = reauthenticationPlugin
+		session.AuthResponse = nil
+		return mysqlproto.WritePacket(conn, mysqlproto.Packet{Sequence: sequence, Payload: session.Marshal()})
+	}()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
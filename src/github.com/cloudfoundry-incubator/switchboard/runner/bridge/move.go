@@ -0,0 +1,138 @@
+package bridge
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/reconnect"
+)
+
+var errNoBackendToMoveTo = errors.New("no active backend to move the session to")
+
+// activeBackend shares the runner's active backend with the sessions waiting
+// to move to it after a failover.
+type activeBackend struct {
+	mutex          sync.Mutex
+	backend        *domain.Backend
+	trafficEnabled bool
+	changed        chan struct{}
+}
+
+func newActiveBackend(trafficEnabled bool) *activeBackend {
+	return &activeBackend{trafficEnabled: trafficEnabled, changed: make(chan struct{})}
+}
+
+func (a *activeBackend) setBackend(backend *domain.Backend) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.backend = backend
+	a.unsafeNotify()
+}
+
+func (a *activeBackend) setTrafficEnabled(trafficEnabled bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.trafficEnabled = trafficEnabled
+	a.unsafeNotify()
+}
+
+func (a *activeBackend) unsafeNotify() {
+	close(a.changed)
+	a.changed = make(chan struct{})
+}
+
+// await returns the active backend once there is one and traffic is enabled,
+// waiting at most timeout.
+func (a *activeBackend) await(timeout time.Duration) (*domain.Backend, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		a.mutex.Lock()
+		backend, trafficEnabled, changed := a.backend, a.trafficEnabled, a.changed
+		a.mutex.Unlock()
+
+		if backend != nil && trafficEnabled {
+			return backend, nil
+		}
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return nil, errNoBackendToMoveTo
+		}
+	}
+}
+
+// bridgeMovable bridges a tracked session to backend and, each time a
+// failover detaches it, to the next active backend. Each backend's part of the
+// session is recorded on its own: the first from when the client was
+// accepted, the others from when the failover detached it.
+func (r Runner) bridgeMovable(clientConn net.Conn, accepted time.Time, backend *domain.Backend, backendConn net.Conn, session *reconnect.Session) {
+	start := accepted
+	for {
+		stats := backend.BridgeConnection(session.Client(), session.Backend(backendConn))
+		stats.Start = start
+		r.record(clientConn, backend, stats)
+		if stats.CloseReason != domain.CloseReasonMovedByFailover {
+			return
+		}
+		start = stats.End
+
+		previous := backend.AsJSON().Name
+		var err error
+		backend, backendConn, err = r.moveSession(session)
+		if err != nil {
+			r.logger.Error("Failed to move session to the new active backend", err, lager.Data{
+				"client":  clientConn.RemoteAddr().String(),
+				"user":    session.Handshake().Username,
+				"backend": previous,
+			})
+			clientConn.Close()
+			r.recordRejected(clientConn, start, backend, domain.CloseReasonReconnectFailed)
+			return
+		}
+
+		r.logger.Info("Moved idle session to the new active backend", lager.Data{
+			"client":   clientConn.RemoteAddr().String(),
+			"user":     session.Handshake().Username,
+			"previous": previous,
+			"backend":  backend.AsJSON().Name,
+		})
+		session.Resume()
+	}
+}
+
+// moveSession connects to the active backend and authenticates the session
+// with it, using the password the runner holds for the session's user. It
+// returns the backend it tried to connect to, if any, even when it fails.
+func (r Runner) moveSession(session *reconnect.Session) (*domain.Backend, net.Conn, error) {
+	backend, err := r.active.await(r.reconnectTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn, err := backend.Connect()
+	if err != nil {
+		return backend, nil, err
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(r.handshakeTimeout))
+	handshake := session.Handshake()
+	err = reconnect.Authenticate(conn, handshake, session.Capabilities(), r.passwords[handshake.Username])
+	if err == nil {
+		err = r.prepareMovedSession(conn)
+	}
+	if err != nil {
+		conn.Close()
+		return backend, nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	return backend, conn, nil
+}
@@ -0,0 +1,55 @@
+package bridge
+
+import (
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+	"github.com/cloudfoundry-incubator/switchboard/routing"
+)
+
+// RuleRouter is a Router that applies the first matching routing.Rule to
+// each session.
+type RuleRouter struct {
+	rules    []routing.Rule
+	backends map[string]*domain.Backend
+}
+
+// NewRuleRouter returns a RuleRouter for rules, which may route to any of
+// backends by name.
+func NewRuleRouter(rules []routing.Rule, backends map[string]*domain.Backend) (*RuleRouter, error) {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+
+	if err := routing.Validate(rules, names); err != nil {
+		return nil, err
+	}
+
+	return &RuleRouter{rules: rules, backends: backends}, nil
+}
+
+// Route returns the backend for session, given the backends currently
+// chosen for writes and reads. Sessions that match no rule, or whose target
+// is unavailable, go to writer.
+func (r *RuleRouter) Route(session mysqlproto.HandshakeResponse, writer, reader *domain.Backend) *domain.Backend {
+	for _, rule := range r.rules {
+		if !rule.Matches(session) {
+			continue
+		}
+
+		var target *domain.Backend
+		switch {
+		case rule.Backend != "":
+			target = r.backends[rule.Backend]
+		case rule.Pool == routing.PoolReaders:
+			target = reader
+		}
+
+		if target == nil || !target.Healthy() {
+			return writer
+		}
+		return target
+	}
+
+	return writer
+}
@@ -0,0 +1,94 @@
+package bridge_test
+
+import (
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+	"github.com/cloudfoundry-incubator/switchboard/routing"
+	"github.com/cloudfoundry-incubator/switchboard/runner/bridge"
+)
+
+var _ = Describe("RuleRouter", func() {
+	var (
+		writer, reader, batch *domain.Backend
+		backends              []*domain.Backend
+	)
+
+	BeforeEach(func() {
+		logger := lagertest.NewTestLogger("router")
+		writer = domain.NewBackend("backend-0", "10.0.0.1", 3306, 9200, "api/v1/status", logger)
+		reader = domain.NewBackend("backend-1", "10.0.0.2", 3306, 9200, "api/v1/status", logger)
+		batch = domain.NewBackend("backend-2", "10.0.0.3", 3306, 9200, "api/v1/status", logger)
+		backends = []*domain.Backend{writer, reader, batch}
+		for _, b := range backends {
+			b.SetHealthy()
+		}
+	})
+
+	newRouter := func(rules ...routing.Rule) *bridge.RuleRouter {
+		byName := map[string]*domain.Backend{}
+		for _, b := range backends {
+			byName[b.AsJSON().Name] = b
+		}
+		router, err := bridge.NewRuleRouter(rules, byName)
+		Expect(err).NotTo(HaveOccurred())
+		return router
+	}
+
+	It("routes sessions matching no rule to the writer", func() {
+		router := newRouter(routing.Rule{User: "reporting", Pool: routing.PoolReaders})
+
+		Expect(router.Route(mysqlproto.HandshakeResponse{Username: "app"}, writer, reader)).To(Equal(writer))
+	})
+
+	It("routes by user to the readers", func() {
+		router := newRouter(routing.Rule{User: "reporting", Pool: routing.PoolReaders})
+
+		Expect(router.Route(mysqlproto.HandshakeResponse{Username: "reporting"}, writer, reader)).To(Equal(reader))
+	})
+
+	It("routes by schema pattern to a named backend", func() {
+		router := newRouter(routing.Rule{Schema: "batch_*", Backend: "backend-2"})
+
+		Expect(router.Route(mysqlproto.HandshakeResponse{Database: "batch_orders"}, writer, reader)).To(Equal(batch))
+		Expect(router.Route(mysqlproto.HandshakeResponse{Database: "orders"}, writer, reader)).To(Equal(writer))
+	})
+
+	It("routes by connection attributes", func() {
+		router := newRouter(routing.Rule{Attributes: map[string]string{"program_name": "etl-*"}, Pool: routing.PoolReaders})
+
+		Expect(router.Route(mysqlproto.HandshakeResponse{Attributes: map[string]string{"program_name": "etl-nightly"}}, writer, reader)).To(Equal(reader))
+		Expect(router.Route(mysqlproto.HandshakeResponse{Attributes: map[string]string{"_client_name": "libmysql"}}, writer, reader)).To(Equal(writer))
+	})
+
+	It("requires every condition of a rule to match", func() {
+		router := newRouter(routing.Rule{User: "reporting", Schema: "batch_*", Backend: "backend-2"})
+
+		Expect(router.Route(mysqlproto.HandshakeResponse{Username: "reporting", Database: "orders"}, writer, reader)).To(Equal(writer))
+		Expect(router.Route(mysqlproto.HandshakeResponse{Username: "reporting", Database: "batch_x"}, writer, reader)).To(Equal(batch))
+	})
+
+	It("applies the first matching rule", func() {
+		router := newRouter(
+			routing.Rule{User: "reporting", Pool: routing.PoolWriter},
+			routing.Rule{User: "*", Pool: routing.PoolReaders},
+		)
+
+		Expect(router.Route(mysqlproto.HandshakeResponse{Username: "reporting"}, writer, reader)).To(Equal(writer))
+		Expect(router.Route(mysqlproto.HandshakeResponse{Username: "app"}, writer, reader)).To(Equal(reader))
+	})
+
+	It("falls back to the writer when the target is unavailable", func() {
+		router := newRouter(
+			routing.Rule{User: "reporting", Pool: routing.PoolReaders},
+			routing.Rule{User: "batch", Backend: "backend-2"},
+		)
+		batch.SetUnhealthy()
+
+		Expect(router.Route(mysqlproto.HandshakeResponse{Username: "reporting"}, writer, nil)).To(Equal(writer))
+		Expect(router.Route(mysqlproto.HandshakeResponse{Username: "batch"}, writer, reader)).To(Equal(writer))
+	})
+})
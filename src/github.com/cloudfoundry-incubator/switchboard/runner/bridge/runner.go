@@ -4,24 +4,119 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strconv"
 	"time"
 
 	"code.cloudfoundry.org/lager/v3"
 
+	"github.com/cloudfoundry-incubator/switchboard/accesslog"
+	"github.com/cloudfoundry-incubator/switchboard/admission"
 	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/listener"
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
 )
 
+// AccessLog records one entry per client connection.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . AccessLog
+type AccessLog interface {
+	Record(accesslog.Entry) error
+}
+
+// Admission decides whether a client connection may proceed to a backend.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Admission
+type Admission interface {
+	Admit(clientIP string) (release func(), outcome admission.Outcome)
+}
+
+// SourceFilter decides whether a client address may connect at all.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . SourceFilter
+type SourceFilter interface {
+	Allows(addr net.Addr) bool
+}
+
+// Fencer stops a backend from accepting writes when it stops being the
+// active backend, and lets it accept writes again when it becomes active.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Fencer
+type Fencer interface {
+	Fence(backend *domain.Backend) error
+	Unfence(backend *domain.Backend) error
+}
+
+// Router picks the backend of a session from its MySQL handshake, given the
+// backends currently chosen for writes and reads.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Router
+type Router interface {
+	Route(session mysqlproto.HandshakeResponse, writer, reader *domain.Backend) *domain.Backend
+}
+
+// UserQuotas limits the concurrent sessions of each MySQL user, and counts
+// the sessions that escape the limits because their user cannot be read.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . UserQuotas
+type UserQuotas interface {
+	Acquire(user string) (release func(), ok bool)
+	AcquireUninspected() (release func())
+}
+
+// TrafficState provides the message an operator gave when disabling traffic.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . TrafficState
+type TrafficState interface {
+	TrafficMessage() string
+}
+
+// refusalWriteTimeout bounds writing the error packet to a refused client.
+const refusalWriteTimeout = time.Second
+
+// acceptedConn is a client connection and the time it was accepted, from
+// which its session is timed.
+type acceptedConn struct {
+	conn     net.Conn
+	accepted time.Time
+}
+
+type admittedConn struct {
+	acceptedConn
+	release func()
+}
+
 type Runner struct {
 	logger             lager.Logger
-	address            string
+	addresses          []listener.Address
 	TrafficEnabledChan chan bool
 	ActiveBackendChan  chan *domain.Backend
+	ReaderBackendChan  chan *domain.Backend
 	timeout            time.Duration
+	trafficEnabled     bool
+	accessLog          AccessLog
+	admission          Admission
+	sourceFilter       SourceFilter
+	fencer             Fencer
+	fenceTimeout       time.Duration
+	handshakeTimeout   time.Duration
+	router             Router
+	userQuotas         UserQuotas
+	refuseTLS          bool
+	trafficState       TrafficState
+	syncWait           uint
+	passwords          map[string]string
+	reconnectTimeout   time.Duration
+	active             *activeBackend
 }
 
 func NewRunner(
-	address string,
+	addresses []listener.Address,
 	timeout time.Duration,
+	trafficEnabled bool,
+	accessLog AccessLog,
+	admission Admission,
+	sourceFilter SourceFilter,
+	fencer Fencer,
 	logger lager.Logger,
 ) Runner {
 	backendChan := make(chan *domain.Backend)
@@ -30,37 +125,158 @@ func NewRunner(
 	return Runner{
 		logger:             logger,
 		ActiveBackendChan:  backendChan,
+		ReaderBackendChan:  make(chan *domain.Backend),
 		TrafficEnabledChan: trafficEnabledChan,
-		address:            address,
+		addresses:          addresses,
 		timeout:            timeout,
+		trafficEnabled:     trafficEnabled,
+		accessLog:          accessLog,
+		admission:          admission,
+		sourceFilter:       sourceFilter,
+		fencer:             fencer,
+		active:             newActiveBackend(trafficEnabled),
 	}
 }
 
+// LimitFenceWait bounds how long new clients are held while a new active
+// backend is fenced: once timeout has passed they are routed to it even if
+// the galera-agents have not answered. Without a limit, they wait for the
+// fencer.
+func (r *Runner) LimitFenceWait(timeout time.Duration) {
+	r.fenceTimeout = timeout
+}
+
+// InspectHandshakes makes the runner relay the server greeting and read each
+// client's handshake response, waiting at most timeout for it, before it
+// picks the session's backend. Routing and user quotas only apply to
+// inspected sessions.
+func (r *Runner) InspectHandshakes(timeout time.Duration) {
+	r.handshakeTimeout = timeout
+}
+
+// RouteByHandshake bridges each inspected session to the backend router
+// picks instead of always the active backend. The router may pick the backend
+// last received on ReaderBackendChan.
+func (r *Runner) RouteByHandshake(router Router) {
+	r.router = router
+}
+
+// LimitUserSessions rejects inspected sessions of users over their quota
+// with a MySQL error.
+func (r *Runner) LimitUserSessions(quotas UserQuotas) {
+	r.userQuotas = quotas
+}
+
+// RefuseTLS stops offering TLS to inspected sessions and closes those that
+// request it anyway, so that no session escapes the user quotas.
+func (r *Runner) RefuseTLS() {
+	r.refuseTLS = true
+}
+
+// EnforceSyncWait makes every inspected session set wsrep_sync_wait to level
+// once it has authenticated, before the client sends any statement, so that
+// its reads wait for the writes the cluster has already committed.
+func (r *Runner) EnforceSyncWait(level uint) {
+	r.syncWait = level
+}
+
+// ReconnectIdleSessions moves inspected sessions to the new active backend on
+// failover, instead of closing them, when they are idle, outside a
+// transaction and hold no session state. Sessions of users in passwords wait
+// at most timeout for a new active backend, and authenticate with it using
+// their user's password.
+func (r *Runner) ReconnectIdleSessions(passwords map[string]string, timeout time.Duration) {
+	r.passwords = passwords
+	r.reconnectTimeout = timeout
+}
+
+// DescribeDisabledTraffic includes the operator's message from state in the
+// error clients receive while traffic is disabled.
+func (r *Runner) DescribeDisabledTraffic(state TrafficState) {
+	r.trafficState = state
+}
+
 func (r Runner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
-	r.logger.Info(fmt.Sprintf("Proxy listening on %s", r.address))
+	r.logger.Info(fmt.Sprintf("Proxy listening on %s", listener.Name(r.addresses)))
 
-	listener, err := net.Listen("tcp", r.address)
+	listeners, err := listener.ListenAll(r.addresses)
 	if err != nil {
 		return err
 	}
 
 	shutdown := make(chan interface{})
-	go func(shutdown <-chan interface{}, listener net.Listener) {
-		trafficEnabled := true
-		var activeBackend *domain.Backend
-		e := make(chan error)
-		c := make(chan net.Conn)
+	e := make(chan error)
+	c := make(chan acceptedConn)
+	for _, l := range listeners {
+		go accept(l, c, e, shutdown)
+	}
+
+	go func(shutdown <-chan interface{}) {
+		trafficEnabled := r.trafficEnabled
+		var activeBackend, readerBackend *domain.Backend
+		admitted := make(chan admittedConn)
+
+		// Fencing is ordered across failovers; the first has nothing to
+		// wait for. lastBackend is the backend the next failover fences.
+		noFencing := make(chan struct{})
+		close(noFencing)
+		var fenced <-chan struct{} = noFencing
+		var lastBackend *domain.Backend
+
+		// While nextBackend is fenced, new clients are held until fencing
+		// finishes or fenceTimeout fires.
+		var (
+			fencing      <-chan struct{}
+			fenceTimer   *time.Timer
+			fenceTimeout <-chan time.Time
+			nextBackend  *domain.Backend
+			held         []admittedConn
+		)
+
+		// switchTo routes new clients, the clients held so far and the
+		// sessions moved by failover to backend.
+		switchTo := func(backend *domain.Backend) {
+			if fenceTimer != nil {
+				fenceTimer.Stop()
+			}
+			fencing, fenceTimer, fenceTimeout, nextBackend = nil, nil, nil, nil
+
+			activeBackend = backend
+			r.active.setBackend(backend)
+
+			for _, h := range held {
+				if !trafficEnabled {
+					h.release()
+					r.refuse(h.conn, h.accepted, nil, domain.CloseReasonTrafficDisabled)
+					continue
+				}
+				r.route(h.acceptedConn, activeBackend, readerBackend, h.release)
+			}
+			held = nil
+		}
+
+		dispatch := func(a acceptedConn, release func()) {
+			if fencing != nil {
+				held = append(held, admittedConn{acceptedConn: a, release: release})
+				return
+			}
+			r.route(a, activeBackend, readerBackend, release)
+		}
 
 		for {
-			go blockingAccept(listener, c, e)
 			select {
 			case <-shutdown:
+				for _, h := range held {
+					h.release()
+					h.conn.Close()
+				}
 				return
 			case t := <-r.TrafficEnabledChan:
 				// ENABLED -> DISABLED
+				r.active.setTrafficEnabled(t)
 				if trafficEnabled && !t {
 					if activeBackend != nil {
-						activeBackend.SeverConnections()
+						activeBackend.SeverConnections(domain.CloseReasonTrafficDisabled)
 					}
 				}
 
@@ -68,36 +284,80 @@ func (r Runner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 
 			case a := <-r.ActiveBackendChan:
 				// NEW ACTIVE BACKEND
+				// Sessions severed below must not move back to the backend
+				// they are severed from, so they wait until a is published.
+				r.active.setBackend(nil)
 				if activeBackend != nil {
-					activeBackend.SeverConnections()
+					activeBackend.SeverConnections(domain.CloseReasonSeveredByFailover)
+				}
+
+				// New clients and sessions severed above go to a only once
+				// it accepts writes and the previous backend does not.
+				if r.fencer != nil {
+					fenced = r.fenceInOrder(fenced, lastBackend, a)
+				}
+				lastBackend = a
+
+				if r.fencer != nil && a != nil {
+					fencing, nextBackend = fenced, a
+					if r.fenceTimeout > 0 {
+						if fenceTimer != nil {
+							fenceTimer.Stop()
+						}
+						fenceTimer = time.NewTimer(r.fenceTimeout)
+						fenceTimeout = fenceTimer.C
+					}
+				} else {
+					switchTo(a)
 				}
 
-				activeBackend = a
 				if a != nil {
 					r.logger.Info("Done severing connections, new active backend:", lager.Data{"backend": a.AsJSON()})
 				} else {
 					r.logger.Info("Done severing connections, new active backend:", lager.Data{"backend": nil})
 				}
 
-			case clientConn := <-c:
+			case <-fencing:
+				switchTo(nextBackend)
+
+			case <-fenceTimeout:
+				r.logger.Error("Fencing did not finish in time, routing to the new active backend", nil, lager.Data{"backend": nextBackend.AsJSON().Name})
+				switchTo(nextBackend)
+
+			case b := <-r.ReaderBackendChan:
+				readerBackend = b
+
+			case a := <-c:
+				clientConn := a.conn
+				if r.sourceFilter != nil && !r.sourceFilter.Allows(clientConn.RemoteAddr()) {
+					r.logger.Info("Rejected client connection from denied source address", lager.Data{"client": clientConn.RemoteAddr().String()})
+					r.refuse(clientConn, a.accepted, nil, domain.CloseReasonSourceDenied)
+					continue
+				}
+
 				if !trafficEnabled {
-					clientConn.Close()
+					r.refuse(clientConn, a.accepted, nil, domain.CloseReasonTrafficDisabled)
 					continue
 				}
 
-				go func(clientConn net.Conn, activeBackend *domain.Backend) {
-					if activeBackend == nil {
-						clientConn.Close()
-						r.logger.Error("No active backend", err)
-						return
-					}
+				if r.admission == nil {
+					dispatch(a, func() {})
+					continue
+				}
+
+				// Admission may queue the connection, so it is routed to whichever
+				// backend is active once it has been admitted.
+				go r.admit(a, admitted, shutdown)
+
+			case a := <-admitted:
+				if !trafficEnabled {
+					a.release()
+					r.refuse(a.conn, a.accepted, nil, domain.CloseReasonTrafficDisabled)
+					continue
+				}
+
+				dispatch(a.acceptedConn, a.release)
 
-					err := activeBackend.Bridge(clientConn)
-					if err != nil {
-						clientConn.Close()
-						r.logger.Error("Error routing to backend", err)
-					}
-				}(clientConn, activeBackend)
 			case err := <-e:
 				if err != nil {
 					r.logger.Error("Error accepting client connection", err)
@@ -105,7 +365,7 @@ func (r Runner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 				}
 			}
 		}
-	}(shutdown, listener)
+	}(shutdown)
 
 	close(ready)
 
@@ -115,19 +375,194 @@ func (r Runner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 	time.Sleep(r.timeout)
 
 	close(shutdown)
-	listener.Close()
+	for _, l := range listeners {
+		l.Close()
+	}
 
 	r.logger.Info("Proxy runner has exited")
 	return nil
 }
 
-func blockingAccept(l net.Listener, c chan<- net.Conn, e chan<- error) {
-	clientConn, err := l.Accept()
+// fenceInOrder fences previous and unfences next once earlier is closed, that
+// is once the fencing of the previous failover has finished. It returns a
+// channel closed once it is done. Fencing runs outside the runner's loop, as
+// a galera-agent that does not answer would otherwise stop the runner from
+// receiving backends and traffic changes for the whole timeout of the agent's
+// client; the loop holds new clients meanwhile.
+func (r Runner) fenceInOrder(earlier <-chan struct{}, previous, next *domain.Backend) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-earlier
+		r.fence(previous, next)
+	}()
+	return done
+}
+
+// fence makes the previous backend read-only and the new one writable. Both
+// are best effort: an unreachable galera-agent must not hold up failover.
+func (r Runner) fence(previous, next *domain.Backend) {
+	if previous != nil && previous != next {
+		if err := r.fencer.Fence(previous); err != nil {
+			r.logger.Error("Failed to fence previous active backend", err, lager.Data{"backend": previous.AsJSON().Name})
+		} else {
+			r.logger.Info("Fenced previous active backend", lager.Data{"backend": previous.AsJSON().Name})
+		}
+	}
+
+	if next != nil {
+		if err := r.fencer.Unfence(next); err != nil {
+			r.logger.Error("Failed to unfence new active backend", err, lager.Data{"backend": next.AsJSON().Name})
+		}
+	}
+}
 
+func (r Runner) admit(a acceptedConn, admitted chan<- admittedConn, shutdown <-chan interface{}) {
+	clientConn := a.conn
+	clientIP, _, err := net.SplitHostPort(clientConn.RemoteAddr().String())
 	if err != nil {
-		e <- err
+		clientIP = clientConn.RemoteAddr().String()
+	}
+
+	release, outcome := r.admission.Admit(clientIP)
+	if !outcome.Admitted() {
+		r.logger.Debug("Client connection rejected by admission control", lager.Data{"client": clientIP, "outcome": outcome})
+		r.refuse(clientConn, a.accepted, nil, domain.CloseReason(outcome))
+		return
+	}
+
+	select {
+	case admitted <- admittedConn{acceptedConn: a, release: release}:
+	case <-shutdown:
+		release()
+		clientConn.Close()
+	}
+}
+
+func (r Runner) route(a acceptedConn, activeBackend, readerBackend *domain.Backend, release func()) {
+	go func() {
+		defer release()
+
+		clientConn := a.conn
+		if activeBackend == nil {
+			r.logger.Error("No active backend", nil)
+			r.refuse(clientConn, a.accepted, nil, domain.CloseReasonNoActiveBackend)
+			return
+		}
+
+		if r.handshakeTimeout > 0 {
+			r.routeByHandshake(clientConn, a.accepted, activeBackend, readerBackend)
+			return
+		}
+
+		stats, err := activeBackend.Bridge(clientConn)
+		if err != nil {
+			r.logger.Error("Error routing to backend", err)
+			r.refuse(clientConn, a.accepted, activeBackend, domain.CloseReasonBackendDialFailed)
+			return
+		}
+
+		stats.Start = a.accepted
+		r.record(clientConn, activeBackend, stats)
+	}()
+}
+
+// refuse sends the client a MySQL error in place of the server greeting, so
+// that it reports why it cannot connect rather than a lost connection, then
+// closes the connection and records it as a session since accepted.
+func (r Runner) refuse(clientConn net.Conn, accepted time.Time, backend *domain.Backend, reason domain.CloseReason) {
+	code, message := r.refusal(clientConn, backend, reason)
+
+	_ = clientConn.SetWriteDeadline(time.Now().Add(refusalWriteTimeout))
+	_ = mysqlproto.WritePacket(clientConn, mysqlproto.Packet{Payload: mysqlproto.PreHandshakeErrorPacket(code, message)})
+	clientConn.Close()
+
+	r.recordRejected(clientConn, accepted, backend, reason)
+}
+
+func (r Runner) refusal(clientConn net.Conn, backend *domain.Backend, reason domain.CloseReason) (code uint16, message string) {
+	switch reason {
+	case domain.CloseReasonSourceDenied:
+		host, _, err := net.SplitHostPort(clientConn.RemoteAddr().String())
+		if err != nil {
+			host = clientConn.RemoteAddr().String()
+		}
+		return mysqlproto.CodeHostNotPrivileged, fmt.Sprintf("switchboard: host '%s' is not allowed to connect", host)
+	case domain.CloseReasonTrafficDisabled:
+		message = "switchboard: traffic disabled"
+		if r.trafficState != nil {
+			if m := r.trafficState.TrafficMessage(); m != "" {
+				message += ": " + m
+			}
+		}
+		return mysqlproto.CodeServerOfflineMode, message
+	case domain.CloseReasonNoActiveBackend:
+		return mysqlproto.CodeServerIsntAvailable, "switchboard: no healthy backend"
+	case domain.CloseReasonBackendDialFailed:
+		return mysqlproto.CodeServerIsntAvailable, fmt.Sprintf("switchboard: cannot connect to backend %s", backend.AsJSON().Name)
+	}
+
+	// Everything else is an admission control outcome.
+	return mysqlproto.CodeConCount, fmt.Sprintf("switchboard: too many connections: %s", reason)
+}
+
+// recordRejected records a session that ended with reason before it was
+// bridged, timed from start so that the time spent waiting for admission, a
+// backend dial or a handshake shows in the access log.
+func (r Runner) recordRejected(clientConn net.Conn, start time.Time, backend *domain.Backend, reason domain.CloseReason) {
+	r.record(clientConn, backend, domain.SessionStats{
+		Start:       start,
+		End:         time.Now(),
+		CloseReason: reason,
+	})
+}
+
+func (r Runner) record(clientConn net.Conn, backend *domain.Backend, stats domain.SessionStats) {
+	if r.accessLog == nil {
 		return
 	}
 
-	c <- clientConn
+	entry := accesslog.Entry{
+		ClientAddress:    clientConn.RemoteAddr().String(),
+		Listener:         listener.Name(r.addresses),
+		Start:            stats.Start,
+		End:              stats.End,
+		DurationMillis:   stats.End.Sub(stats.Start).Milliseconds(),
+		BytesFromClient:  stats.BytesFromClient,
+		BytesFromBackend: stats.BytesFromBackend,
+		CloseReason:      string(stats.CloseReason),
+	}
+
+	if backend != nil {
+		j := backend.AsJSON()
+		entry.Backend = j.Name
+		entry.BackendAddress = net.JoinHostPort(j.Host, strconv.FormatUint(uint64(j.Port), 10))
+	}
+
+	if err := r.accessLog.Record(entry); err != nil {
+		r.logger.Error("Failed to write access log entry", err)
+	}
+}
+
+// accept hands the connections l accepts to c, and its errors to e, until
+// shutdown.
+func accept(l net.Listener, c chan<- acceptedConn, e chan<- error, shutdown <-chan interface{}) {
+	for {
+		clientConn, err := l.Accept()
+		if err != nil {
+			select {
+			case e <- err:
+			case <-shutdown:
+				return
+			}
+			continue
+		}
+
+		select {
+		case c <- acceptedConn{conn: clientConn, accepted: time.Now()}:
+		case <-shutdown:
+			clientConn.Close()
+			return
+		}
+	}
 }
@@ -1,20 +1,37 @@
 package bridge_test
 
 import (
+	"database/sql"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
-	"strconv"
+	"path/filepath"
 	"time"
 
 	"code.cloudfoundry.org/lager/v3/lagertest"
+	"github.com/go-sql-driver/mysql"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/grouper"
 
+	"github.com/cloudfoundry-incubator/switchboard/admission"
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/fakecluster"
+	"github.com/cloudfoundry-incubator/switchboard/listener"
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
 	"github.com/cloudfoundry-incubator/switchboard/runner/bridge"
+	"github.com/cloudfoundry-incubator/switchboard/runner/bridge/bridgefakes"
 )
 
+// tcp is the listener address of host:port.
+func tcp(address string) []listener.Address {
+	return []listener.Address{{Network: "tcp", Address: address}}
+}
+
 var _ = Describe("Bridge Runner", func() {
 	It("shuts down gracefully when signalled", func() {
 		timeout := 100 * time.Millisecond
@@ -22,7 +39,7 @@ var _ = Describe("Bridge Runner", func() {
 		proxyPort := 10000 + GinkgoParallelProcess()
 		logger := lagertest.NewTestLogger("ProxyRunner test")
 
-		proxyRunner := bridge.NewRunner("127.0.0.1:"+strconv.Itoa(proxyPort), timeout, logger)
+		proxyRunner := bridge.NewRunner(listener.TCP([]string{"127.0.0.1"}, uint(proxyPort)), timeout, true, nil, nil, nil, nil, logger)
 		proxyProcess := ifrit.Invoke(proxyRunner)
 
 		Eventually(func() error {
@@ -46,4 +63,1133 @@ var _ = Describe("Bridge Runner", func() {
 		_, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", proxyPort))
 		Expect(err).To(HaveOccurred())
 	})
+
+	Describe("listening on several addresses", func() {
+		var (
+			socketDir    string
+			addresses    []listener.Address
+			accessLog    *bridgefakes.FakeAccessLog
+			proxyProcess ifrit.Process
+		)
+
+		BeforeEach(func() {
+			var err error
+			socketDir, err = os.MkdirTemp("", "bridge")
+			Expect(err).NotTo(HaveOccurred())
+
+			port := uint(10050 + GinkgoParallelProcess())
+			addresses = append(
+				listener.TCP([]string{"127.0.0.1", "::1"}, port),
+				listener.Unix(filepath.Join(socketDir, "mysql.sock")),
+			)
+			accessLog = &bridgefakes.FakeAccessLog{}
+
+			logger := lagertest.NewTestLogger("ProxyRunner test")
+			proxyProcess = ifrit.Invoke(bridge.NewRunner(addresses, 0, true, accessLog, nil, nil, nil, logger))
+			Eventually(proxyProcess.Ready()).Should(BeClosed())
+		})
+
+		AfterEach(func() {
+			proxyProcess.Signal(os.Kill)
+			Eventually(proxyProcess.Wait()).Should(Receive())
+			os.RemoveAll(socketDir)
+		})
+
+		It("accepts clients on each of them", func() {
+			for i, a := range addresses {
+				conn, err := net.Dial(a.Network, a.Address)
+				Expect(err).NotTo(HaveOccurred())
+
+				packet, err := mysqlproto.ReadPacket(conn)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(packet.Payload[0]).To(Equal(byte(0xff)))
+				conn.Close()
+
+				Eventually(accessLog.RecordCallCount).Should(Equal(i + 1))
+				entry := accessLog.RecordArgsForCall(i)
+				Expect(entry.Listener).To(Equal(fmt.Sprintf("127.0.0.1:%[1]d,[::1]:%[1]d,unix:%[2]s", 10050+GinkgoParallelProcess(), addresses[2].Address)))
+				Expect(entry.CloseReason).To(Equal(string(domain.CloseReasonNoActiveBackend)))
+			}
+		})
+
+		It("removes the Unix domain socket when it stops", func() {
+			proxyProcess.Signal(os.Kill)
+			Eventually(proxyProcess.Wait()).Should(Receive())
+
+			_, err := os.Stat(addresses[2].Address)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+
+	Describe("rejected connections", func() {
+		var (
+			proxyAddress     string
+			accessLog        *bridgefakes.FakeAccessLog
+			admissionControl bridge.Admission
+			sourceFilter     bridge.SourceFilter
+			trafficState     *bridgefakes.FakeTrafficState
+			proxyRunner      bridge.Runner
+			proxyProcess     ifrit.Process
+			trafficEnabled   bool
+		)
+
+		BeforeEach(func() {
+			proxyAddress = fmt.Sprintf("127.0.0.1:%d", 10100+GinkgoParallelProcess())
+			accessLog = &bridgefakes.FakeAccessLog{}
+			trafficEnabled = true
+			admissionControl = nil
+			sourceFilter = nil
+			trafficState = &bridgefakes.FakeTrafficState{}
+		})
+
+		JustBeforeEach(func() {
+			logger := lagertest.NewTestLogger("ProxyRunner test")
+			proxyRunner = bridge.NewRunner(tcp(proxyAddress), 0, trafficEnabled, accessLog, admissionControl, sourceFilter, nil, logger)
+			proxyRunner.DescribeDisabledTraffic(trafficState)
+			proxyProcess = ifrit.Invoke(proxyRunner)
+		})
+
+		AfterEach(func() {
+			proxyProcess.Signal(os.Kill)
+			Eventually(proxyProcess.Wait()).Should(Receive())
+		})
+
+		// dialAndWaitForClose returns the client's address and the MySQL error
+		// the proxy sent before closing the connection.
+		dialAndWaitForClose := func() (net.Addr, *mysql.MySQLError) {
+			conn, err := net.Dial("tcp", proxyAddress)
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			packet, err := mysqlproto.ReadPacket(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(packet.Sequence).To(BeZero())
+
+			_, err = conn.Read(make([]byte, 1))
+			Expect(err).To(Equal(io.EOF))
+
+			// Clients read an error sent in place of the greeting without a
+			// SQL state, so the packet must not carry one.
+			code, sqlState, message, err := mysqlproto.ParseErrorPacket(packet.Payload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sqlState).To(BeEmpty())
+			Expect(message).NotTo(HavePrefix("#"))
+			return conn.LocalAddr(), &mysql.MySQLError{Number: code, Message: message}
+		}
+
+		It("records connections rejected because there is no active backend", func() {
+			clientAddr, mysqlErr := dialAndWaitForClose()
+			Expect(mysqlErr.Number).To(Equal(mysqlproto.CodeServerIsntAvailable))
+			Expect(mysqlErr.Message).To(Equal("switchboard: no healthy backend"))
+
+			Eventually(accessLog.RecordCallCount).Should(Equal(1))
+			entry := accessLog.RecordArgsForCall(0)
+			Expect(entry.ClientAddress).To(Equal(clientAddr.String()))
+			Expect(entry.Listener).To(Equal(proxyAddress))
+			Expect(entry.Backend).To(BeEmpty())
+			Expect(entry.CloseReason).To(Equal(string(domain.CloseReasonNoActiveBackend)))
+		})
+
+		Context("when traffic is disabled", func() {
+			BeforeEach(func() {
+				trafficEnabled = false
+			})
+
+			It("records connections rejected because traffic is disabled", func() {
+				_, mysqlErr := dialAndWaitForClose()
+				Expect(mysqlErr.Number).To(Equal(mysqlproto.CodeServerOfflineMode))
+				Expect(mysqlErr.Message).To(Equal("switchboard: traffic disabled"))
+
+				Eventually(accessLog.RecordCallCount).Should(Equal(1))
+				Expect(accessLog.RecordArgsForCall(0).CloseReason).To(Equal(string(domain.CloseReasonTrafficDisabled)))
+			})
+
+			It("tells clients the operator's message", func() {
+				trafficState.TrafficMessageReturns("upgrading mysql/0")
+
+				_, mysqlErr := dialAndWaitForClose()
+				Expect(mysqlErr.Message).To(Equal("switchboard: traffic disabled: upgrading mysql/0"))
+			})
+		})
+
+		Context("when the active backend cannot be dialed", func() {
+			It("records the connection as rejected by the backend", func() {
+				listener, err := net.Listen("tcp", "127.0.0.1:0")
+				Expect(err).NotTo(HaveOccurred())
+				port := listener.Addr().(*net.TCPAddr).Port
+				listener.Close()
+
+				backend := domain.NewBackend("backend-0", "127.0.0.1", uint(port), 9200, "api/v1/status", lagertest.NewTestLogger("Backend"))
+				proxyRunner.ActiveBackendChan <- backend
+
+				_, mysqlErr := dialAndWaitForClose()
+				Expect(mysqlErr.Number).To(Equal(mysqlproto.CodeServerIsntAvailable))
+				Expect(mysqlErr.Message).To(Equal("switchboard: cannot connect to backend backend-0"))
+
+				Eventually(accessLog.RecordCallCount).Should(Equal(1))
+				Expect(accessLog.RecordArgsForCall(0).Backend).To(Equal("backend-0"))
+				Expect(accessLog.RecordArgsForCall(0).CloseReason).To(Equal(string(domain.CloseReasonBackendDialFailed)))
+			})
+		})
+
+		Context("when admission control rejects the connection", func() {
+			var fakeAdmission *bridgefakes.FakeAdmission
+
+			BeforeEach(func() {
+				fakeAdmission = &bridgefakes.FakeAdmission{}
+				fakeAdmission.AdmitReturns(nil, admission.RejectedRateLimit)
+				admissionControl = fakeAdmission
+			})
+
+			It("closes the connection and records the admission outcome", func() {
+				_, mysqlErr := dialAndWaitForClose()
+				Expect(mysqlErr.Number).To(Equal(mysqlproto.CodeConCount))
+
+				Eventually(accessLog.RecordCallCount).Should(Equal(1))
+				Expect(accessLog.RecordArgsForCall(0).CloseReason).To(Equal("rejected_rate_limit"))
+
+				Expect(fakeAdmission.AdmitCallCount()).To(Equal(1))
+				Expect(fakeAdmission.AdmitArgsForCall(0)).To(Equal("127.0.0.1"))
+			})
+		})
+
+		Context("when admission control admits the connection", func() {
+			var (
+				released      chan struct{}
+				fakeAdmission *bridgefakes.FakeAdmission
+			)
+
+			BeforeEach(func() {
+				released = make(chan struct{})
+				fakeAdmission = &bridgefakes.FakeAdmission{}
+				fakeAdmission.AdmitReturns(func() { close(released) }, admission.Admitted)
+				admissionControl = fakeAdmission
+			})
+
+			It("releases the session once the connection is closed", func() {
+				dialAndWaitForClose()
+
+				Eventually(released).Should(BeClosed())
+				Eventually(accessLog.RecordCallCount).Should(Equal(1))
+				Expect(accessLog.RecordArgsForCall(0).CloseReason).To(Equal(string(domain.CloseReasonNoActiveBackend)))
+			})
+
+			It("times the session from when the connection was accepted", func() {
+				fakeAdmission.AdmitStub = func(string) (func(), admission.Outcome) {
+					time.Sleep(100 * time.Millisecond)
+					return func() {}, admission.Admitted
+				}
+
+				dialed := time.Now()
+				dialAndWaitForClose()
+
+				Eventually(accessLog.RecordCallCount).Should(Equal(1))
+				entry := accessLog.RecordArgsForCall(0)
+				Expect(entry.Start).To(BeTemporally("~", dialed, 50*time.Millisecond))
+				Expect(entry.DurationMillis).To(BeNumerically(">=", 100))
+			})
+		})
+
+		Context("when the source filter denies the client", func() {
+			var (
+				fakeSourceFilter *bridgefakes.FakeSourceFilter
+				fakeAdmission    *bridgefakes.FakeAdmission
+			)
+
+			BeforeEach(func() {
+				fakeSourceFilter = &bridgefakes.FakeSourceFilter{}
+				fakeSourceFilter.AllowsReturns(false)
+				sourceFilter = fakeSourceFilter
+
+				fakeAdmission = &bridgefakes.FakeAdmission{}
+				admissionControl = fakeAdmission
+			})
+
+			It("closes the connection before admission control sees it", func() {
+				clientAddr, mysqlErr := dialAndWaitForClose()
+				Expect(mysqlErr.Number).To(Equal(mysqlproto.CodeHostNotPrivileged))
+				Expect(mysqlErr.Message).To(Equal("switchboard: host '127.0.0.1' is not allowed to connect"))
+
+				Eventually(accessLog.RecordCallCount).Should(Equal(1))
+				Expect(accessLog.RecordArgsForCall(0).CloseReason).To(Equal(string(domain.CloseReasonSourceDenied)))
+
+				Expect(fakeSourceFilter.AllowsCallCount()).To(Equal(1))
+				Expect(fakeSourceFilter.AllowsArgsForCall(0).String()).To(Equal(clientAddr.String()))
+				Expect(fakeAdmission.AdmitCallCount()).To(BeZero())
+			})
+		})
+	})
+
+	Describe("idle sessions", func() {
+		var (
+			accessLog       *bridgefakes.FakeAccessLog
+			backendListener net.Listener
+			proxyAddress    string
+			proxyProcess    ifrit.Process
+		)
+
+		BeforeEach(func() {
+			var err error
+			backendListener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			go func(backendListener net.Listener) {
+				for {
+					conn, err := backendListener.Accept()
+					if err != nil {
+						return
+					}
+					go func() {
+						_, _ = io.Copy(io.Discard, conn)
+						conn.Close()
+					}()
+				}
+			}(backendListener)
+
+			logger := lagertest.NewTestLogger("ProxyRunner test")
+			backend := domain.NewBackend("backend-0", "127.0.0.1", uint(backendListener.Addr().(*net.TCPAddr).Port), 9200, "api/v1/status", logger)
+			backend.SetSocketOptions(domain.SocketOptions{DialTimeout: time.Second, IdleTimeout: 200 * time.Millisecond})
+
+			accessLog = &bridgefakes.FakeAccessLog{}
+			proxyAddress = fmt.Sprintf("127.0.0.1:%d", 10150+GinkgoParallelProcess())
+			proxyRunner := bridge.NewRunner(tcp(proxyAddress), 0, true, accessLog, nil, nil, nil, logger)
+			proxyProcess = ifrit.Invoke(proxyRunner)
+			proxyRunner.ActiveBackendChan <- backend
+		})
+
+		AfterEach(func() {
+			proxyProcess.Signal(os.Kill)
+			Eventually(proxyProcess.Wait()).Should(Receive())
+			backendListener.Close()
+		})
+
+		It("closes sessions that stay idle for the idle timeout and records why", func() {
+			conn, err := net.Dial("tcp", proxyAddress)
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			_, err = conn.Read(make([]byte, 1))
+			Expect(err).To(Equal(io.EOF))
+
+			Eventually(accessLog.RecordCallCount).Should(Equal(1))
+			entry := accessLog.RecordArgsForCall(0)
+			Expect(entry.Backend).To(Equal("backend-0"))
+			Expect(entry.CloseReason).To(Equal(string(domain.CloseReasonIdleTimeout)))
+			Expect(entry.DurationMillis).To(BeNumerically(">=", 200))
+		})
+	})
+
+	Describe("writer fencing", func() {
+		var (
+			fencer       *bridgefakes.FakeFencer
+			fenceTimeout time.Duration
+			proxyAddress string
+			proxyProcess ifrit.Process
+			proxyRunner  bridge.Runner
+		)
+
+		BeforeEach(func() {
+			fencer = &bridgefakes.FakeFencer{}
+			fenceTimeout = 0
+			proxyAddress = fmt.Sprintf("127.0.0.1:%d", 10200+GinkgoParallelProcess())
+		})
+
+		JustBeforeEach(func() {
+			logger := lagertest.NewTestLogger("ProxyRunner test")
+			proxyRunner = bridge.NewRunner(tcp(proxyAddress), 0, true, nil, nil, nil, fencer, logger)
+			proxyRunner.LimitFenceWait(fenceTimeout)
+			proxyProcess = ifrit.Invoke(proxyRunner)
+		})
+
+		AfterEach(func() {
+			proxyProcess.Signal(os.Kill)
+			Eventually(proxyProcess.Wait()).Should(Receive())
+		})
+
+		It("fences the previous active backend and unfences the new one", func() {
+			logger := lagertest.NewTestLogger("Backend")
+			backend1 := domain.NewBackend("backend-1", "10.0.0.1", 3306, 9200, "api/v1/status", logger)
+			backend2 := domain.NewBackend("backend-2", "10.0.0.2", 3306, 9200, "api/v1/status", logger)
+
+			proxyRunner.ActiveBackendChan <- backend1
+			Eventually(fencer.UnfenceCallCount).Should(Equal(1))
+			Expect(fencer.UnfenceArgsForCall(0)).To(Equal(backend1))
+			Expect(fencer.FenceCallCount()).To(BeZero())
+
+			proxyRunner.ActiveBackendChan <- backend2
+			Eventually(fencer.UnfenceCallCount).Should(Equal(2))
+			Expect(fencer.FenceCallCount()).To(Equal(1))
+			Expect(fencer.FenceArgsForCall(0)).To(Equal(backend1))
+			Expect(fencer.UnfenceArgsForCall(1)).To(Equal(backend2))
+		})
+
+		It("still unfences the new backend when the previous one cannot be fenced", func() {
+			fencer.FenceReturns(errors.New("connection refused"))
+			logger := lagertest.NewTestLogger("Backend")
+			backend1 := domain.NewBackend("backend-1", "10.0.0.1", 3306, 9200, "api/v1/status", logger)
+			backend2 := domain.NewBackend("backend-2", "10.0.0.2", 3306, 9200, "api/v1/status", logger)
+
+			proxyRunner.ActiveBackendChan <- backend1
+			proxyRunner.ActiveBackendChan <- backend2
+			Eventually(fencer.UnfenceCallCount).Should(Equal(2))
+			Expect(fencer.UnfenceArgsForCall(1)).To(Equal(backend2))
+		})
+
+		It("keeps accepting backends and clients while a galera-agent does not answer", func() {
+			unblock := make(chan struct{})
+			defer close(unblock)
+			fencer.FenceStub = func(*domain.Backend) error {
+				<-unblock
+				return nil
+			}
+
+			logger := lagertest.NewTestLogger("Backend")
+			backend1 := domain.NewBackend("backend-1", "10.0.0.1", 3306, 9200, "api/v1/status", logger)
+			backend2 := domain.NewBackend("backend-2", "10.0.0.2", 3306, 9200, "api/v1/status", logger)
+			backend3 := domain.NewBackend("backend-3", "10.0.0.3", 3306, 9200, "api/v1/status", logger)
+
+			proxyRunner.ActiveBackendChan <- backend1
+			proxyRunner.ActiveBackendChan <- backend2
+			Eventually(fencer.FenceCallCount).Should(Equal(1))
+
+			Eventually(proxyRunner.ActiveBackendChan, time.Second).Should(BeSent(backend3))
+			Eventually(proxyRunner.TrafficEnabledChan, time.Second).Should(BeSent(true))
+
+			// Failovers are fenced in order once the agent answers.
+			Consistently(fencer.FenceCallCount).Should(Equal(1))
+			unblock <- struct{}{}
+			unblock <- struct{}{}
+			Eventually(fencer.UnfenceCallCount).Should(Equal(3))
+			Expect(fencer.FenceArgsForCall(0)).To(Equal(backend1))
+			Expect(fencer.FenceArgsForCall(1)).To(Equal(backend2))
+			Expect(fencer.UnfenceArgsForCall(1)).To(Equal(backend2))
+			Expect(fencer.UnfenceArgsForCall(2)).To(Equal(backend3))
+		})
+
+		Context("when a client connects while the previous backend is fenced", func() {
+			var (
+				unblock            chan struct{}
+				backend1, backend2 *domain.Backend
+				backendListener    net.Listener
+				backendConns       chan net.Conn
+				clientConn         net.Conn
+			)
+
+			BeforeEach(func() {
+				unblock = make(chan struct{})
+				fencer.FenceStub = func(*domain.Backend) error {
+					<-unblock
+					return nil
+				}
+
+				var err error
+				backendListener, err = net.Listen("tcp", "127.0.0.1:0")
+				Expect(err).NotTo(HaveOccurred())
+				backendConns = make(chan net.Conn, 1)
+				go func() {
+					conn, err := backendListener.Accept()
+					if err == nil {
+						backendConns <- conn
+					}
+				}()
+
+				logger := lagertest.NewTestLogger("Backend")
+				backend1 = domain.NewBackend("backend-1", "10.0.0.1", 3306, 9200, "api/v1/status", logger)
+				backend2 = domain.NewBackend("backend-2", "127.0.0.1", uint(backendListener.Addr().(*net.TCPAddr).Port), 9200, "api/v1/status", logger)
+			})
+
+			JustBeforeEach(func() {
+				proxyRunner.ActiveBackendChan <- backend1
+				proxyRunner.ActiveBackendChan <- backend2
+				Eventually(fencer.FenceCallCount).Should(Equal(1))
+
+				var err error
+				clientConn, err = net.Dial("tcp", proxyAddress)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				close(unblock)
+				clientConn.Close()
+				backendListener.Close()
+			})
+
+			It("holds the client until fencing has finished", func() {
+				Consistently(backendConns, 200*time.Millisecond).ShouldNot(Receive())
+
+				unblock <- struct{}{}
+				Eventually(backendConns).Should(Receive())
+				Expect(fencer.UnfenceCallCount()).To(Equal(2))
+			})
+
+			Context("and fencing takes longer than the fence timeout", func() {
+				BeforeEach(func() {
+					fenceTimeout = 300 * time.Millisecond
+				})
+
+				It("routes the client to the new backend once the timeout has passed", func() {
+					Consistently(backendConns, 100*time.Millisecond).ShouldNot(Receive())
+					Eventually(backendConns).Should(Receive())
+					Expect(fencer.UnfenceCallCount()).To(Equal(1))
+				})
+			})
+		})
+	})
+
+	Describe("routing by handshake", func() {
+		var (
+			proxyAddress     string
+			accessLog        *bridgefakes.FakeAccessLog
+			router           *bridgefakes.FakeRouter
+			userQuotas       *bridgefakes.FakeUserQuotas
+			refuseTLS        bool
+			writer, other    *domain.Backend
+			backendsProcess  ifrit.Process
+			proxyRunner      bridge.Runner
+			proxyProcess     ifrit.Process
+			handshakeTimeout time.Duration
+		)
+
+		BeforeEach(func() {
+			logger := lagertest.NewTestLogger("ProxyRunner test")
+
+			writerPort := 10400 + GinkgoParallelProcess()
+			otherPort := 10500 + GinkgoParallelProcess()
+			writer = domain.NewBackend("backend-0", "127.0.0.1", uint(writerPort), 9200, "api/v1/status", logger)
+			other = domain.NewBackend("backend-1", "127.0.0.1", uint(otherPort), 9200, "api/v1/status", logger)
+			writer.SetHealthy()
+			other.SetHealthy()
+
+			backendsProcess = ifrit.Invoke(grouper.NewParallel(os.Kill, grouper.Members{
+				{Name: "backend-0", Runner: fakecluster.NewBackendRunner(fmt.Sprintf("127.0.0.1:%d", writerPort), fakecluster.NewNode("fake-node-0", 0), fakecluster.ProtocolMySQL, logger)},
+				{Name: "backend-1", Runner: fakecluster.NewBackendRunner(fmt.Sprintf("127.0.0.1:%d", otherPort), fakecluster.NewNode("fake-node-1", 1), fakecluster.ProtocolMySQL, logger)},
+			}))
+			Eventually(backendsProcess.Ready()).Should(BeClosed())
+
+			proxyAddress = fmt.Sprintf("127.0.0.1:%d", 10300+GinkgoParallelProcess())
+			accessLog = &bridgefakes.FakeAccessLog{}
+			router = &bridgefakes.FakeRouter{}
+			router.RouteStub = func(_ mysqlproto.HandshakeResponse, writer, _ *domain.Backend) *domain.Backend {
+				return writer
+			}
+			userQuotas = nil
+			refuseTLS = false
+			handshakeTimeout = time.Second
+		})
+
+		JustBeforeEach(func() {
+			logger := lagertest.NewTestLogger("ProxyRunner test")
+			proxyRunner = bridge.NewRunner(tcp(proxyAddress), 0, true, accessLog, nil, nil, nil, logger)
+			proxyRunner.InspectHandshakes(handshakeTimeout)
+			proxyRunner.RouteByHandshake(router)
+			if userQuotas != nil {
+				proxyRunner.LimitUserSessions(userQuotas)
+			}
+			if refuseTLS {
+				proxyRunner.RefuseTLS()
+			}
+			proxyProcess = ifrit.Invoke(proxyRunner)
+
+			proxyRunner.ActiveBackendChan <- writer
+			proxyRunner.ReaderBackendChan <- other
+		})
+
+		AfterEach(func() {
+			proxyProcess.Signal(os.Kill)
+			Eventually(proxyProcess.Wait()).Should(Receive())
+			backendsProcess.Signal(os.Kill)
+			Eventually(backendsProcess.Wait()).Should(Receive())
+		})
+
+		ping := func(dsnParams string) error {
+			_ = mysql.SetLogger(&mysql.NopLogger{})
+			db, err := sql.Open("mysql", "reporting:secret@tcp("+proxyAddress+")/"+dsnParams)
+			Expect(err).NotTo(HaveOccurred())
+			defer db.Close()
+			return db.Ping()
+		}
+
+		It("passes the client's handshake to the router", func() {
+			Expect(ping("orders")).To(Succeed())
+
+			Eventually(router.RouteCallCount).Should(Equal(1))
+			session, routedWriter, routedReader := router.RouteArgsForCall(0)
+			Expect(session.Username).To(Equal("reporting"))
+			Expect(session.Database).To(Equal("orders"))
+			Expect(routedWriter).To(Equal(writer))
+			Expect(routedReader).To(Equal(other))
+
+			Eventually(accessLog.RecordCallCount).Should(Equal(1))
+			Expect(accessLog.RecordArgsForCall(0).Backend).To(Equal("backend-0"))
+		})
+
+		Context("when the router picks another backend", func() {
+			BeforeEach(func() {
+				router.RouteStub = func(mysqlproto.HandshakeResponse, *domain.Backend, *domain.Backend) *domain.Backend {
+					return other
+				}
+			})
+
+			It("makes the client authenticate against that backend", func() {
+				Expect(ping("orders")).To(Succeed())
+
+				Eventually(accessLog.RecordCallCount).Should(Equal(1))
+				Expect(accessLog.RecordArgsForCall(0).Backend).To(Equal("backend-1"))
+			})
+		})
+
+		Context("when the user is over its quota", func() {
+			BeforeEach(func() {
+				userQuotas = &bridgefakes.FakeUserQuotas{}
+				userQuotas.AcquireReturns(nil, false)
+			})
+
+			It("rejects the session with a MySQL error", func() {
+				err := ping("orders")
+
+				var mysqlErr *mysql.MySQLError
+				Expect(errors.As(err, &mysqlErr)).To(BeTrue())
+				Expect(mysqlErr.Number).To(Equal(mysqlproto.CodeTooManyUserConnections))
+				Expect(mysqlErr.Message).To(ContainSubstring("User 'reporting' has exceeded its quota"))
+
+				Expect(userQuotas.AcquireArgsForCall(0)).To(Equal("reporting"))
+				Expect(router.RouteCallCount()).To(BeZero())
+				Eventually(accessLog.RecordCallCount).Should(Equal(1))
+				Expect(accessLog.RecordArgsForCall(0).CloseReason).To(Equal(string(domain.CloseReasonUserQuotaExceeded)))
+			})
+		})
+
+		Context("when the user is within its quota", func() {
+			var released chan struct{}
+
+			BeforeEach(func() {
+				released = make(chan struct{})
+				userQuotas = &bridgefakes.FakeUserQuotas{}
+				userQuotas.AcquireReturns(func() { close(released) }, true)
+			})
+
+			It("releases the session once it ends", func() {
+				Expect(ping("orders")).To(Succeed())
+
+				Eventually(released).Should(BeClosed())
+				Expect(userQuotas.AcquireCallCount()).To(Equal(1))
+			})
+		})
+
+		// requestTLS reads the greeting and answers it with a TLS upgrade
+		// request.
+		requestTLS := func(conn net.Conn) {
+			greeting, err := mysqlproto.ReadPacket(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(greeting.Sequence).To(BeZero())
+
+			sslRequest := binary.LittleEndian.AppendUint32(nil, mysqlproto.ClientProtocol41|mysqlproto.ClientSSL)
+			sslRequest = append(sslRequest, make([]byte, 28)...)
+			Expect(mysqlproto.WritePacket(conn, mysqlproto.Packet{Sequence: 1, Payload: sslRequest})).To(Succeed())
+		}
+
+		It("passes TLS upgrade requests to the active backend without routing them", func() {
+			conn, err := net.Dial("tcp", proxyAddress)
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			requestTLS(conn)
+
+			// The fake backend does not speak TLS and answers with OK.
+			reply, err := mysqlproto.ReadPacket(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reply.Sequence).To(Equal(byte(2)))
+			Expect(router.RouteCallCount()).To(BeZero())
+		})
+
+		Context("when user quotas apply", func() {
+			var released chan struct{}
+
+			BeforeEach(func() {
+				released = make(chan struct{})
+				userQuotas = &bridgefakes.FakeUserQuotas{}
+				userQuotas.AcquireUninspectedReturns(func() { close(released) })
+			})
+
+			It("counts TLS sessions as uninspected until they end", func() {
+				conn, err := net.Dial("tcp", proxyAddress)
+				Expect(err).NotTo(HaveOccurred())
+
+				requestTLS(conn)
+				_, err = mysqlproto.ReadPacket(conn)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(userQuotas.AcquireUninspectedCallCount()).To(Equal(1))
+				Expect(userQuotas.AcquireCallCount()).To(BeZero())
+				Consistently(released).ShouldNot(BeClosed())
+
+				conn.Close()
+				Eventually(released).Should(BeClosed())
+			})
+
+			It("counts sessions with an unparseable handshake response as uninspected until they end", func() {
+				conn, err := net.Dial("tcp", proxyAddress)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = mysqlproto.ReadPacket(conn)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(mysqlproto.WritePacket(conn, mysqlproto.Packet{Sequence: 1, Payload: []byte{0x01}})).To(Succeed())
+
+				Eventually(userQuotas.AcquireUninspectedCallCount).Should(Equal(1))
+				Expect(userQuotas.AcquireCallCount()).To(BeZero())
+				Consistently(released).ShouldNot(BeClosed())
+
+				conn.Close()
+				Eventually(released).Should(BeClosed())
+			})
+
+			Context("and TLS is refused", func() {
+				BeforeEach(func() {
+					refuseTLS = true
+				})
+
+				It("closes sessions that request TLS", func() {
+					conn, err := net.Dial("tcp", proxyAddress)
+					Expect(err).NotTo(HaveOccurred())
+					defer conn.Close()
+
+					requestTLS(conn)
+					_, err = io.ReadAll(conn)
+					Expect(err).NotTo(HaveOccurred())
+
+					Eventually(accessLog.RecordCallCount).Should(Equal(1))
+					Expect(accessLog.RecordArgsForCall(0).CloseReason).To(Equal(string(domain.CloseReasonTLSRefused)))
+					Expect(userQuotas.AcquireUninspectedCallCount()).To(BeZero())
+				})
+
+				It("admits clients that go on without TLS", func() {
+					userQuotas.AcquireReturns(func() {}, true)
+
+					Expect(ping("orders")).To(Succeed())
+					Expect(userQuotas.AcquireCallCount()).To(Equal(1))
+				})
+			})
+		})
+
+		Context("when the client does not answer the greeting", func() {
+			BeforeEach(func() {
+				handshakeTimeout = 100 * time.Millisecond
+			})
+
+			It("closes the connection and records the failed handshake", func() {
+				conn, err := net.Dial("tcp", proxyAddress)
+				Expect(err).NotTo(HaveOccurred())
+				defer conn.Close()
+
+				_, err = io.ReadAll(conn)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(accessLog.RecordCallCount).Should(Equal(1))
+				entry := accessLog.RecordArgsForCall(0)
+				Expect(entry.CloseReason).To(Equal(string(domain.CloseReasonHandshakeFailed)))
+				Expect(entry.Backend).To(Equal("backend-0"))
+			})
+		})
+	})
+
+	Describe("enforcing wsrep_sync_wait", func() {
+		var (
+			proxyAddress     string
+			accessLog        *bridgefakes.FakeAccessLog
+			backendListener  net.Listener
+			backend          *domain.Backend
+			proxyProcess     ifrit.Process
+			backendConnChan  chan net.Conn
+			clientConn       net.Conn
+			backendConn      net.Conn
+			clientCapability uint32
+		)
+
+		greeting := []byte{10, '8', '.', '0', 0, 1, 0, 0, 0, 's', 'c', 'r', 'a', 'm', 'b', 'l', 'e', 0, 0, 0}
+
+		BeforeEach(func() {
+			logger := lagertest.NewTestLogger("ProxyRunner test")
+
+			var err error
+			backendListener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			backendConnChan = make(chan net.Conn, 1)
+			go func() {
+				defer GinkgoRecover()
+				conn, err := backendListener.Accept()
+				if err != nil {
+					return
+				}
+				Expect(mysqlproto.WritePacket(conn, mysqlproto.Packet{Payload: greeting})).To(Succeed())
+				backendConnChan <- conn
+			}()
+
+			backendPort := backendListener.Addr().(*net.TCPAddr).Port
+			backend = domain.NewBackend("backend-0", "127.0.0.1", uint(backendPort), 9200, "api/v1/status", logger)
+			backend.SetHealthy()
+
+			proxyAddress = fmt.Sprintf("127.0.0.1:%d", 10900+GinkgoParallelProcess())
+			accessLog = &bridgefakes.FakeAccessLog{}
+			clientCapability = mysqlproto.ClientProtocol41 | mysqlproto.ClientSecureConnection
+		})
+
+		JustBeforeEach(func() {
+			logger := lagertest.NewTestLogger("ProxyRunner test")
+			proxyRunner := bridge.NewRunner(tcp(proxyAddress), 0, true, accessLog, nil, nil, nil, logger)
+			proxyRunner.InspectHandshakes(time.Second)
+			proxyRunner.EnforceSyncWait(3)
+			proxyProcess = ifrit.Invoke(proxyRunner)
+			proxyRunner.ActiveBackendChan <- backend
+
+			var err error
+			clientConn, err = net.Dial("tcp", proxyAddress)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = mysqlproto.ReadPacket(clientConn)
+			Expect(err).NotTo(HaveOccurred())
+			response := mysqlproto.HandshakeResponse{Capabilities: clientCapability, Username: "reporting"}
+			Expect(mysqlproto.WritePacket(clientConn, mysqlproto.Packet{Sequence: 1, Payload: response.Marshal()})).To(Succeed())
+
+			Eventually(backendConnChan).Should(Receive(&backendConn))
+			_, err = mysqlproto.ReadPacket(backendConn)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			clientConn.Close()
+			backendConn.Close()
+			backendListener.Close()
+			proxyProcess.Signal(os.Kill)
+			Eventually(proxyProcess.Wait()).Should(Receive())
+		})
+
+		okPacket := []byte{0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00}
+
+		expectSyncWaitQuery := func() {
+			query, err := mysqlproto.ReadPacket(backendConn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(query.Sequence).To(BeZero())
+			Expect(string(query.Payload)).To(Equal("\x03SET SESSION wsrep_sync_wait = 3"))
+		}
+
+		It("sets wsrep_sync_wait before the client learns it is authenticated", func() {
+			Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{Sequence: 2, Payload: okPacket})).To(Succeed())
+			expectSyncWaitQuery()
+
+			_ = clientConn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+			_, err := mysqlproto.ReadPacket(clientConn)
+			Expect(err).To(MatchError(os.ErrDeadlineExceeded))
+			_ = clientConn.SetReadDeadline(time.Time{})
+
+			Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{Sequence: 1, Payload: okPacket})).To(Succeed())
+
+			authenticated, err := mysqlproto.ReadPacket(clientConn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authenticated).To(Equal(mysqlproto.Packet{Sequence: 2, Payload: okPacket}))
+
+			Expect(mysqlproto.WritePacket(clientConn, mysqlproto.Packet{Payload: []byte{mysqlproto.ComPing}})).To(Succeed())
+			ping, err := mysqlproto.ReadPacket(backendConn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ping.Payload).To(Equal([]byte{mysqlproto.ComPing}))
+		})
+
+		It("relays the authentication exchange first", func() {
+			authSwitch := append([]byte{0xfe}, "mysql_native_password\x00scramble\x00"...)
+			Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{Sequence: 2, Payload: authSwitch})).To(Succeed())
+
+			relayed, err := mysqlproto.ReadPacket(clientConn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(relayed.Payload).To(Equal(authSwitch))
+			Expect(mysqlproto.WritePacket(clientConn, mysqlproto.Packet{Sequence: 3, Payload: []byte("auth data")})).To(Succeed())
+
+			authData, err := mysqlproto.ReadPacket(backendConn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authData).To(Equal(mysqlproto.Packet{Sequence: 3, Payload: []byte("auth data")}))
+
+			Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{Sequence: 4, Payload: okPacket})).To(Succeed())
+			expectSyncWaitQuery()
+		})
+
+		It("relays failed authentications without setting wsrep_sync_wait", func() {
+			denied := mysqlproto.ErrorPacket(1045, "28000", "Access denied for user 'reporting'")
+			Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{Sequence: 2, Payload: denied})).To(Succeed())
+
+			relayed, err := mysqlproto.ReadPacket(clientConn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(relayed.Payload).To(Equal(denied))
+
+			backendConn.Close()
+			Eventually(accessLog.RecordCallCount).Should(Equal(1))
+			Expect(accessLog.RecordArgsForCall(0).CloseReason).To(Equal(string(domain.CloseReasonBackendClosed)))
+		})
+
+		Context("when the backend rejects wsrep_sync_wait", func() {
+			It("refuses the session with the backend's error", func() {
+				Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{Sequence: 2, Payload: okPacket})).To(Succeed())
+				expectSyncWaitQuery()
+				unknown := mysqlproto.ErrorPacket(1193, "HY000", "Unknown system variable 'wsrep_sync_wait'")
+				Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{Sequence: 1, Payload: unknown})).To(Succeed())
+
+				refusal, err := mysqlproto.ReadPacket(clientConn)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(refusal.Sequence).To(Equal(byte(2)))
+				code, _, message, err := mysqlproto.ParseErrorPacket(refusal.Payload)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(code).To(Equal(uint16(1193)))
+				Expect(message).To(Equal("switchboard: cannot set wsrep_sync_wait on backend backend-0: Unknown system variable 'wsrep_sync_wait'"))
+
+				Eventually(accessLog.RecordCallCount).Should(Equal(1))
+				entry := accessLog.RecordArgsForCall(0)
+				Expect(entry.CloseReason).To(Equal(string(domain.CloseReasonSyncWaitFailed)))
+				Expect(entry.Backend).To(Equal("backend-0"))
+			})
+		})
+
+		Context("when the client negotiates compression", func() {
+			BeforeEach(func() {
+				clientCapability |= mysqlproto.ClientCompress
+			})
+
+			It("relays the session without setting wsrep_sync_wait", func() {
+				Expect(mysqlproto.WritePacket(backendConn, mysqlproto.Packet{Sequence: 2, Payload: okPacket})).To(Succeed())
+
+				authenticated, err := mysqlproto.ReadPacket(clientConn)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(authenticated.Payload).To(Equal(okPacket))
+			})
+		})
+	})
+
+	Describe("reconnecting idle sessions", func() {
+		var (
+			proxyAddress          string
+			accessLog             *bridgefakes.FakeAccessLog
+			listeners             []net.Listener
+			backends              []*domain.Backend
+			backendConnChans      []chan net.Conn
+			proxyRunner           bridge.Runner
+			proxyProcess          ifrit.Process
+			fencer                bridge.Fencer
+			clientConn, firstConn net.Conn
+		)
+
+		capabilities := mysqlproto.ClientProtocol41 | mysqlproto.ClientSecureConnection | mysqlproto.ClientPluginAuth |
+			mysqlproto.ClientTransactions | mysqlproto.ClientSessionTrack
+		scramble := []byte("0123456789abcdefghij")
+		okPacket := func(status uint16) []byte {
+			return []byte{0x00, 0x00, 0x00, byte(status), byte(status >> 8), 0x00, 0x00}
+		}
+
+		greeting := func() []byte {
+			p := append([]byte{10}, "8.0.36\x00"...)
+			p = binary.LittleEndian.AppendUint32(p, 1)
+			p = append(p, scramble[:8]...)
+			p = append(p, 0)
+			p = binary.LittleEndian.AppendUint16(p, uint16(capabilities))
+			p = append(p, 0xff)
+			p = binary.LittleEndian.AppendUint16(p, 0x0002)
+			p = binary.LittleEndian.AppendUint16(p, uint16(capabilities>>16))
+			p = append(p, byte(len(scramble)+1))
+			p = append(p, make([]byte, 10)...)
+			p = append(p, scramble[8:]...)
+			p = append(p, 0)
+			return append(p, "mysql_native_password\x00"...)
+		}
+
+		// expectSetUp answers the statements the proxy runs on a backend
+		// once the session has authenticated.
+		expectSetUp := func(conn net.Conn) {
+			query, err := mysqlproto.ReadPacket(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(query.Payload)).To(Equal("\x03SET SESSION session_track_state_change = ON"))
+			Expect(mysqlproto.WritePacket(conn, mysqlproto.Packet{Sequence: 1, Payload: okPacket(0x0002)})).To(Succeed())
+		}
+
+		// exchange sends a command from the client and answers it from conn
+		// with status.
+		exchange := func(conn net.Conn, status uint16) {
+			Expect(mysqlproto.WritePacket(clientConn, mysqlproto.Packet{Payload: []byte{mysqlproto.ComPing}})).To(Succeed())
+			ping, err := mysqlproto.ReadPacket(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ping.Payload).To(Equal([]byte{mysqlproto.ComPing}))
+
+			Expect(mysqlproto.WritePacket(conn, mysqlproto.Packet{Sequence: 1, Payload: okPacket(status)})).To(Succeed())
+			result, err := mysqlproto.ReadPacket(clientConn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Payload).To(Equal(okPacket(status)))
+		}
+
+		BeforeEach(func() {
+			fencer = nil
+		})
+
+		JustBeforeEach(func() {
+			logger := lagertest.NewTestLogger("ProxyRunner test")
+
+			listeners, backends, backendConnChans = nil, nil, nil
+			for i := 0; i < 2; i++ {
+				listener, err := net.Listen("tcp", "127.0.0.1:0")
+				Expect(err).NotTo(HaveOccurred())
+				connChan := make(chan net.Conn, 1)
+				go func() {
+					defer GinkgoRecover()
+					conn, err := listener.Accept()
+					if err != nil {
+						return
+					}
+					Expect(mysqlproto.WritePacket(conn, mysqlproto.Packet{Payload: greeting()})).To(Succeed())
+					connChan <- conn
+				}()
+
+				backend := domain.NewBackend(fmt.Sprintf("backend-%d", i), "127.0.0.1", uint(listener.Addr().(*net.TCPAddr).Port), 9200, "api/v1/status", logger)
+				backend.SetHealthy()
+
+				listeners = append(listeners, listener)
+				backends = append(backends, backend)
+				backendConnChans = append(backendConnChans, connChan)
+			}
+
+			proxyAddress = fmt.Sprintf("127.0.0.1:%d", 10950+GinkgoParallelProcess())
+			accessLog = &bridgefakes.FakeAccessLog{}
+
+			proxyRunner = bridge.NewRunner(tcp(proxyAddress), 0, true, accessLog, nil, nil, fencer, logger)
+			proxyRunner.InspectHandshakes(time.Second)
+			proxyRunner.ReconnectIdleSessions(map[string]string{"app": "secret"}, 500*time.Millisecond)
+			proxyProcess = ifrit.Invoke(proxyRunner)
+			proxyRunner.ActiveBackendChan <- backends[0]
+
+			var err error
+			clientConn, err = net.Dial("tcp", proxyAddress)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = mysqlproto.ReadPacket(clientConn)
+			Expect(err).NotTo(HaveOccurred())
+			response := mysqlproto.HandshakeResponse{
+				Capabilities:   capabilities,
+				Username:       "app",
+				AuthResponse:   []byte("client's own response"),
+				AuthPluginName: "mysql_native_password",
+			}
+			Expect(mysqlproto.WritePacket(clientConn, mysqlproto.Packet{Sequence: 1, Payload: response.Marshal()})).To(Succeed())
+
+			Eventually(backendConnChans[0]).Should(Receive(&firstConn))
+			_, err = mysqlproto.ReadPacket(firstConn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mysqlproto.WritePacket(firstConn, mysqlproto.Packet{Sequence: 2, Payload: okPacket(0x0002)})).To(Succeed())
+			expectSetUp(firstConn)
+
+			authenticated, err := mysqlproto.ReadPacket(clientConn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(authenticated).To(Equal(mysqlproto.Packet{Sequence: 2, Payload: okPacket(0x0002)}))
+		})
+
+		AfterEach(func() {
+			clientConn.Close()
+			firstConn.Close()
+			for _, listener := range listeners {
+				listener.Close()
+			}
+			proxyProcess.Signal(os.Kill)
+			Eventually(proxyProcess.Wait()).Should(Receive())
+		})
+
+		// expectMovedTo expects the session severed from firstConn to
+		// authenticate with backend i.
+		expectMovedTo := func(i int) net.Conn {
+			_, err := firstConn.Read(make([]byte, 1))
+			Expect(err).To(MatchError(io.EOF))
+
+			var conn net.Conn
+			Eventually(backendConnChans[i]).Should(Receive(&conn))
+
+			p, err := mysqlproto.ReadPacket(conn)
+			Expect(err).NotTo(HaveOccurred())
+			response, err := mysqlproto.ParseHandshakeResponse(p.Payload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Username).To(Equal("app"))
+
+			Expect(mysqlproto.WritePacket(conn, mysqlproto.Packet{Sequence: 2, Payload: okPacket(0x0002)})).To(Succeed())
+			expectSetUp(conn)
+			return conn
+		}
+
+		It("moves idle sessions to the new active backend, authenticating with the user's password", func() {
+			exchange(firstConn, 0x0002)
+
+			proxyRunner.ActiveBackendChan <- backends[1]
+			_, err := firstConn.Read(make([]byte, 1))
+			Expect(err).To(MatchError(io.EOF))
+
+			var secondConn net.Conn
+			Eventually(backendConnChans[1]).Should(Receive(&secondConn))
+			defer secondConn.Close()
+
+			p, err := mysqlproto.ReadPacket(secondConn)
+			Expect(err).NotTo(HaveOccurred())
+			response, err := mysqlproto.ParseHandshakeResponse(p.Payload)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Username).To(Equal("app"))
+			expected, err := mysqlproto.ScramblePassword(mysqlproto.NativePassword, "secret", scramble)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.AuthResponse).To(Equal(expected))
+
+			Expect(mysqlproto.WritePacket(secondConn, mysqlproto.Packet{Sequence: 2, Payload: okPacket(0x0002)})).To(Succeed())
+			expectSetUp(secondConn)
+
+			exchange(secondConn, 0x0002)
+
+			Eventually(accessLog.RecordCallCount).Should(Equal(1))
+			entry := accessLog.RecordArgsForCall(0)
+			Expect(entry.CloseReason).To(Equal(string(domain.CloseReasonMovedByFailover)))
+			Expect(entry.Backend).To(Equal("backend-0"))
+		})
+
+		Context("when fencing the previous backend takes a while", func() {
+			var fakeFencer *bridgefakes.FakeFencer
+
+			BeforeEach(func() {
+				fakeFencer = &bridgefakes.FakeFencer{}
+				fakeFencer.FenceStub = func(*domain.Backend) error {
+					time.Sleep(200 * time.Millisecond)
+					return nil
+				}
+				fencer = fakeFencer
+			})
+
+			It("moves idle sessions to the new active backend once it is fenced, not back to the previous one", func() {
+				exchange(firstConn, 0x0002)
+
+				proxyRunner.ActiveBackendChan <- backends[1]
+				secondConn := expectMovedTo(1)
+				defer secondConn.Close()
+				Expect(fakeFencer.FenceCallCount()).To(Equal(1))
+				Expect(fakeFencer.UnfenceCallCount()).To(Equal(2))
+
+				exchange(secondConn, 0x0002)
+			})
+		})
+
+		It("closes sessions within a transaction", func() {
+			exchange(firstConn, mysqlproto.ServerStatusInTrans)
+
+			proxyRunner.ActiveBackendChan <- backends[1]
+			_, err := clientConn.Read(make([]byte, 1))
+			Expect(err).To(MatchError(io.EOF))
+
+			Eventually(accessLog.RecordCallCount).Should(Equal(1))
+			Expect(accessLog.RecordArgsForCall(0).CloseReason).To(Equal(string(domain.CloseReasonSeveredByFailover)))
+			Consistently(backendConnChans[1]).ShouldNot(Receive())
+		})
+
+		It("closes sessions that find no active backend in time", func() {
+			exchange(firstConn, 0x0002)
+
+			proxyRunner.ActiveBackendChan <- nil
+			_, err := clientConn.Read(make([]byte, 1))
+			Expect(err).To(MatchError(io.EOF))
+
+			Eventually(accessLog.RecordCallCount).Should(Equal(2))
+			Expect(accessLog.RecordArgsForCall(0).CloseReason).To(Equal(string(domain.CloseReasonMovedByFailover)))
+			Expect(accessLog.RecordArgsForCall(1).CloseReason).To(Equal(string(domain.CloseReasonReconnectFailed)))
+		})
+	})
 })
@@ -0,0 +1,200 @@
+package bridge
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/mysqlproto"
+	"github.com/cloudfoundry-incubator/switchboard/reconnect"
+)
+
+// trackSessionState makes the backend flag the OK packets of statements that
+// change session state, such as user variables or temporary tables, which a
+// session cannot take to another backend.
+const trackSessionState = "SET SESSION session_track_state_change = ON"
+
+// setUpSession relays the authentication exchange of a negotiated session
+// and, once the backend has accepted the client, prepares the backend before
+// the client learns it is authenticated: it sets wsrep_sync_wait, and turns on
+// session state tracking for sessions that can move to another backend on
+// failover. It returns the tracked session, or nil when the session cannot
+// move. Clients that cannot be authenticated are told so by the backend, and
+// are left to it.
+//
+// Sessions upgraded to TLS or switching to the compressed protocol cannot be
+// modified, and are relayed as they are.
+func (r Runner) setUpSession(clientConn net.Conn, n negotiation, writer *domain.Backend) (*reconnect.Session, error) {
+	if n.session == nil || n.session.Capabilities&mysqlproto.ClientCompress != 0 {
+		if r.syncWait > 0 {
+			r.logger.Info("Relaying session without setting wsrep_sync_wait", lager.Data{
+				"client":  clientConn.RemoteAddr().String(),
+				"backend": n.backend.AsJSON().Name,
+			})
+		}
+		return nil, nil
+	}
+
+	deadline := time.Now().Add(r.handshakeTimeout)
+	_ = clientConn.SetDeadline(deadline)
+	_ = n.conn.SetDeadline(deadline)
+	defer func() {
+		_ = clientConn.SetDeadline(time.Time{})
+		_ = n.conn.SetDeadline(time.Time{})
+	}()
+
+	authenticated, err := relayAuthentication(clientConn, n.conn)
+	if err != nil || authenticated == nil {
+		return nil, err
+	}
+
+	if r.syncWait > 0 {
+		result, err := execute(n.conn, r.syncWaitStatement())
+		if err != nil {
+			return nil, fmt.Errorf("reading wsrep_sync_wait result: %w", err)
+		}
+		if result[0] != 0x00 {
+			r.refuseSyncWait(clientConn, n.backend, *authenticated, result)
+			return nil, errSyncWaitFailed
+		}
+	}
+
+	var session *reconnect.Session
+	if r.movable(n, writer) {
+		result, err := execute(n.conn, trackSessionState)
+		if err != nil {
+			return nil, fmt.Errorf("reading session state tracking result: %w", err)
+		}
+		if result[0] == 0x00 {
+			session = reconnect.NewSession(clientConn, *n.session, n.serverCapabilities)
+		} else {
+			r.logger.Info("Backend rejected session state tracking, session cannot move on failover", lager.Data{
+				"client":  clientConn.RemoteAddr().String(),
+				"backend": n.backend.AsJSON().Name,
+			})
+		}
+	}
+
+	return session, mysqlproto.WritePacket(clientConn, *authenticated)
+}
+
+// movable reports whether the session of n can move to the next active
+// backend on failover. Only sessions bridged to the active backend move, and
+// only those of users the runner has a password for.
+func (r Runner) movable(n negotiation, writer *domain.Backend) bool {
+	if r.passwords == nil || n.backend != writer || !reconnect.Supports(*n.session) {
+		return false
+	}
+	_, ok := r.passwords[n.session.Username]
+	return ok
+}
+
+func (r Runner) syncWaitStatement() string {
+	return fmt.Sprintf("SET SESSION wsrep_sync_wait = %d", r.syncWait)
+}
+
+// refuseSyncWait tells the client that the backend rejected wsrep_sync_wait,
+// in place of the OK packet that would have authenticated it. Reads without
+// wsrep_sync_wait could be stale, so the client is refused rather than given
+// a session without the guarantee it connected for.
+func (r Runner) refuseSyncWait(clientConn net.Conn, backend *domain.Backend, authenticated mysqlproto.Packet, result []byte) {
+	backendName := backend.AsJSON().Name
+	code, sqlState, message, err := mysqlproto.ParseErrorPacket(result)
+	if err != nil {
+		code, sqlState, message = mysqlproto.CodeServerIsntAvailable, "HY000", "unexpected response"
+	}
+	r.logger.Error("Backend rejected wsrep_sync_wait", nil, lager.Data{
+		"client":  clientConn.RemoteAddr().String(),
+		"backend": backendName,
+		"code":    code,
+		"message": message,
+	})
+	_ = mysqlproto.WritePacket(clientConn, mysqlproto.Packet{
+		Sequence: authenticated.Sequence,
+		Payload: mysqlproto.ErrorPacket(
+			code,
+			sqlState,
+			fmt.Sprintf("switchboard: cannot set wsrep_sync_wait on backend %s: %s", backendName, message),
+		),
+	})
+}
+
+// execute sends statement to the backend conn is authenticated with and
+// returns the payload of its response, which is an OK packet when the
+// statement succeeded.
+func execute(conn net.Conn, statement string) ([]byte, error) {
+	// Every command starts a new sequence, which the client never sees.
+	query := append([]byte{mysqlproto.ComQuery}, statement...)
+	if err := mysqlproto.WritePacket(conn, mysqlproto.Packet{Payload: query}); err != nil {
+		return nil, err
+	}
+	result, err := mysqlproto.ReadPacket(conn)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Payload) == 0 {
+		return nil, mysqlproto.ErrMalformedPacket
+	}
+	return result.Payload, nil
+}
+
+// prepareMovedSession runs the statements setUpSession runs on the backend
+// conn a moved session is authenticated with.
+func (r Runner) prepareMovedSession(conn net.Conn) error {
+	statements := []string{trackSessionState}
+	if r.syncWait > 0 {
+		statements = append([]string{r.syncWaitStatement()}, statements...)
+	}
+
+	for _, statement := range statements {
+		result, err := execute(conn, statement)
+		if err != nil {
+			return err
+		}
+		if result[0] != 0x00 {
+			_, _, message, _ := mysqlproto.ParseErrorPacket(result)
+			return fmt.Errorf("%s: %s", statement, message)
+		}
+	}
+	return nil
+}
+
+// relayAuthentication relays the packets that follow the client's handshake
+// response until the backend accepts or rejects the client. It returns the
+// backend's OK packet without relaying it, or nil once it has relayed an
+// error.
+func relayAuthentication(clientConn, backendConn net.Conn) (*mysqlproto.Packet, error) {
+	for {
+		p, err := mysqlproto.ReadPacket(backendConn)
+		if err != nil {
+			return nil, fmt.Errorf("reading backend authentication: %w", err)
+		}
+
+		if len(p.Payload) > 0 && p.Payload[0] == 0x00 {
+			return &p, nil
+		}
+		if err := mysqlproto.WritePacket(clientConn, p); err != nil {
+			return nil, err
+		}
+		if len(p.Payload) == 0 || p.Payload[0] == 0xff {
+			return nil, nil
+		}
+
+		// caching_sha2_password reports a fast authentication success ahead
+		// of the OK packet, without waiting for the client.
+		if len(p.Payload) == 2 && p.Payload[0] == 0x01 && p.Payload[1] == 0x03 {
+			continue
+		}
+
+		p, err = mysqlproto.ReadPacket(clientConn)
+		if err != nil {
+			return nil, fmt.Errorf("reading client authentication: %w", err)
+		}
+		if err := mysqlproto.WritePacket(backendConn, p); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -1,17 +1,70 @@
 package http
 
 import (
+	"context"
 	"crypto/tls"
+	"net"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/tedsuo/ifrit"
-	"github.com/tedsuo/ifrit/http_server"
+
+	"github.com/cloudfoundry-incubator/switchboard/listener"
 )
 
-func NewRunner(address string, handler http.Handler, tlsConfig *tls.Config, enabled bool) ifrit.Runner {
-	if enabled {
-		return http_server.NewTLSServer(address, handler, tlsConfig)
-	} else {
-		return http_server.New(address, handler)
+// shutdownTimeout bounds how long in-flight requests may take to finish
+// once the runner is signalled, as in ifrit's http_server.
+const shutdownTimeout = time.Minute
+
+type runner struct {
+	addresses []listener.Address
+	handler   http.Handler
+	tlsConfig *tls.Config
+}
+
+// NewRunner serves handler on each of addresses, over TLS when enabled.
+func NewRunner(addresses []listener.Address, handler http.Handler, tlsConfig *tls.Config, enabled bool) ifrit.Runner {
+	if !enabled {
+		tlsConfig = nil
+	}
+
+	return &runner{
+		addresses: addresses,
+		handler:   handler,
+		tlsConfig: tlsConfig,
+	}
+}
+
+func (r *runner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	listeners, err := listener.ListenAll(r.addresses)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{Handler: r.handler, TLSConfig: r.tlsConfig}
+
+	serveErrs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		if r.tlsConfig != nil {
+			l = tls.NewListener(l, r.tlsConfig)
+		}
+		go func(l net.Listener) {
+			serveErrs <- server.Serve(l)
+		}(l)
+	}
+
+	close(ready)
+
+	select {
+	case err := <-serveErrs:
+		server.Close()
+		return err
+	case <-signals:
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		server.Shutdown(ctx)
+		return nil
 	}
 }
@@ -10,6 +10,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/tedsuo/ifrit"
 
+	"github.com/cloudfoundry-incubator/switchboard/listener"
 	httprunner "github.com/cloudfoundry-incubator/switchboard/runner/http"
 	"github.com/cloudfoundry-incubator/switchboard/testing"
 )
@@ -37,7 +38,7 @@ var _ = Describe("HTTPRunner", func() {
 
 		runnerURL = "http://" + address
 
-		healthRunner = httprunner.NewRunner(address, handler, nil, false)
+		healthRunner = httprunner.NewRunner(listener.TCP([]string{"127.0.0.1", "::1"}, uint(port)), handler, nil, false)
 		healthProcess = ifrit.Invoke(healthRunner)
 		isReady := healthProcess.Ready()
 		Eventually(isReady, "30s").Should(BeClosed(), "Error starting Health Runner")
@@ -59,6 +60,13 @@ var _ = Describe("HTTPRunner", func() {
 
 			Expect(res.StatusCode).To(Equal(200))
 		})
+
+		It("accepts connections on each of its addresses", func() {
+			res, err := http.Get("http://[::1]:" + strconv.Itoa(10000+GinkgoParallelProcess()))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(res.StatusCode).To(Equal(200))
+		})
 	})
 
 	It("shuts down gracefully when signalled", func() {
@@ -98,7 +106,7 @@ var _ = Describe("HTTPRunnerWithTLS", func() {
 
 		runnerURL = "https://" + address
 
-		healthRunner = httprunner.NewRunner(address, handler, serverTlsCfg, true)
+		healthRunner = httprunner.NewRunner(listener.TCP([]string{"127.0.0.1"}, uint(port)), handler, serverTlsCfg, true)
 		healthProcess = ifrit.Invoke(healthRunner)
 		isReady := healthProcess.Ready()
 		Eventually(isReady, "30s").Should(BeClosed(), "Error starting Health Runner")
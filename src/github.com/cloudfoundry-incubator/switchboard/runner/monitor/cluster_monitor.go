@@ -28,15 +28,20 @@ type BackendStatus struct {
 
 type ClusterMonitor struct {
 	client             UrlGetter
-	backends           []*domain.Backend
+	backends           *domain.BackendSet
 	logger             lager.Logger
 	healthcheckTimeout time.Duration
 	backendSubscribers []chan<- *domain.Backend
+	healthcheckHooks   []func(backend *domain.Backend, healthy bool, latency time.Duration)
 	useLowestIndex     bool
 	useTLSForAgent     bool
+	preferredLabel     string
+	preferredValue     string
+	avoidQuarantined   bool
+	state              monitorState
 }
 
-func NewClusterMonitor(client UrlGetter, useTLSForAgent bool, backends []*domain.Backend, healthcheckTimeout time.Duration, logger lager.Logger, useLowestIndex bool) *ClusterMonitor {
+func NewClusterMonitor(client UrlGetter, useTLSForAgent bool, backends *domain.BackendSet, healthcheckTimeout time.Duration, logger lager.Logger, useLowestIndex bool) *ClusterMonitor {
 	return &ClusterMonitor{
 		client:             client,
 		backends:           backends,
@@ -47,22 +52,109 @@ func NewClusterMonitor(client UrlGetter, useTLSForAgent bool, backends []*domain
 	}
 }
 
+// PreferBackendsLabelled makes the monitor choose among healthy backends
+// whose label key is value, falling back to every healthy backend when none
+// of them are healthy.
+func (c *ClusterMonitor) PreferBackendsLabelled(key, value string) {
+	c.preferredLabel = key
+	c.preferredValue = value
+}
+
+// AvoidQuarantinedBackends makes the monitor skip backends quarantined for
+// flapping, unless every healthy backend is quarantined.
+func (c *ClusterMonitor) AvoidQuarantinedBackends() {
+	c.avoidQuarantined = true
+}
+
 func (c *ClusterMonitor) Monitor(stopChan <-chan interface{}) {
 	backendHealthMap := make(map[*domain.Backend]*BackendStatus)
 
-	for _, backend := range c.backends {
-		backendHealthMap[backend] = &BackendStatus{
-			Index:    -1,
-			Counters: c.SetupCounters(),
+	// A backend whose circuit breaker opens is treated as unhealthy straight
+	// away, rather than at the next healthcheck.
+	circuitOpened := make(chan struct{}, 1)
+
+	// unregister holds, for each tracked backend, the func that stops its
+	// circuit breaker from notifying this monitor.
+	unregister := make(map[*domain.Backend]func())
+
+	// trackBackends follows the backends joining and leaving the set, which
+	// only changes when backends are discovered through DNS.
+	trackBackends := func() {
+		backends := c.backends.All()
+		tracked := make(map[*domain.Backend]bool, len(backends))
+
+		for _, backend := range backends {
+			tracked[backend] = true
+			if _, ok := backendHealthMap[backend]; ok {
+				continue
+			}
+
+			backendHealthMap[backend] = &BackendStatus{
+				Index:    -1,
+				Counters: c.SetupCounters(),
+			}
+			unregister[backend] = backend.OnCircuitOpen(func() {
+				select {
+				case circuitOpened <- struct{}{}:
+				default:
+				}
+			})
+		}
+
+		for backend := range backendHealthMap {
+			if !tracked[backend] {
+				delete(backendHealthMap, backend)
+				unregister[backend]()
+				delete(unregister, backend)
+			}
 		}
 	}
 
+	trackBackends()
+	c.recordBackends(backendHealthMap)
+
 	go func() {
-		var activeBackend *domain.Backend
+		var (
+			activeBackend *domain.Backend
+			published     bool
+		)
+
+		// publish sends the active backend to the subscribers when it
+		// changes. The first choice is always sent, even when no backend is
+		// healthy, so that subscribers learn the initial state.
+		publish := func() {
+			newActiveBackend := c.chooseActiveBackend(backendHealthMap)
+
+			if !published || newActiveBackend != activeBackend {
+				published = true
+				if newActiveBackend != nil {
+					c.logger.Info("New active backend", lager.Data{"backend": newActiveBackend.AsJSON()})
+				}
+
+				activeBackend = newActiveBackend
+				c.recordPublishing(activeBackend, true)
+				for _, s := range c.backendSubscribers {
+					s <- activeBackend
+				}
+				c.recordPublishing(activeBackend, false)
+			}
+		}
 
 		for {
 			select {
+			case <-circuitOpened:
+				for backend, healthStatus := range backendHealthMap {
+					if backend.CircuitOpen() {
+						healthStatus.Healthy = false
+					}
+				}
+
+				c.recordBackends(backendHealthMap)
+				publish()
+
 			case <-time.After(c.healthcheckTimeout / 5):
+				trackBackends()
+
 				var wg sync.WaitGroup
 
 				for backend, healthStatus := range backendHealthMap {
@@ -75,20 +167,13 @@ func (c *ClusterMonitor) Monitor(stopChan <-chan interface{}) {
 
 				wg.Wait()
 
-				newActiveBackend := ChooseActiveBackend(backendHealthMap, c.useLowestIndex)
-
-				if newActiveBackend != activeBackend {
-					if newActiveBackend != nil {
-						c.logger.Info("New active backend", lager.Data{"backend": newActiveBackend.AsJSON()})
-					}
-
-					activeBackend = newActiveBackend
-					for _, s := range c.backendSubscribers {
-						s <- activeBackend
-					}
-				}
+				c.recordBackends(backendHealthMap)
+				publish()
 
 			case <-stopChan:
+				for _, f := range unregister {
+					f()
+				}
 				return
 			}
 		}
@@ -99,6 +184,12 @@ func (c *ClusterMonitor) RegisterBackendSubscriber(newSubscriber chan<- *domain.
 	c.backendSubscribers = append(c.backendSubscribers, newSubscriber)
 }
 
+// OnHealthcheck calls f after each healthcheck of a backend, with its outcome
+// and how long it took. f is called concurrently for different backends.
+func (c *ClusterMonitor) OnHealthcheck(f func(backend *domain.Backend, healthy bool, latency time.Duration)) {
+	c.healthcheckHooks = append(c.healthcheckHooks, f)
+}
+
 func (c *ClusterMonitor) SetupCounters() *DecisionCounters {
 	counters := NewDecisionCounters()
 	logFreq := uint64(5)
@@ -137,6 +228,42 @@ func ChooseActiveBackend(backendHealths map[*domain.Backend]*BackendStatus, useL
 	}
 }
 
+// ChooseActiveBackendPreferring is ChooseActiveBackend restricted to the
+// healthy backends whose label key is value, if there are any.
+func ChooseActiveBackendPreferring(backendHealths map[*domain.Backend]*BackendStatus, useLowestIndex bool, key, value string) *domain.Backend {
+	if key != "" && value != "" {
+		preferred := make(map[*domain.Backend]*BackendStatus)
+		for backend, backendStatus := range backendHealths {
+			if backendStatus.Healthy && backend.Label(key) == value {
+				preferred[backend] = backendStatus
+			}
+		}
+
+		if len(preferred) > 0 {
+			return ChooseActiveBackend(preferred, useLowestIndex)
+		}
+	}
+
+	return ChooseActiveBackend(backendHealths, useLowestIndex)
+}
+
+func (c *ClusterMonitor) chooseActiveBackend(backendHealths map[*domain.Backend]*BackendStatus) *domain.Backend {
+	if c.avoidQuarantined {
+		unquarantined := make(map[*domain.Backend]*BackendStatus)
+		for backend, backendStatus := range backendHealths {
+			if !backend.Quarantined() {
+				unquarantined[backend] = backendStatus
+			}
+		}
+
+		if backend := ChooseActiveBackendPreferring(unquarantined, c.useLowestIndex, c.preferredLabel, c.preferredValue); backend != nil {
+			return backend
+		}
+	}
+
+	return ChooseActiveBackendPreferring(backendHealths, c.useLowestIndex, c.preferredLabel, c.preferredValue)
+}
+
 func (c *ClusterMonitor) determineStateFromBackend(backend *domain.Backend, shouldLog bool) (bool, *int) {
 	urls := backend.HealthcheckUrls(c.useTLSForAgent)
 
@@ -217,12 +344,21 @@ func (c *ClusterMonitor) QueryBackendHealth(backend *domain.Backend, healthMonit
 	shouldLog := healthMonitor.Counters.Should("log")
 	healthMonitor.Counters.IncrementCount("dial")
 
+	started := time.Now()
 	healthy, index := c.determineStateFromBackend(backend, shouldLog)
+	latency := time.Since(started)
 
 	if index != nil {
 		healthMonitor.Index = *index
 	}
 
+	// A backend whose circuit breaker opened stays unhealthy until it accepts
+	// a connection again, even when its galera-agent answers.
+	if healthy && !backend.ProbeCircuit(c.healthcheckTimeout) {
+		c.logger.Info("Healthcheck succeeded but circuit breaker is still open", lager.Data{"backend": backend.AsJSON()})
+		healthy = false
+	}
+
 	if healthy {
 		c.logger.Debug("Querying Backend: healthy", lager.Data{"backend": backend.AsJSON(), "healthMonitor": healthMonitor})
 		backend.SetHealthy()
@@ -234,4 +370,8 @@ func (c *ClusterMonitor) QueryBackendHealth(backend *domain.Backend, healthMonit
 		healthMonitor.Healthy = false
 		healthMonitor.Counters.IncrementCount("consecutiveUnhealthyChecks")
 	}
+
+	for _, f := range c.healthcheckHooks {
+		f(backend, healthy, latency)
+	}
 }
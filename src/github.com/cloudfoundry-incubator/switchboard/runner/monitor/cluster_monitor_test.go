@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"code.cloudfoundry.org/lager/v3"
@@ -17,6 +19,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
 
+	"github.com/cloudfoundry-incubator/switchboard/config"
 	"github.com/cloudfoundry-incubator/switchboard/domain"
 	"github.com/cloudfoundry-incubator/switchboard/runner/monitor"
 	"github.com/cloudfoundry-incubator/switchboard/runner/monitor/monitorfakes"
@@ -28,6 +31,7 @@ const eventuallyTimeout = 3 * time.Second
 var _ = Describe("ClusterMonitor", func() {
 	var (
 		backends                     []*domain.Backend
+		backendSet                   *domain.BackendSet
 		logger                       *lagertest.TestLogger
 		clusterMonitor               *monitor.ClusterMonitor
 		backend1, backend2, backend3 *domain.Backend
@@ -94,7 +98,8 @@ var _ = Describe("ClusterMonitor", func() {
 	})
 
 	JustBeforeEach(func() {
-		clusterMonitor = monitor.NewClusterMonitor(urlGetter, useTLSForAgent, backends, healthcheckTimeout, logger, useLowestIndex)
+		backendSet = domain.NewBackendSet(backends)
+		clusterMonitor = monitor.NewClusterMonitor(urlGetter, useTLSForAgent, backendSet, healthcheckTimeout, logger, useLowestIndex)
 		clusterMonitor.RegisterBackendSubscriber(subscriberA)
 		clusterMonitor.RegisterBackendSubscriber(subscriberB)
 	})
@@ -246,6 +251,102 @@ var _ = Describe("ClusterMonitor", func() {
 			})
 		})
 
+		It("publishes no backend when none is healthy to begin with", func() {
+			urlGetter.GetStub = func(url string) (*http.Response, error) {
+				return unhealthyResponse(0), nil
+			}
+
+			clusterMonitor.Monitor(stopMonitoringChan)
+
+			var published *domain.Backend
+			Eventually(subscriberA).Should(Receive(&published))
+			Expect(published).To(BeNil())
+			Consistently(subscriberA, 4*healthcheckTimeout).ShouldNot(Receive())
+		})
+
+		Context("when the active backend's circuit breaker opens", func() {
+			var mysqlUp atomic.Bool
+
+			BeforeEach(func() {
+				mysqlUp.Store(false)
+				for _, b := range backends {
+					b.EnableCircuitBreaker(1, time.Minute)
+				}
+				domain.Dialer = func(string, string) (net.Conn, error) {
+					if !mysqlUp.Load() {
+						return nil, errors.New("connection refused")
+					}
+					conn, _ := net.Pipe()
+					return conn, nil
+				}
+			})
+
+			AfterEach(func() {
+				domain.Dialer = net.Dial
+			})
+
+			It("publishes another backend while the agent is healthy but MySQL refuses connections", func() {
+				clusterMonitor.Monitor(stopMonitoringChan)
+
+				Eventually(subscriberA).Should(Receive(Equal(backend1)))
+
+				clientConn, _ := net.Pipe()
+				_, err := backend1.Bridge(clientConn)
+				Expect(err).To(HaveOccurred())
+
+				Eventually(subscriberA).Should(Receive(Equal(backend2)))
+				Consistently(subscriberA, 4*healthcheckTimeout).ShouldNot(Receive())
+				Expect(backend1.CircuitOpen()).To(BeTrue())
+				Expect(backend1.Healthy()).To(BeFalse())
+			})
+
+			It("publishes the backend again once a trial dial succeeds", func() {
+				clusterMonitor.Monitor(stopMonitoringChan)
+
+				Eventually(subscriberA).Should(Receive(Equal(backend1)))
+
+				clientConn, _ := net.Pipe()
+				_, err := backend1.Bridge(clientConn)
+				Expect(err).To(HaveOccurred())
+				Eventually(subscriberA).Should(Receive(Equal(backend2)))
+
+				mysqlUp.Store(true)
+				Eventually(subscriberA).Should(Receive(Equal(backend1)))
+				Expect(backend1.CircuitOpen()).To(BeFalse())
+			})
+		})
+
+		Context("when avoiding quarantined backends", func() {
+			JustBeforeEach(func() {
+				clusterMonitor.AvoidQuarantinedBackends()
+			})
+
+			BeforeEach(func() {
+				backend1.EnableFlapDamping(1, time.Minute, time.Hour, time.Hour)
+				backend1.SetUnhealthy()
+			})
+
+			It("publishes another backend until the quarantine is released", func() {
+				clusterMonitor.Monitor(stopMonitoringChan)
+
+				Eventually(subscriberA).Should(Receive(Equal(backend2)))
+
+				Expect(backend1.ReleaseQuarantine()).To(BeTrue())
+				Eventually(subscriberA).Should(Receive(Equal(backend1)))
+			})
+
+			It("publishes a quarantined backend when every healthy backend is quarantined", func() {
+				for _, b := range []*domain.Backend{backend2, backend3} {
+					b.EnableFlapDamping(1, time.Minute, time.Hour, time.Hour)
+					b.SetUnhealthy()
+				}
+
+				clusterMonitor.Monitor(stopMonitoringChan)
+
+				Eventually(subscriberA).Should(Receive(Equal(backend1)))
+			})
+		})
+
 		Context("when useLowestIndex is false", func() {
 			BeforeEach(func() {
 				useLowestIndex = false
@@ -270,6 +371,60 @@ var _ = Describe("ClusterMonitor", func() {
 				})
 			})
 		})
+
+		It("reports its state for diagnostics", func() {
+			clusterMonitor.Monitor(stopMonitoringChan)
+
+			Eventually(func() monitor.State {
+				state := clusterMonitor.State()
+				// Ignore counts that change with each healthcheck.
+				for i := range state.Backends {
+					state.Backends[i].Counters = nil
+				}
+				return state
+			}).Should(Equal(monitor.State{
+				ActiveBackend: "backend-1",
+				Backends: []monitor.BackendState{
+					{Name: "backend-1", Index: 0, Healthy: true},
+					{Name: "backend-2", Index: 1, Healthy: true},
+					{Name: "backend-3", Index: 2, Healthy: true},
+				},
+				Subscribers: []monitor.ChannelBacklog{
+					{Length: 1, Capacity: 100},
+					{Length: 1, Capacity: 100},
+				},
+			}))
+
+			Expect(clusterMonitor.State().Backends[0].Counters).To(HaveKeyWithValue("dial", BeNumerically(">", 0)))
+		})
+
+		Context("when backends join and leave the set", func() {
+			backendConfig := func(name, host string) config.Backend {
+				return config.Backend{Name: name, Host: host, Port: 1337, StatusPort: 1338, StatusEndpoint: "api/v1/status"}
+			}
+
+			It("publishes a backend from the current set", func() {
+				clusterMonitor.Monitor(stopMonitoringChan)
+
+				Eventually(subscriberA).Should(Receive(Equal(backend1)))
+
+				backendSet.Reconcile([]config.Backend{
+					backendConfig("backend-2", "10.10.2.2"),
+					backendConfig("backend-3", "10.10.3.2"),
+				}, logger, func(*domain.Backend) {})
+
+				Eventually(subscriberA).Should(Receive(Equal(backend2)))
+
+				added, _ := backendSet.Reconcile([]config.Backend{
+					backendConfig("backend-1", "10.10.1.2"),
+					backendConfig("backend-2", "10.10.2.2"),
+					backendConfig("backend-3", "10.10.3.2"),
+				}, logger, func(*domain.Backend) {})
+				Expect(added).To(HaveLen(1))
+
+				Eventually(subscriberA).Should(Receive(Equal(added[0])))
+			})
+		})
 	})
 
 	Describe("QueryBackendHealth", func() {
@@ -336,6 +491,29 @@ var _ = Describe("ClusterMonitor", func() {
 			Expect(backendStatus.Index).To(Equal(0))
 		})
 
+		It("reports each healthcheck with how long it took", func() {
+			urlGetter.GetStub = func(url string) (*http.Response, error) {
+				time.Sleep(20 * time.Millisecond)
+				return healthyResponse(0), nil
+			}
+
+			var (
+				checked   *domain.Backend
+				healthy   bool
+				latencies []time.Duration
+			)
+			clusterMonitor.OnHealthcheck(func(b *domain.Backend, h bool, latency time.Duration) {
+				checked, healthy = b, h
+				latencies = append(latencies, latency)
+			})
+
+			clusterMonitor.QueryBackendHealth(backend, backendStatus)
+			Expect(checked).To(Equal(backend))
+			Expect(healthy).To(BeTrue())
+			Expect(latencies).To(HaveLen(1))
+			Expect(latencies[0]).To(BeNumerically(">=", 20*time.Millisecond))
+		})
+
 		When("TLS is enabled for Galera Agent communication", func() {
 			BeforeEach(func() {
 				useTLSForAgent = true
@@ -726,6 +904,48 @@ var _ = Describe("ClusterMonitor", func() {
 			})
 		})
 	})
+
+	Describe("ChooseActiveBackendPreferring", func() {
+		var (
+			statuses                             map[*domain.Backend]*monitor.BackendStatus
+			backendAZ1, backendAZ2a, backendAZ2b *domain.Backend
+		)
+
+		newBackend := func(name, az string) *domain.Backend {
+			b := domain.NewBackend(name, "10.10.1.2", 1337, 1338, "healthcheck", logger)
+			b.SetLabels(map[string]string{"az": az})
+			return b
+		}
+
+		BeforeEach(func() {
+			backendAZ1 = newBackend("backend-az1", "z1")
+			backendAZ2a = newBackend("backend-az2a", "z2")
+			backendAZ2b = newBackend("backend-az2b", "z2")
+
+			statuses = map[*domain.Backend]*monitor.BackendStatus{
+				backendAZ1:  {Healthy: true, Index: 0},
+				backendAZ2a: {Healthy: true, Index: 1},
+				backendAZ2b: {Healthy: true, Index: 2},
+			}
+		})
+
+		It("chooses among the healthy backends in the preferred zone", func() {
+			Expect(monitor.ChooseActiveBackendPreferring(statuses, true, "az", "z2")).To(Equal(backendAZ2a))
+			Expect(monitor.ChooseActiveBackendPreferring(statuses, false, "az", "z2")).To(Equal(backendAZ2b))
+		})
+
+		It("falls back to other zones when no backend in the preferred zone is healthy", func() {
+			statuses[backendAZ2a].Healthy = false
+			statuses[backendAZ2b].Healthy = false
+
+			Expect(monitor.ChooseActiveBackendPreferring(statuses, false, "az", "z2")).To(Equal(backendAZ1))
+		})
+
+		It("behaves like ChooseActiveBackend without a matching preference", func() {
+			Expect(monitor.ChooseActiveBackendPreferring(statuses, true, "", "")).To(Equal(backendAZ1))
+			Expect(monitor.ChooseActiveBackendPreferring(statuses, true, "az", "z3")).To(Equal(backendAZ1))
+		})
+	})
 })
 
 func healthyResponse(index int) *http.Response {
@@ -16,6 +16,15 @@ func (c *DecisionCounters) GetCount(name string) uint64 {
 	return c.counters[name]
 }
 
+// Counts returns a copy of every count.
+func (c *DecisionCounters) Counts() map[string]uint64 {
+	counts := make(map[string]uint64, len(c.counters))
+	for name, count := range c.counters {
+		counts[name] = count
+	}
+	return counts
+}
+
 func (c *DecisionCounters) IncrementCount(name string) {
 	c.counters[name] += 1
 }
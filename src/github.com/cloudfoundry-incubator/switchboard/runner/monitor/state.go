@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+// State is what a monitor knew about its backends after its last
+// healthcheck, for diagnosing the proxy at runtime.
+type State struct {
+	ActiveBackend string         `json:"activeBackend"`
+	Backends      []BackendState `json:"backends"`
+	// Publishing is true while the monitor waits for its subscribers to
+	// receive a new active backend. It stays true while a subscriber is
+	// stuck.
+	Publishing  bool             `json:"publishing"`
+	Subscribers []ChannelBacklog `json:"subscribers"`
+}
+
+// BackendState is the BackendStatus of a backend.
+type BackendState struct {
+	Name     string            `json:"name"`
+	Index    int               `json:"index"`
+	Healthy  bool              `json:"healthy"`
+	Counters map[string]uint64 `json:"counters"`
+}
+
+// ChannelBacklog is how many values wait in a channel, out of how many it
+// buffers.
+type ChannelBacklog struct {
+	Length   int `json:"length"`
+	Capacity int `json:"capacity"`
+}
+
+type monitorState struct {
+	mutex         sync.Mutex
+	activeBackend string
+	backends      []BackendState
+	publishing    bool
+}
+
+// State returns the monitor's view of its backends, sorted by name.
+func (c *ClusterMonitor) State() State {
+	c.state.mutex.Lock()
+	defer c.state.mutex.Unlock()
+
+	state := State{
+		ActiveBackend: c.state.activeBackend,
+		Backends:      append([]BackendState(nil), c.state.backends...),
+		Publishing:    c.state.publishing,
+	}
+	for _, s := range c.backendSubscribers {
+		state.Subscribers = append(state.Subscribers, ChannelBacklog{Length: len(s), Capacity: cap(s)})
+	}
+	return state
+}
+
+// recordBackends copies the health map, which only the monitor's goroutine
+// may read, for State.
+func (c *ClusterMonitor) recordBackends(backendHealthMap map[*domain.Backend]*BackendStatus) {
+	backends := make([]BackendState, 0, len(backendHealthMap))
+	for backend, status := range backendHealthMap {
+		backends = append(backends, BackendState{
+			Name:     backend.AsJSON().Name,
+			Index:    status.Index,
+			Healthy:  status.Healthy,
+			Counters: status.Counters.Counts(),
+		})
+	}
+	sort.Slice(backends, func(i, j int) bool { return backends[i].Name < backends[j].Name })
+
+	c.state.mutex.Lock()
+	defer c.state.mutex.Unlock()
+	c.state.backends = backends
+}
+
+func (c *ClusterMonitor) recordPublishing(activeBackend *domain.Backend, publishing bool) {
+	c.state.mutex.Lock()
+	defer c.state.mutex.Unlock()
+
+	c.state.activeBackend = ""
+	if activeBackend != nil {
+		c.state.activeBackend = activeBackend.AsJSON().Name
+	}
+	c.state.publishing = publishing
+}
@@ -14,7 +14,7 @@ import (
 
 type StatusLogger struct {
 	logger      lager.Logger
-	backends    []*domain.Backend
+	backends    *domain.BackendSet
 	monitor     *monitor.ClusterMonitor
 	interval    time.Duration
 	backendChan chan *domain.Backend
@@ -28,7 +28,7 @@ type StatusLogger struct {
 }
 
 func NewStatusLogger(
-	backends []*domain.Backend,
+	backends *domain.BackendSet,
 	monitor *monitor.ClusterMonitor,
 	interval time.Duration,
 	logger lager.Logger,
@@ -51,7 +51,7 @@ func (s *StatusLogger) ActiveBackendChan() chan *domain.Backend {
 func (s *StatusLogger) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 	s.logger.Info("Status logger starting", lager.Data{
 		"interval":      s.interval.String(),
-		"backend_count": len(s.backends),
+		"backend_count": len(s.backends.All()),
 	})
 
 	done := make(chan struct{})
@@ -112,7 +112,8 @@ func (s *StatusLogger) logStatus() {
 		unhealthyBackends []string
 	)
 
-	for _, backend := range s.backends {
+	backends := s.backends.All()
+	for _, backend := range backends {
 		backendJSON := backend.AsJSON()
 		totalConnections += backendJSON.CurrentSessionCount
 
@@ -127,7 +128,7 @@ func (s *StatusLogger) logStatus() {
 	logData := lager.Data{
 		"total_connections": totalConnections,
 		"healthy_backends":  healthyCount,
-		"total_backends":    len(s.backends),
+		"total_backends":    len(backends),
 	}
 
 	// Add active backend info
@@ -54,14 +54,14 @@ var _ = Describe("StatusLogger", func() {
 		clusterMonitor = monitor.NewClusterMonitor(
 			nil, // client
 			false,
-			backends,
+			domain.NewBackendSet(backends),
 			1*time.Second,
 			logger.Session("cluster-monitor"),
 			true,
 		)
 
 		statusLogRunner = statuslogger.NewStatusLogger(
-			backends,
+			domain.NewBackendSet(backends),
 			clusterMonitor,
 			logInterval,
 			logger.Session("status-logger"),
@@ -146,7 +146,7 @@ var _ = Describe("StatusLogger", func() {
 			// if it tries to send during the brief shutdown window.
 			// This test verifies the channel is buffered.
 			testLogger := statuslogger.NewStatusLogger(
-				backends,
+				domain.NewBackendSet(backends),
 				clusterMonitor,
 				logInterval,
 				logger.Session("status-logger-buffer"),
@@ -159,7 +159,7 @@ var _ = Describe("StatusLogger", func() {
 
 		It("ensures backend listener goroutine exits before Run returns", func() {
 			testLogger := statuslogger.NewStatusLogger(
-				backends,
+				domain.NewBackendSet(backends),
 				clusterMonitor,
 				logInterval,
 				logger.Session("status-logger-cleanup"),
@@ -223,7 +223,7 @@ var _ = Describe("StatusLogger", func() {
 		It("updates active backend when notified via channel", func() {
 			// Initialize a fresh status logger
 			testLogger := statuslogger.NewStatusLogger(
-				backends,
+				domain.NewBackendSet(backends),
 				clusterMonitor,
 				logInterval,
 				logger.Session("status-logger-2"),
@@ -248,7 +248,7 @@ var _ = Describe("StatusLogger", func() {
 		It("logs failover information when backend changes", func() {
 			// Initialize a fresh status logger
 			testLogger := statuslogger.NewStatusLogger(
-				backends,
+				domain.NewBackendSet(backends),
 				clusterMonitor,
 				logInterval,
 				logger.Session("status-logger-failover"),
@@ -288,7 +288,7 @@ var _ = Describe("StatusLogger", func() {
 		It("continues to log failover info indefinitely after it occurs", func() {
 			// Initialize a fresh status logger
 			testLogger := statuslogger.NewStatusLogger(
-				backends,
+				domain.NewBackendSet(backends),
 				clusterMonitor,
 				logInterval,
 				logger.Session("status-logger-persistent"),
@@ -0,0 +1,162 @@
+package sourcefilter
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// Rules restrict the client addresses a listener accepts. A client matching
+// any Deny entry is rejected. When Allow is not empty, a client must also
+// match one of its entries. Entries are CIDRs or single IP addresses.
+type Rules struct {
+	Allow []string
+	Deny  []string
+}
+
+type compiledRules struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// Filter applies Rules to client connections. Its rules can be replaced while
+// it is in use.
+type Filter struct {
+	mutex    sync.RWMutex
+	rules    compiledRules
+	rejected atomic.Uint64
+}
+
+func New(rules Rules) (*Filter, error) {
+	f := &Filter{}
+	if err := f.Update(rules); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Update replaces the rules. The existing rules are kept if any entry is
+// invalid.
+func (f *Filter) Update(rules Rules) error {
+	compiled, err := compile(rules)
+	if err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.rules = compiled
+	return nil
+}
+
+// Allows reports whether a client at addr may connect, and counts it as
+// rejected if not.
+func (f *Filter) Allows(addr net.Addr) bool {
+	ip := ipOf(addr)
+
+	f.mutex.RLock()
+	allowed := f.rules.allows(ip)
+	f.mutex.RUnlock()
+
+	if !allowed {
+		f.rejected.Add(1)
+	}
+	return allowed
+}
+
+// Rejected returns the number of connections rejected by the filter.
+func (f *Filter) Rejected() uint64 {
+	return f.rejected.Load()
+}
+
+// Validate reports whether every entry in rules is a valid CIDR or IP address.
+func Validate(rules Rules) error {
+	_, err := compile(rules)
+	return err
+}
+
+func (r compiledRules) allows(ip net.IP) bool {
+	if ip == nil {
+		return len(r.allow) == 0 && len(r.deny) == 0
+	}
+
+	for _, n := range r.deny {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	if len(r.allow) == 0 {
+		return true
+	}
+
+	for _, n := range r.allow {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func compile(rules Rules) (compiledRules, error) {
+	var (
+		compiled compiledRules
+		err      error
+	)
+
+	if compiled.allow, err = parseAll(rules.Allow); err != nil {
+		return compiledRules{}, fmt.Errorf("parsing allow rules: %w", err)
+	}
+	if compiled.deny, err = parseAll(rules.Deny); err != nil {
+		return compiledRules{}, fmt.Errorf("parsing deny rules: %w", err)
+	}
+	return compiled, nil
+}
+
+func parseAll(entries []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range entries {
+		n, err := parse(entry)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+func parse(entry string) (*net.IPNet, error) {
+	if _, n, err := net.ParseCIDR(entry); err == nil {
+		return n, nil
+	}
+
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil, fmt.Errorf("%q is not a CIDR or IP address", entry)
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// ipOf returns the IP address of a client. Clients of a Unix domain socket
+// are on this host, so they match rules as loopback clients do.
+func ipOf(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP
+	case *net.UnixAddr:
+		return net.IPv4(127, 0, 0, 1)
+	case nil:
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		host = addr.String()
+	}
+	return net.ParseIP(host)
+}
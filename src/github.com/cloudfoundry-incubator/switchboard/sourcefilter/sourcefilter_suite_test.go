@@ -0,0 +1,13 @@
+package sourcefilter_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSourceFilter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Source Filter Suite")
+}
@@ -0,0 +1,98 @@
+package sourcefilter_test
+
+import (
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/sourcefilter"
+)
+
+var _ = Describe("Filter", func() {
+	addr := func(ip string) net.Addr {
+		return &net.TCPAddr{IP: net.ParseIP(ip), Port: 51234}
+	}
+
+	It("allows every client when there are no rules", func() {
+		filter, err := sourcefilter.New(sourcefilter.Rules{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filter.Allows(addr("10.0.0.1"))).To(BeTrue())
+		Expect(filter.Allows(addr("::1"))).To(BeTrue())
+		Expect(filter.Rejected()).To(BeZero())
+	})
+
+	It("rejects clients matching a deny rule", func() {
+		filter, err := sourcefilter.New(sourcefilter.Rules{Deny: []string{"10.1.0.0/16", "192.168.0.7"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filter.Allows(addr("10.1.2.3"))).To(BeFalse())
+		Expect(filter.Allows(addr("192.168.0.7"))).To(BeFalse())
+		Expect(filter.Allows(addr("192.168.0.8"))).To(BeTrue())
+		Expect(filter.Rejected()).To(Equal(uint64(2)))
+	})
+
+	It("only allows clients matching an allow rule", func() {
+		filter, err := sourcefilter.New(sourcefilter.Rules{Allow: []string{"10.0.0.0/8", "fd00::/8"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filter.Allows(addr("10.9.8.7"))).To(BeTrue())
+		Expect(filter.Allows(addr("fd00::1"))).To(BeTrue())
+		Expect(filter.Allows(addr("172.16.0.1"))).To(BeFalse())
+	})
+
+	It("gives deny rules precedence over allow rules", func() {
+		filter, err := sourcefilter.New(sourcefilter.Rules{
+			Allow: []string{"10.0.0.0/8"},
+			Deny:  []string{"10.1.0.0/16"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filter.Allows(addr("10.2.0.1"))).To(BeTrue())
+		Expect(filter.Allows(addr("10.1.0.1"))).To(BeFalse())
+	})
+
+	It("matches IPv4 clients connecting over IPv6 sockets", func() {
+		filter, err := sourcefilter.New(sourcefilter.Rules{Deny: []string{"10.1.0.0/16"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filter.Allows(addr("::ffff:10.1.0.1"))).To(BeFalse())
+	})
+
+	It("matches Unix domain socket clients as loopback clients", func() {
+		filter, err := sourcefilter.New(sourcefilter.Rules{Allow: []string{"127.0.0.0/8"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filter.Allows(&net.UnixAddr{Net: "unix"})).To(BeTrue())
+	})
+
+	Describe("Update", func() {
+		It("replaces the rules", func() {
+			filter, err := sourcefilter.New(sourcefilter.Rules{Deny: []string{"10.1.0.0/16"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filter.Update(sourcefilter.Rules{Deny: []string{"10.2.0.0/16"}})).To(Succeed())
+
+			Expect(filter.Allows(addr("10.1.0.1"))).To(BeTrue())
+			Expect(filter.Allows(addr("10.2.0.1"))).To(BeFalse())
+		})
+
+		It("keeps the existing rules when the new rules are invalid", func() {
+			filter, err := sourcefilter.New(sourcefilter.Rules{Deny: []string{"10.1.0.0/16"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = filter.Update(sourcefilter.Rules{Deny: []string{"10.2.0.0/33"}})
+			Expect(err).To(MatchError(ContainSubstring("parsing deny rules")))
+
+			Expect(filter.Allows(addr("10.1.0.1"))).To(BeFalse())
+		})
+	})
+
+	It("rejects invalid entries", func() {
+		_, err := sourcefilter.New(sourcefilter.Rules{Allow: []string{"not-an-ip"}})
+		Expect(err).To(MatchError(ContainSubstring(`"not-an-ip" is not a CIDR or IP address`)))
+
+		Expect(sourcefilter.Validate(sourcefilter.Rules{Deny: []string{"10.0.0.0/8"}})).To(Succeed())
+	})
+})
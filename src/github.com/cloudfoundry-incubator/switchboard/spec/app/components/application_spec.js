@@ -45,6 +45,26 @@ describe('Application', function() {
     expect(request.url).toEqual('/v0/backends');
   });
 
+  it('requests the history', function() {
+    var urls = jasmine.Ajax.requests.filter(/\/v1\/history\//).map(r => r.url);
+    expect(urls).toEqual(['/v1/history/sessions', '/v1/history/failovers', '/v1/history/healthchecks']);
+  });
+
+  describe('when the history is received', function() {
+    beforeEach(function() {
+      var failovers = [{"time": "2026-10-19T12:00:05Z", "from": "backend - 1", "to": "backend - 2"}];
+      jasmine.Ajax.requests.filter('/v1/history/failovers')[0].respondWith({
+        status: 200,
+        responseText: JSON.stringify(failovers)
+      });
+    });
+
+    it('renders it', function() {
+      expect(subject.state.failovers.length).toEqual(1);
+      expect('table:eq(2) tbody').toContainText('backend - 2');
+    });
+  });
+
 
   describe('when some of the backends are unhealthy', function() {
     beforeEach(function() {
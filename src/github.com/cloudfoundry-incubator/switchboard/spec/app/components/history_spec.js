@@ -0,0 +1,64 @@
+require('../spec_helper');
+
+describe('History', function() {
+  var History, sessions, failovers, healthchecks;
+  beforeEach(function() {
+    History = require('../../../app/components/history');
+    sessions = [
+      {"time": "2026-10-19T12:00:00Z", "sessions": {"backend - 1": 2, "backend - 2": 0}},
+      {"time": "2026-10-19T12:00:10Z", "sessions": {"backend - 1": 0, "backend - 2": 3}}
+    ];
+    failovers = [
+      {"time": "2026-10-19T12:00:05Z", "from": "backend - 1", "to": "backend - 2"},
+      {"time": "2026-10-19T12:00:08Z", "from": "backend - 2"}
+    ];
+    healthchecks = {
+      "backend - 2": [{"time": "2026-10-19T12:00:10Z", "healthy": true, "latencyMillis": 2.25}],
+      "backend - 1": []
+    };
+  });
+
+  afterEach(function() {
+    React.unmountComponentAtNode(root);
+  });
+
+  describe('with history', function() {
+    beforeEach(function() {
+      React.render(<History sessions={sessions} failovers={failovers} healthchecks={healthchecks}/>, root);
+    });
+
+    it('renders the backends in sorted order by name', function() {
+      expect($('table:eq(0) tbody tr').map(function() { return $('td:eq(0)', this).text()}).toArray()).toEqual([
+        'backend - 1',
+        'backend - 2'
+      ]);
+    });
+
+    it('draws the sessions and the health check latency of each backend', function() {
+      expect($('table:eq(0) tbody tr:eq(0) svg.sparkline').length).toEqual(2);
+      expect($('table:eq(0) tbody tr:eq(1) td:eq(1) polyline').attr('points')).toEqual('0,32 240,0');
+    });
+
+    it('renders the latency of the last health check', function() {
+      expect($('table:eq(0) tbody tr:eq(0) td:eq(3)').text()).toEqual('-');
+      expect($('table:eq(0) tbody tr:eq(1) td:eq(3)').text()).toEqual('2.3 ms');
+    });
+
+    it('renders the failovers, latest first', function() {
+      expect($('table:eq(1) tbody tr').map(function() { return $('td:eq(1)', this).text() + ' > ' + $('td:eq(2)', this).text()}).toArray()).toEqual([
+        'backend - 2 > none',
+        'backend - 1 > backend - 2'
+      ]);
+    });
+  });
+
+  describe('without failovers', function() {
+    beforeEach(function() {
+      React.render(<History sessions={[]} failovers={[]} healthchecks={{}}/>, root);
+    });
+
+    it('says so', function() {
+      expect('table:eq(1) tbody').toContainText('No failovers recorded.');
+    });
+  });
+});
@@ -0,0 +1,23 @@
+// Package switchboard holds the dashboard assets built into the proxy.
+package switchboard
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// The assets are built into static by the gulp assets task, which clears the
+// directory first, so they are embedded from here rather than from a package
+// inside it. Source maps are left out.
+//
+//go:embed static/index.html static/*.css static/*.js static/*.png static/fonts static/images
+var assets embed.FS
+
+// Static returns the dashboard assets, rooted at the static directory.
+func Static() fs.FS {
+	static, err := fs.Sub(assets, "static")
+	if err != nil {
+		panic(err)
+	}
+	return static
+}
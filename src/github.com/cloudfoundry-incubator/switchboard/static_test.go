@@ -0,0 +1,29 @@
+package switchboard_test
+
+import (
+	"io/fs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard"
+)
+
+var _ = Describe("Static", func() {
+	It("holds the dashboard and the assets it loads", func() {
+		assets := switchboard.Static()
+		for _, name := range []string{"index.html", "application.js", "application.css", "pivotal-ui.min.css", "switchboard-header-logo.png"} {
+			_, err := fs.Stat(assets, name)
+			Expect(err).NotTo(HaveOccurred(), name)
+		}
+
+		fonts, err := fs.ReadDir(assets, "fonts")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fonts).NotTo(BeEmpty())
+	})
+
+	It("leaves out source maps", func() {
+		_, err := fs.Stat(switchboard.Static(), "application.js.map")
+		Expect(err).To(MatchError(fs.ErrNotExist))
+	})
+})
@@ -0,0 +1,13 @@
+package switchboard_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSwitchboard(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Switchboard Suite")
+}
@@ -0,0 +1,114 @@
+// Package userquota limits the concurrent sessions of each MySQL user.
+package userquota
+
+import (
+	"sync"
+)
+
+// Limits caps the concurrent sessions of MySQL users. Users sets the cap of
+// single users; every other user is capped at DefaultMaxSessions. A zero cap
+// leaves the user unlimited.
+type Limits struct {
+	DefaultMaxSessions int
+	Users              map[string]int
+}
+
+func (l Limits) limit(user string) int {
+	if limit, ok := l.Users[user]; ok {
+		return limit
+	}
+	return l.DefaultMaxSessions
+}
+
+// Usage is the current sessions of a user, and how many of its connections
+// were rejected for exceeding its quota.
+type Usage struct {
+	Sessions int
+	Rejected uint64
+}
+
+// Quotas counts the sessions of each user against its Limits. It is shared
+// by every listener the quotas apply to.
+type Quotas struct {
+	limits Limits
+
+	mutex sync.Mutex
+	users map[string]*Usage
+	// uninspected counts the sessions whose user is unknown because they
+	// upgraded to TLS before authenticating.
+	uninspected int
+}
+
+func New(limits Limits) *Quotas {
+	return &Quotas{
+		limits: limits,
+		users:  map[string]*Usage{},
+	}
+}
+
+// Acquire counts a new session of user if the user is within its quota. If
+// it is, release must be called once the session ends.
+func (q *Quotas) Acquire(user string) (release func(), ok bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	usage, found := q.users[user]
+	if !found {
+		usage = &Usage{}
+		q.users[user] = usage
+	}
+
+	if limit := q.limits.limit(user); limit > 0 && usage.Sessions >= limit {
+		usage.Rejected++
+		return nil, false
+	}
+
+	usage.Sessions++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mutex.Lock()
+			defer q.mutex.Unlock()
+			usage.Sessions--
+		})
+	}, true
+}
+
+// AcquireUninspected counts a session that escapes the quotas because its
+// user cannot be read, such as a TLS session or one whose handshake
+// response cannot be parsed. release must be called once the session ends.
+func (q *Quotas) AcquireUninspected() (release func()) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.uninspected++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mutex.Lock()
+			defer q.mutex.Unlock()
+			q.uninspected--
+		})
+	}
+}
+
+// Uninspected returns the current sessions counted by AcquireUninspected.
+func (q *Quotas) Uninspected() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.uninspected
+}
+
+// Usage returns the usage of every user that has connected.
+func (q *Quotas) Usage() map[string]Usage {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	usage := make(map[string]Usage, len(q.users))
+	for user, u := range q.users {
+		usage[user] = *u
+	}
+	return usage
+}
@@ -0,0 +1,13 @@
+package userquota_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestUserQuota(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "User Quota Suite")
+}
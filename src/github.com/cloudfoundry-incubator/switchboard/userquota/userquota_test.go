@@ -0,0 +1,82 @@
+package userquota_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry-incubator/switchboard/userquota"
+)
+
+var _ = Describe("Quotas", func() {
+	It("rejects sessions of a user over the default quota", func() {
+		quotas := userquota.New(userquota.Limits{DefaultMaxSessions: 2})
+
+		_, ok := quotas.Acquire("app")
+		Expect(ok).To(BeTrue())
+		_, ok = quotas.Acquire("app")
+		Expect(ok).To(BeTrue())
+		_, ok = quotas.Acquire("app")
+		Expect(ok).To(BeFalse())
+
+		_, ok = quotas.Acquire("other")
+		Expect(ok).To(BeTrue())
+
+		Expect(quotas.Usage()).To(Equal(map[string]userquota.Usage{
+			"app":   {Sessions: 2, Rejected: 1},
+			"other": {Sessions: 1},
+		}))
+	})
+
+	It("admits a user again once a session is released", func() {
+		quotas := userquota.New(userquota.Limits{DefaultMaxSessions: 1})
+
+		release, ok := quotas.Acquire("app")
+		Expect(ok).To(BeTrue())
+		release()
+		release()
+
+		_, ok = quotas.Acquire("app")
+		Expect(ok).To(BeTrue())
+		Expect(quotas.Usage()["app"].Sessions).To(Equal(1))
+	})
+
+	It("applies the quota of single users instead of the default", func() {
+		quotas := userquota.New(userquota.Limits{
+			DefaultMaxSessions: 1,
+			Users:              map[string]int{"reporting": 2, "admin": 0},
+		})
+
+		for i := 0; i < 2; i++ {
+			_, ok := quotas.Acquire("reporting")
+			Expect(ok).To(BeTrue())
+		}
+		_, ok := quotas.Acquire("reporting")
+		Expect(ok).To(BeFalse())
+
+		for i := 0; i < 10; i++ {
+			_, ok := quotas.Acquire("admin")
+			Expect(ok).To(BeTrue())
+		}
+	})
+
+	It("counts users without a quota", func() {
+		quotas := userquota.New(userquota.Limits{Users: map[string]int{"reporting": 1}})
+
+		_, ok := quotas.Acquire("app")
+		Expect(ok).To(BeTrue())
+		Expect(quotas.Usage()).To(HaveKeyWithValue("app", userquota.Usage{Sessions: 1}))
+	})
+
+	It("counts uninspected sessions apart from every user", func() {
+		quotas := userquota.New(userquota.Limits{DefaultMaxSessions: 1})
+
+		release := quotas.AcquireUninspected()
+		quotas.AcquireUninspected()
+		Expect(quotas.Uninspected()).To(Equal(2))
+		Expect(quotas.Usage()).To(BeEmpty())
+
+		release()
+		release()
+		Expect(quotas.Uninspected()).To(Equal(1))
+	})
+})
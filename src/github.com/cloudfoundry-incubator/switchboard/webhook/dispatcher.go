@@ -0,0 +1,227 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/switchboard/config"
+)
+
+// The headers of each webhook request besides its JSON body.
+const (
+	HeaderEvent     = "X-Switchboard-Event"
+	HeaderDelivery  = "X-Switchboard-Delivery"
+	HeaderSignature = "X-Switchboard-Signature"
+)
+
+// maxRetryAfter bounds the backoff between attempts to deliver an event.
+const maxRetryAfter = 30 * time.Second
+
+// Dispatcher delivers events to each webhook URL in the order they were
+// notified. Each URL has a queue and a goroutine of its own, so a slow or
+// failing receiver delays neither the callers of Notify nor the other
+// receivers; once its queue is full, further events for it are dropped.
+type Dispatcher struct {
+	client      *http.Client
+	receivers   []*receiver
+	secret      []byte
+	maxAttempts int
+	retryAfter  time.Duration
+	logger      lager.Logger
+}
+
+type receiver struct {
+	url string
+	// host names the receiver in logs, as webhook URLs often hold a token.
+	host  string
+	queue chan delivery
+}
+
+type delivery struct {
+	id        string
+	eventType string
+	body      []byte
+}
+
+var _ ifrit.Runner = (*Dispatcher)(nil)
+var _ Notifier = (*Dispatcher)(nil)
+
+// NewDispatcher returns a dispatcher to the webhooks of c, which must have
+// been validated. Events can be notified before it runs.
+func NewDispatcher(c config.Webhooks, logger lager.Logger) *Dispatcher {
+	d := &Dispatcher{
+		client:      c.HTTPClient(),
+		secret:      []byte(c.Secret),
+		maxAttempts: c.Attempts(),
+		retryAfter:  c.RetryAfter(),
+		logger:      logger,
+	}
+
+	for _, u := range c.URLs {
+		host := u
+		if parsed, err := url.Parse(u); err == nil {
+			host = parsed.Scheme + "://" + parsed.Host
+		}
+		d.receivers = append(d.receivers, &receiver{
+			url:   u,
+			host:  host,
+			queue: make(chan delivery, c.Queue()),
+		})
+	}
+
+	return d
+}
+
+// Notify queues event for each receiver, setting its ID.
+func (d *Dispatcher) Notify(event Event) {
+	event.ID = newID()
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		d.logger.Error("webhook-marshal-failed", err, lager.Data{"event": event.Type})
+		return
+	}
+
+	dl := delivery{id: event.ID, eventType: event.Type, body: body}
+	for _, r := range d.receivers {
+		select {
+		case r.queue <- dl:
+		default:
+			d.logger.Error("webhook-queue-full", fmt.Errorf("dropped %s event %s", dl.eventType, dl.id), lager.Data{"receiver": r.host})
+		}
+	}
+}
+
+// Run delivers the queued events until signalled. An attempt in flight is
+// cancelled, and events still queued are dropped.
+func (d *Dispatcher) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	for _, r := range d.receivers {
+		wg.Add(1)
+		go func(r *receiver) {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case dl := <-r.queue:
+					d.deliver(ctx, r, dl)
+				}
+			}
+		}(r)
+	}
+
+	close(ready)
+
+	<-signals
+	cancel()
+	wg.Wait()
+	return nil
+}
+
+// deliver tries to post dl to r up to maxAttempts times, doubling the wait
+// after each failure that may be temporary.
+func (d *Dispatcher) deliver(ctx context.Context, r *receiver, dl delivery) {
+	retryAfter := d.retryAfter
+	data := lager.Data{"receiver": r.host, "event": dl.eventType, "id": dl.id}
+
+	for attempt := 1; ; attempt++ {
+		retry, err := d.post(ctx, r.url, dl)
+		if err == nil {
+			d.logger.Debug("webhook-delivered", data)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !retry || attempt >= d.maxAttempts {
+			d.logger.Error("webhook-delivery-failed", err, data, lager.Data{"attempts": attempt})
+			return
+		}
+		d.logger.Info("webhook-delivery-retrying", data, lager.Data{"attempts": attempt, "error": err.Error()})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryAfter):
+		}
+
+		retryAfter *= 2
+		if retryAfter > maxRetryAfter {
+			retryAfter = maxRetryAfter
+		}
+	}
+}
+
+// post sends dl to target once. It reports whether a failure is worth
+// retrying: receivers that cannot be reached, time out, are rate limiting or
+// fail with a server error may accept the event later, but other client
+// errors would be repeated.
+func (d *Dispatcher) post(ctx context.Context, target string, dl delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(dl.body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "switchboard")
+	req.Header.Set(HeaderEvent, dl.eventType)
+	req.Header.Set(HeaderDelivery, dl.id)
+	if len(d.secret) > 0 {
+		req.Header.Set(HeaderSignature, Sign(d.secret, dl.body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		// The error would otherwise log the URL.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook responded %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook responded %s", resp.Status)
+	}
+}
+
+// Sign returns the signature header of body: "sha256=" followed by the hex
+// HMAC-SHA256 of body keyed with secret. Receivers compute the same to check
+// that a request comes from a proxy that knows secret.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
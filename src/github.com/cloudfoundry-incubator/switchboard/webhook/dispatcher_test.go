@@ -0,0 +1,261 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/webhook"
+)
+
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver records the requests it gets and answers each with the next of
+// statuses, repeating the last one.
+func receiver(statuses ...int) (*httptest.Server, chan request) {
+	requests := make(chan request, 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		requests <- request{header: req.Header, body: body}
+
+		status := statuses[0]
+		if len(statuses) > 1 {
+			statuses = statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	return server, requests
+}
+
+var _ = Describe("Dispatcher", func() {
+	var (
+		logger     *lagertest.TestLogger
+		webhooks   config.Webhooks
+		dispatcher *webhook.Dispatcher
+		process    ifrit.Process
+		event      webhook.Event
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("webhook test")
+		webhooks = config.Webhooks{Secret: "hook-secret", RetryMillis: 10}
+		event = webhook.Event{
+			Type:    webhook.EventActiveBackendChanged,
+			Time:    time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+			Cluster: "orders",
+			From:    "backend-0",
+			To:      "backend-1",
+		}
+	})
+
+	JustBeforeEach(func() {
+		dispatcher = webhook.NewDispatcher(webhooks, logger)
+		process = ifrit.Invoke(dispatcher)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+	})
+
+	Context("with several receivers", func() {
+		var (
+			servers  []*httptest.Server
+			requests []chan request
+		)
+
+		BeforeEach(func() {
+			servers, requests = nil, nil
+			for i := 0; i < 2; i++ {
+				server, reqs := receiver(http.StatusNoContent)
+				servers = append(servers, server)
+				requests = append(requests, reqs)
+				webhooks.URLs = append(webhooks.URLs, server.URL+"/hooks/secret-token")
+			}
+		})
+
+		AfterEach(func() {
+			for _, server := range servers {
+				server.Close()
+			}
+		})
+
+		It("posts each event to every receiver, signed with the secret", func() {
+			dispatcher.Notify(event)
+
+			for _, reqs := range requests {
+				var req request
+				Eventually(reqs).Should(Receive(&req))
+
+				Expect(req.header.Get("Content-Type")).To(Equal("application/json"))
+				Expect(req.header.Get(webhook.HeaderEvent)).To(Equal(webhook.EventActiveBackendChanged))
+				Expect(req.header.Get(webhook.HeaderSignature)).To(Equal(webhook.Sign([]byte("hook-secret"), req.body)))
+
+				var received webhook.Event
+				Expect(json.Unmarshal(req.body, &received)).To(Succeed())
+				Expect(received.ID).NotTo(BeEmpty())
+				Expect(req.header.Get(webhook.HeaderDelivery)).To(Equal(received.ID))
+
+				received.ID = ""
+				Expect(received).To(Equal(event))
+			}
+		})
+
+		It("delivers events in the order they were notified", func() {
+			dispatcher.Notify(webhook.Event{Type: webhook.EventTrafficDisabled})
+			dispatcher.Notify(webhook.Event{Type: webhook.EventTrafficEnabled})
+
+			var first, second request
+			Eventually(requests[0]).Should(Receive(&first))
+			Eventually(requests[0]).Should(Receive(&second))
+			Expect(first.header.Get(webhook.HeaderEvent)).To(Equal(webhook.EventTrafficDisabled))
+			Expect(second.header.Get(webhook.HeaderEvent)).To(Equal(webhook.EventTrafficEnabled))
+		})
+
+		Context("without a secret", func() {
+			BeforeEach(func() {
+				webhooks.Secret = ""
+			})
+
+			It("does not sign the events", func() {
+				dispatcher.Notify(event)
+
+				var req request
+				Eventually(requests[0]).Should(Receive(&req))
+				Expect(req.header).NotTo(HaveKey(webhook.HeaderSignature))
+			})
+		})
+	})
+
+	Context("when the receiver fails", func() {
+		var (
+			server   *httptest.Server
+			requests chan request
+		)
+
+		respondWith := func(statuses ...int) {
+			server, requests = receiver(statuses...)
+			webhooks.URLs = []string{server.URL + "/hooks/secret-token"}
+			webhooks.MaxAttempts = 3
+		}
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		Context("with server errors that stop", func() {
+			BeforeEach(func() {
+				respondWith(http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+			})
+
+			It("retries the same event", func() {
+				dispatcher.Notify(event)
+
+				var ids []string
+				for i := 0; i < 3; i++ {
+					var req request
+					Eventually(requests).Should(Receive(&req))
+					ids = append(ids, req.header.Get(webhook.HeaderDelivery))
+				}
+				Expect(ids[1]).To(Equal(ids[0]))
+				Expect(ids[2]).To(Equal(ids[0]))
+				Consistently(requests, 100*time.Millisecond).ShouldNot(Receive())
+				Expect(logger).To(gbytes.Say("webhook-delivery-retrying"))
+			})
+		})
+
+		Context("with server errors that persist", func() {
+			BeforeEach(func() {
+				respondWith(http.StatusInternalServerError)
+			})
+
+			It("gives up after the maximum attempts", func() {
+				dispatcher.Notify(event)
+
+				Eventually(requests).Should(HaveLen(3))
+				Consistently(requests, 200*time.Millisecond).Should(HaveLen(3))
+				Eventually(logger).Should(gbytes.Say("webhook-delivery-failed"))
+			})
+
+			It("does not log the receiver's URL path", func() {
+				dispatcher.Notify(event)
+
+				Eventually(logger).Should(gbytes.Say("webhook-delivery-failed"))
+				Expect(string(logger.Buffer().Contents())).NotTo(ContainSubstring("secret-token"))
+			})
+		})
+
+		Context("with a client error", func() {
+			BeforeEach(func() {
+				respondWith(http.StatusBadRequest)
+			})
+
+			It("does not retry", func() {
+				dispatcher.Notify(event)
+
+				Eventually(requests).Should(Receive())
+				Consistently(requests, 200*time.Millisecond).ShouldNot(Receive())
+				Eventually(logger).Should(gbytes.Say("webhook-delivery-failed"))
+			})
+		})
+	})
+
+	Context("when a receiver is slow", func() {
+		var (
+			slow, fast   *httptest.Server
+			release      chan struct{}
+			fastRequests chan request
+		)
+
+		BeforeEach(func() {
+			release = make(chan struct{})
+			slow = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				select {
+				case <-release:
+				case <-req.Context().Done():
+				}
+			}))
+			fast, fastRequests = receiver(http.StatusOK)
+
+			webhooks.URLs = []string{slow.URL, fast.URL}
+			webhooks.QueueSize = 2
+			webhooks.TimeoutMillis = 60000
+		})
+
+		AfterEach(func() {
+			close(release)
+			slow.Close()
+			fast.Close()
+		})
+
+		It("neither blocks Notify nor the other receivers", func() {
+			for i := 0; i < 10; i++ {
+				dispatcher.Notify(event)
+				Eventually(fastRequests).Should(Receive())
+			}
+
+			Expect(logger).To(gbytes.Say("webhook-queue-full"))
+		})
+
+		It("stops without waiting for the receiver", func() {
+			dispatcher.Notify(event)
+			Eventually(fastRequests).Should(Receive())
+
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+		})
+	})
+})
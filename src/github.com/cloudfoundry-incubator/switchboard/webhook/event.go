@@ -0,0 +1,39 @@
+// Package webhook tells receivers outside the proxy, such as chat-ops and
+// incident tooling, about failovers and traffic changes as they happen.
+package webhook
+
+import "time"
+
+// The types of Event.
+const (
+	EventActiveBackendChanged = "active-backend-changed"
+	EventBackendUnhealthy     = "backend-unhealthy"
+	EventAllBackendsUnhealthy = "all-backends-unhealthy"
+	EventTrafficEnabled       = "traffic-enabled"
+	EventTrafficDisabled      = "traffic-disabled"
+)
+
+// Event is the body of a webhook request. ID is the same for every attempt
+// to deliver the event, so that receivers can discard duplicates.
+type Event struct {
+	ID      string    `json:"id"`
+	Type    string    `json:"type"`
+	Time    time.Time `json:"time"`
+	Cluster string    `json:"cluster"`
+	// From and To are the previous and new active backends of an
+	// active-backend-changed event, and From the last active backend of an
+	// all-backends-unhealthy one. They are empty when there was none.
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+	// Backend is the backend of a backend-unhealthy event.
+	Backend string `json:"backend,omitempty"`
+	// Message is the operator's reason for a traffic change.
+	Message string `json:"message,omitempty"`
+}
+
+// Notifier sends events to the webhooks. Notify must not block.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Notifier
+type Notifier interface {
+	Notify(event Event)
+}
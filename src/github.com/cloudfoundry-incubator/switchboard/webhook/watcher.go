@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+)
+
+// Watcher notifies the events of one cluster: it receives the active backend
+// from the cluster's active monitor, and is told of healthchecks and traffic
+// changes through RecordHealthcheck and RecordTraffic.
+type Watcher struct {
+	cluster  string
+	notifier Notifier
+
+	// ActiveBackendChan receives the active backend from a cluster monitor.
+	ActiveBackendChan chan *domain.Backend
+
+	mutex     sync.Mutex
+	unhealthy map[string]bool
+}
+
+var _ ifrit.Runner = (*Watcher)(nil)
+
+func NewWatcher(cluster string, notifier Notifier) *Watcher {
+	return &Watcher{
+		cluster:           cluster,
+		notifier:          notifier,
+		ActiveBackendChan: make(chan *domain.Backend),
+		unhealthy:         map[string]bool{},
+	}
+}
+
+// Run follows the active backend until signalled. The monitor's first
+// choice is not a change. The monitor publishes no backend once every
+// backend is unhealthy, which is also notified as such, including when no
+// backend is healthy to begin with.
+func (w *Watcher) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+
+	var (
+		active    *domain.Backend
+		published bool
+	)
+	for {
+		select {
+		case <-signals:
+			return nil
+		case backend := <-w.ActiveBackendChan:
+			previous, first := active, !published
+			active, published = backend, true
+			if first {
+				if backend == nil {
+					w.notify(Event{Type: EventAllBackendsUnhealthy})
+				}
+				continue
+			}
+			if previous == backend {
+				continue
+			}
+
+			w.notify(Event{Type: EventActiveBackendChanged, From: name(previous), To: name(backend)})
+			if backend == nil {
+				w.notify(Event{Type: EventAllBackendsUnhealthy, From: name(previous)})
+			}
+		}
+	}
+}
+
+// RecordHealthcheck notifies that backend became unhealthy when its
+// healthcheck fails for the first time since it last passed, or since the
+// proxy started.
+func (w *Watcher) RecordHealthcheck(backend *domain.Backend, healthy bool, _ time.Duration) {
+	backendName := name(backend)
+
+	w.mutex.Lock()
+	wasUnhealthy := w.unhealthy[backendName]
+	w.unhealthy[backendName] = !healthy
+	w.mutex.Unlock()
+
+	if !healthy && !wasUnhealthy {
+		w.notify(Event{Type: EventBackendUnhealthy, Backend: backendName})
+	}
+}
+
+// RecordTraffic notifies that traffic was enabled or disabled, with the
+// operator's message.
+func (w *Watcher) RecordTraffic(enabled bool, message string) {
+	eventType := EventTrafficDisabled
+	if enabled {
+		eventType = EventTrafficEnabled
+	}
+	w.notify(Event{Type: eventType, Message: message})
+}
+
+func (w *Watcher) notify(event Event) {
+	event.Time = time.Now().UTC()
+	event.Cluster = w.cluster
+	w.notifier.Notify(event)
+}
+
+func name(backend *domain.Backend) string {
+	if backend == nil {
+		return ""
+	}
+	return backend.AsJSON().Name
+}
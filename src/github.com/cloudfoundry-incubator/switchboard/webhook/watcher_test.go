@@ -0,0 +1,104 @@
+package webhook_test
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+
+	"github.com/cloudfoundry-incubator/switchboard/config"
+	"github.com/cloudfoundry-incubator/switchboard/domain"
+	"github.com/cloudfoundry-incubator/switchboard/webhook"
+	"github.com/cloudfoundry-incubator/switchboard/webhook/webhookfakes"
+)
+
+var _ = Describe("Watcher", func() {
+	var (
+		backends []*domain.Backend
+		notifier *webhookfakes.FakeNotifier
+		watcher  *webhook.Watcher
+		process  ifrit.Process
+	)
+
+	BeforeEach(func() {
+		backends = domain.NewBackends([]config.Backend{
+			{Name: "backend-0", Host: "127.0.0.1", Port: 3306, StatusPort: 9200},
+			{Name: "backend-1", Host: "127.0.0.2", Port: 3306, StatusPort: 9200},
+		}, lagertest.NewTestLogger("webhook test"))
+
+		notifier = &webhookfakes.FakeNotifier{}
+		watcher = webhook.NewWatcher("orders", notifier)
+		process = ifrit.Invoke(watcher)
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+	})
+
+	events := func() []webhook.Event {
+		var events []webhook.Event
+		for i := 0; i < notifier.NotifyCallCount(); i++ {
+			event := notifier.NotifyArgsForCall(i)
+			Expect(event.Cluster).To(Equal("orders"))
+			Expect(event.Time).To(BeTemporally("~", time.Now(), time.Minute))
+			event.Cluster, event.Time = "", time.Time{}
+			events = append(events, event)
+		}
+		return events
+	}
+
+	It("notifies changes of the active backend after the first one", func() {
+		watcher.ActiveBackendChan <- backends[0]
+		watcher.ActiveBackendChan <- backends[1]
+		watcher.ActiveBackendChan <- nil
+		watcher.ActiveBackendChan <- backends[0]
+
+		Eventually(notifier.NotifyCallCount).Should(Equal(4))
+		Expect(events()).To(Equal([]webhook.Event{
+			{Type: webhook.EventActiveBackendChanged, From: "backend-0", To: "backend-1"},
+			{Type: webhook.EventActiveBackendChanged, From: "backend-1"},
+			{Type: webhook.EventAllBackendsUnhealthy, From: "backend-1"},
+			{Type: webhook.EventActiveBackendChanged, To: "backend-0"},
+		}))
+	})
+
+	It("notifies that every backend is unhealthy when none is healthy to begin with", func() {
+		watcher.ActiveBackendChan <- nil
+		watcher.ActiveBackendChan <- backends[0]
+
+		Eventually(notifier.NotifyCallCount).Should(Equal(2))
+		Expect(events()).To(Equal([]webhook.Event{
+			{Type: webhook.EventAllBackendsUnhealthy},
+			{Type: webhook.EventActiveBackendChanged, To: "backend-0"},
+		}))
+	})
+
+	It("notifies once that a backend became unhealthy", func() {
+		watcher.RecordHealthcheck(backends[0], true, time.Millisecond)
+		watcher.RecordHealthcheck(backends[0], false, time.Millisecond)
+		watcher.RecordHealthcheck(backends[0], false, time.Millisecond)
+		watcher.RecordHealthcheck(backends[1], false, time.Millisecond)
+		watcher.RecordHealthcheck(backends[0], true, time.Millisecond)
+		watcher.RecordHealthcheck(backends[0], false, time.Millisecond)
+
+		Expect(events()).To(Equal([]webhook.Event{
+			{Type: webhook.EventBackendUnhealthy, Backend: "backend-0"},
+			{Type: webhook.EventBackendUnhealthy, Backend: "backend-1"},
+			{Type: webhook.EventBackendUnhealthy, Backend: "backend-0"},
+		}))
+	})
+
+	It("notifies traffic changes with their message", func() {
+		watcher.RecordTraffic(false, "maintenance")
+		watcher.RecordTraffic(true, "")
+
+		Expect(events()).To(Equal([]webhook.Event{
+			{Type: webhook.EventTrafficDisabled, Message: "maintenance"},
+			{Type: webhook.EventTrafficEnabled},
+		}))
+	})
+})